@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/nik-mLb/avito_task/config"
+	"github.com/nik-mLb/avito_task/internal/repository"
+	rolluprepo "github.com/nik-mLb/avito_task/internal/repository/rollup"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	rollupuc "github.com/nik-mLb/avito_task/internal/usecase/rollup"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `usage:
+  rollup backfill -from 2025-01-01 -to 2025-01-31   пересчитать агрегаты за диапазон дней
+  rollup check    -from 2025-01-01 -to 2025-01-31   сверить агрегаты с сырыми данными`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	fromStr := flags.String("from", "", "first day, YYYY-MM-DD")
+	toStr := flags.String("to", time.Now().Format(time.DateOnly), "last day, YYYY-MM-DD")
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("invalid flags: %v", err)
+	}
+
+	from, err := time.Parse(time.DateOnly, *fromStr)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	to, err := time.Parse(time.DateOnly, *toStr)
+	if err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("config error: %v", err)
+	}
+
+	dsn, err := repository.GetConnectionString(conf.DBConfig)
+	if err != nil {
+		log.Fatalf("failed to get connection string: %v", err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	logger := logrus.New()
+	ctx := logctx.WithLogger(context.Background(), logrus.NewEntry(logger))
+	uc := rollupuc.NewRollupUsecase(rolluprepo.NewRollupRepository(db), conf.RollupConfig.Lag)
+
+	switch command {
+	case "backfill":
+		count, err := uc.Backfill(ctx, from, to)
+		if err != nil {
+			log.Fatalf("backfill failed: %v", err)
+		}
+		log.Printf("backfilled %d pvz-days", count)
+	case "check":
+		mismatches, err := uc.Check(ctx, from, to)
+		if err != nil {
+			log.Fatalf("check failed: %v", err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, m := range mismatches {
+			if err := enc.Encode(m); err != nil {
+				log.Fatalf("failed to write mismatch: %v", err)
+			}
+		}
+		if len(mismatches) > 0 {
+			log.Printf("found %d mismatches, run backfill for the same range to fix them", len(mismatches))
+			os.Exit(1)
+		}
+		log.Println("rollups match raw data")
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -6,17 +6,62 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Mode режим работы приложения
+type Mode string
+
+const (
+	ModeDev  Mode = "dev"
+	ModeTest Mode = "test"
+	ModeProd Mode = "prod"
+)
+
+// AllowsDummyLogin сообщает, доступен ли вход без пароля через /dummyLogin
+func (m Mode) AllowsDummyLogin() bool {
+	return m == ModeDev || m == ModeTest
+}
+
+// AllowsDebug сообщает, доступны ли отладочные эндпоинты (/debug/pprof)
+func (m Mode) AllowsDebug() bool {
+	return m == ModeDev
+}
+
+// insecureJWTSignatures - секреты из примеров и тестов, которые нельзя использовать в prod
+var insecureJWTSignatures = map[string]bool{
+	"my_secret_key": true,
+	"test_secret":   true,
+	"secret":        true,
+}
+
+// minProdJWTSignatureLength - минимальная длина секрета HS256 в prod (256 бит)
+const minProdJWTSignatureLength = 32
+
 // Config сохраняет оригинальную структуру для совместимости
 type Config struct {
-	DBConfig         *DBConfig
-	ServerConfig     *ServerConfig
-	JWTConfig        *JWTConfig
-	MigrationsConfig *MigrationsConfig
+	Mode                Mode
+	DBConfig            *DBConfig
+	ServerConfig        *ServerConfig
+	JWTConfig           *JWTConfig
+	MigrationsConfig    *MigrationsConfig
+	ReceptionConfig     *ReceptionConfig
+	RollupConfig        *RollupConfig
+	EventsConfig        *EventsConfig
+	WebhookConfig       *WebhookConfig
+	OutboxConfig        *OutboxConfig
+	PasswordConfig      *PasswordConfig
+	LoginConfig         *LoginConfig
+	RateLimitConfig     *RateLimitConfig
+	MailConfig          *MailConfig
+	PasswordResetConfig *PasswordResetConfig
+	PermissionsConfig   *PermissionsConfig
+	RegistrationConfig  *RegistrationConfig
+	TwoFactorConfig     *TwoFactorConfig
+	TracingConfig       *TracingConfig
 }
 
 // Оригинальные структуры (оставляем без изменений)
@@ -35,15 +80,146 @@ type ServerConfig struct {
 	Port string
 }
 
+// JWTConfig настройки подписи токенов. Algorithm - HS256 (общий секрет Signature), RS256 или EdDSA.
+// Для RS256 и EdDSA ключи берутся из Keys: подписывает последний активный ключ,
+// а предыдущие продолжают проверять токены, пока не истекут выданные ими
 type JWTConfig struct {
 	Signature     string
 	TokenLifeSpan time.Duration
+	Algorithm     string
+	Keys          []JWTKey
+}
+
+// JWTKey закрытый ключ в PEM-файле Path. Ключ подписывает токены начиная с ActiveFrom
+type JWTKey struct {
+	ID         string
+	Path       string
+	ActiveFrom time.Time
 }
 
 type MigrationsConfig struct {
 	Path string
 }
 
+// ReceptionConfig настройки автоматического закрытия зависших приемок
+type ReceptionConfig struct {
+	AutoCloseIdleTimeout time.Duration
+	AutoCloseInterval    time.Duration
+	ReopenWindow         time.Duration
+}
+
+// RollupConfig настройки пересчета суточных агрегатов для отчетов
+type RollupConfig struct {
+	Interval time.Duration
+	Lag      time.Duration
+}
+
+// EventsConfig настройки живой ленты событий
+type EventsConfig struct {
+	BufferSize        int
+	HeartbeatInterval time.Duration
+}
+
+// WebhookConfig настройки доставки вебхуков
+type WebhookConfig struct {
+	DeliveryInterval time.Duration
+	Timeout          time.Duration
+	MaxAttempts      int
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	// AllowPrivateTargets разрешает доставку на loopback и адреса внутренней сети (для локальной разработки)
+	AllowPrivateTargets bool
+}
+
+// PasswordConfig политика паролей при регистрации
+type PasswordConfig struct {
+	MinLength      int
+	RequireLetter  bool
+	RequireDigit   bool
+	RequireUpper   bool
+	RequireSpecial bool
+}
+
+// LoginConfig защита входа от перебора паролей
+type LoginConfig struct {
+	MaxFailures     int
+	MaxIPFailures   int
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+	DelayBase       time.Duration
+	DelayMax        time.Duration
+}
+
+// MailConfig настройки отправки писем. Sender - file (письма сохраняются в Dir) или smtp
+type MailConfig struct {
+	Sender       string
+	From         string
+	Dir          string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// PasswordResetConfig настройки сброса пароля. URL - страница, на которую ведет ссылка из письма
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	URL      string
+}
+
+// PermissionsConfig права ролей: роль -> список прав. Пустой Roles означает права по умолчанию
+type PermissionsConfig struct {
+	Roles map[string][]string
+}
+
+// RegistrationConfig настройки регистрации. Без SelfRegistration учетные записи создаются только
+// по приглашениям администратора. InvitationURL - страница, на которую ведет ссылка из письма
+type RegistrationConfig struct {
+	SelfRegistration bool
+	InvitationTTL    time.Duration
+	InvitationURL    string
+}
+
+// TwoFactorConfig настройки TOTP. Для ролей из RequiredRoles второй фактор обязателен при входе.
+// Issuer - название сервиса в приложении-аутентификаторе, ChallengeTTL - время на второй шаг входа
+type TwoFactorConfig struct {
+	RequiredRoles []string
+	Issuer        string
+	ChallengeTTL  time.Duration
+}
+
+// TracingConfig настройки трассировки. Exporter - none (спаны не выгружаются), stdout или otlp.
+// Endpoint - адрес OTLP-коллектора (host:port, протокол HTTP)
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	ServiceName string
+}
+
+// RateLimit - не больше Requests запросов за Period. Нулевое значение отключает ограничение
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitConfig ограничения частоты запросов. Default и Roles действуют для пользователей с токеном,
+// Anonymous - для запросов без токена (по IP). Routes задаются по ключу "METHOD /path/{var}"
+// и действуют дополнительно к общему лимиту
+type RateLimitConfig struct {
+	Default   RateLimit
+	Anonymous RateLimit
+	Roles     map[string]RateLimit
+	Routes    map[string]RateLimit
+}
+
+// OutboxConfig настройки рассылки событий из outbox.
+// Sinks - дополнительные получатели помимо ленты и вебхуков: stdout, file
+type OutboxConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	Sinks     []string
+	FilePath  string
+}
+
 // NewConfig сохраняет оригинальную сигнатуру, но с улучшенной реализацией
 func NewConfig() (*Config, error) {
 	// Читаем конфиг из файла
@@ -71,22 +247,158 @@ func NewConfig() (*Config, error) {
 	jwtConfig := &JWTConfig{
 		Signature:     raw.JwtSignature,
 		TokenLifeSpan: raw.JwtTokenLife,
+		Algorithm:     raw.JwtAlgorithm,
+		Keys:          raw.JwtKeys,
 	}
 
 	migrationsConfig := &MigrationsConfig{
 		Path: raw.MigrationsPath,
 	}
 
+	receptionConfig := &ReceptionConfig{
+		AutoCloseIdleTimeout: raw.AutoCloseIdleTimeout,
+		AutoCloseInterval:    raw.AutoCloseInterval,
+		ReopenWindow:         raw.ReopenWindow,
+	}
+
+	rollupConfig := &RollupConfig{
+		Interval: raw.RollupInterval,
+		Lag:      raw.RollupLag,
+	}
+
+	eventsConfig := &EventsConfig{
+		BufferSize:        raw.EventsBufferSize,
+		HeartbeatInterval: raw.EventsHeartbeat,
+	}
+
+	webhookConfig := &WebhookConfig{
+		DeliveryInterval:    raw.WebhookInterval,
+		Timeout:             raw.WebhookTimeout,
+		MaxAttempts:         raw.WebhookMaxAttempts,
+		BackoffBase:         raw.WebhookBackoffBase,
+		BackoffMax:          raw.WebhookBackoffMax,
+		AllowPrivateTargets: raw.WebhookAllowPrivate,
+	}
+
+	outboxConfig := &OutboxConfig{
+		Interval:  raw.OutboxInterval,
+		BatchSize: raw.OutboxBatchSize,
+		Sinks:     raw.OutboxSinks,
+		FilePath:  raw.OutboxFilePath,
+	}
+
+	passwordConfig := &PasswordConfig{
+		MinLength:      raw.PasswordMinLength,
+		RequireLetter:  raw.PasswordRequireLetter,
+		RequireDigit:   raw.PasswordRequireDigit,
+		RequireUpper:   raw.PasswordRequireUpper,
+		RequireSpecial: raw.PasswordRequireSpecial,
+	}
+
+	loginConfig := &LoginConfig{
+		MaxFailures:     raw.LoginMaxFailures,
+		MaxIPFailures:   raw.LoginMaxIPFailures,
+		FailureWindow:   raw.LoginFailureWindow,
+		LockoutDuration: raw.LoginLockout,
+		DelayBase:       raw.LoginDelayBase,
+		DelayMax:        raw.LoginDelayMax,
+	}
+
+	rateLimitConfig := &RateLimitConfig{
+		Default:   raw.RateLimitDefault,
+		Anonymous: raw.RateLimitAnonymous,
+		Roles:     raw.RateLimitRoles,
+		Routes:    raw.RateLimitRoutes,
+	}
+
+	mailConfig := &MailConfig{
+		Sender:       raw.MailSender,
+		From:         raw.MailFrom,
+		Dir:          raw.MailDir,
+		SMTPAddr:     raw.SMTPAddr,
+		SMTPUsername: raw.SMTPUsername,
+		SMTPPassword: raw.SMTPPassword,
+	}
+
+	passwordResetConfig := &PasswordResetConfig{
+		TokenTTL: raw.PasswordResetTTL,
+		URL:      raw.PasswordResetURL,
+	}
+
+	permissionsConfig := &PermissionsConfig{
+		Roles: raw.RolePermissions,
+	}
+
+	registrationConfig := &RegistrationConfig{
+		SelfRegistration: raw.SelfRegistration,
+		InvitationTTL:    raw.InvitationTTL,
+		InvitationURL:    raw.InvitationURL,
+	}
+
+	twoFactorConfig := &TwoFactorConfig{
+		RequiredRoles: raw.TwoFactorRequiredRoles,
+		Issuer:        raw.TwoFactorIssuer,
+		ChallengeTTL:  raw.TwoFactorChallengeTTL,
+	}
+
+	tracingConfig := &TracingConfig{
+		Exporter:    raw.TracingExporter,
+		Endpoint:    raw.TracingEndpoint,
+		ServiceName: raw.TracingServiceName,
+	}
+
 	return &Config{
-		DBConfig:         dbConfig,
-		ServerConfig:     serverConfig,
-		JWTConfig:        jwtConfig,
-		MigrationsConfig: migrationsConfig,
+		Mode:                raw.Mode,
+		DBConfig:            dbConfig,
+		ServerConfig:        serverConfig,
+		JWTConfig:           jwtConfig,
+		MigrationsConfig:    migrationsConfig,
+		ReceptionConfig:     receptionConfig,
+		RollupConfig:        rollupConfig,
+		EventsConfig:        eventsConfig,
+		WebhookConfig:       webhookConfig,
+		OutboxConfig:        outboxConfig,
+		PasswordConfig:      passwordConfig,
+		LoginConfig:         loginConfig,
+		RateLimitConfig:     rateLimitConfig,
+		MailConfig:          mailConfig,
+		PasswordResetConfig: passwordResetConfig,
+		PermissionsConfig:   permissionsConfig,
+		RegistrationConfig:  registrationConfig,
+		TwoFactorConfig:     twoFactorConfig,
+		TracingConfig:       tracingConfig,
 	}, nil
 }
 
+// Validate проверяет режим работы и отказывается запускать prod с небезопасными настройками
+func (c *Config) Validate() error {
+	switch c.Mode {
+	case ModeDev, ModeTest:
+		return nil
+	case ModeProd:
+	default:
+		return fmt.Errorf("unknown APP_MODE value: %q", c.Mode)
+	}
+
+	var problems []error
+	if c.JWTConfig.Algorithm == "" || c.JWTConfig.Algorithm == "HS256" {
+		if insecureJWTSignatures[c.JWTConfig.Signature] || len(c.JWTConfig.Signature) < minProdJWTSignatureLength {
+			problems = append(problems, fmt.Errorf("JWT_SIGNATURE must be a random secret of at least %d bytes", minProdJWTSignatureLength))
+		}
+	}
+	if c.DBConfig.Password == "" || c.DBConfig.Password == "password" {
+		problems = append(problems, errors.New("POSTGRES_PASSWORD must not be empty or default"))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("insecure settings in prod mode: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
 // Внутренняя структура для парсинга YAML
 type yamlConfig struct {
+	Mode           Mode   `yaml:"APP_MODE"`
 	ServerPort     string `yaml:"SERVER_PORT"`
 	JwtSignature   string `yaml:"JWT_SIGNATURE"`
 	PostgresUser   string `yaml:"POSTGRES_USER"`
@@ -96,6 +408,58 @@ type yamlConfig struct {
 	PostgresHost   string `yaml:"POSTGRES_HOST"`
 	MigrationsPath string `yaml:"MIGRATIONS_PATH"`
 	JwtTokenLife   time.Duration `yaml:"JWT_TOKEN_LIFESPAN"`
+	JwtAlgorithm   string        `yaml:"JWT_ALGORITHM"`
+	JwtKeys        []JWTKey      `yaml:"JWT_KEYS"`
+	AutoCloseIdleTimeout time.Duration `yaml:"RECEPTION_AUTO_CLOSE_IDLE_TIMEOUT"`
+	AutoCloseInterval    time.Duration `yaml:"RECEPTION_AUTO_CLOSE_INTERVAL"`
+	ReopenWindow         time.Duration `yaml:"RECEPTION_REOPEN_WINDOW"`
+	RollupInterval       time.Duration `yaml:"ROLLUP_INTERVAL"`
+	RollupLag            time.Duration `yaml:"ROLLUP_LAG"`
+	EventsBufferSize     int           `yaml:"EVENTS_BUFFER_SIZE"`
+	EventsHeartbeat      time.Duration `yaml:"EVENTS_HEARTBEAT_INTERVAL"`
+	WebhookInterval      time.Duration `yaml:"WEBHOOK_DELIVERY_INTERVAL"`
+	WebhookTimeout       time.Duration `yaml:"WEBHOOK_TIMEOUT"`
+	WebhookMaxAttempts   int           `yaml:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoffBase   time.Duration `yaml:"WEBHOOK_BACKOFF_BASE"`
+	WebhookBackoffMax    time.Duration `yaml:"WEBHOOK_BACKOFF_MAX"`
+	WebhookAllowPrivate  bool          `yaml:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+	OutboxInterval       time.Duration `yaml:"OUTBOX_DISPATCH_INTERVAL"`
+	OutboxBatchSize      int           `yaml:"OUTBOX_BATCH_SIZE"`
+	OutboxSinks          []string      `yaml:"OUTBOX_SINKS"`
+	OutboxFilePath       string        `yaml:"OUTBOX_FILE_PATH"`
+	PasswordMinLength      int         `yaml:"PASSWORD_MIN_LENGTH"`
+	PasswordRequireLetter  bool        `yaml:"PASSWORD_REQUIRE_LETTER"`
+	PasswordRequireDigit   bool        `yaml:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireUpper   bool        `yaml:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireSpecial bool        `yaml:"PASSWORD_REQUIRE_SPECIAL"`
+	LoginMaxFailures       int           `yaml:"LOGIN_MAX_FAILURES"`
+	LoginMaxIPFailures     int           `yaml:"LOGIN_MAX_IP_FAILURES"`
+	LoginFailureWindow     time.Duration `yaml:"LOGIN_FAILURE_WINDOW"`
+	LoginLockout           time.Duration `yaml:"LOGIN_LOCKOUT_DURATION"`
+	LoginDelayBase         time.Duration `yaml:"LOGIN_DELAY_BASE"`
+	LoginDelayMax          time.Duration `yaml:"LOGIN_DELAY_MAX"`
+	RateLimitDefault       RateLimit            `yaml:"RATE_LIMIT_DEFAULT"`
+	RateLimitAnonymous     RateLimit            `yaml:"RATE_LIMIT_ANONYMOUS"`
+	RateLimitRoles         map[string]RateLimit `yaml:"RATE_LIMIT_ROLES"`
+	RateLimitRoutes        map[string]RateLimit `yaml:"RATE_LIMIT_ROUTES"`
+	MailSender             string        `yaml:"MAIL_SENDER"`
+	MailFrom               string        `yaml:"MAIL_FROM"`
+	MailDir                string        `yaml:"MAIL_DIR"`
+	SMTPAddr               string        `yaml:"SMTP_ADDR"`
+	SMTPUsername           string        `yaml:"SMTP_USERNAME"`
+	SMTPPassword           string        `yaml:"SMTP_PASSWORD"`
+	PasswordResetTTL       time.Duration `yaml:"PASSWORD_RESET_TTL"`
+	PasswordResetURL       string        `yaml:"PASSWORD_RESET_URL"`
+	RolePermissions        map[string][]string `yaml:"ROLE_PERMISSIONS"`
+	SelfRegistration       bool          `yaml:"SELF_REGISTRATION"`
+	InvitationTTL          time.Duration `yaml:"INVITATION_TTL"`
+	InvitationURL          string        `yaml:"INVITATION_URL"`
+	TwoFactorRequiredRoles []string      `yaml:"TWO_FACTOR_REQUIRED_ROLES"`
+	TwoFactorIssuer        string        `yaml:"TWO_FACTOR_ISSUER"`
+	TwoFactorChallengeTTL  time.Duration `yaml:"TWO_FACTOR_CHALLENGE_TTL"`
+	TracingExporter        string        `yaml:"TRACING_EXPORTER"`
+	TracingEndpoint        string        `yaml:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName     string        `yaml:"TRACING_SERVICE_NAME"`
 }
 
 // loadYamlConfig вынесен для удобства тестирования
@@ -106,6 +470,7 @@ func loadYamlConfig(path string) (*yamlConfig, error) {
 	}
 
 	var cfg struct {
+		Mode           string `yaml:"APP_MODE"`
 		ServerPort     string `yaml:"SERVER_PORT"`
 		JwtSignature   string `yaml:"JWT_SIGNATURE"`
 		PostgresUser   string `yaml:"POSTGRES_USER"`
@@ -115,6 +480,58 @@ func loadYamlConfig(path string) (*yamlConfig, error) {
 		PostgresHost   string `yaml:"POSTGRES_HOST"`
 		MigrationsPath string `yaml:"MIGRATIONS_PATH"`
 		JwtTokenLife   string `yaml:"JWT_TOKEN_LIFESPAN"`
+		JwtAlgorithm   string `yaml:"JWT_ALGORITHM"`
+		JwtKeys        string `yaml:"JWT_KEYS"`
+		AutoCloseIdleTimeout string `yaml:"RECEPTION_AUTO_CLOSE_IDLE_TIMEOUT"`
+		AutoCloseInterval    string `yaml:"RECEPTION_AUTO_CLOSE_INTERVAL"`
+		ReopenWindow         string `yaml:"RECEPTION_REOPEN_WINDOW"`
+		RollupInterval       string `yaml:"ROLLUP_INTERVAL"`
+		RollupLag            string `yaml:"ROLLUP_LAG"`
+		EventsBufferSize     string `yaml:"EVENTS_BUFFER_SIZE"`
+		EventsHeartbeat      string `yaml:"EVENTS_HEARTBEAT_INTERVAL"`
+		WebhookInterval      string `yaml:"WEBHOOK_DELIVERY_INTERVAL"`
+		WebhookTimeout       string `yaml:"WEBHOOK_TIMEOUT"`
+		WebhookMaxAttempts   string `yaml:"WEBHOOK_MAX_ATTEMPTS"`
+		WebhookBackoffBase   string `yaml:"WEBHOOK_BACKOFF_BASE"`
+		WebhookBackoffMax    string `yaml:"WEBHOOK_BACKOFF_MAX"`
+		WebhookAllowPrivate  string `yaml:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+		OutboxInterval       string `yaml:"OUTBOX_DISPATCH_INTERVAL"`
+		OutboxBatchSize      string `yaml:"OUTBOX_BATCH_SIZE"`
+		OutboxSinks          string `yaml:"OUTBOX_SINKS"`
+		OutboxFilePath       string `yaml:"OUTBOX_FILE_PATH"`
+		PasswordMinLength      string `yaml:"PASSWORD_MIN_LENGTH"`
+		PasswordRequireLetter  string `yaml:"PASSWORD_REQUIRE_LETTER"`
+		PasswordRequireDigit   string `yaml:"PASSWORD_REQUIRE_DIGIT"`
+		PasswordRequireUpper   string `yaml:"PASSWORD_REQUIRE_UPPER"`
+		PasswordRequireSpecial string `yaml:"PASSWORD_REQUIRE_SPECIAL"`
+		LoginMaxFailures       string `yaml:"LOGIN_MAX_FAILURES"`
+		LoginMaxIPFailures     string `yaml:"LOGIN_MAX_IP_FAILURES"`
+		LoginFailureWindow     string `yaml:"LOGIN_FAILURE_WINDOW"`
+		LoginLockout           string `yaml:"LOGIN_LOCKOUT_DURATION"`
+		LoginDelayBase         string `yaml:"LOGIN_DELAY_BASE"`
+		LoginDelayMax          string `yaml:"LOGIN_DELAY_MAX"`
+		RateLimitDefault       string `yaml:"RATE_LIMIT_DEFAULT"`
+		RateLimitAnonymous     string `yaml:"RATE_LIMIT_ANONYMOUS"`
+		RateLimitRoles         string `yaml:"RATE_LIMIT_ROLES"`
+		RateLimitRoutes        string `yaml:"RATE_LIMIT_ROUTES"`
+		MailSender             string `yaml:"MAIL_SENDER"`
+		MailFrom               string `yaml:"MAIL_FROM"`
+		MailDir                string `yaml:"MAIL_DIR"`
+		SMTPAddr               string `yaml:"SMTP_ADDR"`
+		SMTPUsername           string `yaml:"SMTP_USERNAME"`
+		SMTPPassword           string `yaml:"SMTP_PASSWORD"`
+		PasswordResetTTL       string `yaml:"PASSWORD_RESET_TTL"`
+		PasswordResetURL       string `yaml:"PASSWORD_RESET_URL"`
+		RolePermissions        string `yaml:"ROLE_PERMISSIONS"`
+		SelfRegistration       string `yaml:"SELF_REGISTRATION"`
+		InvitationTTL          string `yaml:"INVITATION_TTL"`
+		InvitationURL          string `yaml:"INVITATION_URL"`
+		TwoFactorRequiredRoles string `yaml:"TWO_FACTOR_REQUIRED_ROLES"`
+		TwoFactorIssuer        string `yaml:"TWO_FACTOR_ISSUER"`
+		TwoFactorChallengeTTL  string `yaml:"TWO_FACTOR_CHALLENGE_TTL"`
+		TracingExporter        string `yaml:"TRACING_EXPORTER"`
+		TracingEndpoint        string `yaml:"TRACING_OTLP_ENDPOINT"`
+		TracingServiceName     string `yaml:"TRACING_SERVICE_NAME"`
 	}
 
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
@@ -125,8 +542,37 @@ func loadYamlConfig(path string) (*yamlConfig, error) {
 	if cfg.ServerPort == "" {
 		return nil, errors.New("SERVER_PORT is required")
 	}
-	if cfg.JwtSignature == "" {
-		return nil, errors.New("JWT_SIGNATURE is required")
+	mode := ModeProd // значение по умолчанию
+	if cfg.Mode != "" {
+		mode = Mode(cfg.Mode)
+	}
+	switch mode {
+	case ModeDev, ModeTest, ModeProd:
+	default:
+		return nil, fmt.Errorf("unknown APP_MODE value: %s", cfg.Mode)
+	}
+
+	jwtAlgorithm := "HS256" // значение по умолчанию
+	if cfg.JwtAlgorithm != "" {
+		jwtAlgorithm = cfg.JwtAlgorithm
+	}
+
+	jwtKeys, err := parseJWTKeys(cfg.JwtKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEYS value: %v", err)
+	}
+
+	switch jwtAlgorithm {
+	case "HS256":
+		if cfg.JwtSignature == "" {
+			return nil, errors.New("JWT_SIGNATURE is required")
+		}
+	case "RS256", "EdDSA":
+		if len(jwtKeys) == 0 {
+			return nil, fmt.Errorf("JWT_KEYS is required for %s", jwtAlgorithm)
+		}
+	default:
+		return nil, fmt.Errorf("unknown JWT_ALGORITHM value: %s", jwtAlgorithm)
 	}
 
 	port, err := strconv.Atoi(cfg.PostgresPort)
@@ -141,7 +587,310 @@ func loadYamlConfig(path string) (*yamlConfig, error) {
 		}
 	}
 
+	autoCloseIdle := 12 * time.Hour // значение по умолчанию
+	if cfg.AutoCloseIdleTimeout != "" {
+		if d, err := time.ParseDuration(cfg.AutoCloseIdleTimeout); err == nil {
+			autoCloseIdle = d
+		}
+	}
+
+	autoCloseInterval := 5 * time.Minute // значение по умолчанию
+	if cfg.AutoCloseInterval != "" {
+		if d, err := time.ParseDuration(cfg.AutoCloseInterval); err == nil {
+			autoCloseInterval = d
+		}
+	}
+
+	reopenWindow := 30 * time.Minute // значение по умолчанию
+	if cfg.ReopenWindow != "" {
+		if d, err := time.ParseDuration(cfg.ReopenWindow); err == nil {
+			reopenWindow = d
+		}
+	}
+
+	rollupInterval := 5 * time.Minute // значение по умолчанию
+	if cfg.RollupInterval != "" {
+		if d, err := time.ParseDuration(cfg.RollupInterval); err == nil {
+			rollupInterval = d
+		}
+	}
+
+	rollupLag := time.Minute // значение по умолчанию
+	if cfg.RollupLag != "" {
+		if d, err := time.ParseDuration(cfg.RollupLag); err == nil {
+			rollupLag = d
+		}
+	}
+
+	eventsBufferSize := 1000 // значение по умолчанию
+	if cfg.EventsBufferSize != "" {
+		if n, err := strconv.Atoi(cfg.EventsBufferSize); err == nil && n > 0 {
+			eventsBufferSize = n
+		}
+	}
+
+	eventsHeartbeat := 15 * time.Second // значение по умолчанию
+	if cfg.EventsHeartbeat != "" {
+		if d, err := time.ParseDuration(cfg.EventsHeartbeat); err == nil && d > 0 {
+			eventsHeartbeat = d
+		}
+	}
+
+	webhookInterval := 5 * time.Second // значение по умолчанию
+	if cfg.WebhookInterval != "" {
+		if d, err := time.ParseDuration(cfg.WebhookInterval); err == nil {
+			webhookInterval = d
+		}
+	}
+
+	webhookTimeout := 10 * time.Second // значение по умолчанию
+	if cfg.WebhookTimeout != "" {
+		if d, err := time.ParseDuration(cfg.WebhookTimeout); err == nil && d > 0 {
+			webhookTimeout = d
+		}
+	}
+
+	webhookMaxAttempts := 8 // значение по умолчанию
+	if cfg.WebhookMaxAttempts != "" {
+		if n, err := strconv.Atoi(cfg.WebhookMaxAttempts); err == nil && n > 0 {
+			webhookMaxAttempts = n
+		}
+	}
+
+	webhookBackoffBase := 30 * time.Second // значение по умолчанию
+	if cfg.WebhookBackoffBase != "" {
+		if d, err := time.ParseDuration(cfg.WebhookBackoffBase); err == nil && d > 0 {
+			webhookBackoffBase = d
+		}
+	}
+
+	webhookBackoffMax := time.Hour // значение по умолчанию
+	if cfg.WebhookBackoffMax != "" {
+		if d, err := time.ParseDuration(cfg.WebhookBackoffMax); err == nil && d > 0 {
+			webhookBackoffMax = d
+		}
+	}
+
+	webhookAllowPrivate, err := parseBool(cfg.WebhookAllowPrivate, false)
+	if err != nil {
+		return nil, errors.New("invalid WEBHOOK_ALLOW_PRIVATE_TARGETS value")
+	}
+
+	outboxInterval := time.Second // значение по умолчанию
+	if cfg.OutboxInterval != "" {
+		if d, err := time.ParseDuration(cfg.OutboxInterval); err == nil {
+			outboxInterval = d
+		}
+	}
+
+	outboxBatchSize := 100 // значение по умолчанию
+	if cfg.OutboxBatchSize != "" {
+		if n, err := strconv.Atoi(cfg.OutboxBatchSize); err == nil && n > 0 {
+			outboxBatchSize = n
+		}
+	}
+
+	var outboxSinks []string
+	for _, sink := range strings.Split(cfg.OutboxSinks, ",") {
+		switch sink = strings.TrimSpace(sink); sink {
+		case "":
+		case "stdout", "file":
+			outboxSinks = append(outboxSinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown OUTBOX_SINKS value: %s", sink)
+		}
+	}
+
+	outboxFilePath := "outbox_events.jsonl" // значение по умолчанию
+	if cfg.OutboxFilePath != "" {
+		outboxFilePath = cfg.OutboxFilePath
+	}
+
+	passwordMinLength := 8 // значение по умолчанию
+	if cfg.PasswordMinLength != "" {
+		if n, err := strconv.Atoi(cfg.PasswordMinLength); err == nil && n > 0 {
+			passwordMinLength = n
+		}
+	}
+
+	passwordRequireLetter, err := parseBool(cfg.PasswordRequireLetter, true)
+	if err != nil {
+		return nil, errors.New("invalid PASSWORD_REQUIRE_LETTER value")
+	}
+	passwordRequireDigit, err := parseBool(cfg.PasswordRequireDigit, true)
+	if err != nil {
+		return nil, errors.New("invalid PASSWORD_REQUIRE_DIGIT value")
+	}
+	passwordRequireUpper, err := parseBool(cfg.PasswordRequireUpper, false)
+	if err != nil {
+		return nil, errors.New("invalid PASSWORD_REQUIRE_UPPER value")
+	}
+	passwordRequireSpecial, err := parseBool(cfg.PasswordRequireSpecial, false)
+	if err != nil {
+		return nil, errors.New("invalid PASSWORD_REQUIRE_SPECIAL value")
+	}
+
+	loginMaxFailures := 5 // значение по умолчанию
+	if cfg.LoginMaxFailures != "" {
+		if n, err := strconv.Atoi(cfg.LoginMaxFailures); err == nil && n > 0 {
+			loginMaxFailures = n
+		}
+	}
+
+	loginMaxIPFailures := 20 // значение по умолчанию
+	if cfg.LoginMaxIPFailures != "" {
+		if n, err := strconv.Atoi(cfg.LoginMaxIPFailures); err == nil && n > 0 {
+			loginMaxIPFailures = n
+		}
+	}
+
+	loginFailureWindow := 15 * time.Minute // значение по умолчанию
+	if cfg.LoginFailureWindow != "" {
+		if d, err := time.ParseDuration(cfg.LoginFailureWindow); err == nil && d > 0 {
+			loginFailureWindow = d
+		}
+	}
+
+	loginLockout := 15 * time.Minute // значение по умолчанию
+	if cfg.LoginLockout != "" {
+		if d, err := time.ParseDuration(cfg.LoginLockout); err == nil && d > 0 {
+			loginLockout = d
+		}
+	}
+
+	loginDelayBase := 250 * time.Millisecond // значение по умолчанию
+	if cfg.LoginDelayBase != "" {
+		if d, err := time.ParseDuration(cfg.LoginDelayBase); err == nil {
+			loginDelayBase = d
+		}
+	}
+
+	loginDelayMax := 4 * time.Second // значение по умолчанию
+	if cfg.LoginDelayMax != "" {
+		if d, err := time.ParseDuration(cfg.LoginDelayMax); err == nil {
+			loginDelayMax = d
+		}
+	}
+
+	rateLimitDefault, err := parseRateLimit(cfg.RateLimitDefault, RateLimit{Requests: 600, Period: time.Minute})
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_DEFAULT value: %v", err)
+	}
+
+	rateLimitAnonymous, err := parseRateLimit(cfg.RateLimitAnonymous, RateLimit{Requests: 120, Period: time.Minute})
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_ANONYMOUS value: %v", err)
+	}
+
+	rateLimitRoles, err := parseRateLimits(cfg.RateLimitRoles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_ROLES value: %v", err)
+	}
+
+	rateLimitRoutes, err := parseRateLimits(cfg.RateLimitRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES value: %v", err)
+	}
+
+	mailSender := "file" // значение по умолчанию
+	if cfg.MailSender != "" {
+		mailSender = cfg.MailSender
+	}
+	switch mailSender {
+	case "file":
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, errors.New("SMTP_ADDR is required for MAIL_SENDER smtp")
+		}
+	default:
+		return nil, fmt.Errorf("unknown MAIL_SENDER value: %s", mailSender)
+	}
+
+	mailFrom := "no-reply@localhost" // значение по умолчанию
+	if cfg.MailFrom != "" {
+		mailFrom = cfg.MailFrom
+	}
+
+	mailDir := "mail" // значение по умолчанию
+	if cfg.MailDir != "" {
+		mailDir = cfg.MailDir
+	}
+
+	passwordResetTTL := time.Hour // значение по умолчанию
+	if cfg.PasswordResetTTL != "" {
+		if d, err := time.ParseDuration(cfg.PasswordResetTTL); err == nil && d > 0 {
+			passwordResetTTL = d
+		}
+	}
+
+	passwordResetURL := "http://localhost:8080/password/reset" // значение по умолчанию
+	if cfg.PasswordResetURL != "" {
+		passwordResetURL = cfg.PasswordResetURL
+	}
+
+	rolePermissions, err := parseRolePermissions(cfg.RolePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ROLE_PERMISSIONS value: %v", err)
+	}
+
+	selfRegistration, err := parseBool(cfg.SelfRegistration, false)
+	if err != nil {
+		return nil, errors.New("invalid SELF_REGISTRATION value")
+	}
+
+	invitationTTL := 72 * time.Hour // значение по умолчанию
+	if cfg.InvitationTTL != "" {
+		if d, err := time.ParseDuration(cfg.InvitationTTL); err == nil && d > 0 {
+			invitationTTL = d
+		}
+	}
+
+	invitationURL := "http://localhost:8080/register" // значение по умолчанию
+	if cfg.InvitationURL != "" {
+		invitationURL = cfg.InvitationURL
+	}
+
+	var twoFactorRequiredRoles []string
+	for _, role := range strings.Split(cfg.TwoFactorRequiredRoles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			twoFactorRequiredRoles = append(twoFactorRequiredRoles, role)
+		}
+	}
+
+	twoFactorIssuer := "PVZ Service" // значение по умолчанию
+	if cfg.TwoFactorIssuer != "" {
+		twoFactorIssuer = cfg.TwoFactorIssuer
+	}
+
+	twoFactorChallengeTTL := 5 * time.Minute // значение по умолчанию
+	if cfg.TwoFactorChallengeTTL != "" {
+		if d, err := time.ParseDuration(cfg.TwoFactorChallengeTTL); err == nil && d > 0 {
+			twoFactorChallengeTTL = d
+		}
+	}
+
+	tracingExporter := "none" // значение по умолчанию
+	if cfg.TracingExporter != "" {
+		tracingExporter = cfg.TracingExporter
+	}
+	switch tracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		return nil, fmt.Errorf("unknown TRACING_EXPORTER value: %s", tracingExporter)
+	}
+
+	tracingEndpoint := "localhost:4318" // значение по умолчанию
+	if cfg.TracingEndpoint != "" {
+		tracingEndpoint = cfg.TracingEndpoint
+	}
+
+	tracingServiceName := "pvz-service" // значение по умолчанию
+	if cfg.TracingServiceName != "" {
+		tracingServiceName = cfg.TracingServiceName
+	}
+
 	return &yamlConfig{
+		Mode:           mode,
 		ServerPort:     cfg.ServerPort,
 		JwtSignature:   cfg.JwtSignature,
 		PostgresUser:   cfg.PostgresUser,
@@ -151,9 +900,183 @@ func loadYamlConfig(path string) (*yamlConfig, error) {
 		PostgresHost:   cfg.PostgresHost,
 		MigrationsPath: cfg.MigrationsPath,
 		JwtTokenLife:   tokenLife,
+		JwtAlgorithm:   jwtAlgorithm,
+		JwtKeys:        jwtKeys,
+		AutoCloseIdleTimeout: autoCloseIdle,
+		AutoCloseInterval:    autoCloseInterval,
+		ReopenWindow:         reopenWindow,
+		RollupInterval:       rollupInterval,
+		RollupLag:            rollupLag,
+		EventsBufferSize:     eventsBufferSize,
+		EventsHeartbeat:      eventsHeartbeat,
+		WebhookInterval:      webhookInterval,
+		WebhookTimeout:       webhookTimeout,
+		WebhookMaxAttempts:   webhookMaxAttempts,
+		WebhookBackoffBase:   webhookBackoffBase,
+		WebhookBackoffMax:    webhookBackoffMax,
+		WebhookAllowPrivate:  webhookAllowPrivate,
+		OutboxInterval:       outboxInterval,
+		OutboxBatchSize:      outboxBatchSize,
+		OutboxSinks:          outboxSinks,
+		OutboxFilePath:       outboxFilePath,
+		PasswordMinLength:      passwordMinLength,
+		PasswordRequireLetter:  passwordRequireLetter,
+		PasswordRequireDigit:   passwordRequireDigit,
+		PasswordRequireUpper:   passwordRequireUpper,
+		PasswordRequireSpecial: passwordRequireSpecial,
+		LoginMaxFailures:       loginMaxFailures,
+		LoginMaxIPFailures:     loginMaxIPFailures,
+		LoginFailureWindow:     loginFailureWindow,
+		LoginLockout:           loginLockout,
+		LoginDelayBase:         loginDelayBase,
+		LoginDelayMax:          loginDelayMax,
+		RateLimitDefault:       rateLimitDefault,
+		RateLimitAnonymous:     rateLimitAnonymous,
+		RateLimitRoles:         rateLimitRoles,
+		RateLimitRoutes:        rateLimitRoutes,
+		MailSender:             mailSender,
+		MailFrom:               mailFrom,
+		MailDir:                mailDir,
+		SMTPAddr:               cfg.SMTPAddr,
+		SMTPUsername:           cfg.SMTPUsername,
+		SMTPPassword:           cfg.SMTPPassword,
+		PasswordResetTTL:       passwordResetTTL,
+		PasswordResetURL:       passwordResetURL,
+		RolePermissions:        rolePermissions,
+		SelfRegistration:       selfRegistration,
+		InvitationTTL:          invitationTTL,
+		InvitationURL:          invitationURL,
+		TwoFactorRequiredRoles: twoFactorRequiredRoles,
+		TwoFactorIssuer:        twoFactorIssuer,
+		TwoFactorChallengeTTL:  twoFactorChallengeTTL,
+		TracingExporter:        tracingExporter,
+		TracingEndpoint:        tracingEndpoint,
+		TracingServiceName:     tracingServiceName,
 	}, nil
 }
 
+// parseBool разбирает флаг конфигурации, пустое значение заменяется на def
+func parseBool(value string, def bool) (bool, error) {
+	if value == "" {
+		return def, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseRateLimit разбирает лимит вида "100/1m". Значение "off" отключает ограничение,
+// пустое значение заменяется на def
+func parseRateLimit(value string, def RateLimit) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return def, nil
+	case "off":
+		return RateLimit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("%q: expected <requests>/<period>", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("%q: invalid number of requests", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("%q: invalid period", value)
+	}
+	// Корзина пополняется по токену раз в period/requests, и этот интервал не может быть нулевым
+	if d/time.Duration(n) == 0 {
+		return RateLimit{}, fmt.Errorf("%q: period is too short for %d requests", value, n)
+	}
+
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+// parseRateLimits разбирает список вида "admin=600/1m,worker=300/1m"
+func parseRateLimits(value string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, limit, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q: expected <key>=<requests>/<period>", item)
+		}
+		parsed, err := parseRateLimit(limit, RateLimit{})
+		if err != nil {
+			return nil, err
+		}
+		limits[key] = parsed
+	}
+	return limits, nil
+}
+
+// parseRolePermissions разбирает права ролей вида "admin=pvz:create pvz:read,worker=reception:open".
+// Права роли перечисляются через пробел, роль с пустым списком не имеет прав
+func parseRolePermissions(value string) (map[string][]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	roles := make(map[string][]string)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		role, perms, ok := strings.Cut(item, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("%q: expected <role>=<permission> <permission>...", item)
+		}
+		if _, exists := roles[role]; exists {
+			return nil, fmt.Errorf("duplicate role %q", role)
+		}
+		roles[role] = strings.Fields(perms)
+	}
+	return roles, nil
+}
+
+// parseJWTKeys разбирает список ключей вида "2025-01=keys/2025-01.pem,2025-04=keys/2025-04.pem@2025-04-01T00:00:00Z".
+// Ключ без даты активен сразу
+func parseJWTKeys(value string) ([]JWTKey, error) {
+	var keys []JWTKey
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		id, rest, ok := strings.Cut(item, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%q: expected <kid>=<path>[@<time>]", item)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate kid %q", id)
+		}
+		seen[id] = true
+
+		key := JWTKey{ID: id}
+		path, activeFrom, scheduled := strings.Cut(rest, "@")
+		key.Path = strings.TrimSpace(path)
+		if key.Path == "" {
+			return nil, fmt.Errorf("%q: key path is required", item)
+		}
+		if scheduled {
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(activeFrom))
+			if err != nil {
+				return nil, fmt.Errorf("%q: activation time must be RFC 3339", item)
+			}
+			key.ActiveFrom = t
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 // ConfigureDB оставляем без изменений для совместимости
 func ConfigureDB(db *sql.DB, cfg *DBConfig) {
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
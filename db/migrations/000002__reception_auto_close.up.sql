@@ -0,0 +1,10 @@
+-- Время и причина закрытия приемки (ручное закрытие или автоматическое по простою)
+ALTER TABLE reception
+    ADD COLUMN closed_at            TIMESTAMP,
+    ADD COLUMN close_reason         TEXT;
+
+-- Для поиска активных приемок фоновой задачей
+CREATE INDEX IF NOT EXISTS reception_in_progress_idx ON reception(pickup_point_id) WHERE status = 'in_progress';
+
+-- Для поиска последнего товара приемки
+CREATE INDEX IF NOT EXISTS product_reception_id_idx ON product(reception_id, reception_date);
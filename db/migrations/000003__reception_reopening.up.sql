@@ -0,0 +1,10 @@
+-- Журнал повторных открытий закрытых приемок
+CREATE TABLE reception_reopening (
+    id                      UUID PRIMARY KEY,
+    reception_id            UUID NOT NULL REFERENCES reception(id) ON DELETE CASCADE,
+    reopened_by             UUID NOT NULL,
+    reason                  TEXT NOT NULL,
+    reopened_at             TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS reception_reopening_reception_id_idx ON reception_reopening(reception_id);
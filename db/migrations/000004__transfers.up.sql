@@ -0,0 +1,37 @@
+-- Приемки, созданные при перемещении товаров между ПВЗ, отличаются от обычных
+CREATE TYPE reception_kind AS ENUM (
+    'regular',
+    'transfer'
+);
+
+ALTER TABLE reception ADD COLUMN kind reception_kind NOT NULL DEFAULT 'regular';
+
+CREATE TYPE transfer_status AS ENUM (
+    'created',
+    'dispatched',
+    'accepted'
+);
+
+-- Создание таблицы Перемещений товаров между ПВЗ
+CREATE TABLE transfer (
+    id                      UUID PRIMARY KEY,
+    from_pickup_point_id    UUID NOT NULL REFERENCES pickup_point(id) ON DELETE CASCADE,
+    to_pickup_point_id      UUID NOT NULL REFERENCES pickup_point(id) ON DELETE CASCADE,
+    status                  transfer_status NOT NULL DEFAULT 'created',
+    created_by              UUID NOT NULL,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    dispatched_at           TIMESTAMP,
+    accepted_at             TIMESTAMP,
+    reception_id            UUID REFERENCES reception(id) ON DELETE SET NULL
+);
+
+-- Товары, входящие в перемещение
+CREATE TABLE transfer_product (
+    transfer_id             UUID NOT NULL REFERENCES transfer(id) ON DELETE CASCADE,
+    product_id              UUID NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    PRIMARY KEY (transfer_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS transfer_from_pickup_point_id_idx ON transfer(from_pickup_point_id);
+CREATE INDEX IF NOT EXISTS transfer_to_pickup_point_id_idx ON transfer(to_pickup_point_id);
+CREATE INDEX IF NOT EXISTS transfer_product_product_id_idx ON transfer_product(product_id);
@@ -0,0 +1,9 @@
+CREATE TYPE capacity_mode AS ENUM (
+    'hard',
+    'soft'
+);
+
+-- Вместимость ПВЗ в товарах, NULL означает отсутствие ограничения
+ALTER TABLE pickup_point
+    ADD COLUMN capacity         INT CHECK (capacity IS NULL OR capacity >= 0),
+    ADD COLUMN capacity_mode    capacity_mode NOT NULL DEFAULT 'hard';
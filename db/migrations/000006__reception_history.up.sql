@@ -0,0 +1,13 @@
+-- История событий приемки: открытие, добавление и удаление товаров, закрытие
+CREATE TABLE reception_event (
+    id                      BIGSERIAL PRIMARY KEY,
+    reception_id            UUID NOT NULL REFERENCES reception(id) ON DELETE CASCADE,
+    event_type              TEXT NOT NULL,
+    product_id              UUID,
+    product_type            TEXT,
+    actor_id                UUID,
+    details                 TEXT,
+    created_at              TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS reception_event_reception_id_idx ON reception_event(reception_id, id);
@@ -0,0 +1,34 @@
+-- Суточные агрегаты для отчетов. День - дата открытия приемки, учитываются только обычные приемки.
+-- durations хранит длительности закрытых приемок в секундах (по возрастанию), чтобы считать перцентили
+CREATE TABLE daily_pvz_rollup (
+    day                     DATE NOT NULL,
+    pickup_point_id         UUID NOT NULL REFERENCES pickup_point(id) ON DELETE CASCADE,
+    receptions              INTEGER NOT NULL,
+    products                INTEGER NOT NULL,
+    durations               DOUBLE PRECISION[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (day, pickup_point_id)
+);
+
+-- То же в разрезе типа товара: приемка учитывается, если в ней есть товар этого типа
+CREATE TABLE daily_product_type_rollup (
+    day                     DATE NOT NULL,
+    pickup_point_id         UUID NOT NULL REFERENCES pickup_point(id) ON DELETE CASCADE,
+    product_type            TEXT NOT NULL,
+    receptions              INTEGER NOT NULL,
+    products                INTEGER NOT NULL,
+    durations               DOUBLE PRECISION[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (day, pickup_point_id, product_type)
+);
+
+-- Изменения сырых данных до watermark уже учтены в агрегатах
+CREATE TABLE rollup_watermark (
+    name                    TEXT PRIMARY KEY,
+    watermark               TIMESTAMP NOT NULL
+);
+
+INSERT INTO rollup_watermark (name, watermark) VALUES ('daily', 'epoch');
+
+-- Для поиска изменений после watermark
+CREATE INDEX IF NOT EXISTS reception_closed_at_idx ON reception(closed_at);
+CREATE INDEX IF NOT EXISTS reception_event_created_at_idx ON reception_event(created_at);
+CREATE INDEX IF NOT EXISTS transfer_accepted_at_idx ON transfer(accepted_at);
@@ -0,0 +1,31 @@
+-- Подписки партнеров на события. Пустой список типов и пустой ПВЗ означают "все"
+CREATE TABLE webhook_subscription (
+    id                      UUID PRIMARY KEY,
+    url                     TEXT NOT NULL,
+    event_types             TEXT[] NOT NULL DEFAULT '{}',
+    pickup_point_id         UUID REFERENCES pickup_point(id) ON DELETE CASCADE,
+    secret                  TEXT NOT NULL,
+    created_by              UUID NOT NULL,
+    created_at              TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'failed');
+
+-- Очередь доставки и одновременно журнал: строка живет и после успешной отправки
+CREATE TABLE webhook_delivery (
+    id                      BIGSERIAL PRIMARY KEY,
+    subscription_id         UUID NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    event_type              TEXT NOT NULL,
+    payload                 JSONB NOT NULL,
+    status                  webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts                INT NOT NULL DEFAULT 0,
+    next_attempt_at         TIMESTAMP NOT NULL DEFAULT now(),
+    last_status_code        INT,
+    last_error              TEXT,
+    redelivery_of           BIGINT REFERENCES webhook_delivery(id) ON DELETE SET NULL,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    delivered_at            TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_delivery_subscription_idx ON webhook_delivery(subscription_id, id DESC);
@@ -0,0 +1,15 @@
+-- Исходящие доменные события. Пишутся в той же транзакции, что и изменение данных,
+-- и рассылаются диспетчером. aggregate_id - ПВЗ: события одного ПВЗ доставляются по порядку id
+CREATE TABLE outbox (
+    id                      BIGSERIAL PRIMARY KEY,
+    aggregate_id            UUID NOT NULL,
+    event_type              TEXT NOT NULL,
+    payload                 JSONB NOT NULL,
+    attempts                INT NOT NULL DEFAULT 0,
+    last_error              TEXT,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    dispatched_at           TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox(id) WHERE dispatched_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_pending_aggregate_idx ON outbox(aggregate_id, id) WHERE dispatched_at IS NULL;
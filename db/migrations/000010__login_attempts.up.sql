@@ -0,0 +1,9 @@
+-- Неудачные попытки входа по email и по IP. Хранятся в БД, чтобы блокировка переживала перезапуск
+CREATE TABLE login_attempt (
+    scope                   TEXT NOT NULL,
+    key                     TEXT NOT NULL,
+    failures                INT NOT NULL,
+    last_failure_at         TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until            TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
@@ -0,0 +1,13 @@
+-- Токены старше tokens_valid_after отклоняются: так отзываются все сессии пользователя
+ALTER TABLE "user" ADD COLUMN tokens_valid_after TIMESTAMP;
+
+-- Одноразовые токены сброса пароля. Хранится только SHA-256 от токена
+CREATE TABLE password_reset (
+    token_hash              BYTEA PRIMARY KEY,
+    user_id                 UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at              TIMESTAMP NOT NULL,
+    used_at                 TIMESTAMP
+);
+
+CREATE INDEX password_reset_user_id_idx ON password_reset(user_id) WHERE used_at IS NULL;
@@ -0,0 +1,5 @@
+-- Деактивированный пользователь не может войти, его токены отклоняются
+ALTER TABLE "user" ADD COLUMN active BOOLEAN NOT NULL DEFAULT true;
+
+-- Для списка пользователей в админке
+CREATE INDEX user_created_at_idx ON "user"(created_at);
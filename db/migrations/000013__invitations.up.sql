@@ -0,0 +1,18 @@
+-- Точка выдачи, к которой привязан пользователь. Пустое значение - без ограничения по ПВЗ
+ALTER TABLE "user" ADD COLUMN pickup_point_id UUID REFERENCES pickup_point(id) ON DELETE SET NULL;
+
+-- Одноразовые приглашения на регистрацию. Хранится только SHA-256 от токена,
+-- роль и ПВЗ нового пользователя берутся из приглашения
+CREATE TABLE invitation (
+    id                      UUID PRIMARY KEY,
+    token_hash              BYTEA NOT NULL UNIQUE,
+    email                   TEXT NOT NULL,
+    role                    user_role NOT NULL,
+    pickup_point_id         UUID REFERENCES pickup_point(id) ON DELETE CASCADE,
+    created_by              UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at              TIMESTAMP NOT NULL,
+    used_at                 TIMESTAMP
+);
+
+CREATE INDEX invitation_pending_idx ON invitation(created_at) WHERE used_at IS NULL;
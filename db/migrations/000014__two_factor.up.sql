@@ -0,0 +1,22 @@
+-- TOTP: секрет задается при настройке, totp_enabled - после подтверждения кодом.
+-- totp_last_step - последний принятый шаг, чтобы один код нельзя было использовать дважды
+ALTER TABLE "user" ADD COLUMN totp_secret TEXT;
+ALTER TABLE "user" ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE "user" ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Одноразовые коды восстановления на случай потери аутентификатора. Хранится только SHA-256
+CREATE TABLE recovery_code (
+    user_id                 UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    code_hash               BYTEA NOT NULL,
+    used_at                 TIMESTAMP,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+-- Вход, прошедший проверку пароля и ожидающий кода. attempts ограничивает перебор кодов
+CREATE TABLE login_challenge (
+    token_hash              BYTEA PRIMARY KEY,
+    user_id                 UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    attempts                INT NOT NULL DEFAULT 0,
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at              TIMESTAMP NOT NULL
+);
@@ -0,0 +1,14 @@
+-- Сессия создается при каждом входе, ее id записывается в токен (claim sid).
+-- Отозванная сессия делает токен недействительным раньше срока
+CREATE TABLE session (
+    id                      UUID PRIMARY KEY,
+    user_id                 UUID NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    device                  TEXT NOT NULL DEFAULT '',
+    ip                      TEXT NOT NULL DEFAULT '',
+    user_agent              TEXT NOT NULL DEFAULT '',
+    created_at              TIMESTAMP NOT NULL DEFAULT now(),
+    last_seen_at            TIMESTAMP NOT NULL DEFAULT now(),
+    revoked_at              TIMESTAMP
+);
+
+CREATE INDEX session_user_active_idx ON session(user_id, created_at) WHERE revoked_at IS NULL;
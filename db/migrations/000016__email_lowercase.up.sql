@@ -0,0 +1,17 @@
+-- Вход и регистрация приводят email к нижнему регистру, поэтому старые записи со смешанным
+-- регистром больше не находятся. Если несколько записей совпадают без учета регистра,
+-- за адресом остается самая ранняя, остальные деактивируются и получают суффикс с id,
+-- чтобы их можно было найти и разобрать вручную
+WITH ranked AS (
+    SELECT id, row_number() OVER (PARTITION BY lower(email) ORDER BY created_at, id) AS rn
+    FROM "user"
+)
+UPDATE "user" u
+SET email = lower(u.email) || '#duplicate-' || u.id,
+    active = false
+FROM ranked r
+WHERE u.id = r.id AND r.rn > 1;
+
+UPDATE "user" SET email = lower(email) WHERE email <> lower(email);
+
+CREATE UNIQUE INDEX user_email_lower_key ON "user"(lower(email));
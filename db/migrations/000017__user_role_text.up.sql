@@ -0,0 +1,6 @@
+-- Роли задаются политикой прав (ROLE_PERMISSIONS), а не схемой БД. Допустимость роли
+-- проверяет приложение, поэтому новая роль в конфиге не требует миграции
+ALTER TABLE "user" ALTER COLUMN role TYPE TEXT USING role::text;
+ALTER TABLE invitation ALTER COLUMN role TYPE TEXT USING role::text;
+
+DROP TYPE user_role;
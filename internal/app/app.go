@@ -1,42 +1,107 @@
 package app
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/nik-mLb/avito_task/config"
+	"github.com/nik-mLb/avito_task/internal/eventbus"
+	"github.com/nik-mLb/avito_task/internal/eventsink"
+	"github.com/nik-mLb/avito_task/internal/mailer"
+	"github.com/nik-mLb/avito_task/internal/permission"
+	"github.com/nik-mLb/avito_task/internal/ratelimit"
 	"github.com/nik-mLb/avito_task/internal/repository"
 	authrepo "github.com/nik-mLb/avito_task/internal/repository/auth"
+	historyrepo "github.com/nik-mLb/avito_task/internal/repository/history"
+	outboxrepo "github.com/nik-mLb/avito_task/internal/repository/outbox"
 	pickuprepo "github.com/nik-mLb/avito_task/internal/repository/pickup_point"
 	receptionrepo "github.com/nik-mLb/avito_task/internal/repository/reception"
 	productrepo "github.com/nik-mLb/avito_task/internal/repository/product"
+	transferrepo "github.com/nik-mLb/avito_task/internal/repository/transfer"
+	statisticsrepo "github.com/nik-mLb/avito_task/internal/repository/statistics"
+	rolluprepo "github.com/nik-mLb/avito_task/internal/repository/rollup"
+	webhookrepo "github.com/nik-mLb/avito_task/internal/repository/webhook"
+	userrepo "github.com/nik-mLb/avito_task/internal/repository/user"
+	invitationrepo "github.com/nik-mLb/avito_task/internal/repository/invitation"
+	sessionrepo "github.com/nik-mLb/avito_task/internal/repository/session"
+	"github.com/nik-mLb/avito_task/internal/tracing"
 	autht "github.com/nik-mLb/avito_task/internal/transport/auth"
 	pickupt "github.com/nik-mLb/avito_task/internal/transport/pickup_point"
 	receptiont "github.com/nik-mLb/avito_task/internal/transport/reception"
 	productt "github.com/nik-mLb/avito_task/internal/transport/product"
+	transfert "github.com/nik-mLb/avito_task/internal/transport/transfer"
+	statisticst "github.com/nik-mLb/avito_task/internal/transport/statistics"
+	eventst "github.com/nik-mLb/avito_task/internal/transport/events"
+	scannert "github.com/nik-mLb/avito_task/internal/transport/scanner"
+	webhookt "github.com/nik-mLb/avito_task/internal/transport/webhook"
+	jwkst "github.com/nik-mLb/avito_task/internal/transport/jwks"
+	passwordt "github.com/nik-mLb/avito_task/internal/transport/password"
+	usert "github.com/nik-mLb/avito_task/internal/transport/user"
+	permissiont "github.com/nik-mLb/avito_task/internal/transport/permission"
+	invitationt "github.com/nik-mLb/avito_task/internal/transport/invitation"
+	sessiont "github.com/nik-mLb/avito_task/internal/transport/session"
 	"github.com/nik-mLb/avito_task/internal/transport/jwt"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware"
 	authuc "github.com/nik-mLb/avito_task/internal/usecase/auth"
+	outboxuc "github.com/nik-mLb/avito_task/internal/usecase/outbox"
+	passworduc "github.com/nik-mLb/avito_task/internal/usecase/password"
+	useruc "github.com/nik-mLb/avito_task/internal/usecase/user"
+	invitationuc "github.com/nik-mLb/avito_task/internal/usecase/invitation"
+	sessionuc "github.com/nik-mLb/avito_task/internal/usecase/session"
 	pickupuc "github.com/nik-mLb/avito_task/internal/usecase/pickup_point"
 	receptionuc "github.com/nik-mLb/avito_task/internal/usecase/reception"
 	productuc "github.com/nik-mLb/avito_task/internal/usecase/product"
+	transferuc "github.com/nik-mLb/avito_task/internal/usecase/transfer"
+	statisticsuc "github.com/nik-mLb/avito_task/internal/usecase/statistics"
+	rollupuc "github.com/nik-mLb/avito_task/internal/usecase/rollup"
+	webhookuc "github.com/nik-mLb/avito_task/internal/usecase/webhook"
+	"github.com/nik-mLb/avito_task/internal/webhook"
+	"github.com/nik-mLb/avito_task/internal/worker"
 	"github.com/sirupsen/logrus"
 )
 
+// webhookBatchSize - сколько доставок вебхуков отправляется за одну итерацию
+const webhookBatchSize = 20
+
+// outboxGapTimeout - сколько лента ждет событие с пропущенным номером outbox, прежде чем считать его откатившимся
+const outboxGapTimeout = 30 * time.Second
+
 // App объединяет все компоненты приложения
 type App struct {
 	conf   *config.Config
 	logger *logrus.Logger
 	db     *sql.DB
 	router *mux.Router
+	tasks  []worker.Task
+	// shutdownTracing выгружает спаны, которые экспортер еще не отправил
+	shutdownTracing tracing.Shutdown
 }
 
 // NewApp инициализирует приложение
 func NewApp(conf *config.Config) (*App, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.WithField("mode", conf.Mode).Info("starting application")
+
+	shutdownTracing, err := tracing.Setup(context.Background(), conf.TracingConfig.Exporter,
+		conf.TracingConfig.Endpoint, conf.TracingConfig.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init tracing: %v", err)
+	}
 
 	// Подключение к БД
 	dbConnStr, err := repository.GetConnectionString(conf.DBConfig)
@@ -44,68 +109,340 @@ func NewApp(conf *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to get connection string: %v", err)
 	}
 
-	db, err := sql.Open("postgres", dbConnStr)
+	db, err := tracing.OpenPostgres(dbConnStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 	config.ConfigureDB(db, conf.DBConfig)
 
+	// Назначать пользователям можно только роли из политики прав
+	policy, err := permission.NewPolicy(rolePermissions(conf.PermissionsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init permissions: %v", err)
+	}
+
 	authRepo := authrepo.New(db)
-	tokenator := jwt.NewTokenator(conf.JWTConfig)
-	authUC := authuc.New(authRepo, tokenator)
+	tokenator, err := jwt.NewTokenator(conf.JWTConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init tokenator: %v", err)
+	}
+	jwksHandler := jwkst.NewJWKSHandler(tokenator)
+	passwordPolicy := authuc.PasswordPolicy{
+		MinLength:      conf.PasswordConfig.MinLength,
+		RequireLetter:  conf.PasswordConfig.RequireLetter,
+		RequireDigit:   conf.PasswordConfig.RequireDigit,
+		RequireUpper:   conf.PasswordConfig.RequireUpper,
+		RequireSpecial: conf.PasswordConfig.RequireSpecial,
+	}
+	authUC := authuc.New(authRepo, tokenator, passwordPolicy, authuc.LoginPolicy{
+		MaxFailures:     conf.LoginConfig.MaxFailures,
+		MaxIPFailures:   conf.LoginConfig.MaxIPFailures,
+		Window:          conf.LoginConfig.FailureWindow,
+		LockoutDuration: conf.LoginConfig.LockoutDuration,
+		DelayBase:       conf.LoginConfig.DelayBase,
+		DelayMax:        conf.LoginConfig.DelayMax,
+	}, authuc.RegistrationPolicy{
+		SelfRegistration: conf.RegistrationConfig.SelfRegistration,
+		Roles:            policy,
+	}, authuc.TwoFactorPolicy{
+		RequiredRoles: twoFactorRequiredRoles(conf.TwoFactorConfig),
+		Issuer:        conf.TwoFactorConfig.Issuer,
+		ChallengeTTL:  conf.TwoFactorConfig.ChallengeTTL,
+	})
 	authHandler := autht.New(authUC)
 
+	var mail passworduc.Mailer
+	switch conf.MailConfig.Sender {
+	case "smtp":
+		mail = mailer.NewSMTPMailer(conf.MailConfig.SMTPAddr, conf.MailConfig.From,
+			conf.MailConfig.SMTPUsername, conf.MailConfig.SMTPPassword)
+	default:
+		fileMailer, err := mailer.NewFileMailer(conf.MailConfig.Dir, conf.MailConfig.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init file mailer: %v", err)
+		}
+		mail = fileMailer
+	}
+
+	passwordUC := passworduc.NewPasswordUsecase(authRepo, mail, passwordPolicy, passworduc.ResetPolicy{
+		TokenTTL: conf.PasswordResetConfig.TokenTTL,
+		ResetURL: conf.PasswordResetConfig.URL,
+	})
+	passwordHandler := passwordt.NewPasswordHandler(passwordUC)
+
+	invitationRepo := invitationrepo.NewInvitationRepository(db)
+	invitationUC := invitationuc.NewInvitationUsecase(invitationRepo, mail, invitationuc.InvitePolicy{
+		TokenTTL:    conf.RegistrationConfig.InvitationTTL,
+		RegisterURL: conf.RegistrationConfig.InvitationURL,
+		Roles:       policy,
+	})
+	invitationHandler := invitationt.NewInvitationHandler(invitationUC)
+
+	sessionRepo := sessionrepo.NewSessionRepository(db)
+	sessionUC := sessionuc.NewSessionUsecase(sessionRepo, conf.JWTConfig.TokenLifeSpan)
+	sessionHandler := sessiont.NewSessionHandler(sessionUC)
+
+	userRepo := userrepo.NewUserRepository(db)
+	userUC := useruc.NewUserUsecase(userRepo, policy)
+	userHandler := usert.NewUserHandler(userUC)
+
+	permissionHandler := permissiont.NewPermissionHandler(policy)
+
 	pickupRepo := pickuprepo.NewPickupPointRepository(db)
 	pickupUC := pickupuc.NewPickupPointUsecase(pickupRepo)
 	pickupHandler := pickupt.NewPickupPointHandler(pickupUC)
 
+	historyRepo := historyrepo.NewHistoryRepository(db)
+	eventBus := eventbus.New(conf.EventsConfig.BufferSize)
+	eventsHandler := eventst.NewEventsHandler(eventBus, conf.EventsConfig.HeartbeatInterval)
+
+	webhookRepo := webhookrepo.NewWebhookRepository(db)
+	webhookUC := webhookuc.NewWebhookUsecase(webhookRepo, webhook.NewSender(conf.WebhookConfig.Timeout, conf.WebhookConfig.AllowPrivateTargets), webhookuc.DeliveryPolicy{
+		MaxAttempts: conf.WebhookConfig.MaxAttempts,
+		BackoffBase: conf.WebhookConfig.BackoffBase,
+		BackoffMax:  conf.WebhookConfig.BackoffMax,
+		BatchSize:   webhookBatchSize,
+		// Отправка идет последовательно, аренды должно хватить на всю порцию
+		Lease: webhookBatchSize*conf.WebhookConfig.Timeout + time.Minute,
+	})
+	webhookHandler := webhookt.NewWebhookHandler(webhookUC)
+
+	// События попадают в ленту и вебхуки только через outbox, после коммита изменения.
+	// Вебхуки и внешние получатели разбирают outbox между репликами, а ленту каждая реплика
+	// читает целиком, иначе клиенты SSE на других репликах не увидят событие
+	outboxRepo := outboxrepo.NewOutboxRepository(db)
+	sinks := []outboxuc.EventSink{webhookUC}
+	for _, sink := range conf.OutboxConfig.Sinks {
+		switch sink {
+		case "stdout":
+			sinks = append(sinks, eventsink.NewStdoutSink())
+		case "file":
+			fileSink, err := eventsink.NewFileSink(conf.OutboxConfig.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open outbox file sink: %v", err)
+			}
+			sinks = append(sinks, fileSink)
+		}
+	}
+	outboxDispatcher := outboxuc.NewDispatcher(outboxRepo, conf.OutboxConfig.BatchSize, sinks...)
+	outboxFanout := outboxuc.NewFanout(outboxRepo, conf.OutboxConfig.BatchSize, outboxGapTimeout, eventBus)
+
 	receptionRepo := receptionrepo.NewReceptionRepository(db)
-	receptionUC := receptionuc.NewReceptionUsecase(receptionRepo)
+	receptionUC := receptionuc.NewReceptionUsecase(receptionRepo, historyRepo, conf.ReceptionConfig.ReopenWindow)
 	receptionHandler := receptiont.NewReceptionHandler(receptionUC)
 
 	productRepo := productrepo.NewProductRepository(db)
 	productuc := productuc.NewProductUsecase(productRepo)
 	productHandler := productt.NewProductHandler(productuc)
+	scannerHandler := scannert.NewScannerHandler(productuc, receptionUC)
+
+	transferRepo := transferrepo.NewTransferRepository(db)
+	transferUC := transferuc.NewTransferUsecase(transferRepo)
+	transferHandler := transfert.NewTransferHandler(transferUC)
+
+	statisticsRepo := statisticsrepo.NewStatisticsRepository(db)
+	statisticsUC := statisticsuc.NewStatisticsUsecase(statisticsRepo)
+	statisticsHandler := statisticst.NewStatisticsHandler(statisticsUC)
+
+	rollupRepo := rolluprepo.NewRollupRepository(db)
+	rollupUC := rollupuc.NewRollupUsecase(rollupRepo, conf.RollupConfig.Lag)
+
+	// Фоновые задачи
+	var tasks []worker.Task
+	if conf.ReceptionConfig.AutoCloseIdleTimeout > 0 && conf.ReceptionConfig.AutoCloseInterval > 0 {
+		idleTimeout := conf.ReceptionConfig.AutoCloseIdleTimeout
+		tasks = append(tasks, worker.Task{
+			Name:     "reception_auto_close",
+			Interval: conf.ReceptionConfig.AutoCloseInterval,
+			Job: func(ctx context.Context) error {
+				_, err := receptionUC.CloseStaleReceptions(ctx, idleTimeout)
+				return err
+			},
+		})
+	}
+
+	if conf.RollupConfig.Interval > 0 {
+		tasks = append(tasks, worker.Task{
+			Name:     "daily_rollup",
+			Interval: conf.RollupConfig.Interval,
+			Job: func(ctx context.Context) error {
+				_, err := rollupUC.Refresh(ctx)
+				return err
+			},
+		})
+	}
+
+	if conf.WebhookConfig.DeliveryInterval > 0 {
+		tasks = append(tasks, worker.Task{
+			Name:     "webhook_delivery",
+			Interval: conf.WebhookConfig.DeliveryInterval,
+			Job: func(ctx context.Context) error {
+				_, err := webhookUC.DeliverDue(ctx)
+				return err
+			},
+		})
+	}
+
+	if conf.OutboxConfig.Interval > 0 {
+		tasks = append(tasks, worker.Task{
+			Name:     "outbox_dispatch",
+			Interval: conf.OutboxConfig.Interval,
+			Job: func(ctx context.Context) error {
+				_, err := outboxDispatcher.DispatchPending(ctx)
+				return err
+			},
+		}, worker.Task{
+			Name:     "outbox_fanout",
+			Interval: conf.OutboxConfig.Interval,
+			Job: func(ctx context.Context) error {
+				_, err := outboxFanout.Poll(ctx)
+				return err
+			},
+		})
+	}
 
 	// Настройка маршрутизатора
 	router := mux.NewRouter()
+	// Спан открывается раньше логирования, чтобы trace_id попал в записи о запросе
+	router.Use(middleware.TraceRequest)
 	router.Use(func(next http.Handler) http.Handler {
 		return middleware.LogRequest(logger, next)
 	})
 
-	router.HandleFunc("/dummyLogin", authHandler.DummyLogin).Methods("POST")
+	router.Use(middleware.RateLimitMiddleware(ratelimit.NewMemoryStore(), rateLimitPolicy(conf.RateLimitConfig), tokenator))
+
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler.JWKS).Methods("GET")
+	// Маршруты для разработки регистрируются только в режимах, где они разрешены
+	if conf.Mode.AllowsDummyLogin() {
+		router.HandleFunc("/dummyLogin", authHandler.DummyLogin).Methods("POST")
+	}
+	if conf.Mode.AllowsDebug() {
+		router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		router.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+	}
 	router.HandleFunc("/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/login/2fa", authHandler.VerifyLogin).Methods("POST")
+	router.HandleFunc("/login/2fa/setup", authHandler.SetupLoginTwoFactor).Methods("POST")
 	router.HandleFunc("/register", authHandler.Register).Methods("POST")
+	router.HandleFunc("/password/forgot", passwordHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/password/reset", passwordHandler.ResetPassword).Methods("POST")
 
-	admin := router.PathPrefix("/pvz").Subrouter()
-	admin.Use(middleware.AuthMiddleware(tokenator))
-	admin.Use(middleware.RoleMiddleware("admin"))
-	admin.HandleFunc("", pickupHandler.CreatePickupPoint).Methods("POST")
-
-	worker := router.PathPrefix("").Subrouter()
-	{
-		worker.HandleFunc("/receptions", receptionHandler.CreateReception).Methods("POST")
-		worker.HandleFunc("/products", productHandler.AddProduct).Methods("POST")
-		worker.HandleFunc("/pvz/{pvzId}/delete_last_product", productHandler.DeleteLastProduct).Methods("POST")
-		worker.HandleFunc("/pvz/{pvzId}/close_last_reception", receptionHandler.CloseReception).Methods("POST")
+	// Все маршруты ниже требуют токен, а каждый из них - свое право
+	api := router.PathPrefix("").Subrouter()
+	api.Use(middleware.AuthMiddleware(tokenator, authUC))
+	require := func(perm permission.Permission, handler http.HandlerFunc) http.Handler {
+		return middleware.PermissionMiddleware(policy, perm)(handler)
 	}
-	worker.Use(middleware.AuthMiddleware(tokenator))
-	worker.Use(middleware.RoleMiddleware("worker"))
 
-	// Добавляем новый endpoint
-	reader := router.PathPrefix("/pvz").Subrouter()
-	reader.Use(middleware.AuthMiddleware(tokenator))
-	reader.Use(middleware.RoleMiddleware("admin", "worker"))
-	reader.HandleFunc("", pickupHandler.GetPickupPointsWithReceptions).Methods("GET")
+	api.HandleFunc("/me/permissions", permissionHandler.Permissions).Methods("GET")
+	api.HandleFunc("/me/sessions", sessionHandler.ListMySessions).Methods("GET")
+	api.HandleFunc("/me/sessions/{sessionId}", sessionHandler.RevokeMySession).Methods("DELETE")
+	api.HandleFunc("/me/2fa/setup", authHandler.SetupTwoFactor).Methods("POST")
+	api.HandleFunc("/me/2fa/enable", authHandler.EnableTwoFactor).Methods("POST")
+	api.HandleFunc("/me/2fa/disable", authHandler.DisableTwoFactor).Methods("POST")
+
+	api.Handle("/pvz", require(permission.PickupPointCreate, pickupHandler.CreatePickupPoint)).Methods("POST")
+	api.Handle("/pvz", require(permission.PickupPointRead, pickupHandler.GetPickupPointsWithReceptions)).Methods("GET")
+	api.Handle("/pvz/export", require(permission.PickupPointRead, pickupHandler.ExportPickupPoints)).Methods("GET")
+	api.Handle("/pvz/{pvzId}/capacity", require(permission.PickupPointUpdate, pickupHandler.SetCapacity)).Methods("PUT")
+	api.Handle("/pvz/{pvzId}/occupancy", require(permission.PickupPointRead, pickupHandler.GetOccupancy)).Methods("GET")
+	api.Handle("/pvz/{pvzId}/transfers", require(permission.TransferRead, transferHandler.ListTransfers)).Methods("GET")
+	api.Handle("/pvz/{pvzId}/transfers/{transferId}/dispatch", require(permission.TransferDispatch, transferHandler.DispatchTransfer)).Methods("POST")
+	api.Handle("/pvz/{pvzId}/transfers/{transferId}/accept", require(permission.TransferAccept, transferHandler.AcceptTransfer)).Methods("POST")
+	api.Handle("/pvz/{pvzId}/delete_last_product", require(permission.ProductDelete, productHandler.DeleteLastProduct)).Methods("POST")
+	api.Handle("/pvz/{pvzId}/close_last_reception", require(permission.ReceptionClose, receptionHandler.CloseReception)).Methods("POST")
+
+	api.Handle("/receptions", require(permission.ReceptionOpen, receptionHandler.CreateReception)).Methods("POST")
+	api.Handle("/receptions/{receptionId}/reopen", require(permission.ReceptionReopen, receptionHandler.ReopenReception)).Methods("POST")
+	api.Handle("/receptions/{receptionId}/history", require(permission.ReceptionRead, receptionHandler.GetReceptionHistory)).Methods("GET")
+	api.Handle("/products", require(permission.ProductAdd, productHandler.AddProduct)).Methods("POST")
+	api.Handle("/scanner/session", require(permission.ScannerUse, scannerHandler.Session)).Methods("GET")
+
+	api.Handle("/transfers", require(permission.TransferCreate, transferHandler.CreateTransfer)).Methods("POST")
+
+	api.Handle("/stats", require(permission.StatsRead, statisticsHandler.GetStats)).Methods("GET")
+	api.Handle("/events/stream", require(permission.EventsRead, eventsHandler.Stream)).Methods("GET")
+
+	api.Handle("/webhooks", require(permission.WebhookManage, webhookHandler.CreateSubscription)).Methods("POST")
+	api.Handle("/webhooks", require(permission.WebhookManage, webhookHandler.ListSubscriptions)).Methods("GET")
+	api.Handle("/webhooks/{webhookId}", require(permission.WebhookManage, webhookHandler.DeleteSubscription)).Methods("DELETE")
+	api.Handle("/webhooks/{webhookId}/deliveries", require(permission.WebhookManage, webhookHandler.ListDeliveries)).Methods("GET")
+	api.Handle("/webhooks/deliveries/{deliveryId}/redeliver", require(permission.WebhookManage, webhookHandler.Redeliver)).Methods("POST")
+
+	api.Handle("/users", require(permission.UserRead, userHandler.ListUsers)).Methods("GET")
+	api.Handle("/users/unlock", require(permission.UserManage, authHandler.UnlockLogin)).Methods("POST")
+	api.Handle("/users/{userId}", require(permission.UserRead, userHandler.GetUser)).Methods("GET")
+	api.Handle("/users/{userId}/role", require(permission.UserManage, userHandler.ChangeRole)).Methods("PUT")
+	api.Handle("/users/{userId}/deactivate", require(permission.UserManage, userHandler.Deactivate)).Methods("POST")
+	api.Handle("/users/{userId}/reactivate", require(permission.UserManage, userHandler.Reactivate)).Methods("POST")
+	api.Handle("/users/{userId}/sessions", require(permission.UserRead, sessionHandler.ListUserSessions)).Methods("GET")
+	api.Handle("/users/{userId}/sessions/{sessionId}", require(permission.UserManage, sessionHandler.RevokeUserSession)).Methods("DELETE")
+
+	api.Handle("/invitations", require(permission.UserInvite, invitationHandler.CreateInvitation)).Methods("POST")
+	api.Handle("/invitations", require(permission.UserInvite, invitationHandler.ListInvitations)).Methods("GET")
+	api.Handle("/invitations/{invitationId}", require(permission.UserInvite, invitationHandler.RevokeInvitation)).Methods("DELETE")
 
 	return &App{
 		conf:   conf,
 		logger: logger,
 		db:     db,
 		router: router,
+		tasks:  tasks,
+
+		shutdownTracing: shutdownTracing,
 	}, nil
 }
 
+// rateLimitPolicy переводит ограничения из конфига в политику ограничителя запросов
+func rateLimitPolicy(conf *config.RateLimitConfig) ratelimit.Policy {
+	limit := func(l config.RateLimit) ratelimit.Limit {
+		return ratelimit.Limit{Requests: l.Requests, Period: l.Period}
+	}
+
+	policy := ratelimit.Policy{
+		Default:   limit(conf.Default),
+		Anonymous: limit(conf.Anonymous),
+		Roles:     make(map[string]ratelimit.Limit, len(conf.Roles)),
+		Routes:    make(map[string]ratelimit.Limit, len(conf.Routes)),
+	}
+	for role, l := range conf.Roles {
+		policy.Roles[role] = limit(l)
+	}
+	for route, l := range conf.Routes {
+		policy.Routes[route] = limit(l)
+	}
+	return policy
+}
+
+// rolePermissions переводит права ролей из конфига в политику. Без настроек действуют права по умолчанию
+func rolePermissions(conf *config.PermissionsConfig) map[string][]permission.Permission {
+	if len(conf.Roles) == 0 {
+		return permission.DefaultRoles
+	}
+
+	roles := make(map[string][]permission.Permission, len(conf.Roles))
+	for role, perms := range conf.Roles {
+		for _, perm := range perms {
+			roles[role] = append(roles[role], permission.Permission(perm))
+		}
+	}
+	return roles
+}
+
+func twoFactorRequiredRoles(conf *config.TwoFactorConfig) map[string]bool {
+	roles := make(map[string]bool, len(conf.RequiredRoles))
+	for _, role := range conf.RequiredRoles {
+		roles[role] = true
+	}
+	return roles
+}
+
+// shutdownTimeout сколько ждем завершения активных запросов и выгрузки спанов при остановке
+const shutdownTimeout = 15 * time.Second
+
 // Run запускает HTTP-сервер
 func (a *App) Run() {
 	server := &http.Server{
@@ -113,9 +450,62 @@ func (a *App) Run() {
 		Handler: a.router,
 	}
 
-	a.logger.Infof("Starting server on port %s", a.conf.ServerConfig.Port)
-	if err := server.ListenAndServe(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	for _, task := range a.tasks {
+		workers.Add(1)
+		go func(task worker.Task) {
+			defer workers.Done()
+			worker.Run(ctx, a.logger, task)
+		}(task)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.logger.Infof("Starting server on port %s", a.conf.ServerConfig.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		stop()
+		workers.Wait()
+		a.flushTracing()
 		a.logger.Fatalf("Server failed: %v", err)
+	case <-ctx.Done():
+	}
+	// повторный сигнал завершает процесс сразу, не дожидаясь остановки
+	stop()
+	a.logger.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// долгие соединения (SSE) не закрываются сами, обрываем их принудительно
+		a.logger.WithError(err).Error("graceful shutdown timed out, closing connections")
+		if err := server.Close(); err != nil {
+			a.logger.WithError(err).Error("failed to close server")
+		}
+	}
+	workers.Wait()
+
+	a.flushTracing()
+	if err := a.db.Close(); err != nil {
+		a.logger.WithError(err).Error("failed to close database")
+	}
+	a.logger.Info("Server stopped")
+}
+
+// flushTracing выгружает накопленные спаны, не дольше shutdownTimeout
+func (a *App) flushTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := a.shutdownTracing(ctx); err != nil {
+		a.logger.WithError(err).Error("failed to flush traces")
 	}
 }
 
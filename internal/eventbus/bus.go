@@ -0,0 +1,154 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+)
+
+// subscriberBuffer - сколько событий может ждать медленный подписчик, прежде чем его отключат
+const subscriberBuffer = 64
+
+// Bus - внутрипроцессная шина событий. Последние события хранятся в кольцевом буфере
+// для повторной отправки переподключившимся клиентам (Last-Event-ID)
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []models.Event
+	start       int
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription - подписка на события. Канал Events закрывается, если подписчик не успевает
+// читать события или шина отписала его через Close
+type Subscription struct {
+	// Replay - события из буфера после lastEventID, подходящие под фильтр
+	Replay []models.Event
+	// Missed - часть событий после lastEventID уже вытеснена из буфера, и клиенту стоит перечитать состояние
+	Missed bool
+	Events <-chan models.Event
+
+	events chan models.Event
+	filter models.Filter
+	bus    *Bus
+	once   sync.Once
+}
+
+func New(size int) *Bus {
+	if size < 1 {
+		size = 1
+	}
+	return &Bus{
+		buffer:      make([]models.Event, 0, size),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish сохраняет событие в буфере и рассылает подписчикам. Номер события - id из outbox,
+// он же Last-Event-ID клиента; повторно пришедшее событие с тем же номером пропускается.
+// Не блокируется: подписчик с переполненным каналом отключается
+func (b *Bus) Publish(_ context.Context, event models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.bufferedLocked(event.ID) {
+		return
+	}
+	if event.ID > b.lastID {
+		b.lastID = event.ID
+	}
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	if len(b.buffer) < cap(b.buffer) {
+		b.buffer = append(b.buffer, event)
+	} else {
+		b.buffer[b.start] = event
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.dropLocked(sub)
+		}
+	}
+}
+
+// Handle позволяет подключить шину получателем событий outbox
+func (b *Bus) Handle(ctx context.Context, event models.Event) error {
+	b.Publish(ctx, event)
+	return nil
+}
+
+// Subscribe подписывает на события после lastEventID (0 - только новые)
+func (b *Bus) Subscribe(lastEventID uint64, filter models.Filter) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan models.Event, subscriberBuffer)
+	sub := &Subscription{
+		Events: events,
+		events: events,
+		filter: filter,
+		bus:    b,
+	}
+
+	if lastEventID > 0 {
+		// Номер из будущего означает, что реплика еще не получила эти события или клиент
+		// переподключился к другой реплике, поэтому досылать по нему нечего
+		sub.Missed = lastEventID > b.lastID || lastEventID+1 < b.oldestLocked()
+
+		for i := 0; i < len(b.buffer); i++ {
+			event := b.buffer[(b.start+i)%len(b.buffer)]
+			if (sub.Missed || event.ID > lastEventID) && filter.Match(event) {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Close отписывает подписчика; повторный вызов безопасен
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.dropLocked(s)
+}
+
+// oldestLocked возвращает наименьший номер в буфере: события из outbox приходят не строго по порядку
+func (b *Bus) oldestLocked() uint64 {
+	var oldest uint64
+	for i, event := range b.buffer {
+		if i == 0 || event.ID < oldest {
+			oldest = event.ID
+		}
+	}
+	return oldest
+}
+
+func (b *Bus) bufferedLocked(id uint64) bool {
+	for _, event := range b.buffer {
+		if event.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bus) dropLocked(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	})
+}
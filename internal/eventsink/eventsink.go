@@ -0,0 +1,79 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+)
+
+// WriterSink пишет события в формате JSON Lines
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewStdoutSink пишет события в стандартный вывод
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+func (s *WriterSink) Handle(_ context.Context, event models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(line); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	return nil
+}
+
+// FileSink дописывает события в файл и сбрасывает их на диск до подтверждения
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event file: %w", err)
+	}
+
+	return &FileSink{
+		WriterSink: NewWriterSink(file),
+		file:       file,
+	}, nil
+}
+
+func (s *FileSink) Handle(ctx context.Context, event models.Event) error {
+	if err := s.WriterSink.Handle(ctx, event); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("sync event file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -104,6 +104,7 @@ func (s *IntegrationTestSuite) SetupSuite() {
 
 	// Тестовый конфиг
 	testConfig := &config.Config{
+		Mode: config.ModeTest,
 		DBConfig: &config.DBConfig{
 			User:            "test",
 			Password:        "test",
@@ -124,6 +125,71 @@ func (s *IntegrationTestSuite) SetupSuite() {
 		MigrationsConfig: &config.MigrationsConfig{
 			Path: fmt.Sprintf("file://%s", migrationsPath),
 		},
+		ReceptionConfig: &config.ReceptionConfig{
+			AutoCloseIdleTimeout: 12 * time.Hour,
+			AutoCloseInterval:    5 * time.Minute,
+			ReopenWindow:         30 * time.Minute,
+		},
+		RollupConfig: &config.RollupConfig{
+			Interval: 5 * time.Minute,
+			Lag:      time.Minute,
+		},
+		EventsConfig: &config.EventsConfig{
+			BufferSize:        1000,
+			HeartbeatInterval: 15 * time.Second,
+		},
+		WebhookConfig: &config.WebhookConfig{
+			DeliveryInterval: 5 * time.Second,
+			Timeout:          10 * time.Second,
+			MaxAttempts:      8,
+			BackoffBase:      30 * time.Second,
+			BackoffMax:       time.Hour,
+		},
+		OutboxConfig: &config.OutboxConfig{
+			Interval:  time.Second,
+			BatchSize: 100,
+		},
+		PasswordConfig: &config.PasswordConfig{
+			MinLength:     8,
+			RequireLetter: true,
+			RequireDigit:  true,
+		},
+		LoginConfig: &config.LoginConfig{
+			MaxFailures:     5,
+			MaxIPFailures:   20,
+			FailureWindow:   15 * time.Minute,
+			LockoutDuration: 15 * time.Minute,
+			DelayBase:       250 * time.Millisecond,
+			DelayMax:        4 * time.Second,
+		},
+		RateLimitConfig: &config.RateLimitConfig{
+			Default:   config.RateLimit{Requests: 600, Period: time.Minute},
+			Anonymous: config.RateLimit{Requests: 120, Period: time.Minute},
+		},
+		MailConfig: &config.MailConfig{
+			Sender: "file",
+			From:   "no-reply@localhost",
+			Dir:    s.T().TempDir(),
+		},
+		PasswordResetConfig: &config.PasswordResetConfig{
+			TokenTTL: time.Hour,
+			URL:      "http://localhost:8080/password/reset",
+		},
+		PermissionsConfig: &config.PermissionsConfig{},
+		// Тест регистрирует пользователей сам, без приглашений
+		RegistrationConfig: &config.RegistrationConfig{
+			SelfRegistration: true,
+			InvitationTTL:    72 * time.Hour,
+			InvitationURL:    "http://localhost:8080/register",
+		},
+		TwoFactorConfig: &config.TwoFactorConfig{
+			Issuer:       "PVZ Service",
+			ChallengeTTL: 5 * time.Minute,
+		},
+		TracingConfig: &config.TracingConfig{
+			Exporter:    "none",
+			ServiceName: "pvz-service",
+		},
 	}
 
 	application, err := app.NewApp(testConfig)
@@ -139,7 +205,7 @@ func (s *IntegrationTestSuite) getAuthToken() {
 	// Регистрация администратора
 	registerData := map[string]interface{}{
 		"email":    "admin@test.com",
-		"password": "testpass",
+		"password": "testpass1",
 		"role":     "admin",
 	}
 	jsonData, _ := json.Marshal(registerData)
@@ -151,7 +217,7 @@ func (s *IntegrationTestSuite) getAuthToken() {
 	// Логин для получения токена
 	loginData := map[string]interface{}{
 		"email":    "admin@test.com",
-		"password": "testpass",
+		"password": "testpass1",
 	}
 	jsonData, _ = json.Marshal(loginData)
 
@@ -192,7 +258,7 @@ func (s *IntegrationTestSuite) TestFullFlow() {
     // 1. Создаем нового worker пользователя
     workerData := map[string]interface{}{
         "email":    "worker@test.com",
-        "password": "workerpass",
+        "password": "workerpass1",
         "role":     "worker",
     }
     jsonWorker, _ := json.Marshal(workerData)
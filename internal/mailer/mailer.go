@@ -0,0 +1,105 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "github.com/nik-mLb/avito_task/internal/models/mail"
+)
+
+// format собирает письмо в формате RFC 5322. Перевод строки в заголовках запрещен,
+// чтобы через адрес или тему нельзя было подставить свои заголовки
+func format(from string, msg models.Message, now time.Time) ([]byte, error) {
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errors.New("mail header contains a line break")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
+
+// FileMailer сохраняет каждое письмо в отдельный .eml файл. Подходит для разработки и тестов
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create mail dir: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg models.Message) error {
+	now := time.Now()
+	data, err := format(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("generate mail file name: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("write mail file: %w", err)
+	}
+	return nil
+}
+
+// SMTPMailer отправляет письма через SMTP-сервер. Без имени пользователя отправка идет без авторизации
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(addr, from, username, password string) *SMTPMailer {
+	m := &SMTPMailer{addr: addr, from: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send отправляет письмо. net/smtp не поддерживает контекст, поэтому отмена проверяется только до отправки
+func (m *SMTPMailer) Send(ctx context.Context, msg models.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := format(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, data); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
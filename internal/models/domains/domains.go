@@ -3,4 +3,10 @@ package domains
 type (
 	ReqIDKey  struct{}
 	LoggerKey struct{}
-)
\ No newline at end of file
+	UserIDKey struct{}
+	RoleKey   struct{}
+	// SessionIDKey - сессия токена запроса
+	SessionIDKey struct{}
+	// PickupPointIDKey - ПВЗ, которым ограничен пользователь токена
+	PickupPointIDKey struct{}
+)
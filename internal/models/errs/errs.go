@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
@@ -10,4 +13,81 @@ var (
 	ErrNoActiveReceptionToClose = errors.New("no active reception to close")
 	ErrCityNotAllowed = errors.New("city not allowed")
 	ErrRoleNotAllowed = errors.New("role not allowed")
-)
\ No newline at end of file
+	ErrInvalidReceptionID = errors.New("invalid reception id")
+	ErrReceptionNotFound = errors.New("reception not found")
+	ErrReceptionNotClosed = errors.New("reception is not closed")
+	ErrReopenWindowExpired = errors.New("reopen window has expired")
+	ErrNewerReceptionExists = errors.New("pickup point has a newer reception")
+	ErrReopenReasonRequired = errors.New("reopen reason is required")
+	ErrInvalidTransferRequest = errors.New("invalid transfer request")
+	ErrTransferSamePickupPoint = errors.New("transfer source and destination are the same")
+	ErrTransferNotFound = errors.New("transfer not found")
+	ErrTransferStatusConflict = errors.New("transfer is not in the required status")
+	ErrTransferProductsUnavailable = errors.New("some products are not available for transfer")
+	ErrTransferWrongPickupPoint = errors.New("transfer does not belong to this pickup point")
+	ErrPickupPointNotFound = errors.New("pickup point not found")
+	ErrPickupPointFull = errors.New("pickup point capacity exceeded")
+	ErrPickupPointForbidden = errors.New("user is not allowed to work with this pickup point")
+	ErrInvalidCapacity = errors.New("invalid capacity settings")
+	ErrInvalidPickupPointID = errors.New("invalid pickup point id")
+	ErrInvalidPagination = errors.New("invalid page or limit")
+	ErrInvalidStatsGroupBy = errors.New("invalid stats grouping")
+	ErrInvalidDateRange = errors.New("invalid date range")
+	ErrInvalidProductType = errors.New("invalid product type")
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidEmail = errors.New("invalid email")
+	ErrWeakPassword = errors.New("password does not meet the policy")
+	ErrUserAlreadyExists = errors.New("user with this email already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrLoginLocked = errors.New("too many failed login attempts")
+	ErrInvalidUnlockRequest = errors.New("email or ip is required")
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+	ErrTokenRevoked = errors.New("token has been revoked")
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidUserID = errors.New("invalid user id")
+	ErrUserDeactivated = errors.New("user is deactivated")
+	ErrSelfModification = errors.New("admin cannot change own role or status")
+	ErrRegistrationClosed = errors.New("registration is available by invitation only")
+	ErrInvalidInvitation = errors.New("invitation is invalid, used or expired")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidInvitationID = errors.New("invalid invitation id")
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required")
+	ErrInvalidLoginChallenge = errors.New("login challenge is invalid or expired")
+	ErrInvalidOTP = errors.New("invalid one-time code")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetUp = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorMandatory = errors.New("two-factor authentication is mandatory for the role")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidSessionID = errors.New("invalid session id")
+)
+
+// LoginLockedError сообщает, через сколько можно повторить вход. Сравнивается с ErrLoginLocked через errors.Is
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
+
+// TwoFactorRequiredError - пароль верный, но для входа нужен код. Challenge передается во второй шаг входа,
+// Enroll означает, что TOTP для роли обязателен, а пользователь его еще не настроил.
+// Сравнивается с ErrTwoFactorRequired через errors.Is
+type TwoFactorRequiredError struct {
+	Challenge string
+	Enroll    bool
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
\ No newline at end of file
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+)
+
+// Event - событие живой ленты. Типы совпадают с типами истории приемки
+type Event struct {
+	ID            uint64            `json:"id"`
+	Type          history.EventType `json:"type"`
+	PickupPointID uuid.UUID         `json:"pvzId"`
+	ReceptionID   uuid.UUID         `json:"receptionId"`
+	ProductID     *uuid.UUID        `json:"productId,omitempty"`
+	ProductType   string            `json:"productType,omitempty"`
+	ActorID       *uuid.UUID        `json:"actorId,omitempty"`
+	Details       string            `json:"details,omitempty"`
+	At            time.Time         `json:"at"`
+}
+
+// Filter отбирает события подписчика; пустые поля не ограничивают выборку
+type Filter struct {
+	PickupPointID *uuid.UUID
+	Types         map[history.EventType]bool
+}
+
+func (f Filter) Match(event Event) bool {
+	if f.PickupPointID != nil && *f.PickupPointID != event.PickupPointID {
+		return false
+	}
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	return true
+}
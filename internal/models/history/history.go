@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	ReceptionOpened     EventType = "reception_opened"
+	ProductAdded        EventType = "product_added"
+	ProductDeleted      EventType = "product_deleted"
+	ReceptionClosed     EventType = "reception_closed"
+	ReceptionAutoClosed EventType = "reception_auto_closed"
+	ReceptionReopened   EventType = "reception_reopened"
+	TransferAccepted    EventType = "transfer_accepted"
+	ProductTransferred  EventType = "product_transferred"
+)
+
+type Event struct {
+	ID          int64      `json:"id"`
+	ReceptionID uuid.UUID  `json:"receptionId"`
+	Type        EventType  `json:"type"`
+	ProductID   *uuid.UUID `json:"productId,omitempty"`
+	ProductType string     `json:"productType,omitempty"`
+	ActorID     *uuid.UUID `json:"actorId,omitempty"`
+	Details     string     `json:"details,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation - приглашение на регистрацию. Пустой PickupPointID означает доступ ко всем ПВЗ.
+// Сам токен приглашения не хранится и уходит только в письме
+type Invitation struct {
+	ID            uuid.UUID  `json:"id"`
+	Email         string     `json:"email"`
+	Role          string     `json:"role"`
+	PickupPointID *uuid.UUID `json:"pvzId,omitempty"`
+	CreatedBy     uuid.UUID  `json:"createdBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+}
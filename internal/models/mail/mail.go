@@ -0,0 +1,8 @@
+package models
+
+// Message - письмо пользователю
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -2,10 +2,42 @@ package models
 
 import (
 	"github.com/google/uuid"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+	reception "github.com/nik-mLb/avito_task/internal/models/reception"
 )
 
 type PickupPoint struct {
 	ID        uuid.UUID `json:"id"`
 	City      string    `json:"city"`
 	RegistrationDate string `json:"registrationDate"`
-}
\ No newline at end of file
+}
+
+type CapacityMode string
+
+const (
+	// CapacityHard запрещает добавлять товары сверх вместимости
+	CapacityHard CapacityMode = "hard"
+	// CapacitySoft разрешает превышение, но фиксирует его в логах
+	CapacitySoft CapacityMode = "soft"
+)
+
+type Occupancy struct {
+	PickupPointID uuid.UUID       `json:"pvzId"`
+	Capacity      *int            `json:"capacity"`
+	Mode          CapacityMode    `json:"mode"`
+	Occupied      int             `json:"occupied"`
+	Available     *int            `json:"available"`
+	ByType        []TypeOccupancy `json:"byType"`
+}
+
+type TypeOccupancy struct {
+	ProductType string `json:"type"`
+	Count       int    `json:"count"`
+}
+
+// ExportRow - плоская строка выгрузки: ПВЗ, приемка и товар. Product равен nil для приемки без товаров
+type ExportRow struct {
+	PickupPoint PickupPoint         `json:"pvz"`
+	Reception   reception.Reception `json:"reception"`
+	Product     *product.Product    `json:"product"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mismatch - расхождение агрегата с сырыми данными. ProductType пуст для агрегата по ПВЗ
+type Mismatch struct {
+	Day               time.Time `json:"day"`
+	PickupPointID     uuid.UUID `json:"pvzId"`
+	ProductType       string    `json:"productType,omitempty"`
+	RollupReceptions  int64     `json:"rollupReceptions"`
+	RawReceptions     int64     `json:"rawReceptions"`
+	RollupProducts    int64     `json:"rollupProducts"`
+	RawProducts       int64     `json:"rawProducts"`
+	DurationsMismatch bool      `json:"durationsMismatch"`
+}
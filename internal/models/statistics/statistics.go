@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type GroupBy string
+
+const (
+	GroupByPickupPoint GroupBy = "pvz"
+	GroupByCity        GroupBy = "city"
+	GroupByDay         GroupBy = "day"
+	GroupByWeek        GroupBy = "week"
+	GroupByProductType GroupBy = "productType"
+)
+
+// Filter задает группировку и диапазон дат приемок; nil означает отсутствие границы
+type Filter struct {
+	GroupBy   GroupBy
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// Duration описывает длительность приемок в секундах. Учитываются только закрытые приемки,
+// поэтому при их отсутствии значения не заполняются
+type Duration struct {
+	Avg *float64 `json:"avg"`
+	P50 *float64 `json:"p50"`
+	P90 *float64 `json:"p90"`
+	P95 *float64 `json:"p95"`
+}
+
+type Group struct {
+	Key                  string   `json:"key"`
+	Receptions           int64    `json:"receptions"`
+	Products             int64    `json:"products"`
+	ProductsPerReception float64  `json:"productsPerReception"`
+	DurationSeconds      Duration `json:"durationSeconds"`
+}
+
+type Report struct {
+	GroupBy   GroupBy    `json:"groupBy"`
+	StartDate *time.Time `json:"startDate,omitempty"`
+	EndDate   *time.Time `json:"endDate,omitempty"`
+	Groups    []Group    `json:"groups"`
+}
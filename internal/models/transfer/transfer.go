@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+)
+
+type Status string
+
+const (
+	StatusCreated    Status = "created"
+	StatusDispatched Status = "dispatched"
+	StatusAccepted   Status = "accepted"
+)
+
+type Transfer struct {
+	ID                uuid.UUID         `json:"id"`
+	FromPickupPointID uuid.UUID         `json:"fromPvzId"`
+	ToPickupPointID   uuid.UUID         `json:"toPvzId"`
+	Status            Status            `json:"status"`
+	CreatedBy         uuid.UUID         `json:"createdBy"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	DispatchedAt      *time.Time        `json:"dispatchedAt,omitempty"`
+	AcceptedAt        *time.Time        `json:"acceptedAt,omitempty"`
+	ReceptionID       *uuid.UUID        `json:"receptionId,omitempty"`
+	Products          []product.Product `json:"products"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 const (
 	RoleClient   = "client"
@@ -13,4 +17,61 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash []byte    `json:"-"`
 	Role         string    `json:"role"`
-}
\ No newline at end of file
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// PickupPointID - ПВЗ из приглашения, пустое значение - без привязки к ПВЗ
+	PickupPointID    *uuid.UUID `json:"pvzId,omitempty"`
+	TwoFactorEnabled bool       `json:"twoFactorEnabled"`
+}
+
+// UserFilter - условия выборки пользователей в админке. Query ищет по подстроке email,
+// пустые Role и Active не ограничивают выборку
+type UserFilter struct {
+	Query  string
+	Role   string
+	Active *bool
+	Page   int
+	Limit  int
+}
+
+// LoginScope - по какому признаку считаются неудачные попытки входа
+type LoginScope string
+
+const (
+	LoginScopeEmail LoginScope = "email"
+	LoginScopeIP    LoginScope = "ip"
+)
+
+// LoginThrottle - состояние попыток входа для пары email и IP.
+// Failures - наибольшее число неудач в окне, LockedFor - сколько еще действует блокировка
+type LoginThrottle struct {
+	Failures  int
+	LockedFor time.Duration
+}
+
+// TokenState - что нужно знать о пользователе для проверки его токена.
+// Токены, выданные раньше ValidAfter, отозваны, а токены деактивированного пользователя не принимаются
+type TokenState struct {
+	ValidAfter time.Time
+	Active     bool
+}
+
+// TwoFactor - состояние TOTP пользователя. Secret появляется при настройке, а Enabled - после
+// подтверждения первым кодом. Коды с шагом не больше LastStep уже использованы
+type TwoFactor struct {
+	UserID   uuid.UUID
+	Email    string
+	Role     string
+	Active   bool
+	Secret   string
+	Enabled  bool
+	LastStep int64
+	// PickupPointID - ПВЗ пользователя, попадает в токен после второго шага входа
+	PickupPointID *uuid.UUID
+}
+
+// TwoFactorSetup - данные для приложения-аутентификатора. URI обычно показывают QR-кодом
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Subscription - подписка партнера. Пустой EventTypes означает все типы, пустой PickupPointID - все ПВЗ
+type Subscription struct {
+	ID            uuid.UUID           `json:"id"`
+	URL           string              `json:"url"`
+	EventTypes    []history.EventType `json:"eventTypes"`
+	PickupPointID *uuid.UUID          `json:"pvzId,omitempty"`
+	Secret        string              `json:"secret,omitempty"`
+	CreatedBy     uuid.UUID           `json:"createdBy"`
+	CreatedAt     time.Time           `json:"createdAt"`
+}
+
+// Delivery - запись очереди доставки и журнала попыток
+type Delivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscriptionId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	RedeliveryOf   *int64          `json:"redeliveryOf,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// Target - доставка вместе с адресом и секретом подписки, выбранная для отправки
+type Target struct {
+	Delivery Delivery
+	URL      string
+	Secret   string
+}
@@ -0,0 +1,108 @@
+package permission
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Permission - право на действие в формате "<ресурс>:<действие>"
+type Permission string
+
+const (
+	PickupPointCreate Permission = "pvz:create"
+	PickupPointUpdate Permission = "pvz:update"
+	PickupPointRead   Permission = "pvz:read"
+	ReceptionOpen     Permission = "reception:open"
+	ReceptionClose    Permission = "reception:close"
+	ReceptionReopen   Permission = "reception:reopen"
+	ReceptionRead     Permission = "reception:read"
+	ProductAdd        Permission = "product:add"
+	ProductDelete     Permission = "product:delete"
+	TransferCreate    Permission = "transfer:create"
+	TransferDispatch  Permission = "transfer:dispatch"
+	TransferAccept    Permission = "transfer:accept"
+	TransferRead      Permission = "transfer:read"
+	ScannerUse        Permission = "scanner:use"
+	StatsRead         Permission = "stats:read"
+	EventsRead        Permission = "events:read"
+	WebhookManage     Permission = "webhook:manage"
+	UserRead          Permission = "user:read"
+	UserManage        Permission = "user:manage"
+	UserInvite        Permission = "user:invite"
+)
+
+// All - все известные права. Права не из этого списка в конфиге считаются ошибкой
+var All = []Permission{
+	PickupPointCreate, PickupPointUpdate, PickupPointRead,
+	ReceptionOpen, ReceptionClose, ReceptionReopen, ReceptionRead,
+	ProductAdd, ProductDelete,
+	TransferCreate, TransferDispatch, TransferAccept, TransferRead,
+	ScannerUse, StatsRead, EventsRead, WebhookManage,
+	UserRead, UserManage, UserInvite,
+}
+
+// DefaultRoles - права ролей, если в конфиге они не заданы
+var DefaultRoles = map[string][]Permission{
+	"admin": {
+		PickupPointCreate, PickupPointUpdate, PickupPointRead,
+		ReceptionReopen, ReceptionRead,
+		TransferCreate, TransferRead,
+		StatsRead, EventsRead, WebhookManage,
+		UserRead, UserManage, UserInvite,
+	},
+	"worker": {
+		PickupPointRead,
+		ReceptionOpen, ReceptionClose, ReceptionRead,
+		ProductAdd, ProductDelete,
+		TransferDispatch, TransferAccept, TransferRead,
+		ScannerUse, EventsRead,
+	},
+}
+
+// Policy - соответствие ролей и прав. Роль, которой нет в политике, не имеет прав
+type Policy struct {
+	roles map[string]map[Permission]bool
+}
+
+// NewPolicy проверяет, что все права известны, и строит политику
+func NewPolicy(roles map[string][]Permission) (*Policy, error) {
+	known := make(map[Permission]bool, len(All))
+	for _, perm := range All {
+		known[perm] = true
+	}
+
+	p := &Policy{roles: make(map[string]map[Permission]bool, len(roles))}
+	for role, perms := range roles {
+		set := make(map[Permission]bool, len(perms))
+		for _, perm := range perms {
+			if !known[perm] {
+				return nil, fmt.Errorf("unknown permission %q for role %q", perm, role)
+			}
+			set[perm] = true
+		}
+		p.roles[role] = set
+	}
+
+	return p, nil
+}
+
+// Allowed сообщает, есть ли у роли право
+func (p *Policy) Allowed(role string, perm Permission) bool {
+	return p.roles[role][perm]
+}
+
+// HasRole сообщает, есть ли роль в политике. Только такие роли можно назначать пользователям
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Permissions возвращает права роли в алфавитном порядке
+func (p *Policy) Permissions(role string) []Permission {
+	perms := make([]Permission, 0, len(p.roles[role]))
+	for perm := range p.roles[role] {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval - как часто MemoryStore удаляет заполнившиеся корзины
+const sweepInterval = time.Minute
+
+// Limit - не больше Requests запросов за Period. Корзина вмещает Requests токенов
+// и пополняется равномерно, поэтому допускает всплеск до Requests запросов подряд
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled сообщает, задано ли ограничение
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// Result - решение по запросу и состояние корзины после него
+type Result struct {
+	Allowed bool
+	// Remaining - сколько запросов можно сделать сразу
+	Remaining int
+	// RetryAfter - через сколько появится следующий токен, если запрос отклонен
+	RetryAfter time.Duration
+	// Reset - через сколько корзина заполнится полностью
+	Reset time.Duration
+}
+
+// Bucket - корзина Key с ограничением Limit
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store хранит корзины токенов. MemoryStore подходит для одного экземпляра сервиса,
+// при нескольких экземплярах нужна общая реализация (например, поверх Redis)
+type Store interface {
+	// Take забирает по токену из каждой корзины, только если токен есть во всех.
+	// Если хоть одна корзина отклоняет запрос, ни одна не списывается.
+	// Результаты возвращаются в порядке buckets
+	Take(ctx context.Context, buckets ...Bucket) ([]Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// MemoryStore - хранилище корзин в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take забирает по токену из всех корзин сразу. Новая корзина создается полной
+func (s *MemoryStore) Take(_ context.Context, buckets ...Bucket) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	states := make([]*bucket, len(buckets))
+	allowed := true
+	for i, req := range buckets {
+		states[i] = s.refill(req.Key, req.Limit, now)
+		if states[i].tokens < 1 {
+			allowed = false
+		}
+	}
+
+	results := make([]Result, len(buckets))
+	for i, req := range buckets {
+		b := states[i]
+		capacity := float64(req.Limit.Requests)
+		perToken := req.Limit.Period / time.Duration(req.Limit.Requests)
+
+		result := Result{Allowed: b.tokens >= 1}
+		if allowed {
+			b.tokens--
+		} else if !result.Allowed {
+			result.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+		}
+		result.Remaining = int(b.tokens)
+		result.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+		b.full = now.Add(result.Reset)
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// refill возвращает корзину key, пополненную на момент now
+func (s *MemoryStore) refill(key string, limit Limit, now time.Time) *bucket {
+	capacity := float64(limit.Requests)
+	perToken := limit.Period / time.Duration(limit.Requests)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens += float64(now.Sub(b.updated)) / float64(perToken)
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.updated = now
+	return b
+}
+
+// sweep удаляет корзины, которые уже заполнились: они не отличаются от новых
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// Policy - ограничения запросов. Общий лимит субъекта берется из Roles по роли пользователя,
+// иначе Default, а для запросов без токена - Anonymous. Лимит из Routes
+// (ключ "METHOD /path/{var}") действует дополнительно к общему, отдельно для каждого субъекта
+type Policy struct {
+	Default   Limit
+	Anonymous Limit
+	Roles     map[string]Limit
+	Routes    map[string]Limit
+}
+
+// SubjectLimit возвращает общий лимит для роли. Пустая роль означает анонимный запрос
+func (p Policy) SubjectLimit(role string) Limit {
+	if role == "" {
+		return p.Anonymous
+	}
+	if limit, ok := p.Roles[role]; ok {
+		return limit
+	}
+	return p.Default
+}
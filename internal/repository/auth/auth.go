@@ -5,8 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
 	models "github.com/nik-mLb/avito_task/internal/models/user"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
@@ -18,11 +22,161 @@ const (
 		RETURNING id, email, role`
 
 	getUserByEmailQuery = `
-		SELECT id, email, password_hash, role 
+		SELECT id, email, password_hash, role, active, totp_enabled, pickup_point_id
 		FROM "user" 
-		WHERE email = $1`
+		WHERE lower(email) = lower($1)`
+
+	// Неудачи старше окна не учитываются, блокировка действует до locked_until
+	GetLoginThrottleQuery = `
+		SELECT
+			COALESCE(max(CASE WHEN last_failure_at >= now() - make_interval(secs => $3) THEN failures END), 0),
+			COALESCE(max(EXTRACT(EPOCH FROM locked_until - now())), 0)
+		FROM login_attempt
+		WHERE (scope = 'email' AND key = $1) OR (scope = 'ip' AND key = $2)`
+
+	// Счетчик начинается заново, если прошлая неудача была раньше окна $4.
+	// Достигнув $3 неудач, ключ блокируется на $5 секунд
+	RecordLoginFailureQuery = `
+		INSERT INTO login_attempt AS a (scope, key, failures, last_failure_at, locked_until)
+		VALUES ($1, $2, 1, now(), CASE WHEN $3 <= 1 THEN now() + make_interval(secs => $5) END)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures = CASE WHEN a.last_failure_at < now() - make_interval(secs => $4) THEN 1 ELSE a.failures + 1 END,
+			last_failure_at = now(),
+			locked_until = CASE
+				WHEN (CASE WHEN a.last_failure_at < now() - make_interval(secs => $4) THEN 1 ELSE a.failures + 1 END) >= $3
+				THEN now() + make_interval(secs => $5)
+				ELSE a.locked_until
+			END`
+
+	ResetLoginFailuresQuery = `
+		DELETE FROM login_attempt
+		WHERE scope = $1 AND key = $2`
+
+	GetTokenStateQuery = `
+		SELECT tokens_valid_after, active
+		FROM "user"
+		WHERE id = $1`
+
+	CreatePasswordResetQuery = `
+		INSERT INTO password_reset (token_hash, user_id, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))`
+
+	ConsumePasswordResetQuery = `
+		UPDATE password_reset
+		SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id`
+
+	// Смена пароля отзывает все ранее выданные токены пользователя
+	UpdatePasswordQuery = `
+		UPDATE "user"
+		SET password_hash = $2, tokens_valid_after = now()
+		WHERE id = $1`
+
+	// Остальные токены сброса этого пользователя больше не нужны
+	RevokePasswordResetsQuery = `
+		UPDATE password_reset
+		SET used_at = now()
+		WHERE user_id = $1 AND used_at IS NULL`
+
+	// Сессии со старыми токенами отзываются, чтобы не показываться среди активных
+	RevokeUserSessionsQuery = `
+		UPDATE session
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL`
+
+	ConsumeInvitationQuery = `
+		UPDATE invitation
+		SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING email, role, pickup_point_id`
+
+	CreateInvitedUserQuery = `
+		INSERT INTO "user" (id, email, password_hash, role, pickup_point_id)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	CreateLoginChallengeQuery = `
+		INSERT INTO login_challenge (token_hash, user_id, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))`
+
+	// Каждое обращение к challenge считается попыткой, после $2 попыток он перестает действовать
+	UseLoginChallengeQuery = `
+		WITH c AS (
+			UPDATE login_challenge
+			SET attempts = attempts + 1
+			WHERE token_hash = $1 AND expires_at > now() AND attempts < $2
+			RETURNING user_id
+		)
+		SELECT u.id, u.email, u.role, u.active, COALESCE(u.totp_secret, ''), u.totp_enabled, u.totp_last_step, u.pickup_point_id
+		FROM c JOIN "user" u ON u.id = c.user_id`
+
+	DeleteLoginChallengeQuery = `
+		DELETE FROM login_challenge
+		WHERE token_hash = $1`
+
+	GetTwoFactorQuery = `
+		SELECT id, email, role, active, COALESCE(totp_secret, ''), totp_enabled, totp_last_step, pickup_point_id
+		FROM "user"
+		WHERE id = $1`
+
+	// Секрет можно заменить, пока TOTP не подтвержден
+	SetTOTPSecretQuery = `
+		UPDATE "user"
+		SET totp_secret = $2
+		WHERE id = $1 AND NOT totp_enabled`
+
+	// Шаг принимается, только если он новее последнего принятого: так один код не сработает дважды
+	UseTOTPStepQuery = `
+		UPDATE "user"
+		SET totp_last_step = $2
+		WHERE id = $1 AND totp_last_step < $2`
+
+	EnableTwoFactorQuery = `
+		UPDATE "user"
+		SET totp_enabled = true, totp_last_step = $2
+		WHERE id = $1 AND totp_secret IS NOT NULL AND NOT totp_enabled`
+
+	DisableTwoFactorQuery = `
+		UPDATE "user"
+		SET totp_enabled = false, totp_secret = NULL, totp_last_step = 0
+		WHERE id = $1`
+
+	DeleteRecoveryCodesQuery = `
+		DELETE FROM recovery_code
+		WHERE user_id = $1`
+
+	InsertRecoveryCodeQuery = `
+		INSERT INTO recovery_code (user_id, code_hash)
+		VALUES ($1, $2)`
+
+	UseRecoveryCodeQuery = `
+		UPDATE recovery_code
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	CreateSessionQuery = `
+		INSERT INTO session (id, user_id, device, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	// last_seen_at обновляется не чаще раза в $3 секунд, чтобы не писать в БД на каждый запрос.
+	// SELECT видит строку до обновления, поэтому результат не зависит от того, было ли оно
+	TouchSessionQuery = `
+		WITH touched AS (
+			UPDATE session
+			SET last_seen_at = now()
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+				AND last_seen_at < now() - make_interval(secs => $3)
+			RETURNING id
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM session
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		)`
 )
 
+// pqUniqueViolation код ошибки Postgres при нарушении уникальности
+const pqUniqueViolation = "23505"
+
 type AuthRepository struct {
 	db *sql.DB
 }
@@ -42,6 +196,7 @@ func (r *AuthRepository) CreateUser(ctx context.Context, email string, passwordH
 		Email:        email,
 		PasswordHash: passwordHash,
 		Role:         role,
+		Active:       true,
 	}
 
 	err := r.db.QueryRowContext(ctx, createUserQuery, 
@@ -49,6 +204,11 @@ func (r *AuthRepository) CreateUser(ctx context.Context, email string, passwordH
 		Scan(&user.ID, &user.Email, &user.Role)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+			logger.Warn("user already exists")
+			return nil, errs.ErrUserAlreadyExists
+		}
 		logger.WithError(err).Error("failed to create user")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -60,9 +220,12 @@ func (r *AuthRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 	const op = "AuthRepository.GetUserByEmail"
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email)
 
-	var user models.User
+	var (
+		user  models.User
+		pvzID uuid.NullUUID
+	)
 	err := r.db.QueryRowContext(ctx, getUserByEmailQuery, email).
-		Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role)
+		Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Active, &user.TwoFactorEnabled, &pvzID)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -72,6 +235,445 @@ func (r *AuthRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 		logger.WithError(err).Error("failed to get user by email")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	if pvzID.Valid {
+		user.PickupPointID = &pvzID.UUID
+	}
 
 	return &user, nil
-}
\ No newline at end of file
+}
+
+// GetLoginThrottle возвращает состояние попыток входа для email и IP
+func (r *AuthRepository) GetLoginThrottle(ctx context.Context, email, ip string, window time.Duration) (models.LoginThrottle, error) {
+	const op = "AuthRepository.GetLoginThrottle"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email).WithField("ip", ip)
+
+	var (
+		throttle  models.LoginThrottle
+		lockedFor float64
+	)
+	err := r.db.QueryRowContext(ctx, GetLoginThrottleQuery, email, ip, window.Seconds()).
+		Scan(&throttle.Failures, &lockedFor)
+	if err != nil {
+		logger.WithError(err).Error("failed to get login throttle")
+		return models.LoginThrottle{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if lockedFor > 0 {
+		throttle.LockedFor = time.Duration(lockedFor * float64(time.Second))
+	}
+
+	return throttle, nil
+}
+
+// RecordLoginFailure учитывает неудачную попытку и блокирует ключ после maxFailures неудач в окне
+func (r *AuthRepository) RecordLoginFailure(ctx context.Context, scope models.LoginScope, key string, maxFailures int, window, lockout time.Duration) error {
+	const op = "AuthRepository.RecordLoginFailure"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("scope", scope).WithField("key", key)
+
+	_, err := r.db.ExecContext(ctx, RecordLoginFailureQuery, string(scope), key, maxFailures, window.Seconds(), lockout.Seconds())
+	if err != nil {
+		logger.WithError(err).Error("failed to record login failure")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResetLoginFailures сбрасывает счетчик и блокировку ключа
+func (r *AuthRepository) ResetLoginFailures(ctx context.Context, scope models.LoginScope, key string) error {
+	const op = "AuthRepository.ResetLoginFailures"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("scope", scope).WithField("key", key)
+
+	if _, err := r.db.ExecContext(ctx, ResetLoginFailuresQuery, string(scope), key); err != nil {
+		logger.WithError(err).Error("failed to reset login failures")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetTokenState возвращает состояние токенов пользователя или nil, если пользователя нет
+func (r *AuthRepository) GetTokenState(ctx context.Context, userID uuid.UUID) (*models.TokenState, error) {
+	const op = "AuthRepository.GetTokenState"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	var (
+		validAfter sql.NullTime
+		active     bool
+	)
+	err := r.db.QueryRowContext(ctx, GetTokenStateQuery, userID).Scan(&validAfter, &active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("user not found")
+			return nil, nil
+		}
+		logger.WithError(err).Error("failed to get token state")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.TokenState{ValidAfter: validAfter.Time, Active: active}, nil
+}
+
+// CreatePasswordReset сохраняет хеш токена сброса пароля, действующего ttl
+func (r *AuthRepository) CreatePasswordReset(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error {
+	const op = "AuthRepository.CreatePasswordReset"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	if _, err := r.db.ExecContext(ctx, CreatePasswordResetQuery, tokenHash, userID, ttl.Seconds()); err != nil {
+		logger.WithError(err).Error("failed to create password reset")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResetPassword погашает токен сброса, меняет пароль и отзывает сессии пользователя в одной транзакции.
+// Возвращает ErrInvalidResetToken, если токен неизвестен, уже использован или истек
+func (r *AuthRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash []byte) (uuid.UUID, error) {
+	const op = "AuthRepository.ResetPassword"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	if err := tx.QueryRowContext(ctx, ConsumePasswordResetQuery, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("password reset token is invalid or expired")
+			return uuid.Nil, errs.ErrInvalidResetToken
+		}
+		logger.WithError(err).Error("failed to consume password reset token")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, UpdatePasswordQuery, userID, passwordHash); err != nil {
+		logger.WithError(err).Error("failed to update password")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, RevokePasswordResetsQuery, userID); err != nil {
+		logger.WithError(err).Error("failed to revoke password resets")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, RevokeUserSessionsQuery, userID); err != nil {
+		logger.WithError(err).Error("failed to revoke sessions")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+
+// CreateUserFromInvitation погашает приглашение и создает пользователя с его email, ролью и ПВЗ
+// в одной транзакции. Возвращает ErrInvalidInvitation, если приглашение неизвестно, уже использовано или истекло
+func (r *AuthRepository) CreateUserFromInvitation(ctx context.Context, tokenHash, passwordHash []byte) (*models.User, error) {
+	const op = "AuthRepository.CreateUserFromInvitation"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	user := &models.User{
+		ID:           uuid.New(),
+		PasswordHash: passwordHash,
+		Active:       true,
+	}
+	var pvzID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, ConsumeInvitationQuery, tokenHash).Scan(&user.Email, &user.Role, &pvzID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("invitation is invalid or expired")
+			return nil, errs.ErrInvalidInvitation
+		}
+		logger.WithError(err).Error("failed to consume invitation")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if pvzID.Valid {
+		user.PickupPointID = &pvzID.UUID
+	}
+	logger = logger.WithField("email", user.Email).WithField("role", user.Role)
+
+	if _, err := tx.ExecContext(ctx, CreateInvitedUserQuery, user.ID, user.Email, user.PasswordHash, user.Role, pvzID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+			logger.Warn("user already exists")
+			return nil, errs.ErrUserAlreadyExists
+		}
+		logger.WithError(err).Error("failed to create user")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// CreateLoginChallenge сохраняет хеш токена второго шага входа, действующего ttl
+func (r *AuthRepository) CreateLoginChallenge(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error {
+	const op = "AuthRepository.CreateLoginChallenge"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	if _, err := r.db.ExecContext(ctx, CreateLoginChallengeQuery, tokenHash, userID, ttl.Seconds()); err != nil {
+		logger.WithError(err).Error("failed to create login challenge")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseLoginChallenge учитывает попытку и возвращает состояние TOTP пользователя.
+// Возвращает ErrInvalidLoginChallenge, если challenge неизвестен, истек или попытки кончились
+func (r *AuthRepository) UseLoginChallenge(ctx context.Context, tokenHash []byte, maxAttempts int) (*models.TwoFactor, error) {
+	const op = "AuthRepository.UseLoginChallenge"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tf, err := scanTwoFactor(r.db.QueryRowContext(ctx, UseLoginChallengeQuery, tokenHash, maxAttempts))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("login challenge is invalid or expired")
+			return nil, errs.ErrInvalidLoginChallenge
+		}
+		logger.WithError(err).Error("failed to use login challenge")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tf, nil
+}
+
+// DeleteLoginChallenge удаляет challenge после успешного входа
+func (r *AuthRepository) DeleteLoginChallenge(ctx context.Context, tokenHash []byte) error {
+	const op = "AuthRepository.DeleteLoginChallenge"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	if _, err := r.db.ExecContext(ctx, DeleteLoginChallengeQuery, tokenHash); err != nil {
+		logger.WithError(err).Error("failed to delete login challenge")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetTwoFactor возвращает состояние TOTP пользователя или ErrUserNotFound
+func (r *AuthRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	const op = "AuthRepository.GetTwoFactor"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tf, err := scanTwoFactor(r.db.QueryRowContext(ctx, GetTwoFactorQuery, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("user not found")
+			return nil, errs.ErrUserNotFound
+		}
+		logger.WithError(err).Error("failed to get two-factor state")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tf, nil
+}
+
+func scanTwoFactor(row *sql.Row) (*models.TwoFactor, error) {
+	var (
+		tf    models.TwoFactor
+		pvzID uuid.NullUUID
+	)
+	if err := row.Scan(&tf.UserID, &tf.Email, &tf.Role, &tf.Active, &tf.Secret, &tf.Enabled, &tf.LastStep, &pvzID); err != nil {
+		return nil, err
+	}
+	if pvzID.Valid {
+		tf.PickupPointID = &pvzID.UUID
+	}
+	return &tf, nil
+}
+
+// SetTOTPSecret сохраняет секрет настраиваемого TOTP. Возвращает ErrTwoFactorAlreadyEnabled,
+// если TOTP уже подтвержден
+func (r *AuthRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	const op = "AuthRepository.SetTOTPSecret"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	res, err := r.db.ExecContext(ctx, SetTOTPSecretQuery, userID, secret)
+	if err != nil {
+		logger.WithError(err).Error("failed to set totp secret")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("two-factor authentication is already enabled")
+		return errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+// UseTOTPStep запоминает принятый шаг. false означает, что код этого или более позднего шага уже использован
+func (r *AuthRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	const op = "AuthRepository.UseTOTPStep"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	res, err := r.db.ExecContext(ctx, UseTOTPStepQuery, userID, step)
+	if err != nil {
+		logger.WithError(err).Error("failed to use totp step")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+// UseRecoveryCode погашает код восстановления. false означает, что кода нет или он уже использован
+func (r *AuthRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) (bool, error) {
+	const op = "AuthRepository.UseRecoveryCode"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	res, err := r.db.ExecContext(ctx, UseRecoveryCodeQuery, userID, codeHash)
+	if err != nil {
+		logger.WithError(err).Error("failed to use recovery code")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+// EnableTwoFactor включает TOTP, запоминает шаг подтверждающего кода и заменяет коды восстановления
+// в одной транзакции. Возвращает ErrTwoFactorNotSetUp, если секрета нет или TOTP уже включен
+func (r *AuthRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes [][]byte) error {
+	const op = "AuthRepository.EnableTwoFactor"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, EnableTwoFactorQuery, userID, step)
+	if err != nil {
+		logger.WithError(err).Error("failed to enable two-factor authentication")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("two-factor authentication is not set up")
+		return errs.ErrTwoFactorNotSetUp
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		logger.WithError(err).Error("failed to store recovery codes")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DisableTwoFactor выключает TOTP, удаляет секрет и коды восстановления
+func (r *AuthRepository) DisableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	const op = "AuthRepository.DisableTwoFactor"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, DisableTwoFactorQuery, userID); err != nil {
+		logger.WithError(err).Error("failed to disable two-factor authentication")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		logger.WithError(err).Error("failed to delete recovery codes")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes удаляет прежние коды восстановления пользователя и сохраняет новые
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes [][]byte) error {
+	if _, err := tx.ExecContext(ctx, DeleteRecoveryCodesQuery, userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, InsertRecoveryCodeQuery, userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSession сохраняет сессию нового входа и возвращает ее id для токена
+func (r *AuthRepository) CreateSession(ctx context.Context, userID uuid.UUID, client session.Client) (uuid.UUID, error) {
+	const op = "AuthRepository.CreateSession"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	id := uuid.New()
+	if _, err := r.db.ExecContext(ctx, CreateSessionQuery, id, userID, client.Device, client.IP, client.UserAgent); err != nil {
+		logger.WithError(err).Error("failed to create session")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// TouchSession отмечает активность сессии и сообщает, не отозвана ли она
+func (r *AuthRepository) TouchSession(ctx context.Context, sessionID, userID uuid.UUID, interval time.Duration) (bool, error) {
+	const op = "AuthRepository.TouchSession"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("session_id", sessionID)
+
+	var active bool
+	if err := r.db.QueryRowContext(ctx, TouchSessionQuery, sessionID, userID, interval.Seconds()).Scan(&active); err != nil {
+		logger.WithError(err).Error("failed to touch session")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return active, nil
+}
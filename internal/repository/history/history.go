@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/history"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	CreateEventQuery = `
+		INSERT INTO reception_event (reception_id, event_type, product_id, product_type, actor_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	CheckReceptionExistsQuery = `
+		SELECT EXISTS (SELECT 1 FROM reception WHERE id = $1)`
+
+	GetReceptionPickupPointQuery = `
+		SELECT pickup_point_id FROM reception WHERE id = $1`
+
+	GetReceptionHistoryQuery = `
+		SELECT id, reception_id, event_type, product_id, product_type, actor_id, details, created_at
+		FROM reception_event
+		WHERE reception_id = $1
+		ORDER BY created_at, id`
+)
+
+type HistoryRepository struct {
+	db *sql.DB
+}
+
+func NewHistoryRepository(db *sql.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// Write добавляет событие в историю приемки в транзакции изменения. Если автор не задан, берется пользователь из контекста
+func Write(ctx context.Context, tx *sql.Tx, event models.Event) error {
+	if event.ActorID == nil {
+		if userID, ok := authctx.GetUserUUID(ctx); ok {
+			event.ActorID = &userID
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, CreateEventQuery, eventArgs(event)...); err != nil {
+		return fmt.Errorf("insert reception event: %w", err)
+	}
+
+	return nil
+}
+
+// GetReceptionPickupPoint возвращает ПВЗ приемки или ErrReceptionNotFound
+func (r *HistoryRepository) GetReceptionPickupPoint(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error) {
+	const op = "HistoryRepository.GetReceptionPickupPoint"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("reception_id", receptionID)
+
+	var pvzID uuid.UUID
+	if err := r.db.QueryRowContext(ctx, GetReceptionPickupPointQuery, receptionID).Scan(&pvzID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("reception not found")
+			return uuid.Nil, errs.ErrReceptionNotFound
+		}
+		logger.WithError(err).Error("failed to get reception pickup point")
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzID, nil
+}
+
+func (r *HistoryRepository) GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.Event, error) {
+	const op = "HistoryRepository.GetReceptionHistory"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("reception_id", receptionID)
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, CheckReceptionExistsQuery, receptionID).Scan(&exists); err != nil {
+		logger.WithError(err).Error("failed to check reception")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		logger.Warn("reception not found")
+		return nil, errs.ErrReceptionNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, GetReceptionHistoryQuery, receptionID)
+	if err != nil {
+		logger.WithError(err).Error("failed to query history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var (
+			event       models.Event
+			eventType   string
+			productID   uuid.NullUUID
+			productType sql.NullString
+			actorID     uuid.NullUUID
+			details     sql.NullString
+		)
+		err := rows.Scan(&event.ID, &event.ReceptionID, &eventType, &productID, &productType, &actorID, &details, &event.CreatedAt)
+		if err != nil {
+			logger.WithError(err).Error("scan error")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		event.Type = models.EventType(eventType)
+		if productID.Valid {
+			event.ProductID = &productID.UUID
+		}
+		if actorID.Valid {
+			event.ActorID = &actorID.UUID
+		}
+		event.ProductType = productType.String
+		event.Details = details.String
+
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func eventArgs(event models.Event) []any {
+	return []any{
+		event.ReceptionID,
+		string(event.Type),
+		nullUUID(event.ProductID),
+		sql.NullString{String: event.ProductType, Valid: event.ProductType != ""},
+		nullUUID(event.ActorID),
+		sql.NullString{String: event.Details, Valid: event.Details != ""},
+	}
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	EmailRegisteredQuery = `
+		SELECT EXISTS (SELECT 1 FROM "user" WHERE lower(email) = lower($1))`
+
+	CreateInvitationQuery = `
+		INSERT INTO invitation (id, token_hash, email, role, pickup_point_id, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, now() + make_interval(secs => $7))
+		RETURNING id, email, role, pickup_point_id, created_by, created_at, expires_at`
+
+	// Показываются только приглашения, которыми еще можно воспользоваться
+	ListInvitationsQuery = `
+		SELECT id, email, role, pickup_point_id, created_by, created_at, expires_at
+		FROM invitation
+		WHERE used_at IS NULL AND expires_at > now()
+		ORDER BY created_at DESC, id`
+
+	// Использованное приглашение остается в истории, отозвать можно только неиспользованное
+	RevokeInvitationQuery = `
+		DELETE FROM invitation
+		WHERE id = $1 AND used_at IS NULL`
+)
+
+// pqForeignKeyViolation код ошибки Postgres при нарушении внешнего ключа
+const pqForeignKeyViolation = "23503"
+
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvitation(row rowScanner) (*models.Invitation, error) {
+	var (
+		inv   models.Invitation
+		pvzID uuid.NullUUID
+	)
+
+	if err := row.Scan(&inv.ID, &inv.Email, &inv.Role, &pvzID, &inv.CreatedBy, &inv.CreatedAt, &inv.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if pvzID.Valid {
+		inv.PickupPointID = &pvzID.UUID
+	}
+
+	return &inv, nil
+}
+
+// EmailRegistered сообщает, есть ли пользователь с таким email
+func (r *InvitationRepository) EmailRegistered(ctx context.Context, email string) (bool, error) {
+	const op = "InvitationRepository.EmailRegistered"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email)
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, EmailRegisteredQuery, email).Scan(&exists); err != nil {
+		logger.WithError(err).Error("failed to check email")
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+// CreateInvitation сохраняет приглашение с хешем токена, действующее ttl
+func (r *InvitationRepository) CreateInvitation(ctx context.Context, inv models.Invitation, tokenHash []byte, ttl time.Duration) (*models.Invitation, error) {
+	const op = "InvitationRepository.CreateInvitation"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("invitation_id", inv.ID)
+
+	var pvzID uuid.NullUUID
+	if inv.PickupPointID != nil {
+		pvzID = uuid.NullUUID{UUID: *inv.PickupPointID, Valid: true}
+	}
+
+	created, err := scanInvitation(r.db.QueryRowContext(ctx, CreateInvitationQuery,
+		inv.ID, tokenHash, inv.Email, inv.Role, pvzID, inv.CreatedBy, ttl.Seconds()))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+			logger.Warn("pickup point not found")
+			return nil, errs.ErrPickupPointNotFound
+		}
+		logger.WithError(err).Error("failed to create invitation")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (r *InvitationRepository) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	const op = "InvitationRepository.ListInvitations"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	rows, err := r.db.QueryContext(ctx, ListInvitationsQuery)
+	if err != nil {
+		logger.WithError(err).Error("failed to query invitations")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			logger.WithError(err).Error("failed to scan invitation")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		invitations = append(invitations, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invitations, nil
+}
+
+func (r *InvitationRepository) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	const op = "InvitationRepository.RevokeInvitation"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("invitation_id", id)
+
+	res, err := r.db.ExecContext(ctx, RevokeInvitationQuery, id)
+	if err != nil {
+		logger.WithError(err).Error("failed to revoke invitation")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("invitation not found")
+		return errs.ErrInvitationNotFound
+	}
+
+	return nil
+}
@@ -7,9 +7,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	models "github.com/nik-mLb/avito_task/internal/models/user"
+	uuid "github.com/google/uuid"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	models0 "github.com/nik-mLb/avito_task/internal/models/user"
 )
 
 // MockAuthRepository is a mock of AuthRepository interface.
@@ -35,11 +38,40 @@ func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateLoginChallenge mocks base method.
+func (m *MockAuthRepository) CreateLoginChallenge(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", ctx, userID, tokenHash, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge.
+func (mr *MockAuthRepositoryMockRecorder) CreateLoginChallenge(ctx, userID, tokenHash, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockAuthRepository)(nil).CreateLoginChallenge), ctx, userID, tokenHash, ttl)
+}
+
+// CreateSession mocks base method.
+func (m *MockAuthRepository) CreateSession(ctx context.Context, userID uuid.UUID, client models.Client) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, userID, client)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockAuthRepositoryMockRecorder) CreateSession(ctx, userID, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockAuthRepository)(nil).CreateSession), ctx, userID, client)
+}
+
 // CreateUser mocks base method.
-func (m *MockAuthRepository) CreateUser(ctx context.Context, email string, passwordHash []byte, role string) (*models.User, error) {
+func (m *MockAuthRepository) CreateUser(ctx context.Context, email string, passwordHash []byte, role string) (*models0.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateUser", ctx, email, passwordHash, role)
-	ret0, _ := ret[0].(*models.User)
+	ret0, _ := ret[0].(*models0.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -50,11 +82,113 @@ func (mr *MockAuthRepositoryMockRecorder) CreateUser(ctx, email, passwordHash, r
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthRepository)(nil).CreateUser), ctx, email, passwordHash, role)
 }
 
+// CreateUserFromInvitation mocks base method.
+func (m *MockAuthRepository) CreateUserFromInvitation(ctx context.Context, tokenHash, passwordHash []byte) (*models0.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserFromInvitation", ctx, tokenHash, passwordHash)
+	ret0, _ := ret[0].(*models0.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserFromInvitation indicates an expected call of CreateUserFromInvitation.
+func (mr *MockAuthRepositoryMockRecorder) CreateUserFromInvitation(ctx, tokenHash, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserFromInvitation", reflect.TypeOf((*MockAuthRepository)(nil).CreateUserFromInvitation), ctx, tokenHash, passwordHash)
+}
+
+// DeleteLoginChallenge mocks base method.
+func (m *MockAuthRepository) DeleteLoginChallenge(ctx context.Context, tokenHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginChallenge indicates an expected call of DeleteLoginChallenge.
+func (mr *MockAuthRepositoryMockRecorder) DeleteLoginChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginChallenge", reflect.TypeOf((*MockAuthRepository)(nil).DeleteLoginChallenge), ctx, tokenHash)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockAuthRepository) DisableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) DisableTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).DisableTwoFactor), ctx, userID)
+}
+
+// EnableTwoFactor mocks base method.
+func (m *MockAuthRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", ctx, userID, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) EnableTwoFactor(ctx, userID, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).EnableTwoFactor), ctx, userID, step, recoveryCodeHashes)
+}
+
+// GetLoginThrottle mocks base method.
+func (m *MockAuthRepository) GetLoginThrottle(ctx context.Context, email, ip string, window time.Duration) (models0.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginThrottle", ctx, email, ip, window)
+	ret0, _ := ret[0].(models0.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginThrottle indicates an expected call of GetLoginThrottle.
+func (mr *MockAuthRepositoryMockRecorder) GetLoginThrottle(ctx, email, ip, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginThrottle", reflect.TypeOf((*MockAuthRepository)(nil).GetLoginThrottle), ctx, email, ip, window)
+}
+
+// GetTokenState mocks base method.
+func (m *MockAuthRepository) GetTokenState(ctx context.Context, userID uuid.UUID) (*models0.TokenState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenState", ctx, userID)
+	ret0, _ := ret[0].(*models0.TokenState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenState indicates an expected call of GetTokenState.
+func (mr *MockAuthRepositoryMockRecorder) GetTokenState(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenState", reflect.TypeOf((*MockAuthRepository)(nil).GetTokenState), ctx, userID)
+}
+
+// GetTwoFactor mocks base method.
+func (m *MockAuthRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*models0.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(*models0.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) GetTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).GetTwoFactor), ctx, userID)
+}
+
 // GetUserByEmail mocks base method.
-func (m *MockAuthRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+func (m *MockAuthRepository) GetUserByEmail(ctx context.Context, email string) (*models0.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
-	ret0, _ := ret[0].(*models.User)
+	ret0, _ := ret[0].(*models0.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -64,3 +198,105 @@ func (mr *MockAuthRepositoryMockRecorder) GetUserByEmail(ctx, email interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockAuthRepository)(nil).GetUserByEmail), ctx, email)
 }
+
+// RecordLoginFailure mocks base method.
+func (m *MockAuthRepository) RecordLoginFailure(ctx context.Context, scope models0.LoginScope, key string, maxFailures int, window, lockout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, scope, key, maxFailures, window, lockout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockAuthRepositoryMockRecorder) RecordLoginFailure(ctx, scope, key, maxFailures, window, lockout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockAuthRepository)(nil).RecordLoginFailure), ctx, scope, key, maxFailures, window, lockout)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockAuthRepository) ResetLoginFailures(ctx context.Context, scope models0.LoginScope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", ctx, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockAuthRepositoryMockRecorder) ResetLoginFailures(ctx, scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockAuthRepository)(nil).ResetLoginFailures), ctx, scope, key)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockAuthRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockAuthRepositoryMockRecorder) SetTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockAuthRepository)(nil).SetTOTPSecret), ctx, userID, secret)
+}
+
+// TouchSession mocks base method.
+func (m *MockAuthRepository) TouchSession(ctx context.Context, sessionID, userID uuid.UUID, interval time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sessionID, userID, interval)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockAuthRepositoryMockRecorder) TouchSession(ctx, sessionID, userID, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockAuthRepository)(nil).TouchSession), ctx, sessionID, userID, interval)
+}
+
+// UseLoginChallenge mocks base method.
+func (m *MockAuthRepository) UseLoginChallenge(ctx context.Context, tokenHash []byte, maxAttempts int) (*models0.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginChallenge", ctx, tokenHash, maxAttempts)
+	ret0, _ := ret[0].(*models0.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginChallenge indicates an expected call of UseLoginChallenge.
+func (mr *MockAuthRepositoryMockRecorder) UseLoginChallenge(ctx, tokenHash, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallenge", reflect.TypeOf((*MockAuthRepository)(nil).UseLoginChallenge), ctx, tokenHash, maxAttempts)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockAuthRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAuthRepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAuthRepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockAuthRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockAuthRepositoryMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockAuthRepository)(nil).UseTOTPStep), ctx, userID, step)
+}
@@ -0,0 +1,135 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invitation.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	models0 "github.com/nik-mLb/avito_task/internal/models/mail"
+)
+
+// MockInvitationRepository is a mock of InvitationRepository interface.
+type MockInvitationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationRepositoryMockRecorder
+}
+
+// MockInvitationRepositoryMockRecorder is the mock recorder for MockInvitationRepository.
+type MockInvitationRepositoryMockRecorder struct {
+	mock *MockInvitationRepository
+}
+
+// NewMockInvitationRepository creates a new mock instance.
+func NewMockInvitationRepository(ctrl *gomock.Controller) *MockInvitationRepository {
+	mock := &MockInvitationRepository{ctrl: ctrl}
+	mock.recorder = &MockInvitationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationRepository) EXPECT() *MockInvitationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateInvitation mocks base method.
+func (m *MockInvitationRepository) CreateInvitation(ctx context.Context, inv models.Invitation, tokenHash []byte, ttl time.Duration) (*models.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, inv, tokenHash, ttl)
+	ret0, _ := ret[0].(*models.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockInvitationRepositoryMockRecorder) CreateInvitation(ctx, inv, tokenHash, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockInvitationRepository)(nil).CreateInvitation), ctx, inv, tokenHash, ttl)
+}
+
+// EmailRegistered mocks base method.
+func (m *MockInvitationRepository) EmailRegistered(ctx context.Context, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailRegistered", ctx, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmailRegistered indicates an expected call of EmailRegistered.
+func (mr *MockInvitationRepositoryMockRecorder) EmailRegistered(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailRegistered", reflect.TypeOf((*MockInvitationRepository)(nil).EmailRegistered), ctx, email)
+}
+
+// ListInvitations mocks base method.
+func (m *MockInvitationRepository) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx)
+	ret0, _ := ret[0].([]models.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockInvitationRepositoryMockRecorder) ListInvitations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockInvitationRepository)(nil).ListInvitations), ctx)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockInvitationRepository) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockInvitationRepositoryMockRecorder) RevokeInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockInvitationRepository)(nil).RevokeInvitation), ctx, id)
+}
+
+// MockInvitationMailer is a mock of Mailer interface.
+type MockInvitationMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationMailerMockRecorder
+}
+
+// MockInvitationMailerMockRecorder is the mock recorder for MockInvitationMailer.
+type MockInvitationMailerMockRecorder struct {
+	mock *MockInvitationMailer
+}
+
+// NewMockInvitationMailer creates a new mock instance.
+func NewMockInvitationMailer(ctrl *gomock.Controller) *MockInvitationMailer {
+	mock := &MockInvitationMailer{ctrl: ctrl}
+	mock.recorder = &MockInvitationMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationMailer) EXPECT() *MockInvitationMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockInvitationMailer) Send(ctx context.Context, msg models0.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockInvitationMailerMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockInvitationMailer)(nil).Send), ctx, msg)
+}
@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+)
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Dispatch mocks base method.
+func (m *MockOutboxRepository) Dispatch(ctx context.Context, limit int, handle func(context.Context, models.Event) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dispatch", ctx, limit, handle)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockOutboxRepositoryMockRecorder) Dispatch(ctx, limit, handle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockOutboxRepository)(nil).Dispatch), ctx, limit, handle)
+}
+
+// MockOutboxFeedRepository is a mock of OutboxFeedRepository interface.
+type MockOutboxFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxFeedRepositoryMockRecorder
+}
+
+// MockOutboxFeedRepositoryMockRecorder is the mock recorder for MockOutboxFeedRepository.
+type MockOutboxFeedRepositoryMockRecorder struct {
+	mock *MockOutboxFeedRepository
+}
+
+// NewMockOutboxFeedRepository creates a new mock instance.
+func NewMockOutboxFeedRepository(ctrl *gomock.Controller) *MockOutboxFeedRepository {
+	mock := &MockOutboxFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxFeedRepository) EXPECT() *MockOutboxFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// LastID mocks base method.
+func (m *MockOutboxFeedRepository) LastID(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastID", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastID indicates an expected call of LastID.
+func (mr *MockOutboxFeedRepositoryMockRecorder) LastID(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastID", reflect.TypeOf((*MockOutboxFeedRepository)(nil).LastID), ctx)
+}
+
+// ListAfter mocks base method.
+func (m *MockOutboxFeedRepository) ListAfter(ctx context.Context, afterID uint64, gaps []uint64, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, afterID, gaps, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockOutboxFeedRepositoryMockRecorder) ListAfter(ctx, afterID, gaps, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockOutboxFeedRepository)(nil).ListAfter), ctx, afterID, gaps, limit)
+}
+
+// MockEventSink is a mock of EventSink interface.
+type MockEventSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSinkMockRecorder
+}
+
+// MockEventSinkMockRecorder is the mock recorder for MockEventSink.
+type MockEventSinkMockRecorder struct {
+	mock *MockEventSink
+}
+
+// NewMockEventSink creates a new mock instance.
+func NewMockEventSink(ctrl *gomock.Controller) *MockEventSink {
+	mock := &MockEventSink{ctrl: ctrl}
+	mock.recorder = &MockEventSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSink) EXPECT() *MockEventSinkMockRecorder {
+	return m.recorder
+}
+
+// Handle mocks base method.
+func (m *MockEventSink) Handle(ctx context.Context, event models.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Handle", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Handle indicates an expected call of Handle.
+func (mr *MockEventSinkMockRecorder) Handle(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockEventSink)(nil).Handle), ctx, event)
+}
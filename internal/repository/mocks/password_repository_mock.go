@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: password.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	models "github.com/nik-mLb/avito_task/internal/models/mail"
+	models0 "github.com/nik-mLb/avito_task/internal/models/user"
+)
+
+// MockPasswordRepository is a mock of PasswordRepository interface.
+type MockPasswordRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordRepositoryMockRecorder
+}
+
+// MockPasswordRepositoryMockRecorder is the mock recorder for MockPasswordRepository.
+type MockPasswordRepositoryMockRecorder struct {
+	mock *MockPasswordRepository
+}
+
+// NewMockPasswordRepository creates a new mock instance.
+func NewMockPasswordRepository(ctrl *gomock.Controller) *MockPasswordRepository {
+	mock := &MockPasswordRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordRepository) EXPECT() *MockPasswordRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockPasswordRepository) CreatePasswordReset(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", ctx, userID, tokenHash, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockPasswordRepositoryMockRecorder) CreatePasswordReset(ctx, userID, tokenHash, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockPasswordRepository)(nil).CreatePasswordReset), ctx, userID, tokenHash, ttl)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockPasswordRepository) GetUserByEmail(ctx context.Context, email string) (*models0.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(*models0.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockPasswordRepositoryMockRecorder) GetUserByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockPasswordRepository)(nil).GetUserByEmail), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockPasswordRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash []byte) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, tokenHash, passwordHash)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockPasswordRepositoryMockRecorder) ResetPassword(ctx, tokenHash, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockPasswordRepository)(nil).ResetPassword), ctx, tokenHash, passwordHash)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, msg models.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, msg)
+}
@@ -10,6 +10,7 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	models "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	dto "github.com/nik-mLb/avito_task/internal/transport/dto"
 )
@@ -52,17 +53,74 @@ func (mr *MockPickupPointRepositoryMockRecorder) CreatePickupPoint(ctx, city int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePickupPoint", reflect.TypeOf((*MockPickupPointRepository)(nil).CreatePickupPoint), ctx, city)
 }
 
+// ExportPickupPoints mocks base method.
+func (m *MockPickupPointRepository) ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, fn func(models.ExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPickupPoints", ctx, startDate, endDate, pvzID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPickupPoints indicates an expected call of ExportPickupPoints.
+func (mr *MockPickupPointRepositoryMockRecorder) ExportPickupPoints(ctx, startDate, endDate, pvzID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPickupPoints", reflect.TypeOf((*MockPickupPointRepository)(nil).ExportPickupPoints), ctx, startDate, endDate, pvzID, fn)
+}
+
+// GetOccupancy mocks base method.
+func (m *MockPickupPointRepository) GetOccupancy(ctx context.Context, pvzID uuid.UUID) (*models.Occupancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOccupancy", ctx, pvzID)
+	ret0, _ := ret[0].(*models.Occupancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOccupancy indicates an expected call of GetOccupancy.
+func (mr *MockPickupPointRepositoryMockRecorder) GetOccupancy(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOccupancy", reflect.TypeOf((*MockPickupPointRepository)(nil).GetOccupancy), ctx, pvzID)
+}
+
 // GetPickupPointsWithReceptions mocks base method.
-func (m *MockPickupPointRepository) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error) {
+func (m *MockPickupPointRepository) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int) ([]dto.PickupPointListResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPickupPointsWithReceptions", ctx, startDate, endDate, page, limit)
+	ret := m.ctrl.Call(m, "GetPickupPointsWithReceptions", ctx, startDate, endDate, pvzID, page, limit)
 	ret0, _ := ret[0].([]dto.PickupPointListResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPickupPointsWithReceptions indicates an expected call of GetPickupPointsWithReceptions.
-func (mr *MockPickupPointRepositoryMockRecorder) GetPickupPointsWithReceptions(ctx, startDate, endDate, page, limit interface{}) *gomock.Call {
+func (mr *MockPickupPointRepositoryMockRecorder) GetPickupPointsWithReceptions(ctx, startDate, endDate, pvzID, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupPointsWithReceptions", reflect.TypeOf((*MockPickupPointRepository)(nil).GetPickupPointsWithReceptions), ctx, startDate, endDate, pvzID, page, limit)
+}
+
+// SetCapacity mocks base method.
+func (m *MockPickupPointRepository) SetCapacity(ctx context.Context, pvzID uuid.UUID, capacity *int, mode models.CapacityMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCapacity", ctx, pvzID, capacity, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCapacity indicates an expected call of SetCapacity.
+func (mr *MockPickupPointRepositoryMockRecorder) SetCapacity(ctx, pvzID, capacity, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCapacity", reflect.TypeOf((*MockPickupPointRepository)(nil).SetCapacity), ctx, pvzID, capacity, mode)
+}
+
+// StreamPickupPointsWithReceptions mocks base method.
+func (m *MockPickupPointRepository) StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int, fn func(dto.PickupPointListResponse) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPickupPointsWithReceptions", ctx, startDate, endDate, pvzID, page, limit, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPickupPointsWithReceptions indicates an expected call of StreamPickupPointsWithReceptions.
+func (mr *MockPickupPointRepositoryMockRecorder) StreamPickupPointsWithReceptions(ctx, startDate, endDate, pvzID, page, limit, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupPointsWithReceptions", reflect.TypeOf((*MockPickupPointRepository)(nil).GetPickupPointsWithReceptions), ctx, startDate, endDate, page, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPickupPointsWithReceptions", reflect.TypeOf((*MockPickupPointRepository)(nil).StreamPickupPointsWithReceptions), ctx, startDate, endDate, pvzID, page, limit, fn)
 }
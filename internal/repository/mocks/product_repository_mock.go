@@ -52,11 +52,12 @@ func (mr *MockProductRepositoryMockRecorder) AddProduct(ctx, pvzID, productType
 }
 
 // DeleteLastProduct mocks base method.
-func (m *MockProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+func (m *MockProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteLastProduct", ctx, pvzID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteLastProduct indicates an expected call of DeleteLastProduct.
@@ -7,10 +7,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
-	models "github.com/nik-mLb/avito_task/internal/models/reception"
+	models "github.com/nik-mLb/avito_task/internal/models/history"
+	models0 "github.com/nik-mLb/avito_task/internal/models/reception"
 )
 
 // MockReceptionRepository is a mock of ReceptionRepository interface.
@@ -37,10 +39,10 @@ func (m *MockReceptionRepository) EXPECT() *MockReceptionRepositoryMockRecorder
 }
 
 // CloseReception mocks base method.
-func (m *MockReceptionRepository) CloseReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+func (m *MockReceptionRepository) CloseReception(ctx context.Context, pvzID uuid.UUID) (*models0.Reception, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CloseReception", ctx, pvzID)
-	ret0, _ := ret[0].(*models.Reception)
+	ret0, _ := ret[0].(*models0.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -51,11 +53,26 @@ func (mr *MockReceptionRepositoryMockRecorder) CloseReception(ctx, pvzID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReceptionRepository)(nil).CloseReception), ctx, pvzID)
 }
 
+// CloseStaleReceptions mocks base method.
+func (m *MockReceptionRepository) CloseStaleReceptions(ctx context.Context, idleTimeout time.Duration, reason string) ([]models0.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseStaleReceptions", ctx, idleTimeout, reason)
+	ret0, _ := ret[0].([]models0.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseStaleReceptions indicates an expected call of CloseStaleReceptions.
+func (mr *MockReceptionRepositoryMockRecorder) CloseStaleReceptions(ctx, idleTimeout, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseStaleReceptions", reflect.TypeOf((*MockReceptionRepository)(nil).CloseStaleReceptions), ctx, idleTimeout, reason)
+}
+
 // CreateReception mocks base method.
-func (m *MockReceptionRepository) CreateReception(ctx context.Context, receptionID, pvzID uuid.UUID) (*models.Reception, error) {
+func (m *MockReceptionRepository) CreateReception(ctx context.Context, receptionID, pvzID uuid.UUID) (*models0.Reception, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateReception", ctx, receptionID, pvzID)
-	ret0, _ := ret[0].(*models.Reception)
+	ret0, _ := ret[0].(*models0.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -65,3 +82,71 @@ func (mr *MockReceptionRepositoryMockRecorder) CreateReception(ctx, receptionID,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReception", reflect.TypeOf((*MockReceptionRepository)(nil).CreateReception), ctx, receptionID, pvzID)
 }
+
+// ReopenReception mocks base method.
+func (m *MockReceptionRepository) ReopenReception(ctx context.Context, receptionID, reopenedBy uuid.UUID, reason string, window time.Duration) (*models0.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenReception", ctx, receptionID, reopenedBy, reason, window)
+	ret0, _ := ret[0].(*models0.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenReception indicates an expected call of ReopenReception.
+func (mr *MockReceptionRepositoryMockRecorder) ReopenReception(ctx, receptionID, reopenedBy, reason, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenReception", reflect.TypeOf((*MockReceptionRepository)(nil).ReopenReception), ctx, receptionID, reopenedBy, reason, window)
+}
+
+// MockReceptionHistoryRepository is a mock of ReceptionHistoryRepository interface.
+type MockReceptionHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReceptionHistoryRepositoryMockRecorder
+}
+
+// MockReceptionHistoryRepositoryMockRecorder is the mock recorder for MockReceptionHistoryRepository.
+type MockReceptionHistoryRepositoryMockRecorder struct {
+	mock *MockReceptionHistoryRepository
+}
+
+// NewMockReceptionHistoryRepository creates a new mock instance.
+func NewMockReceptionHistoryRepository(ctrl *gomock.Controller) *MockReceptionHistoryRepository {
+	mock := &MockReceptionHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockReceptionHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReceptionHistoryRepository) EXPECT() *MockReceptionHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetReceptionHistory mocks base method.
+func (m *MockReceptionHistoryRepository) GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionHistory", ctx, receptionID)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionHistory indicates an expected call of GetReceptionHistory.
+func (mr *MockReceptionHistoryRepositoryMockRecorder) GetReceptionHistory(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionHistory", reflect.TypeOf((*MockReceptionHistoryRepository)(nil).GetReceptionHistory), ctx, receptionID)
+}
+
+// GetReceptionPickupPoint mocks base method.
+func (m *MockReceptionHistoryRepository) GetReceptionPickupPoint(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionPickupPoint", ctx, receptionID)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionPickupPoint indicates an expected call of GetReceptionPickupPoint.
+func (mr *MockReceptionHistoryRepositoryMockRecorder) GetReceptionPickupPoint(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionPickupPoint", reflect.TypeOf((*MockReceptionHistoryRepository)(nil).GetReceptionPickupPoint), ctx, receptionID)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rollup.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/rollup"
+)
+
+// MockRollupRepository is a mock of RollupRepository interface.
+type MockRollupRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollupRepositoryMockRecorder
+}
+
+// MockRollupRepositoryMockRecorder is the mock recorder for MockRollupRepository.
+type MockRollupRepositoryMockRecorder struct {
+	mock *MockRollupRepository
+}
+
+// NewMockRollupRepository creates a new mock instance.
+func NewMockRollupRepository(ctrl *gomock.Controller) *MockRollupRepository {
+	mock := &MockRollupRepository{ctrl: ctrl}
+	mock.recorder = &MockRollupRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRollupRepository) EXPECT() *MockRollupRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Backfill mocks base method.
+func (m *MockRollupRepository) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backfill", ctx, from, to)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backfill indicates an expected call of Backfill.
+func (mr *MockRollupRepositoryMockRecorder) Backfill(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backfill", reflect.TypeOf((*MockRollupRepository)(nil).Backfill), ctx, from, to)
+}
+
+// Check mocks base method.
+func (m *MockRollupRepository) Check(ctx context.Context, from, to time.Time) ([]models.Mismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, from, to)
+	ret0, _ := ret[0].([]models.Mismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockRollupRepositoryMockRecorder) Check(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockRollupRepository)(nil).Check), ctx, from, to)
+}
+
+// Refresh mocks base method.
+func (m *MockRollupRepository) Refresh(ctx context.Context, lag time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, lag)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockRollupRepositoryMockRecorder) Refresh(ctx, lag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockRollupRepository)(nil).Refresh), ctx, lag)
+}
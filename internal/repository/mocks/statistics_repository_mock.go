@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: statistics.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+)
+
+// MockStatisticsRepository is a mock of StatisticsRepository interface.
+type MockStatisticsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatisticsRepositoryMockRecorder
+}
+
+// MockStatisticsRepositoryMockRecorder is the mock recorder for MockStatisticsRepository.
+type MockStatisticsRepositoryMockRecorder struct {
+	mock *MockStatisticsRepository
+}
+
+// NewMockStatisticsRepository creates a new mock instance.
+func NewMockStatisticsRepository(ctrl *gomock.Controller) *MockStatisticsRepository {
+	mock := &MockStatisticsRepository{ctrl: ctrl}
+	mock.recorder = &MockStatisticsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatisticsRepository) EXPECT() *MockStatisticsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockStatisticsRepository) GetStats(ctx context.Context, filter models.Filter) ([]models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, filter)
+	ret0, _ := ret[0].([]models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockStatisticsRepositoryMockRecorder) GetStats(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockStatisticsRepository)(nil).GetStats), ctx, filter)
+}
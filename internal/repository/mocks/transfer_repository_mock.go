@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transfer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+)
+
+// MockTransferRepository is a mock of TransferRepository interface.
+type MockTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferRepositoryMockRecorder
+}
+
+// MockTransferRepositoryMockRecorder is the mock recorder for MockTransferRepository.
+type MockTransferRepositoryMockRecorder struct {
+	mock *MockTransferRepository
+}
+
+// NewMockTransferRepository creates a new mock instance.
+func NewMockTransferRepository(ctrl *gomock.Controller) *MockTransferRepository {
+	mock := &MockTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferRepository) EXPECT() *MockTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptTransfer mocks base method.
+func (m *MockTransferRepository) AcceptTransfer(ctx context.Context, transferID, pvzID, receptionID uuid.UUID) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptTransfer", ctx, transferID, pvzID, receptionID)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptTransfer indicates an expected call of AcceptTransfer.
+func (mr *MockTransferRepositoryMockRecorder) AcceptTransfer(ctx, transferID, pvzID, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptTransfer", reflect.TypeOf((*MockTransferRepository)(nil).AcceptTransfer), ctx, transferID, pvzID, receptionID)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockTransferRepository) CreateTransfer(ctx context.Context, transferID, fromPvzID, toPvzID, createdBy uuid.UUID, productIDs []uuid.UUID) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, transferID, fromPvzID, toPvzID, createdBy, productIDs)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockTransferRepositoryMockRecorder) CreateTransfer(ctx, transferID, fromPvzID, toPvzID, createdBy, productIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockTransferRepository)(nil).CreateTransfer), ctx, transferID, fromPvzID, toPvzID, createdBy, productIDs)
+}
+
+// DispatchTransfer mocks base method.
+func (m *MockTransferRepository) DispatchTransfer(ctx context.Context, transferID, pvzID uuid.UUID) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchTransfer", ctx, transferID, pvzID)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DispatchTransfer indicates an expected call of DispatchTransfer.
+func (mr *MockTransferRepositoryMockRecorder) DispatchTransfer(ctx, transferID, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchTransfer", reflect.TypeOf((*MockTransferRepository)(nil).DispatchTransfer), ctx, transferID, pvzID)
+}
+
+// ListTransfers mocks base method.
+func (m *MockTransferRepository) ListTransfers(ctx context.Context, pvzID uuid.UUID, status *models.Status) ([]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", ctx, pvzID, status)
+	ret0, _ := ret[0].([]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockTransferRepositoryMockRecorder) ListTransfers(ctx, pvzID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockTransferRepository)(nil).ListTransfers), ctx, pvzID, status)
+}
@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	url "net/url"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+)
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.Target, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]models.Target)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDueDeliveries(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDueDeliveries), ctx, limit, lease)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, sub models.Subscription) (*models.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, sub)
+	ret0, _ := ret[0].(*models.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) CreateSubscription(ctx, sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).CreateSubscription), ctx, sub)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteSubscription), ctx, id)
+}
+
+// EnqueueEvent mocks base method.
+func (m *MockWebhookRepository) EnqueueEvent(ctx context.Context, eventType string, pvzID uuid.UUID, payload []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueEvent", ctx, eventType, pvzID, payload)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueEvent indicates an expected call of EnqueueEvent.
+func (mr *MockWebhookRepositoryMockRecorder) EnqueueEvent(ctx, eventType, pvzID, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueEvent", reflect.TypeOf((*MockWebhookRepository)(nil).EnqueueEvent), ctx, eventType, pvzID, payload)
+}
+
+// FailDelivery mocks base method.
+func (m *MockWebhookRepository) FailDelivery(ctx context.Context, id int64, statusCode *int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDelivery", ctx, id, statusCode, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDelivery indicates an expected call of FailDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) FailDelivery(ctx, id, statusCode, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).FailDelivery), ctx, id, statusCode, lastError)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]models.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ListDeliveries(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ListDeliveries), ctx, subscriptionID, limit)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]models.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).ListSubscriptions), ctx)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockWebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ctx, id, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockWebhookRepositoryMockRecorder) MarkDelivered(ctx, id, statusCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockWebhookRepository)(nil).MarkDelivered), ctx, id, statusCode)
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhookRepository) Redeliver(ctx context.Context, deliveryID int64) (*models.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, deliveryID)
+	ret0, _ := ret[0].(*models.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookRepositoryMockRecorder) Redeliver(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookRepository)(nil).Redeliver), ctx, deliveryID)
+}
+
+// RetryDelivery mocks base method.
+func (m *MockWebhookRepository) RetryDelivery(ctx context.Context, id int64, statusCode *int, lastError string, delay time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryDelivery", ctx, id, statusCode, lastError, delay)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryDelivery indicates an expected call of RetryDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) RetryDelivery(ctx, id, statusCode, lastError, delay interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).RetryDelivery), ctx, id, statusCode, lastError, delay)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// CheckURL mocks base method.
+func (m *MockWebhookSender) CheckURL(u *url.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckURL", u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckURL indicates an expected call of CheckURL.
+func (mr *MockWebhookSenderMockRecorder) CheckURL(u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckURL", reflect.TypeOf((*MockWebhookSender)(nil).CheckURL), u)
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(ctx context.Context, target models.Target) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, target)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(ctx, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), ctx, target)
+}
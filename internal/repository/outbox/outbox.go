@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// Блокировка агрегата до конца транзакции: следующая запись того же ПВЗ получит id
+	// только после коммита предыдущей, поэтому порядок id совпадает с порядком коммитов
+	LockOutboxAggregateQuery = `SELECT pg_advisory_xact_lock($1, hashtext($2::text))`
+
+	InsertOutboxQuery = `
+		INSERT INTO outbox (aggregate_id, event_type, payload)
+		VALUES ($1, $2, $3)`
+
+	ClaimOutboxQuery = `
+		SELECT id, aggregate_id, payload, created_at
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	// Самое раннее неразосланное событие каждого агрегата, включая заблокированные другими диспетчерами
+	GetOutboxHeadsQuery = `
+		SELECT aggregate_id, min(id)
+		FROM outbox
+		WHERE dispatched_at IS NULL AND aggregate_id = ANY($1::uuid[])
+		GROUP BY aggregate_id`
+
+	MarkOutboxDispatchedQuery = `
+		UPDATE outbox
+		SET dispatched_at = now()
+		WHERE id = ANY($1::bigint[])`
+
+	RecordOutboxFailureQuery = `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2
+		WHERE id = $1`
+
+	GetOutboxLastIDQuery = `SELECT COALESCE(max(id), 0) FROM outbox`
+
+	// Чтение без блокировок и независимо от dispatched_at: каждая реплика читает все события.
+	// $2 - пропуски номеров, которые могут оказаться транзакциями, закоммиченными позже
+	ListOutboxAfterQuery = `
+		SELECT id, aggregate_id, payload, created_at
+		FROM outbox
+		WHERE id > $1 OR id = ANY($2::bigint[])
+		ORDER BY id
+		LIMIT $3`
+)
+
+// outboxLockClass - первый ключ advisory lock агрегатов, чтобы не пересекаться с другими блокировками
+const outboxLockClass int32 = 0x5056_5a03
+
+// Write добавляет событие в outbox в транзакции изменения. Если автор не задан, берется пользователь из контекста
+func Write(ctx context.Context, tx *sql.Tx, event models.Event) error {
+	if event.ActorID == nil {
+		if userID, ok := authctx.GetUserUUID(ctx); ok {
+			event.ActorID = &userID
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal outbox event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, LockOutboxAggregateQuery, outboxLockClass, event.PickupPointID); err != nil {
+		return fmt.Errorf("lock outbox aggregate: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, InsertOutboxQuery, event.PickupPointID, string(event.Type), payload); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+
+	return nil
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+type outboxRow struct {
+	id          int64
+	aggregateID uuid.UUID
+	event       models.Event
+}
+
+// Dispatch забирает до limit неразосланных событий и передает их handle по порядку id.
+// Событие агрегата пропускается, если более раннее событие того же агрегата еще не разослано
+// (его держит другой диспетчер) или не удалось в этой порции. Разосланные события отмечаются
+// в той же транзакции, поэтому при сбое до коммита они будут отправлены повторно (at-least-once)
+func (r *OutboxRepository) Dispatch(ctx context.Context, limit int, handle func(context.Context, models.Event) error) (int, error) {
+	const op = "OutboxRepository.Dispatch"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	batch, err := claimOutbox(ctx, tx, limit)
+	if err != nil {
+		logger.WithError(err).Error("claim outbox events")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	heads, err := outboxHeads(ctx, tx, batch)
+	if err != nil {
+		logger.WithError(err).Error("get outbox heads")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	blocked := make(map[uuid.UUID]bool)
+	seen := make(map[uuid.UUID]bool)
+	var dispatched pq.Int64Array
+	for _, row := range batch {
+		if !seen[row.aggregateID] {
+			seen[row.aggregateID] = true
+			if heads[row.aggregateID] < row.id {
+				blocked[row.aggregateID] = true
+			}
+		}
+		if blocked[row.aggregateID] {
+			continue
+		}
+
+		if err := handle(ctx, row.event); err != nil {
+			logger.WithError(err).WithField("outbox_id", row.id).Warn("failed to dispatch outbox event")
+			blocked[row.aggregateID] = true
+			if _, err := tx.ExecContext(ctx, RecordOutboxFailureQuery, row.id, err.Error()); err != nil {
+				logger.WithError(err).Error("record outbox failure")
+				return 0, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+		dispatched = append(dispatched, row.id)
+	}
+
+	if len(dispatched) > 0 {
+		if _, err := tx.ExecContext(ctx, MarkOutboxDispatchedQuery, dispatched); err != nil {
+			logger.WithError(err).Error("mark outbox events dispatched")
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(dispatched), nil
+}
+
+// LastID возвращает номер последнего события outbox (0, если событий нет)
+func (r *OutboxRepository) LastID(ctx context.Context) (uint64, error) {
+	const op = "OutboxRepository.LastID"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	var lastID int64
+	if err := r.db.QueryRowContext(ctx, GetOutboxLastIDQuery).Scan(&lastID); err != nil {
+		logger.WithError(err).Error("get last outbox id")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return uint64(lastID), nil
+}
+
+// ListAfter возвращает до limit событий с номером больше afterID или из списка gaps по порядку id,
+// не отмечая их разосланными
+func (r *OutboxRepository) ListAfter(ctx context.Context, afterID uint64, gaps []uint64, limit int) ([]models.Event, error) {
+	const op = "OutboxRepository.ListAfter"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	gapIDs := make(pq.Int64Array, 0, len(gaps))
+	for _, id := range gaps {
+		gapIDs = append(gapIDs, int64(id))
+	}
+
+	rows, err := r.db.QueryContext(ctx, ListOutboxAfterQuery, int64(afterID), gapIDs, limit)
+	if err != nil {
+		logger.WithError(err).Error("list outbox events")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	batch, err := scanOutbox(rows)
+	if err != nil {
+		logger.WithError(err).Error("scan outbox events")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events := make([]models.Event, 0, len(batch))
+	for _, row := range batch {
+		events = append(events, row.event)
+	}
+	return events, nil
+}
+
+func claimOutbox(ctx context.Context, tx *sql.Tx, limit int) ([]outboxRow, error) {
+	rows, err := tx.QueryContext(ctx, ClaimOutboxQuery, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutbox(rows)
+}
+
+func scanOutbox(rows *sql.Rows) ([]outboxRow, error) {
+
+	var batch []outboxRow
+	for rows.Next() {
+		var (
+			row       outboxRow
+			payload   []byte
+			createdAt time.Time
+		)
+		if err := rows.Scan(&row.id, &row.aggregateID, &payload, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &row.event); err != nil {
+			return nil, fmt.Errorf("decode outbox event %d: %w", row.id, err)
+		}
+		row.event.ID = uint64(row.id)
+		row.event.At = createdAt
+		batch = append(batch, row)
+	}
+
+	return batch, rows.Err()
+}
+
+func outboxHeads(ctx context.Context, tx *sql.Tx, batch []outboxRow) (map[uuid.UUID]int64, error) {
+	ids := make(pq.StringArray, 0, len(batch))
+	added := make(map[uuid.UUID]bool)
+	for _, row := range batch {
+		if !added[row.aggregateID] {
+			added[row.aggregateID] = true
+			ids = append(ids, row.aggregateID.String())
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, GetOutboxHeadsQuery, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	heads := make(map[uuid.UUID]int64, len(ids))
+	for rows.Next() {
+		var (
+			aggregateID uuid.UUID
+			head        int64
+		)
+		if err := rows.Scan(&aggregateID, &head); err != nil {
+			return nil, err
+		}
+		heads[aggregateID] = head
+	}
+
+	return heads, rows.Err()
+}
@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	product "github.com/nik-mLb/avito_task/internal/models/product"
 	reception "github.com/nik-mLb/avito_task/internal/models/reception"
@@ -30,8 +32,39 @@ const (
 		INNER JOIN product p ON r.id = p.reception_id  -- <- Тут INNER вместо LEFT
 		WHERE ($1::timestamp IS NULL OR r.reception_date >= $1)
 		AND ($2::timestamp IS NULL OR r.reception_date <= $2)
-		ORDER BY pp.registration_date DESC
+		AND ($5::uuid IS NULL OR pp.id = $5)
+		ORDER BY pp.registration_date DESC, pp.id, r.reception_date, p.reception_date
 		LIMIT $3 OFFSET $4`
+
+	GetPickupPointCapacityQuery = `
+		SELECT capacity, capacity_mode FROM pickup_point
+		WHERE id = $1`
+
+	GetOccupancyByTypeQuery = `
+		SELECT p.product_type, count(*)
+		FROM product p
+		JOIN reception r ON r.id = p.reception_id
+		WHERE r.pickup_point_id = $1
+		GROUP BY p.product_type
+		ORDER BY p.product_type`
+
+	ExportPickupPointsQuery = `
+		SELECT
+			pp.id, pp.city, pp.registration_date,
+			r.id, r.reception_date, r.status,
+			p.id, p.product_type, p.reception_date
+		FROM pickup_point pp
+		JOIN reception r ON pp.id = r.pickup_point_id
+		LEFT JOIN product p ON r.id = p.reception_id
+		WHERE ($1::timestamp IS NULL OR r.reception_date >= $1)
+		AND ($2::timestamp IS NULL OR r.reception_date <= $2)
+		AND ($3::uuid IS NULL OR pp.id = $3)
+		ORDER BY pp.registration_date DESC, pp.id, r.reception_date, p.reception_date`
+
+	SetCapacityQuery = `
+		UPDATE pickup_point
+		SET capacity = $2, capacity_mode = $3
+		WHERE id = $1`
 )
 
 type PickupPointRepository struct {
@@ -63,8 +96,25 @@ func (r *PickupPointRepository) CreatePickupPoint(ctx context.Context, city stri
 	return pickupPoint, err
 }
 
-func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error) {
-	const op = "PickupPointRepository.GetPickupPointsWithReceptions"
+// GetPickupPointsWithReceptions возвращает страницу ПВЗ с приемками, pvzID != nil оставляет только этот ПВЗ
+func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int) ([]dto.PickupPointListResponse, error) {
+	output := []dto.PickupPointListResponse{}
+	err := r.StreamPickupPointsWithReceptions(ctx, startDate, endDate, pvzID, page, limit, func(item dto.PickupPointListResponse) error {
+		output = append(output, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// StreamPickupPointsWithReceptions передает в fn ПВЗ по одному, как только прочитаны все его строки.
+// Запрос упорядочен по ПВЗ, поэтому строки одного ПВЗ идут подряд и в памяти держится только текущий.
+// pvzID != nil оставляет только этот ПВЗ. Ошибка fn прерывает чтение и возвращается как есть
+func (r *PickupPointRepository) StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int, fn func(dto.PickupPointListResponse) error) error {
+	const op = "PickupPointRepository.StreamPickupPointsWithReceptions"
 	logger := logctx.GetLogger(ctx).WithField("op", op).
 		WithField("start_date", startDate).
 		WithField("end_date", endDate).
@@ -73,14 +123,14 @@ func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Contex
 
 	offset := (page - 1) * limit
 
-	rows, err := r.db.QueryContext(ctx, GetPickupPointsWithReceptionsQuery, startDate, endDate, limit, offset)
+	rows, err := r.db.QueryContext(ctx, GetPickupPointsWithReceptionsQuery, startDate, endDate, limit, offset, pvzID)
 	if err != nil {
 		logger.WithError(err).Error("failed to query pickup points")
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
-	results := make(map[uuid.UUID]*dto.PickupPointListResponse)
+	var current *dto.PickupPointListResponse
 
 	for rows.Next() {
 		var (
@@ -102,9 +152,14 @@ func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Contex
 			continue
 		}
 
-		// Если это новый ПВЗ, добавляем его в результаты
-		if _, exists := results[pp.ID]; !exists {
-			results[pp.ID] = &dto.PickupPointListResponse{
+		// Начался новый ПВЗ - отдаем предыдущий
+		if current == nil || current.PickupPoint.ID != pp.ID {
+			if current != nil {
+				if err := fn(*current); err != nil {
+					return err
+				}
+			}
+			current = &dto.PickupPointListResponse{
 				PickupPoint: pp,
 				Receptions:  []dto.ReceptionWithProducts{},
 			}
@@ -117,20 +172,20 @@ func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Contex
 
 			// Ищем приемку в списке
 			var foundRec *dto.ReceptionWithProducts
-			for i := range results[pp.ID].Receptions {
-				if results[pp.ID].Receptions[i].Reception.ID == rec.ID {
-					foundRec = &results[pp.ID].Receptions[i]
+			for i := range current.Receptions {
+				if current.Receptions[i].Reception.ID == rec.ID {
+					foundRec = &current.Receptions[i]
 					break
 				}
 			}
 
 			// Если приемка новая, добавляем ее
 			if foundRec == nil {
-				results[pp.ID].Receptions = append(results[pp.ID].Receptions, dto.ReceptionWithProducts{
+				current.Receptions = append(current.Receptions, dto.ReceptionWithProducts{
 					Reception: rec,
 					Products:  []product.Product{},
 				})
-				foundRec = &results[pp.ID].Receptions[len(results[pp.ID].Receptions)-1]
+				foundRec = &current.Receptions[len(current.Receptions)-1]
 			}
 
 			// Если есть товар, добавляем его
@@ -144,14 +199,154 @@ func (r *PickupPointRepository) GetPickupPointsWithReceptions(ctx context.Contex
 
 	if err = rows.Err(); err != nil {
 		logger.WithError(err).Error("rows iteration error")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if current != nil {
+		return fn(*current)
+	}
+
+	return nil
+}
+
+func (r *PickupPointRepository) GetOccupancy(ctx context.Context, pvzID uuid.UUID) (*pickup.Occupancy, error) {
+	const op = "PickupPointRepository.GetOccupancy"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
+
+	var (
+		capacity sql.NullInt64
+		mode     string
+	)
+	err := r.db.QueryRowContext(ctx, GetPickupPointCapacityQuery, pvzID).Scan(&capacity, &mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("pickup point not found")
+			return nil, errs.ErrPickupPointNotFound
+		}
+		logger.WithError(err).Error("failed to get capacity")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Преобразуем map в slice
-	output := make([]dto.PickupPointListResponse, 0, len(results))
-	for _, v := range results {
-		output = append(output, *v)
+	rows, err := r.db.QueryContext(ctx, GetOccupancyByTypeQuery, pvzID)
+	if err != nil {
+		logger.WithError(err).Error("failed to query occupancy")
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
-	return output, nil
-}
\ No newline at end of file
+	occupancy := &pickup.Occupancy{
+		PickupPointID: pvzID,
+		Mode:          pickup.CapacityMode(mode),
+		ByType:        []pickup.TypeOccupancy{},
+	}
+	for rows.Next() {
+		var item pickup.TypeOccupancy
+		if err := rows.Scan(&item.ProductType, &item.Count); err != nil {
+			logger.WithError(err).Error("scan error")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		occupancy.Occupied += item.Count
+		occupancy.ByType = append(occupancy.ByType, item)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if capacity.Valid {
+		c := int(capacity.Int64)
+		available := c - occupancy.Occupied
+		if available < 0 {
+			available = 0
+		}
+		occupancy.Capacity = &c
+		occupancy.Available = &available
+	}
+
+	return occupancy, nil
+}
+
+// SetCapacity задает вместимость ПВЗ, capacity == nil снимает ограничение
+func (r *PickupPointRepository) SetCapacity(ctx context.Context, pvzID uuid.UUID, capacity *int, mode pickup.CapacityMode) error {
+	const op = "PickupPointRepository.SetCapacity"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("mode", mode)
+
+	var capacityArg sql.NullInt64
+	if capacity != nil {
+		capacityArg = sql.NullInt64{Int64: int64(*capacity), Valid: true}
+	}
+
+	res, err := r.db.ExecContext(ctx, SetCapacityQuery, pvzID, capacityArg, string(mode))
+	if err != nil {
+		logger.WithError(err).Error("failed to set capacity")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("pickup point not found")
+		return errs.ErrPickupPointNotFound
+	}
+
+	return nil
+}
+
+// ExportPickupPoints построчно передает в fn ПВЗ с приемками и товарами, не накапливая результат в памяти.
+// pvzID != nil ограничивает выгрузку одним ПВЗ. Ошибка fn прерывает выгрузку и возвращается как есть
+func (r *PickupPointRepository) ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, fn func(pickup.ExportRow) error) error {
+	const op = "PickupPointRepository.ExportPickupPoints"
+	logger := logctx.GetLogger(ctx).WithField("op", op).
+		WithField("start_date", startDate).
+		WithField("end_date", endDate)
+
+	rows, err := r.db.QueryContext(ctx, ExportPickupPointsQuery, startDate, endDate, pvzID)
+	if err != nil {
+		logger.WithError(err).Error("failed to query pickup points")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row      pickup.ExportRow
+			prodID   uuid.NullUUID
+			prodType sql.NullString
+			prodDate sql.NullTime
+		)
+
+		err := rows.Scan(
+			&row.PickupPoint.ID, &row.PickupPoint.City, &row.PickupPoint.RegistrationDate,
+			&row.Reception.ID, &row.Reception.ReceptionDate, &row.Reception.Status,
+			&prodID, &prodType, &prodDate,
+		)
+		if err != nil {
+			logger.WithError(err).Error("scan error")
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		row.Reception.PickupPointID = row.PickupPoint.ID
+		if prodID.Valid {
+			row.Product = &product.Product{
+				ID:            prodID.UUID,
+				ReceptionDate: prodDate.Time,
+				ReceptionID:   row.Reception.ID,
+				ProductType:   product.ProductType(prodType.String),
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
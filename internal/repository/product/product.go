@@ -8,7 +8,12 @@ import (
 
 	"github.com/google/uuid"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	models "github.com/nik-mLb/avito_task/internal/models/product"
+	historyrepo "github.com/nik-mLb/avito_task/internal/repository/history"
+	outbox "github.com/nik-mLb/avito_task/internal/repository/outbox"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
 
@@ -18,6 +23,16 @@ const (
 		VALUES ($1, $2, $3, now())
 		RETURNING id, reception_id, product_type, reception_date`
 
+	LockPickupPointCapacityQuery = `
+		SELECT capacity, capacity_mode FROM pickup_point
+		WHERE id = $1
+		FOR UPDATE`
+
+	CountPickupPointProductsQuery = `
+		SELECT count(*) FROM product p
+		JOIN reception r ON r.id = p.reception_id
+		WHERE r.pickup_point_id = $1`
+
 	GetActiveReceptionQuery = `
 		SELECT id FROM reception 
 		WHERE pickup_point_id = $1 AND status = 'in_progress'
@@ -37,7 +52,7 @@ const (
     DeleteProductQuery = `
         DELETE FROM product 
         WHERE id = $1
-        RETURNING id`
+        RETURNING id, reception_id, product_type, reception_date`
 )
 
 type ProductRepository struct {
@@ -51,9 +66,16 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 func (r *ProductRepository) AddProduct(ctx context.Context, pvzID uuid.UUID, productType string) (*models.Product, error) {
 	const op = "ProductRepository.AddProduct"
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("product_type", productType)
-    
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
 	var receptionID uuid.UUID
-	err := r.db.QueryRowContext(ctx, GetActiveReceptionQuery, pvzID).Scan(&receptionID)
+	err = tx.QueryRowContext(ctx, GetActiveReceptionQuery, pvzID).Scan(&receptionID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("no active reception found")
@@ -63,26 +85,97 @@ func (r *ProductRepository) AddProduct(ctx context.Context, pvzID uuid.UUID, pro
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := CheckCapacity(ctx, tx, pvzID, 1); err != nil {
+		if errors.Is(err, errs.ErrPickupPointFull) {
+			logger.Warn("pickup point is full")
+			return nil, err
+		}
+		logger.WithError(err).Error("check capacity")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	product := &models.Product{}
-	err = r.db.QueryRowContext(ctx, CreateProductQuery, uuid.New(), receptionID, productType).
+	err = tx.QueryRowContext(ctx, CreateProductQuery, uuid.New(), receptionID, productType).
 		Scan(&product.ID, &product.ReceptionID, &product.ProductType, &product.ReceptionDate)
+	if err != nil {
+		logger.WithError(err).Error("create product")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-    if err != nil {
-        logger.WithError(err).Error("create product")
-        return nil, fmt.Errorf("%s: %w", op, err)
-    }
+	err = historyrepo.Write(ctx, tx, history.Event{
+		ReceptionID: product.ReceptionID,
+		Type:        history.ProductAdded,
+		ProductID:   &product.ID,
+		ProductType: string(product.ProductType),
+	})
+	if err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          history.ProductAdded,
+		PickupPointID: pvzID,
+		ReceptionID:   product.ReceptionID,
+		ProductID:     &product.ID,
+		ProductType:   string(product.ProductType),
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return product, nil
 }
 
-func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+// CheckCapacity блокирует ПВЗ до конца транзакции и проверяет, есть ли место еще под incoming товаров.
+// При мягком лимите превышение только логируется
+func CheckCapacity(ctx context.Context, tx *sql.Tx, pvzID uuid.UUID, incoming int64) error {
+	logger := logctx.GetLogger(ctx).WithField("pvz_id", pvzID)
+
+	var (
+		capacity sql.NullInt64
+		mode     string
+	)
+	if err := tx.QueryRowContext(ctx, LockPickupPointCapacityQuery, pvzID).Scan(&capacity, &mode); err != nil {
+		return err
+	}
+	if !capacity.Valid {
+		return nil
+	}
+
+	var occupied int64
+	if err := tx.QueryRowContext(ctx, CountPickupPointProductsQuery, pvzID).Scan(&occupied); err != nil {
+		return err
+	}
+	if occupied+incoming <= capacity.Int64 {
+		return nil
+	}
+
+	if pickup.CapacityMode(mode) == pickup.CapacitySoft {
+		logger.WithField("capacity", capacity.Int64).
+			WithField("occupied", occupied).
+			WithField("incoming", incoming).
+			Warn("soft capacity limit exceeded")
+		return nil
+	}
+
+	return errs.ErrPickupPointFull
+}
+
+func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error) {
     const op = "ProductRepository.DeleteLastProduct"
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
 
     tx, err := r.db.BeginTx(ctx, nil)
     if err != nil {
 		logger.WithError(err).Error("begin transaction")
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
     defer tx.Rollback()
 
@@ -91,22 +184,47 @@ func (r *ProductRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UU
     if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("no products to delete")
-			return errs.ErrNoProductsToDelete
+			return nil, errs.ErrNoProductsToDelete
 		}
 		logger.WithError(err).Error("query last product")
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-    _, err = tx.ExecContext(ctx, DeleteProductQuery, productID)
+    product := &models.Product{}
+    err = tx.QueryRowContext(ctx, DeleteProductQuery, productID).
+        Scan(&product.ID, &product.ReceptionID, &product.ProductType, &product.ReceptionDate)
     if err != nil {
 		logger.WithError(err).Error("delete product")
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = historyrepo.Write(ctx, tx, history.Event{
+		ReceptionID: product.ReceptionID,
+		Type:        history.ProductDeleted,
+		ProductID:   &product.ID,
+		ProductType: string(product.ProductType),
+	})
+	if err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          history.ProductDeleted,
+		PickupPointID: pvzID,
+		ReceptionID:   product.ReceptionID,
+		ProductID:     &product.ID,
+		ProductType:   string(product.ProductType),
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	if err := tx.Commit(); err != nil {
 		logger.WithError(err).Error("commit transaction")
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-    return nil
+    return product, nil
 }
\ No newline at end of file
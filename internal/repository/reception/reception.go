@@ -5,8 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	historyrepo "github.com/nik-mLb/avito_task/internal/repository/history"
+	outbox "github.com/nik-mLb/avito_task/internal/repository/outbox"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 
 	"github.com/google/uuid"
@@ -14,6 +19,13 @@ import (
 )
 
 const (
+	// Открытие и переоткрытие приемки блокируют ПВЗ, иначе параллельные запросы могут
+	// оставить в нем две приемки in_progress
+	LockPickupPointQuery = `
+		SELECT id FROM pickup_point
+		WHERE id = $1
+		FOR UPDATE`
+
 	CreateReceptionQuery = `
 		INSERT INTO reception (id, pickup_point_id, status) 
 		VALUES ($1, $2, 'in_progress')
@@ -27,15 +39,58 @@ const (
 
 	CloseReceptionQuery = `
         UPDATE reception 
-        SET status = 'close' 
+        SET status = 'close', closed_at = now(), close_reason = 'manual'
         WHERE id = (
             SELECT id FROM reception 
             WHERE pickup_point_id = $1 AND status = 'in_progress'
             LIMIT 1
         )
         RETURNING id, reception_date, pickup_point_id, status`
+
+	TryAutoCloseLockQuery = `SELECT pg_try_advisory_xact_lock($1)`
+
+	// Простой считается от последнего товара приемки, а если товаров нет — от ее создания
+	CloseStaleReceptionsQuery = `
+		UPDATE reception r
+		SET status = 'close', closed_at = now(), close_reason = $2
+		WHERE r.status = 'in_progress'
+		AND COALESCE(
+			(SELECT max(p.reception_date) FROM product p WHERE p.reception_id = r.id),
+			r.reception_date
+		) < now() - make_interval(secs => $1)
+		RETURNING r.id, r.reception_date, r.pickup_point_id, r.status`
+
+	GetReceptionPickupPointQuery = `
+		SELECT pickup_point_id FROM reception
+		WHERE id = $1`
+
+	GetReceptionForReopenQuery = `
+		SELECT id, reception_date, pickup_point_id, status,
+			closed_at IS NOT NULL AND closed_at >= now() - make_interval(secs => $2)
+		FROM reception
+		WHERE id = $1
+		FOR UPDATE`
+
+	CheckNewerReceptionQuery = `
+		SELECT EXISTS (
+			SELECT 1 FROM reception
+			WHERE pickup_point_id = $1 AND id <> $2 AND reception_date > $3
+		)`
+
+	ReopenReceptionQuery = `
+		UPDATE reception
+		SET status = 'in_progress', closed_at = NULL, close_reason = NULL
+		WHERE id = $1
+		RETURNING id, reception_date, pickup_point_id, status`
+
+	CreateReceptionReopeningQuery = `
+		INSERT INTO reception_reopening (id, reception_id, reopened_by, reason)
+		VALUES ($1, $2, $3, $4)`
 )
 
+// autoCloseLockKey ключ advisory lock, чтобы автозакрытие выполняла только одна реплика
+const autoCloseLockKey int64 = 0x5056_5a01
+
 type ReceptionRepository struct {
 	db *sql.DB
 }
@@ -48,8 +103,24 @@ func (r *ReceptionRepository) CreateReception(ctx context.Context, receptionID u
 	const op = "ReceptionRepository.CreateReception"
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pickup_point_id", pvzID)
 	
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := lockPickupPoint(ctx, tx, pvzID); err != nil {
+		if errors.Is(err, errs.ErrPickupPointNotFound) {
+			logger.Warn("pickup point not found")
+			return nil, err
+		}
+		logger.WithError(err).Error("lock pickup point")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	var exists bool
-	err := r.db.QueryRowContext(ctx, CheckActiveReceptionQuery, pvzID).Scan(&exists)
+	err = tx.QueryRowContext(ctx, CheckActiveReceptionQuery, pvzID).Scan(&exists)
 	if err != nil {
 		logger.WithError(err).Error("check active reception")
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -60,7 +131,7 @@ func (r *ReceptionRepository) CreateReception(ctx context.Context, receptionID u
 	}
 
 	reception := &models.Reception{}
-	err = r.db.QueryRowContext(ctx, CreateReceptionQuery, receptionID, pvzID).
+	err = tx.QueryRowContext(ctx, CreateReceptionQuery, receptionID, pvzID).
 		Scan(&reception.ID, &reception.ReceptionDate, &reception.PickupPointID, &reception.Status)
 
 	if err != nil {
@@ -68,6 +139,30 @@ func (r *ReceptionRepository) CreateReception(ctx context.Context, receptionID u
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = historyrepo.Write(ctx, tx, history.Event{
+		ReceptionID: reception.ID,
+		Type:        history.ReceptionOpened,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          history.ReceptionOpened,
+		PickupPointID: reception.PickupPointID,
+		ReceptionID:   reception.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return reception, nil
 }
 
@@ -75,12 +170,17 @@ func (r *ReceptionRepository) CloseReception(ctx context.Context, pvzID uuid.UUI
 	const op = "ReceptionRepository.CloseReception"
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pickup_point_id", pvzID)
 
-    reception := &models.Reception{}
-    
-    err := r.db.QueryRowContext(ctx, CloseReceptionQuery, pvzID).
-        Scan(&reception.ID, &reception.ReceptionDate, &reception.PickupPointID, &reception.Status)
-    
-    if err != nil {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	reception := &models.Reception{}
+	err = tx.QueryRowContext(ctx, CloseReceptionQuery, pvzID).
+		Scan(&reception.ID, &reception.ReceptionDate, &reception.PickupPointID, &reception.Status)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("no active reception to close")
 			return nil, errs.ErrNoActiveReceptionToClose
@@ -88,6 +188,229 @@ func (r *ReceptionRepository) CloseReception(ctx context.Context, pvzID uuid.UUI
 		logger.WithError(err).Error("close reception")
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-    
-    return reception, nil
+
+	err = historyrepo.Write(ctx, tx, history.Event{
+		ReceptionID: reception.ID,
+		Type:        history.ReceptionClosed,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          history.ReceptionClosed,
+		PickupPointID: reception.PickupPointID,
+		ReceptionID:   reception.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reception, nil
+}
+
+// CloseStaleReceptions закрывает приемки, простаивающие дольше idleTimeout.
+// Если блокировку держит другая реплика, возвращает пустой список
+func (r *ReceptionRepository) CloseStaleReceptions(ctx context.Context, idleTimeout time.Duration, reason string) ([]models.Reception, error) {
+	const op = "ReceptionRepository.CloseStaleReceptions"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("idle_timeout", idleTimeout)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, TryAutoCloseLockQuery, autoCloseLockKey).Scan(&locked); err != nil {
+		logger.WithError(err).Error("acquire advisory lock")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !locked {
+		logger.Debug("auto close is running on another replica")
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, CloseStaleReceptionsQuery, idleTimeout.Seconds(), reason)
+	if err != nil {
+		logger.WithError(err).Error("close stale receptions")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var closed []models.Reception
+	for rows.Next() {
+		var reception models.Reception
+		if err := rows.Scan(&reception.ID, &reception.ReceptionDate, &reception.PickupPointID, &reception.Status); err != nil {
+			logger.WithError(err).Error("scan closed reception")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		closed = append(closed, reception)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rows.Close()
+
+	for _, reception := range closed {
+		err := historyrepo.Write(ctx, tx, history.Event{
+			ReceptionID: reception.ID,
+			Type:        history.ReceptionAutoClosed,
+			Details:     reason,
+		})
+		if err != nil {
+			logger.WithError(err).Error("write reception history")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = outbox.Write(ctx, tx, feed.Event{
+			Type:          history.ReceptionAutoClosed,
+			PickupPointID: reception.PickupPointID,
+			ReceptionID:   reception.ID,
+			Details:       reason,
+		})
+		if err != nil {
+			logger.WithError(err).Error("write outbox event")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return closed, nil
+}
+
+// ReopenReception снова открывает закрытую приемку, если с момента закрытия прошло не больше window
+// и в ПВЗ после нее не создавалось других приемок
+func (r *ReceptionRepository) ReopenReception(ctx context.Context, receptionID, reopenedBy uuid.UUID, reason string, window time.Duration) (*models.Reception, error) {
+	const op = "ReceptionRepository.ReopenReception"
+	logger := logctx.GetLogger(ctx).WithField("op", op).
+		WithField("reception_id", receptionID).
+		WithField("reopened_by", reopenedBy)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// ПВЗ блокируется раньше приемки, в том же порядке, что и при добавлении товара
+	var pvzID uuid.UUID
+	err = tx.QueryRowContext(ctx, GetReceptionPickupPointQuery, receptionID).Scan(&pvzID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("reception not found")
+			return nil, errs.ErrReceptionNotFound
+		}
+		logger.WithError(err).Error("get reception pickup point")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := lockPickupPoint(ctx, tx, pvzID); err != nil {
+		logger.WithError(err).Error("lock pickup point")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var (
+		current      models.Reception
+		withinWindow bool
+	)
+	err = tx.QueryRowContext(ctx, GetReceptionForReopenQuery, receptionID, window.Seconds()).
+		Scan(&current.ID, &current.ReceptionDate, &current.PickupPointID, &current.Status, &withinWindow)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("reception not found")
+			return nil, errs.ErrReceptionNotFound
+		}
+		logger.WithError(err).Error("get reception")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if current.Status != "close" {
+		logger.Warn("reception is not closed")
+		return nil, errs.ErrReceptionNotClosed
+	}
+	if !withinWindow {
+		logger.Warn("reopen window has expired")
+		return nil, errs.ErrReopenWindowExpired
+	}
+
+	var newerExists bool
+	err = tx.QueryRowContext(ctx, CheckNewerReceptionQuery, current.PickupPointID, current.ID, current.ReceptionDate).
+		Scan(&newerExists)
+	if err != nil {
+		logger.WithError(err).Error("check newer reception")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if newerExists {
+		logger.Warn("pickup point has a newer reception")
+		return nil, errs.ErrNewerReceptionExists
+	}
+
+	reception := &models.Reception{}
+	err = tx.QueryRowContext(ctx, ReopenReceptionQuery, receptionID).
+		Scan(&reception.ID, &reception.ReceptionDate, &reception.PickupPointID, &reception.Status)
+	if err != nil {
+		logger.WithError(err).Error("reopen reception")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, CreateReceptionReopeningQuery, uuid.New(), receptionID, reopenedBy, reason); err != nil {
+		logger.WithError(err).Error("record reopening")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = historyrepo.Write(ctx, tx, history.Event{
+		ReceptionID: reception.ID,
+		Type:        history.ReceptionReopened,
+		ActorID:     &reopenedBy,
+		Details:     reason,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          history.ReceptionReopened,
+		PickupPointID: reception.PickupPointID,
+		ReceptionID:   reception.ID,
+		ActorID:       &reopenedBy,
+		Details:       reason,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reception, nil
+}
+
+// lockPickupPoint блокирует ПВЗ до конца транзакции
+func lockPickupPoint(ctx context.Context, tx *sql.Tx, pvzID uuid.UUID) error {
+	var id uuid.UUID
+	if err := tx.QueryRowContext(ctx, LockPickupPointQuery, pvzID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrPickupPointNotFound
+		}
+		return err
+	}
+	return nil
 }
\ No newline at end of file
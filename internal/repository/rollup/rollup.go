@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	models "github.com/nik-mLb/avito_task/internal/models/rollup"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+// Агрегаты пересчитываются целиком для пар (день, ПВЗ) из временной таблицы rollup_dirty,
+// поэтому пересчет идемпотентен и одинаков для фоновой задачи и backfill
+const (
+	rawPvzAggregates = `
+		SELECT
+			r.reception_date::date AS day,
+			r.pickup_point_id,
+			count(*) AS receptions,
+			coalesce(sum(x.products), 0)::bigint AS products,
+			coalesce(array_agg(x.duration ORDER BY x.duration) FILTER (WHERE x.duration IS NOT NULL), '{}') AS durations
+		FROM reception r
+		CROSS JOIN LATERAL (
+			SELECT
+				EXTRACT(EPOCH FROM (r.closed_at - r.reception_date))::float8 AS duration,
+				(SELECT count(*) FROM product p WHERE p.reception_id = r.id) AS products
+		) x
+		WHERE r.kind = 'regular'`
+
+	rawProductTypeAggregates = `
+		SELECT
+			t.day,
+			t.pickup_point_id,
+			t.product_type,
+			count(*) AS receptions,
+			sum(t.products)::bigint AS products,
+			coalesce(array_agg(t.duration ORDER BY t.duration) FILTER (WHERE t.duration IS NOT NULL), '{}') AS durations
+		FROM (
+			SELECT
+				r.reception_date::date AS day,
+				r.pickup_point_id,
+				p.product_type,
+				EXTRACT(EPOCH FROM (r.closed_at - r.reception_date))::float8 AS duration,
+				count(*) AS products
+			FROM reception r
+			JOIN product p ON p.reception_id = r.id
+			WHERE r.kind = 'regular'`
+
+	dirtyCondition = `
+			AND (r.reception_date::date, r.pickup_point_id) IN (SELECT day, pickup_point_id FROM rollup_dirty)`
+
+	rangeCondition = `
+			AND r.reception_date::date BETWEEN $1::date AND $2::date`
+
+	TryRollupLockQuery = `SELECT pg_try_advisory_xact_lock($1)`
+
+	RollupLockQuery = `SELECT pg_advisory_xact_lock($1)`
+
+	GetRollupWatermarkQuery = `
+		SELECT watermark, now() - make_interval(secs => $2)
+		FROM rollup_watermark
+		WHERE name = $1
+		FOR UPDATE`
+
+	CreateRollupDirtyQuery = `
+		CREATE TEMP TABLE rollup_dirty (
+			day             DATE NOT NULL,
+			pickup_point_id UUID NOT NULL
+		) ON COMMIT DROP`
+
+	// Пары (день, ПВЗ), затронутые после watermark: новые и закрытые приемки, события истории
+	// (товары, удаление, переоткрытие) и принятые перемещения, забравшие товары из приемок
+	CollectDirtyFromWatermarkQuery = `
+		INSERT INTO rollup_dirty (day, pickup_point_id)
+		SELECT r.reception_date::date, r.pickup_point_id
+		FROM reception r
+		WHERE r.kind = 'regular' AND (r.reception_date >= $1 OR r.closed_at >= $1)
+		UNION
+		SELECT r.reception_date::date, r.pickup_point_id
+		FROM reception_event e
+		JOIN reception r ON r.id = e.reception_id
+		WHERE e.created_at >= $1 AND r.kind = 'regular'
+		UNION
+		SELECT r.reception_date::date, r.pickup_point_id
+		FROM transfer t
+		JOIN transfer_product tp ON tp.transfer_id = t.id
+		JOIN reception_event e ON e.product_id = tp.product_id AND e.event_type = 'product_added'
+		JOIN reception r ON r.id = e.reception_id
+		WHERE t.accepted_at >= $1`
+
+	// Для backfill пересчитываются все пары диапазона, включая уже не существующие в сырых данных
+	CollectDirtyForRangeQuery = `
+		INSERT INTO rollup_dirty (day, pickup_point_id)
+		SELECT r.reception_date::date, r.pickup_point_id
+		FROM reception r
+		WHERE r.kind = 'regular' AND r.reception_date::date BETWEEN $1::date AND $2::date
+		UNION
+		SELECT day, pickup_point_id
+		FROM daily_pvz_rollup
+		WHERE day BETWEEN $1::date AND $2::date`
+
+	CountDirtyQuery = `SELECT count(*) FROM rollup_dirty`
+
+	DeleteDirtyPvzRollupQuery = `
+		DELETE FROM daily_pvz_rollup
+		WHERE (day, pickup_point_id) IN (SELECT day, pickup_point_id FROM rollup_dirty)`
+
+	DeleteDirtyProductTypeRollupQuery = `
+		DELETE FROM daily_product_type_rollup
+		WHERE (day, pickup_point_id) IN (SELECT day, pickup_point_id FROM rollup_dirty)`
+
+	InsertPvzRollupQuery = `
+		INSERT INTO daily_pvz_rollup (day, pickup_point_id, receptions, products, durations)` +
+		rawPvzAggregates + dirtyCondition + `
+		GROUP BY 1, 2`
+
+	InsertProductTypeRollupQuery = `
+		INSERT INTO daily_product_type_rollup (day, pickup_point_id, product_type, receptions, products, durations)` +
+		rawProductTypeAggregates + dirtyCondition + `
+			GROUP BY r.id, p.product_type
+		) t
+		GROUP BY 1, 2, 3`
+
+	UpdateRollupWatermarkQuery = `
+		UPDATE rollup_watermark
+		SET watermark = $2
+		WHERE name = $1`
+
+	// Сравнение агрегатов с сырыми данными за диапазон дней; возвращаются только расхождения
+	CheckPvzRollupQuery = `
+		WITH raw AS (` + rawPvzAggregates + rangeCondition + `
+			GROUP BY 1, 2
+		),
+		agg AS (
+			SELECT day, pickup_point_id, receptions, products, durations
+			FROM daily_pvz_rollup
+			WHERE day BETWEEN $1::date AND $2::date
+		)
+		SELECT
+			coalesce(raw.day, agg.day), coalesce(raw.pickup_point_id, agg.pickup_point_id), '',
+			coalesce(agg.receptions, 0), coalesce(raw.receptions, 0),
+			coalesce(agg.products, 0), coalesce(raw.products, 0),
+			raw.durations IS DISTINCT FROM agg.durations
+		FROM raw
+		FULL JOIN agg ON agg.day = raw.day AND agg.pickup_point_id = raw.pickup_point_id
+		WHERE raw.receptions IS DISTINCT FROM agg.receptions
+		OR raw.products IS DISTINCT FROM agg.products
+		OR raw.durations IS DISTINCT FROM agg.durations
+		ORDER BY 1, 2`
+
+	CheckProductTypeRollupQuery = `
+		WITH raw AS (` + rawProductTypeAggregates + rangeCondition + `
+				GROUP BY r.id, p.product_type
+			) t
+			GROUP BY 1, 2, 3
+		),
+		agg AS (
+			SELECT day, pickup_point_id, product_type, receptions, products, durations
+			FROM daily_product_type_rollup
+			WHERE day BETWEEN $1::date AND $2::date
+		)
+		SELECT
+			coalesce(raw.day, agg.day), coalesce(raw.pickup_point_id, agg.pickup_point_id),
+			coalesce(raw.product_type, agg.product_type),
+			coalesce(agg.receptions, 0), coalesce(raw.receptions, 0),
+			coalesce(agg.products, 0), coalesce(raw.products, 0),
+			raw.durations IS DISTINCT FROM agg.durations
+		FROM raw
+		FULL JOIN agg ON agg.day = raw.day
+			AND agg.pickup_point_id = raw.pickup_point_id
+			AND agg.product_type = raw.product_type
+		WHERE raw.receptions IS DISTINCT FROM agg.receptions
+		OR raw.products IS DISTINCT FROM agg.products
+		OR raw.durations IS DISTINCT FROM agg.durations
+		ORDER BY 1, 2, 3`
+)
+
+// rollupLockKey ключ advisory lock, чтобы агрегаты пересчитывала только одна реплика
+const rollupLockKey int64 = 0x5056_5a02
+
+// dailyWatermark имя водяного знака суточных агрегатов
+const dailyWatermark = "daily"
+
+type RollupRepository struct {
+	db *sql.DB
+}
+
+func NewRollupRepository(db *sql.DB) *RollupRepository {
+	return &RollupRepository{db: db}
+}
+
+// Refresh пересчитывает агрегаты, затронутые изменениями после watermark, и сдвигает его на now() - lag.
+// lag оставляет запас для транзакций, которые еще не закоммичены. Возвращает число пересчитанных пар (день, ПВЗ)
+func (r *RollupRepository) Refresh(ctx context.Context, lag time.Duration) (int, error) {
+	const op = "RollupRepository.Refresh"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, TryRollupLockQuery, rollupLockKey).Scan(&locked); err != nil {
+		logger.WithError(err).Error("acquire advisory lock")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !locked {
+		logger.Debug("rollup refresh is running on another replica")
+		return 0, nil
+	}
+
+	var from, to time.Time
+	if err := tx.QueryRowContext(ctx, GetRollupWatermarkQuery, dailyWatermark, lag.Seconds()).Scan(&from, &to); err != nil {
+		logger.WithError(err).Error("get watermark")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	logger = logger.WithField("watermark", from)
+
+	if _, err := tx.ExecContext(ctx, CreateRollupDirtyQuery); err != nil {
+		logger.WithError(err).Error("create dirty table")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, CollectDirtyFromWatermarkQuery, from); err != nil {
+		logger.WithError(err).Error("collect changed days")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := recompute(ctx, tx)
+	if err != nil {
+		logger.WithError(err).Error("recompute rollups")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, UpdateRollupWatermarkQuery, dailyWatermark, to); err != nil {
+		logger.WithError(err).Error("update watermark")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// Backfill пересчитывает агрегаты за диапазон дней включительно, не трогая watermark
+func (r *RollupRepository) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	const op = "RollupRepository.Backfill"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("from", from).WithField("to", to)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// В отличие от Refresh ждем освобождения блокировки: backfill запускается вручную и должен отработать
+	if _, err := tx.ExecContext(ctx, RollupLockQuery, rollupLockKey); err != nil {
+		logger.WithError(err).Error("acquire advisory lock")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, CreateRollupDirtyQuery); err != nil {
+		logger.WithError(err).Error("create dirty table")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, CollectDirtyForRangeQuery, from, to); err != nil {
+		logger.WithError(err).Error("collect days")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := recompute(ctx, tx)
+	if err != nil {
+		logger.WithError(err).Error("recompute rollups")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// Check сравнивает агрегаты за диапазон дней с сырыми данными и возвращает расхождения
+func (r *RollupRepository) Check(ctx context.Context, from, to time.Time) ([]models.Mismatch, error) {
+	const op = "RollupRepository.Check"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("from", from).WithField("to", to)
+
+	mismatches := []models.Mismatch{}
+	for _, query := range []string{CheckPvzRollupQuery, CheckProductTypeRollupQuery} {
+		found, err := r.queryMismatches(ctx, query, from, to)
+		if err != nil {
+			logger.WithError(err).Error("failed to check rollups")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		mismatches = append(mismatches, found...)
+	}
+
+	return mismatches, nil
+}
+
+func (r *RollupRepository) queryMismatches(ctx context.Context, query string, from, to time.Time) ([]models.Mismatch, error) {
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []models.Mismatch
+	for rows.Next() {
+		var m models.Mismatch
+		err := rows.Scan(&m.Day, &m.PickupPointID, &m.ProductType,
+			&m.RollupReceptions, &m.RawReceptions, &m.RollupProducts, &m.RawProducts, &m.DurationsMismatch)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches, rows.Err()
+}
+
+// recompute заменяет агрегаты для пар из rollup_dirty значениями из сырых данных
+func recompute(ctx context.Context, tx *sql.Tx) (int, error) {
+	var count int
+	if err := tx.QueryRowContext(ctx, CountDirtyQuery).Scan(&count); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	for _, query := range []string{
+		DeleteDirtyPvzRollupQuery,
+		DeleteDirtyProductTypeRollupQuery,
+		InsertPvzRollupQuery,
+		InsertProductTypeRollupQuery,
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// Показываются сессии, токены которых еще могут действовать: не отозванные, моложе срока жизни
+	// токена и созданные после последнего отзыва всех токенов пользователя (смена пароля или роли)
+	ListSessionsQuery = `
+		SELECT s.id, s.user_id, s.device, s.ip, s.user_agent, s.created_at, s.last_seen_at
+		FROM session s
+		JOIN "user" u ON u.id = s.user_id
+		WHERE s.user_id = $1
+			AND s.revoked_at IS NULL
+			AND s.created_at > now() - make_interval(secs => $2)
+			AND (u.tokens_valid_after IS NULL OR s.created_at >= date_trunc('second', u.tokens_valid_after))
+		ORDER BY s.last_seen_at DESC, s.id`
+
+	RevokeSessionQuery = `
+		UPDATE session
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// ListSessions возвращает действующие сессии пользователя. ttl - срок жизни токена
+func (r *SessionRepository) ListSessions(ctx context.Context, userID uuid.UUID, ttl time.Duration) ([]models.Session, error) {
+	const op = "SessionRepository.ListSessions"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	rows, err := r.db.QueryContext(ctx, ListSessionsQuery, userID, ttl.Seconds())
+	if err != nil {
+		logger.WithError(err).Error("failed to query sessions")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Device, &s.IP, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt); err != nil {
+			logger.WithError(err).Error("failed to scan session")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession отзывает сессию пользователя. Токен сессии перестает действовать на следующем запросе
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	const op = "SessionRepository.RevokeSession"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID).WithField("session_id", sessionID)
+
+	res, err := r.db.ExecContext(ctx, RevokeSessionQuery, sessionID, userID)
+	if err != nil {
+		logger.WithError(err).Error("failed to revoke session")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("session not found")
+		return errs.ErrSessionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+// Статистика читается из суточных агрегатов (daily_pvz_rollup, daily_product_type_rollup), а не из сырых таблиц,
+// поэтому фильтр по датам работает с точностью до дня, а свежие данные появляются после очередного пересчета
+const (
+	pvzRollupSource = `
+		WITH src AS (
+			SELECT r.day, r.pickup_point_id, pp.city, r.receptions, r.products, r.durations
+			FROM daily_pvz_rollup r
+			JOIN pickup_point pp ON pp.id = r.pickup_point_id
+			WHERE ($1::date IS NULL OR r.day >= $1::date)
+			AND ($2::date IS NULL OR r.day <= $2::date)
+		)`
+
+	productTypeRollupSource = `
+		WITH src AS (
+			SELECT r.product_type, r.receptions, r.products, r.durations
+			FROM daily_product_type_rollup r
+			WHERE ($1::date IS NULL OR r.day >= $1::date)
+			AND ($2::date IS NULL OR r.day <= $2::date)
+		)`
+
+	// Счетчики суммируются по строкам агрегатов, а длительности для перцентилей разворачиваются из массивов
+	statsAggregation = `,
+		counts AS (
+			SELECT key, sum(receptions)::bigint AS receptions, sum(products)::bigint AS products
+			FROM keyed
+			GROUP BY key
+		),
+		durations AS (
+			SELECT
+				key,
+				avg(d) AS avg,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY d) AS p50,
+				percentile_cont(0.9) WITHIN GROUP (ORDER BY d) AS p90,
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY d) AS p95
+			FROM keyed
+			CROSS JOIN LATERAL unnest(keyed.durations) AS u(d)
+			GROUP BY key
+		)
+		SELECT
+			c.key, c.receptions, c.products,
+			coalesce(c.products::float8 / nullif(c.receptions, 0), 0),
+			d.avg, d.p50, d.p90, d.p95
+		FROM counts c
+		LEFT JOIN durations d ON d.key = c.key
+		ORDER BY c.key`
+
+	StatsByPickupPointQuery = pvzRollupSource + `,
+		keyed AS (
+			SELECT pickup_point_id::text AS key, receptions, products, durations FROM src
+		)` + statsAggregation
+
+	StatsByCityQuery = pvzRollupSource + `,
+		keyed AS (
+			SELECT city AS key, receptions, products, durations FROM src
+		)` + statsAggregation
+
+	StatsByDayQuery = pvzRollupSource + `,
+		keyed AS (
+			SELECT to_char(day, 'YYYY-MM-DD') AS key, receptions, products, durations FROM src
+		)` + statsAggregation
+
+	StatsByWeekQuery = pvzRollupSource + `,
+		keyed AS (
+			SELECT to_char(date_trunc('week', day), 'YYYY-MM-DD') AS key, receptions, products, durations FROM src
+		)` + statsAggregation
+
+	// Для типа товара приемка учитывается, если в ней есть хотя бы один товар этого типа,
+	// а products считает только товары этого типа
+	StatsByProductTypeQuery = productTypeRollupSource + `,
+		keyed AS (
+			SELECT product_type AS key, receptions, products, durations FROM src
+		)` + statsAggregation
+)
+
+var statsQueries = map[models.GroupBy]string{
+	models.GroupByPickupPoint: StatsByPickupPointQuery,
+	models.GroupByCity:        StatsByCityQuery,
+	models.GroupByDay:         StatsByDayQuery,
+	models.GroupByWeek:        StatsByWeekQuery,
+	models.GroupByProductType: StatsByProductTypeQuery,
+}
+
+type StatisticsRepository struct {
+	db *sql.DB
+}
+
+func NewStatisticsRepository(db *sql.DB) *StatisticsRepository {
+	return &StatisticsRepository{db: db}
+}
+
+func (r *StatisticsRepository) GetStats(ctx context.Context, filter models.Filter) ([]models.Group, error) {
+	const op = "StatisticsRepository.GetStats"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("group_by", filter.GroupBy)
+
+	query, ok := statsQueries[filter.GroupBy]
+	if !ok {
+		logger.Error("unknown grouping")
+		return nil, fmt.Errorf("%s: unknown grouping %q", op, filter.GroupBy)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, filter.StartDate, filter.EndDate)
+	if err != nil {
+		logger.WithError(err).Error("failed to query stats")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		var (
+			group    models.Group
+			avg, p50 sql.NullFloat64
+			p90, p95 sql.NullFloat64
+		)
+		err := rows.Scan(&group.Key, &group.Receptions, &group.Products, &group.ProductsPerReception,
+			&avg, &p50, &p90, &p95)
+		if err != nil {
+			logger.WithError(err).Error("scan error")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		group.DurationSeconds = models.Duration{
+			Avg: floatPtr(avg),
+			P50: floatPtr(p50),
+			P90: floatPtr(p90),
+			P95: floatPtr(p95),
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return groups, nil
+}
+
+func floatPtr(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
@@ -5,9 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
 	user "github.com/nik-mLb/avito_task/internal/models/user"
 	repository "github.com/nik-mLb/avito_task/internal/repository/auth"
 	"github.com/stretchr/testify/assert"
@@ -85,6 +89,24 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
+func TestCreateUserDuplicateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+
+	mock.ExpectQuery(`INSERT INTO "user"`).
+		WithArgs(sqlmock.AnyArg(), "taken@example.com", []byte("hashed_password"), "worker").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	user, err := repo.CreateUser(context.Background(), "taken@example.com", []byte("hashed_password"), "worker")
+
+	assert.ErrorIs(t, err, errs.ErrUserAlreadyExists)
+	assert.Nil(t, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetUserByEmail(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -93,6 +115,7 @@ func TestGetUserByEmail(t *testing.T) {
 	defer db.Close()
 
 	repo := repository.New(db)
+	pvzID := uuid.New()
 
 	tests := []struct {
 		name        string
@@ -106,17 +129,20 @@ func TestGetUserByEmail(t *testing.T) {
 			email: "test@example.com",
 			mock: func() {
 				expectedID := uuid.New()
-				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-					AddRow(expectedID, "test@example.com", []byte("hashed_password"), "user")
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role", "active", "totp_enabled", "pickup_point_id"}).
+					AddRow(expectedID, "test@example.com", []byte("hashed_password"), "user", true, true, pvzID)
 				
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM "user"`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, active, totp_enabled, pickup_point_id FROM "user"`).
 					WithArgs("test@example.com").
 					WillReturnRows(rows)
 			},
 			expected: &user.User{
-				Email:        "test@example.com",
-				PasswordHash: []byte("hashed_password"),
-				Role:         "user",
+				Email:            "test@example.com",
+				PasswordHash:     []byte("hashed_password"),
+				Role:             "user",
+				Active:           true,
+				TwoFactorEnabled: true,
+				PickupPointID:    &pvzID,
 			},
 			expectedErr: false,
 		},
@@ -124,7 +150,7 @@ func TestGetUserByEmail(t *testing.T) {
 			name:  "Not Found",
 			email: "notfound@example.com",
 			mock: func() {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM "user"`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, active, totp_enabled, pickup_point_id FROM "user"`).
 					WithArgs("notfound@example.com").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -135,7 +161,7 @@ func TestGetUserByEmail(t *testing.T) {
 			name:  "Database Error",
 			email: "test@example.com",
 			mock: func() {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM "user"`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, active, totp_enabled, pickup_point_id FROM "user"`).
 					WithArgs("test@example.com").
 					WillReturnError(errors.New("database error"))
 			},
@@ -159,6 +185,9 @@ func TestGetUserByEmail(t *testing.T) {
 					assert.Equal(t, tt.expected.Email, user.Email)
 					assert.Equal(t, tt.expected.Role, user.Role)
 					assert.Equal(t, tt.expected.PasswordHash, user.PasswordHash)
+					assert.Equal(t, tt.expected.Active, user.Active)
+					assert.Equal(t, tt.expected.TwoFactorEnabled, user.TwoFactorEnabled)
+					assert.Equal(t, tt.expected.PickupPointID, user.PickupPointID)
 					assert.NotEqual(t, uuid.Nil, user.ID)
 				}
 			}
@@ -166,4 +195,450 @@ func TestGetUserByEmail(t *testing.T) {
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
-}
\ No newline at end of file
+}
+func TestGetLoginThrottle(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+
+	t.Run("locked", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetLoginThrottleQuery).
+			WithArgs("test@example.com", "192.0.2.1", float64(900)).
+			WillReturnRows(sqlmock.NewRows([]string{"failures", "locked_for"}).AddRow(5, 90.5))
+
+		throttle, err := repo.GetLoginThrottle(context.Background(), "test@example.com", "192.0.2.1", 15*time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.LoginThrottle{Failures: 5, LockedFor: 90500 * time.Millisecond}, throttle)
+	})
+
+	t.Run("expired lock is ignored", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetLoginThrottleQuery).
+			WithArgs("test@example.com", "192.0.2.1", float64(900)).
+			WillReturnRows(sqlmock.NewRows([]string{"failures", "locked_for"}).AddRow(0, -30.0))
+
+		throttle, err := repo.GetLoginThrottle(context.Background(), "test@example.com", "192.0.2.1", 15*time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.LoginThrottle{}, throttle)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetLoginThrottleQuery).
+			WithArgs("test@example.com", "192.0.2.1", float64(900)).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.GetLoginThrottle(context.Background(), "test@example.com", "192.0.2.1", 15*time.Minute)
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAndResetLoginFailures(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+
+	mock.ExpectExec(repository.RecordLoginFailureQuery).
+		WithArgs("ip", "192.0.2.1", 20, float64(900), float64(600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(repository.ResetLoginFailuresQuery).
+		WithArgs("email", "test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.RecordLoginFailure(context.Background(), user.LoginScopeIP, "192.0.2.1", 20, 15*time.Minute, 10*time.Minute)
+	assert.NoError(t, err)
+
+	err = repo.ResetLoginFailures(context.Background(), user.LoginScopeEmail, "test@example.com")
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenState(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	validAfter := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("password changed", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetTokenStateQuery).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens_valid_after", "active"}).AddRow(validAfter, true))
+
+		state, err := repo.GetTokenState(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &user.TokenState{ValidAfter: validAfter, Active: true}, state)
+	})
+
+	t.Run("password never changed", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetTokenStateQuery).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens_valid_after", "active"}).AddRow(nil, false))
+
+		state, err := repo.GetTokenState(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &user.TokenState{}, state)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetTokenStateQuery).
+			WithArgs(userID).
+			WillReturnError(sql.ErrNoRows)
+
+		state, err := repo.GetTokenState(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, state)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	tokenHash := []byte("token-hash")
+
+	mock.ExpectExec(repository.CreatePasswordResetQuery).
+		WithArgs(tokenHash, userID, float64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CreatePasswordReset(context.Background(), userID, tokenHash, time.Hour)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPassword(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	tokenHash := []byte("token-hash")
+	passwordHash := []byte("password-hash")
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumePasswordResetQuery).
+			WithArgs(tokenHash).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+		mock.ExpectExec(repository.UpdatePasswordQuery).
+			WithArgs(userID, passwordHash).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(repository.RevokePasswordResetsQuery).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(repository.RevokeUserSessionsQuery).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		id, err := repo.ResetPassword(context.Background(), tokenHash, passwordHash)
+
+		assert.NoError(t, err)
+		assert.Equal(t, userID, id)
+	})
+
+	t.Run("invalid or expired token", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumePasswordResetQuery).
+			WithArgs(tokenHash).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.ResetPassword(context.Background(), tokenHash, passwordHash)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidResetToken)
+	})
+
+	t.Run("update error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumePasswordResetQuery).
+			WithArgs(tokenHash).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+		mock.ExpectExec(repository.UpdatePasswordQuery).
+			WithArgs(userID, passwordHash).
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ResetPassword(context.Background(), tokenHash, passwordHash)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("revoke sessions error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumePasswordResetQuery).
+			WithArgs(tokenHash).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+		mock.ExpectExec(repository.UpdatePasswordQuery).
+			WithArgs(userID, passwordHash).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(repository.RevokePasswordResetsQuery).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(repository.RevokeUserSessionsQuery).
+			WithArgs(userID).
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ResetPassword(context.Background(), tokenHash, passwordHash)
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserFromInvitation(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	pvzID := uuid.New()
+	tokenHash := []byte("token-hash")
+	passwordHash := []byte("password-hash")
+	invitationColumns := []string{"email", "role", "pickup_point_id"}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumeInvitationQuery).
+			WithArgs(tokenHash).
+			WillReturnRows(sqlmock.NewRows(invitationColumns).AddRow("invited@example.com", "worker", pvzID))
+		mock.ExpectExec(repository.CreateInvitedUserQuery).
+			WithArgs(sqlmock.AnyArg(), "invited@example.com", passwordHash, "worker", uuid.NullUUID{UUID: pvzID, Valid: true}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		u, err := repo.CreateUserFromInvitation(context.Background(), tokenHash, passwordHash)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "invited@example.com", u.Email)
+		assert.Equal(t, "worker", u.Role)
+		assert.Equal(t, &pvzID, u.PickupPointID)
+		assert.True(t, u.Active)
+	})
+
+	t.Run("invalid or expired invitation", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumeInvitationQuery).
+			WithArgs(tokenHash).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.CreateUserFromInvitation(context.Background(), tokenHash, passwordHash)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidInvitation)
+	})
+
+	t.Run("email already registered", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(repository.ConsumeInvitationQuery).
+			WithArgs(tokenHash).
+			WillReturnRows(sqlmock.NewRows(invitationColumns).AddRow("taken@example.com", "admin", nil))
+		mock.ExpectExec(repository.CreateInvitedUserQuery).
+			WithArgs(sqlmock.AnyArg(), "taken@example.com", passwordHash, "admin", uuid.NullUUID{}).
+			WillReturnError(&pq.Error{Code: "23505"})
+		mock.ExpectRollback()
+
+		_, err := repo.CreateUserFromInvitation(context.Background(), tokenHash, passwordHash)
+
+		assert.ErrorIs(t, err, errs.ErrUserAlreadyExists)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseLoginChallenge(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	tokenHash := []byte("challenge-hash")
+	pvzID := uuid.New()
+	columns := []string{"id", "email", "role", "active", "totp_secret", "totp_enabled", "totp_last_step", "pickup_point_id"}
+
+	t.Run("valid challenge", func(t *testing.T) {
+		mock.ExpectQuery(repository.UseLoginChallengeQuery).
+			WithArgs(tokenHash, 5).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "admin@example.com", "admin", true, "SECRET", true, int64(42), pvzID))
+
+		tf, err := repo.UseLoginChallenge(context.Background(), tokenHash, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &user.TwoFactor{
+			UserID:        userID,
+			Email:         "admin@example.com",
+			Role:          "admin",
+			Active:        true,
+			Secret:        "SECRET",
+			Enabled:       true,
+			LastStep:      42,
+			PickupPointID: &pvzID,
+		}, tf)
+	})
+
+	t.Run("expired or exhausted", func(t *testing.T) {
+		mock.ExpectQuery(repository.UseLoginChallengeQuery).
+			WithArgs(tokenHash, 5).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UseLoginChallenge(context.Background(), tokenHash, 5)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidLoginChallenge)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	hashes := [][]byte{[]byte("code-1"), []byte("code-2")}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(repository.EnableTwoFactorQuery).
+			WithArgs(userID, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(repository.DeleteRecoveryCodesQuery).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, hash := range hashes {
+			mock.ExpectExec(repository.InsertRecoveryCodeQuery).
+				WithArgs(userID, hash).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.EnableTwoFactor(context.Background(), userID, 100, hashes))
+	})
+
+	t.Run("secret not set", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(repository.EnableTwoFactorQuery).
+			WithArgs(userID, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.EnableTwoFactor(context.Background(), userID, 100, hashes)
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorNotSetUp)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseTOTPStepAndRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+
+	t.Run("new step", func(t *testing.T) {
+		mock.ExpectExec(repository.UseTOTPStepQuery).
+			WithArgs(userID, int64(101)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		used, err := repo.UseTOTPStep(context.Background(), userID, 101)
+
+		assert.NoError(t, err)
+		assert.True(t, used)
+	})
+
+	t.Run("step already used", func(t *testing.T) {
+		mock.ExpectExec(repository.UseTOTPStepQuery).
+			WithArgs(userID, int64(101)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		used, err := repo.UseTOTPStep(context.Background(), userID, 101)
+
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("recovery code used once", func(t *testing.T) {
+		mock.ExpectExec(repository.UseRecoveryCodeQuery).
+			WithArgs(userID, []byte("code-hash")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		used, err := repo.UseRecoveryCode(context.Background(), userID, []byte("code-hash"))
+
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAndTouchSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.New(db)
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	t.Run("create", func(t *testing.T) {
+		mock.ExpectExec(repository.CreateSessionQuery).
+			WithArgs(sqlmock.AnyArg(), userID, "terminal-1", "192.0.2.1", "scanner/1.0").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		id, err := repo.CreateSession(context.Background(), userID, session.Client{Device: "terminal-1", IP: "192.0.2.1", UserAgent: "scanner/1.0"})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, id)
+	})
+
+	t.Run("active session", func(t *testing.T) {
+		mock.ExpectQuery(repository.TouchSessionQuery).
+			WithArgs(sessionID, userID, float64(60)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		active, err := repo.TouchSession(context.Background(), sessionID, userID, time.Minute)
+
+		assert.NoError(t, err)
+		assert.True(t, active)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		mock.ExpectQuery(repository.TouchSessionQuery).
+			WithArgs(sessionID, userID, float64(60)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		active, err := repo.TouchSession(context.Background(), sessionID, userID, time.Minute)
+
+		assert.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
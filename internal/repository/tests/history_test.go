@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/history"
+	repository "github.com/nik-mLb/avito_task/internal/repository/history"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+)
+
+// expectHistoryWrite ожидает запись события в историю приемки внутри транзакции репозитория
+func expectHistoryWrite(mock sqlmock.Sqlmock, receptionID driver.Value, eventType models.EventType) {
+	mock.ExpectExec(repository.CreateEventQuery).
+		WithArgs(receptionID, string(eventType), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestWriteHistory(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	receptionID := uuid.New()
+	productID := uuid.New()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		event       models.Event
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Product Event",
+			event: models.Event{
+				ReceptionID: receptionID,
+				Type:        models.ProductAdded,
+				ProductID:   &productID,
+				ProductType: "обувь",
+				ActorID:     &actorID,
+			},
+			mock: func() {
+				mock.ExpectExec(repository.CreateEventQuery).
+					WithArgs(receptionID, "product_added",
+						uuid.NullUUID{UUID: productID, Valid: true},
+						sql.NullString{String: "обувь", Valid: true},
+						uuid.NullUUID{UUID: actorID, Valid: true},
+						sql.NullString{}).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "System Event",
+			event: models.Event{
+				ReceptionID: receptionID,
+				Type:        models.ReceptionAutoClosed,
+				Details:     "auto: idle for more than 12h0m0s",
+			},
+			mock: func() {
+				mock.ExpectExec(repository.CreateEventQuery).
+					WithArgs(receptionID, "reception_auto_closed",
+						uuid.NullUUID{},
+						sql.NullString{},
+						uuid.NullUUID{},
+						sql.NullString{String: "auto: idle for more than 12h0m0s", Valid: true}).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "Actor From Context",
+			ctx:  authctx.WithUser(context.Background(), userID.String(), "employee"),
+			event: models.Event{
+				ReceptionID: receptionID,
+				Type:        models.ReceptionClosed,
+			},
+			mock: func() {
+				mock.ExpectExec(repository.CreateEventQuery).
+					WithArgs(receptionID, "reception_closed",
+						uuid.NullUUID{},
+						sql.NullString{},
+						uuid.NullUUID{UUID: userID, Valid: true},
+						sql.NullString{}).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "Database Error",
+			event: models.Event{
+				ReceptionID: receptionID,
+				Type:        models.ReceptionOpened,
+			},
+			mock: func() {
+				mock.ExpectExec(repository.CreateEventQuery).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			mock.ExpectBegin()
+			tt.mock()
+			mock.ExpectRollback()
+
+			tx, err := db.BeginTx(ctx, nil)
+			assert.NoError(t, err)
+
+			err = repository.Write(ctx, tx, tt.event)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, tx.Rollback())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetReceptionHistory(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewHistoryRepository(db)
+
+	receptionID := uuid.MustParse("9a080ac9-7577-4e9c-97ab-2a0de0e55fad")
+	productID := uuid.MustParse("3f2c1d4e-5b6a-4c7d-8e9f-0a1b2c3d4e5f")
+	actorID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	openedAt := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+	addedAt := openedAt.Add(time.Minute)
+
+	columns := []string{"id", "reception_id", "event_type", "product_id", "product_type", "actor_id", "details", "created_at"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []models.Event
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckReceptionExistsQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(repository.GetReceptionHistoryQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, receptionID, "reception_opened", nil, nil, actorID, nil, openedAt).
+						AddRow(2, receptionID, "product_added", productID, "обувь", actorID, nil, addedAt))
+			},
+			expected: []models.Event{
+				{
+					ID:          1,
+					ReceptionID: receptionID,
+					Type:        models.ReceptionOpened,
+					ActorID:     &actorID,
+					CreatedAt:   openedAt,
+				},
+				{
+					ID:          2,
+					ReceptionID: receptionID,
+					Type:        models.ProductAdded,
+					ProductID:   &productID,
+					ProductType: "обувь",
+					ActorID:     &actorID,
+					CreatedAt:   addedAt,
+				},
+			},
+		},
+		{
+			name: "Empty History",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckReceptionExistsQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(repository.GetReceptionHistoryQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: []models.Event{},
+		},
+		{
+			name: "Reception Not Found",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckReceptionExistsQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "Database Error",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckReceptionExistsQuery).
+					WithArgs(receptionID).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.GetReceptionHistory(context.Background(), receptionID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetReceptionPickupPoint(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewHistoryRepository(db)
+	receptionID := uuid.New()
+	pvzID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetReceptionPickupPointQuery).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pickup_point_id"}).AddRow(pvzID))
+
+		got, err := repo.GetReceptionPickupPoint(context.Background(), receptionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, pvzID, got)
+	})
+
+	t.Run("reception not found", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetReceptionPickupPointQuery).
+			WithArgs(receptionID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetReceptionPickupPoint(context.Background(), receptionID)
+
+		assert.ErrorIs(t, err, errs.ErrReceptionNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	repository "github.com/nik-mLb/avito_task/internal/repository/invitation"
+	"github.com/stretchr/testify/assert"
+)
+
+var invitationColumns = []string{"id", "email", "role", "pickup_point_id", "created_by", "created_at", "expires_at"}
+
+func TestEmailRegistered(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewInvitationRepository(db)
+
+	mock.ExpectQuery(repository.EmailRegisteredQuery).
+		WithArgs("taken@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	registered, err := repo.EmailRegistered(context.Background(), "taken@example.com")
+
+	assert.NoError(t, err)
+	assert.True(t, registered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateInvitation(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewInvitationRepository(db)
+	pvzID := uuid.New()
+	inv := models.Invitation{
+		ID:            uuid.New(),
+		Email:         "new@example.com",
+		Role:          "worker",
+		PickupPointID: &pvzID,
+		CreatedBy:     uuid.New(),
+	}
+	tokenHash := []byte("token-hash")
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(repository.CreateInvitationQuery).
+			WithArgs(inv.ID, tokenHash, inv.Email, inv.Role, uuid.NullUUID{UUID: pvzID, Valid: true}, inv.CreatedBy, float64(259200)).
+			WillReturnRows(sqlmock.NewRows(invitationColumns).
+				AddRow(inv.ID, inv.Email, inv.Role, pvzID, inv.CreatedBy, now, now.Add(72*time.Hour)))
+
+		created, err := repo.CreateInvitation(context.Background(), inv, tokenHash, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &pvzID, created.PickupPointID)
+		assert.Equal(t, now.Add(72*time.Hour), created.ExpiresAt)
+	})
+
+	t.Run("pickup point not found", func(t *testing.T) {
+		mock.ExpectQuery(repository.CreateInvitationQuery).
+			WithArgs(inv.ID, tokenHash, inv.Email, inv.Role, uuid.NullUUID{UUID: pvzID, Valid: true}, inv.CreatedBy, float64(259200)).
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		_, err := repo.CreateInvitation(context.Background(), inv, tokenHash, 72*time.Hour)
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListInvitations(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewInvitationRepository(db)
+
+	t.Run("pending invitations", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListInvitationsQuery).
+			WillReturnRows(sqlmock.NewRows(invitationColumns).
+				AddRow(uuid.New(), "a@example.com", "admin", nil, uuid.New(), time.Now(), time.Now().Add(time.Hour)))
+
+		invitations, err := repo.ListInvitations(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, invitations, 1)
+		assert.Nil(t, invitations[0].PickupPointID)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListInvitationsQuery).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.ListInvitations(context.Background())
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewInvitationRepository(db)
+	id := uuid.New()
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectExec(repository.RevokeInvitationQuery).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.RevokeInvitation(context.Background(), id))
+	})
+
+	t.Run("not found or already used", func(t *testing.T) {
+		mock.ExpectExec(repository.RevokeInvitationQuery).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.RevokeInvitation(context.Background(), id), errs.ErrInvitationNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	repository "github.com/nik-mLb/avito_task/internal/repository/outbox"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+)
+
+var outboxColumns = []string{"id", "aggregate_id", "payload", "created_at"}
+
+// expectOutboxWrite ожидает запись события в outbox внутри транзакции репозитория
+func expectOutboxWrite(mock sqlmock.Sqlmock, pvzID driver.Value, eventType history.EventType) {
+	mock.ExpectExec(repository.LockOutboxAggregateQuery).
+		WithArgs(sqlmock.AnyArg(), pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(repository.InsertOutboxQuery).
+		WithArgs(pvzID, string(eventType), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestWriteOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	userID := uuid.New()
+	ctx := authctx.WithUser(context.Background(), userID.String(), "employee")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(repository.LockOutboxAggregateQuery).
+		WithArgs(sqlmock.AnyArg(), pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(repository.InsertOutboxQuery).
+		WithArgs(pvzID, "reception_closed", []byte(`{"id":0,"type":"reception_closed","pvzId":"`+pvzID.String()+
+			`","receptionId":"`+receptionID.String()+`","actorId":"`+userID.String()+`","at":"0001-01-01T00:00:00Z"}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+
+	err = repository.Write(ctx, tx, feed.Event{
+		Type:          history.ReceptionClosed,
+		PickupPointID: pvzID,
+		ReceptionID:   receptionID,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatchOutbox(t *testing.T) {
+	first := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	second := uuid.MustParse("66666666-7777-8888-9999-000000000000")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	payload := func(pvzID uuid.UUID, eventType history.EventType) []byte {
+		body, _ := json.Marshal(feed.Event{Type: eventType, PickupPointID: pvzID})
+		return body
+	}
+
+	tests := []struct {
+		name          string
+		mock          func(mock sqlmock.Sqlmock)
+		failOn        map[uint64]bool
+		expectedIDs   []uint64
+		expectedCount int
+	}{
+		{
+			name: "events are handled in id order",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.ClaimOutboxQuery).
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(outboxColumns).
+						AddRow(1, first, payload(first, history.ReceptionOpened), now).
+						AddRow(2, second, payload(second, history.ReceptionOpened), now).
+						AddRow(3, first, payload(first, history.ProductAdded), now))
+				mock.ExpectQuery(repository.GetOutboxHeadsQuery).
+					WithArgs(pq.StringArray{first.String(), second.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"aggregate_id", "min"}).
+						AddRow(first, 1).
+						AddRow(second, 2))
+				mock.ExpectExec(repository.MarkOutboxDispatchedQuery).
+					WithArgs(pq.Int64Array{1, 2, 3}).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			expectedIDs:   []uint64{1, 2, 3},
+			expectedCount: 3,
+		},
+		{
+			name: "aggregate with an earlier event held elsewhere is skipped",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.ClaimOutboxQuery).
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(outboxColumns).
+						AddRow(5, first, payload(first, history.ProductAdded), now).
+						AddRow(6, second, payload(second, history.ProductAdded), now))
+				mock.ExpectQuery(repository.GetOutboxHeadsQuery).
+					WithArgs(pq.StringArray{first.String(), second.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"aggregate_id", "min"}).
+						AddRow(first, 4).
+						AddRow(second, 6))
+				mock.ExpectExec(repository.MarkOutboxDispatchedQuery).
+					WithArgs(pq.Int64Array{6}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedIDs:   []uint64{6},
+			expectedCount: 1,
+		},
+		{
+			name: "failed event blocks the rest of its aggregate",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.ClaimOutboxQuery).
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(outboxColumns).
+						AddRow(1, first, payload(first, history.ReceptionOpened), now).
+						AddRow(2, second, payload(second, history.ReceptionOpened), now).
+						AddRow(3, first, payload(first, history.ProductAdded), now))
+				mock.ExpectQuery(repository.GetOutboxHeadsQuery).
+					WithArgs(pq.StringArray{first.String(), second.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"aggregate_id", "min"}).
+						AddRow(first, 1).
+						AddRow(second, 2))
+				mock.ExpectExec(repository.RecordOutboxFailureQuery).
+					WithArgs(int64(1), "sink unavailable").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(repository.MarkOutboxDispatchedQuery).
+					WithArgs(pq.Int64Array{2}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			failOn:        map[uint64]bool{1: true},
+			expectedIDs:   []uint64{1, 2},
+			expectedCount: 1,
+		},
+		{
+			name: "nothing to dispatch",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.ClaimOutboxQuery).
+					WithArgs(10).
+					WillReturnRows(sqlmock.NewRows(outboxColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := repository.NewOutboxRepository(db)
+			tt.mock(mock)
+
+			var handled []uint64
+			count, err := repo.Dispatch(context.Background(), 10, func(_ context.Context, event feed.Event) error {
+				handled = append(handled, event.ID)
+				assert.Equal(t, now, event.At)
+				if tt.failOn[event.ID] {
+					return errors.New("sink unavailable")
+				}
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+			assert.Equal(t, tt.expectedIDs, handled)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxLastID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(repository.GetOutboxLastIDQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+
+	lastID, err := repository.NewOutboxRepository(db).LastID(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), lastID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListOutboxAfter(t *testing.T) {
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	payload, _ := json.Marshal(feed.Event{Type: history.ProductAdded, PickupPointID: pvzID})
+
+	t.Run("events keep outbox ids", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(repository.ListOutboxAfterQuery).
+			WithArgs(int64(10), pq.Int64Array{8}, 50).
+			WillReturnRows(sqlmock.NewRows(outboxColumns).
+				AddRow(8, pvzID, payload, now).
+				AddRow(11, pvzID, payload, now))
+
+		events, err := repository.NewOutboxRepository(db).ListAfter(context.Background(), 10, []uint64{8}, 50)
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, uint64(8), events[0].ID)
+		assert.Equal(t, uint64(11), events[1].ID)
+		assert.Equal(t, history.ProductAdded, events[1].Type)
+		assert.Equal(t, now, events[1].At)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectedErr := errors.New("db error")
+		mock.ExpectQuery(repository.ListOutboxAfterQuery).
+			WithArgs(int64(0), pq.Int64Array{}, 50).
+			WillReturnError(expectedErr)
+
+		_, err = repository.NewOutboxRepository(db).ListAfter(context.Background(), 0, nil, 50)
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -3,11 +3,13 @@ package tests
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	pickup_point "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	product "github.com/nik-mLb/avito_task/internal/models/product"
 	reception "github.com/nik-mLb/avito_task/internal/models/reception"
@@ -128,7 +130,7 @@ func TestGetPickupPointsWithReceptions(t *testing.T) {
 				)
 
 				mock.ExpectQuery(`SELECT`).
-					WithArgs(startDate, endDate, 10, 0).
+					WithArgs(startDate, endDate, 10, 0, nil).
 					WillReturnRows(rows)
 			},
 			expected: []dto.PickupPointListResponse{
@@ -166,7 +168,7 @@ func TestGetPickupPointsWithReceptions(t *testing.T) {
 				})
 
 				mock.ExpectQuery(`SELECT`).
-					WithArgs(startDate, endDate, 10, 0).
+					WithArgs(startDate, endDate, 10, 0, nil).
 					WillReturnRows(rows)
 			},
 			expected:    []dto.PickupPointListResponse{},
@@ -180,7 +182,7 @@ func TestGetPickupPointsWithReceptions(t *testing.T) {
 			limit:     10,
 			mock: func() {
 				mock.ExpectQuery(`SELECT`).
-					WithArgs(startDate, endDate, 10, 0).
+					WithArgs(startDate, endDate, 10, 0, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expected:    nil,
@@ -192,7 +194,7 @@ func TestGetPickupPointsWithReceptions(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetPickupPointsWithReceptions(context.Background(), tt.startDate, tt.endDate, tt.page, tt.limit)
+			got, err := repo.GetPickupPointsWithReceptions(context.Background(), tt.startDate, tt.endDate, nil, tt.page, tt.limit)
 			if tt.expectedErr {
 				assert.Error(t, err)
 			} else {
@@ -218,3 +220,206 @@ func TestGetPickupPointsWithReceptions(t *testing.T) {
 	}
 }
 
+
+func TestStreamPickupPointsWithReceptions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPickupPointRepository(db)
+
+	now := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+	ppID1, ppID2 := uuid.New(), uuid.New()
+	recID1, recID2, recID3 := uuid.New(), uuid.New(), uuid.New()
+	columns := []string{
+		"id", "city", "registration_date",
+		"id", "reception_date", "status",
+		"id", "product_type", "reception_date",
+	}
+
+	t.Run("Yields Pickup Points One By One", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetPickupPointsWithReceptionsQuery).
+			WithArgs(nil, nil, 10, 10, nil).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(ppID1, "Москва", now, recID1, now, "close", uuid.New(), "обувь", now).
+				AddRow(ppID1, "Москва", now, recID1, now, "close", uuid.New(), "одежда", now).
+				AddRow(ppID1, "Москва", now, recID2, now, "in_progress", uuid.New(), "обувь", now).
+				AddRow(ppID2, "Казань", now, recID3, now, "close", uuid.New(), "электроника", now))
+
+		var got []dto.PickupPointListResponse
+		err := repo.StreamPickupPointsWithReceptions(context.Background(), nil, nil, nil, 2, 10, func(item dto.PickupPointListResponse) error {
+			got = append(got, item)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, ppID1, got[0].PickupPoint.ID)
+		assert.Len(t, got[0].Receptions, 2)
+		assert.Len(t, got[0].Receptions[0].Products, 2)
+		assert.Len(t, got[0].Receptions[1].Products, 1)
+		assert.Equal(t, ppID2, got[1].PickupPoint.ID)
+		assert.Len(t, got[1].Receptions, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Callback Error Stops Stream", func(t *testing.T) {
+		stopErr := errors.New("client gone")
+
+		mock.ExpectQuery(repository.GetPickupPointsWithReceptionsQuery).
+			WithArgs(nil, nil, 10, 0, nil).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(ppID1, "Москва", now, recID1, now, "close", uuid.New(), "обувь", now).
+				AddRow(ppID2, "Казань", now, recID3, now, "close", uuid.New(), "электроника", now))
+
+		calls := 0
+		err := repo.StreamPickupPointsWithReceptions(context.Background(), nil, nil, nil, 1, 10, func(dto.PickupPointListResponse) error {
+			calls++
+			return stopErr
+		})
+
+		assert.ErrorIs(t, err, stopErr)
+		assert.Equal(t, 1, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOccupancy(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPickupPointRepository(db)
+
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+
+	t.Run("Success With Capacity", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetPickupPointCapacityQuery).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(10, "hard"))
+		mock.ExpectQuery(repository.GetOccupancyByTypeQuery).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"product_type", "count"}).
+				AddRow("обувь", 3).
+				AddRow("электроника", 4))
+
+		got, err := repo.GetOccupancy(context.Background(), pvzID)
+
+		capacity, available := 10, 3
+		assert.NoError(t, err)
+		assert.Equal(t, &pickup_point.Occupancy{
+			PickupPointID: pvzID,
+			Capacity:      &capacity,
+			Mode:          pickup_point.CapacityHard,
+			Occupied:      7,
+			Available:     &available,
+			ByType: []pickup_point.TypeOccupancy{
+				{ProductType: "обувь", Count: 3},
+				{ProductType: "электроника", Count: 4},
+			},
+		}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetPickupPointCapacityQuery).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(nil, "hard"))
+		mock.ExpectQuery(repository.GetOccupancyByTypeQuery).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"product_type", "count"}))
+
+		got, err := repo.GetOccupancy(context.Background(), pvzID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, got.Capacity)
+		assert.Nil(t, got.Available)
+		assert.Equal(t, 0, got.Occupied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetPickupPointCapacityQuery).
+			WithArgs(pvzID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetOccupancy(context.Background(), pvzID)
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestExportPickupPoints(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPickupPointRepository(db)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	emptyReceptionID := uuid.New()
+	productID := uuid.New()
+	now := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+	columns := []string{"pp.id", "pp.city", "pp.registration_date", "r.id", "r.reception_date", "r.status", "p.id", "p.product_type", "p.reception_date"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(repository.ExportPickupPointsQuery).
+			WithArgs(nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(pvzID, "Казань", "2025-04-01T00:00:00Z", receptionID, now, "close", productID, "одежда", now).
+				AddRow(pvzID, "Казань", "2025-04-01T00:00:00Z", emptyReceptionID, now, "in_progress", nil, nil, nil))
+
+		var got []pickup_point.ExportRow
+		err := repo.ExportPickupPoints(context.Background(), nil, nil, nil, func(row pickup_point.ExportRow) error {
+			got = append(got, row)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "Казань", got[0].PickupPoint.City)
+		assert.Equal(t, pvzID, got[0].Reception.PickupPointID)
+		assert.Equal(t, &product.Product{
+			ID:            productID,
+			ReceptionDate: now,
+			ReceptionID:   receptionID,
+			ProductType:   product.Clothing,
+		}, got[0].Product)
+		assert.Equal(t, emptyReceptionID, got[1].Reception.ID)
+		assert.Nil(t, got[1].Product)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Callback Error Stops Export", func(t *testing.T) {
+		stopErr := errors.New("client gone")
+
+		mock.ExpectQuery(repository.ExportPickupPointsQuery).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(pvzID, "Казань", "2025-04-01T00:00:00Z", receptionID, now, "close", productID, "одежда", now).
+				AddRow(pvzID, "Казань", "2025-04-01T00:00:00Z", receptionID, now, "close", uuid.New(), "обувь", now))
+
+		calls := 0
+		err := repo.ExportPickupPoints(context.Background(), nil, nil, nil, func(pickup_point.ExportRow) error {
+			calls++
+			return stopErr
+		})
+
+		assert.ErrorIs(t, err, stopErr)
+		assert.Equal(t, 1, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mock.ExpectQuery(repository.ExportPickupPointsQuery).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.ExportPickupPoints(context.Background(), nil, nil, nil, func(pickup_point.ExportRow) error {
+			return nil
+		})
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -12,6 +12,7 @@ import (
 	repository "github.com/nik-mLb/avito_task/internal/repository/product"
 	"github.com/stretchr/testify/assert"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 )
 
 func TestAddProduct(t *testing.T) {
@@ -40,18 +41,36 @@ func TestAddProduct(t *testing.T) {
 				productID := uuid.New()
 				now := time.Now()
 
+				mock.ExpectBegin()
+
 				// Mock GetActiveReceptionQuery
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(receptionID)
 				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnRows(rows)
 
+				// Mock LockPickupPointCapacityQuery - без ограничения вместимости
+				mock.ExpectQuery(`SELECT capacity, capacity_mode FROM pickup_point`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(nil, "hard"))
+
 				// Mock CreateProductQuery
 				rows = sqlmock.NewRows([]string{"id", "reception_id", "product_type", "reception_date"}).
 					AddRow(productID, receptionID, "электроника", now)
 				mock.ExpectQuery(`INSERT INTO product`).
 					WithArgs(sqlmock.AnyArg(), receptionID, "электроника").
 					WillReturnRows(rows)
+
+				mock.ExpectExec(`INSERT INTO reception_event`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`SELECT pg_advisory_xact_lock`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO outbox`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
 			},
 			expected: &models.Product{
 				ProductType: models.ProductType("электроника"),
@@ -67,18 +86,36 @@ func TestAddProduct(t *testing.T) {
 				productID := uuid.New()
 				now := time.Now()
 
+				mock.ExpectBegin()
+
 				// Mock GetActiveReceptionQuery
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(receptionID)
 				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnRows(rows)
 
+				// Mock LockPickupPointCapacityQuery - без ограничения вместимости
+				mock.ExpectQuery(`SELECT capacity, capacity_mode FROM pickup_point`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(nil, "hard"))
+
 				// Mock CreateProductQuery
 				rows = sqlmock.NewRows([]string{"id", "reception_id", "product_type", "reception_date"}).
 					AddRow(productID, receptionID, "одежда", now)
 				mock.ExpectQuery(`INSERT INTO product`).
 					WithArgs(sqlmock.AnyArg(), receptionID, "одежда").
 					WillReturnRows(rows)
+
+				mock.ExpectExec(`INSERT INTO reception_event`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`SELECT pg_advisory_xact_lock`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO outbox`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
 			},
 			expected: &models.Product{
 				ProductType: models.ProductType("одежда"),
@@ -90,10 +127,14 @@ func TestAddProduct(t *testing.T) {
 			pvzID:       uuid.New(),
 			productType: "электроника",
 			mock: func() {
+				mock.ExpectBegin()
+
 				// Mock GetActiveReceptionQuery returning no rows
 				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectRollback()
 			},
 			expected:    nil,
 			expectedErr: errs.ErrNoActiveReception,
@@ -105,20 +146,94 @@ func TestAddProduct(t *testing.T) {
 			mock: func() {
 				receptionID := uuid.New()
 
+				mock.ExpectBegin()
+
 				// Mock GetActiveReceptionQuery
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(receptionID)
 				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnRows(rows)
 
+				mock.ExpectQuery(`SELECT capacity, capacity_mode FROM pickup_point`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(nil, "hard"))
+
 				// Mock CreateProductQuery with error
 				mock.ExpectQuery(`INSERT INTO product`).
 					WithArgs(sqlmock.AnyArg(), receptionID, "электроника").
 					WillReturnError(sql.ErrConnDone)
+
+				mock.ExpectRollback()
 			},
 			expected:    nil,
 			expectedErr: sql.ErrConnDone,
 		},
+		{
+			name:        "Pickup Point Full - Hard Limit",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mock: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+				mock.ExpectQuery(`SELECT capacity, capacity_mode FROM pickup_point`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(10, "hard"))
+
+				mock.ExpectQuery(`SELECT count\(\*\) FROM product p`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrPickupPointFull,
+		},
+		{
+			name:        "Pickup Point Full - Soft Limit",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mock: func() {
+				receptionID := uuid.New()
+
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT id FROM reception WHERE pickup_point_id = \$1 AND status = 'in_progress'`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
+
+				mock.ExpectQuery(`SELECT capacity, capacity_mode FROM pickup_point`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(10, "soft"))
+
+				mock.ExpectQuery(`SELECT count\(\*\) FROM product p`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+				mock.ExpectQuery(`INSERT INTO product`).
+					WithArgs(sqlmock.AnyArg(), receptionID, "обувь").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "product_type", "reception_date"}).
+						AddRow(uuid.New(), receptionID, "обувь", time.Now()))
+
+				mock.ExpectExec(`INSERT INTO reception_event`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`SELECT pg_advisory_xact_lock`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO outbox`).
+					WithArgs(sqlmock.AnyArg(), "product_added", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ProductType: models.ProductType("обувь"),
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -182,12 +297,17 @@ func TestDeleteLastProduct(t *testing.T) {
                     WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(productID))
 
                 // Mock DeleteProductQuery
-                mock.ExpectExec(`
+                mock.ExpectQuery(`
                     DELETE FROM product 
                     WHERE id = $1
-                    RETURNING id`).
+                    RETURNING id, reception_id, product_type, reception_date`).
                     WithArgs(productID).
-                    WillReturnResult(sqlmock.NewResult(1, 1))
+                    WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "product_type", "reception_date"}).
+                        AddRow(productID, uuid.New(), "обувь", time.Now()))
+
+                // Событие удаления пишется в outbox в той же транзакции
+                expectHistoryWrite(mock, sqlmock.AnyArg(), history.ProductDeleted)
+                expectOutboxWrite(mock, sqlmock.AnyArg(), history.ProductDeleted)
 
                 // Mock transaction commit
                 mock.ExpectCommit()
@@ -234,7 +354,7 @@ func TestDeleteLastProduct(t *testing.T) {
         t.Run(tt.name, func(t *testing.T) {
             tt.mock()
 
-            err := repo.DeleteLastProduct(context.Background(), tt.pvzID)
+            deleted, err := repo.DeleteLastProduct(context.Background(), tt.pvzID)
             if tt.expectedErr != nil {
                 assert.Error(t, err)
                 assert.ErrorIs(t, err, tt.expectedErr)
@@ -242,6 +362,7 @@ func TestDeleteLastProduct(t *testing.T) {
             }
 
             assert.NoError(t, err)
+            assert.NotNil(t, deleted)
             assert.NoError(t, mock.ExpectationsWereMet())
         })
     }
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 	models "github.com/nik-mLb/avito_task/internal/models/reception"
 	repository "github.com/nik-mLb/avito_task/internal/repository/reception"
 )
@@ -35,6 +36,8 @@ func TestCreateReception(t *testing.T) {
 		{
 			name: "Success",
 			mock: func() {
+				mock.ExpectBegin()
+				expectLockPickupPoint(mock, pvzID)
 				mock.ExpectQuery(repository.CheckActiveReceptionQuery).
 					WithArgs(pvzID).
 					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
@@ -43,6 +46,9 @@ func TestCreateReception(t *testing.T) {
 					WithArgs(receptionID, pvzID).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_date", "pickup_point_id", "status"}).
 						AddRow(receptionID, now, pvzID, "in_progress"))
+				expectHistoryWrite(mock, receptionID, history.ReceptionOpened)
+				expectOutboxWrite(mock, pvzID, history.ReceptionOpened)
+				mock.ExpectCommit()
 			},
 			expected: &models.Reception{
 				ID:             receptionID,
@@ -55,13 +61,28 @@ func TestCreateReception(t *testing.T) {
 		{
 			name: "Active Reception Exists",
 			mock: func() {
+				mock.ExpectBegin()
+				expectLockPickupPoint(mock, pvzID)
 				mock.ExpectQuery(repository.CheckActiveReceptionQuery).
 					WithArgs(pvzID).
 					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
 			},
 			expected:    nil,
 			expectedErr: errs.ErrActiveReceptionExists,
 		},
+		{
+			name: "Pickup Point Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.LockPickupPointQuery).
+					WithArgs(pvzID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrPickupPointNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,10 +122,14 @@ func TestCloseReception(t *testing.T) {
 		{
 			name: "Success",
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery(repository.CloseReceptionQuery).
 					WithArgs(pvzID).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_date", "pickup_point_id", "status"}).
 						AddRow(receptionID, now, pvzID, "close"))
+				expectHistoryWrite(mock, receptionID, history.ReceptionClosed)
+				expectOutboxWrite(mock, pvzID, history.ReceptionClosed)
+				mock.ExpectCommit()
 			},
 			expected: &models.Reception{
 				ID:             receptionID,
@@ -117,9 +142,11 @@ func TestCloseReception(t *testing.T) {
 		{
 			name: "No Active Reception",
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery(repository.CloseReceptionQuery).
 					WithArgs(pvzID).
 					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
 			expected:    nil,
 			expectedErr: errs.ErrNoActiveReceptionToClose,
@@ -142,3 +169,228 @@ func TestCloseReception(t *testing.T) {
 		})
 	}
 }
+
+func TestCloseStaleReceptions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionRepository(db)
+
+	receptionID := uuid.MustParse("2b1f3c8e-6a4d-4e7b-9c1a-0d5e8f7a6b3c")
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	now := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+	idleTimeout := 12 * time.Hour
+	reason := "auto: idle for more than 12h0m0s"
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []models.Reception
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryAutoCloseLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery(repository.CloseStaleReceptionsQuery).
+					WithArgs(idleTimeout.Seconds(), reason).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_date", "pickup_point_id", "status"}).
+						AddRow(receptionID, now, pvzID, "close"))
+				expectHistoryWrite(mock, receptionID, history.ReceptionAutoClosed)
+				expectOutboxWrite(mock, pvzID, history.ReceptionAutoClosed)
+				mock.ExpectCommit()
+			},
+			expected: []models.Reception{
+				{
+					ID:            receptionID,
+					ReceptionDate: now,
+					PickupPointID: pvzID,
+					Status:        "close",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Lock Held By Another Replica",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryAutoCloseLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: nil,
+		},
+		{
+			name: "Database Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryAutoCloseLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery(repository.CloseStaleReceptionsQuery).
+					WithArgs(idleTimeout.Seconds(), reason).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.CloseStaleReceptions(context.Background(), idleTimeout, reason)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReopenReception(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionRepository(db)
+
+	receptionID := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	adminID := uuid.MustParse("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	window := 30 * time.Minute
+	reason := "pallet left on the dock"
+
+	selectColumns := []string{"id", "reception_date", "pickup_point_id", "status", "within_window"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *models.Reception
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				expectReopenLocks(mock, receptionID, pvzID)
+				mock.ExpectQuery(repository.GetReceptionForReopenQuery).
+					WithArgs(receptionID, window.Seconds()).
+					WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(receptionID, now, pvzID, "close", true))
+				mock.ExpectQuery(repository.CheckNewerReceptionQuery).
+					WithArgs(pvzID, receptionID, now).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(repository.ReopenReceptionQuery).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_date", "pickup_point_id", "status"}).
+						AddRow(receptionID, now, pvzID, "in_progress"))
+				mock.ExpectExec(repository.CreateReceptionReopeningQuery).
+					WithArgs(sqlmock.AnyArg(), receptionID, adminID, reason).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectHistoryWrite(mock, receptionID, history.ReceptionReopened)
+				expectOutboxWrite(mock, pvzID, history.ReceptionReopened)
+				mock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:            receptionID,
+				ReceptionDate: now,
+				PickupPointID: pvzID,
+				Status:        "in_progress",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Reception Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetReceptionPickupPointQuery).
+					WithArgs(receptionID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "Reception Not Closed",
+			mock: func() {
+				mock.ExpectBegin()
+				expectReopenLocks(mock, receptionID, pvzID)
+				mock.ExpectQuery(repository.GetReceptionForReopenQuery).
+					WithArgs(receptionID, window.Seconds()).
+					WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(receptionID, now, pvzID, "in_progress", false))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrReceptionNotClosed,
+		},
+		{
+			name: "Window Expired",
+			mock: func() {
+				mock.ExpectBegin()
+				expectReopenLocks(mock, receptionID, pvzID)
+				mock.ExpectQuery(repository.GetReceptionForReopenQuery).
+					WithArgs(receptionID, window.Seconds()).
+					WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(receptionID, now, pvzID, "close", false))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrReopenWindowExpired,
+		},
+		{
+			name: "Newer Reception Exists",
+			mock: func() {
+				mock.ExpectBegin()
+				expectReopenLocks(mock, receptionID, pvzID)
+				mock.ExpectQuery(repository.GetReceptionForReopenQuery).
+					WithArgs(receptionID, window.Seconds()).
+					WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(receptionID, now, pvzID, "close", true))
+				mock.ExpectQuery(repository.CheckNewerReceptionQuery).
+					WithArgs(pvzID, receptionID, now).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrNewerReceptionExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.ReopenReception(context.Background(), receptionID, adminID, reason, window)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func expectLockPickupPoint(mock sqlmock.Sqlmock, pvzID uuid.UUID) {
+	mock.ExpectQuery(repository.LockPickupPointQuery).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(pvzID))
+}
+
+func expectReopenLocks(mock sqlmock.Sqlmock, receptionID, pvzID uuid.UUID) {
+	mock.ExpectQuery(repository.GetReceptionPickupPointQuery).
+		WithArgs(receptionID).
+		WillReturnRows(sqlmock.NewRows([]string{"pickup_point_id"}).AddRow(pvzID))
+	expectLockPickupPoint(mock, pvzID)
+}
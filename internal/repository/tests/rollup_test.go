@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "github.com/nik-mLb/avito_task/internal/models/rollup"
+	repository "github.com/nik-mLb/avito_task/internal/repository/rollup"
+)
+
+func expectRecompute(mock sqlmock.Sqlmock, dirty int) {
+	mock.ExpectQuery(repository.CountDirtyQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(dirty))
+	if dirty == 0 {
+		return
+	}
+	mock.ExpectExec(repository.DeleteDirtyPvzRollupQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(repository.DeleteDirtyProductTypeRollupQuery).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(repository.InsertPvzRollupQuery).WillReturnResult(sqlmock.NewResult(0, int64(dirty)))
+	mock.ExpectExec(repository.InsertProductTypeRollupQuery).WillReturnResult(sqlmock.NewResult(0, int64(dirty)))
+}
+
+func TestRefreshRollups(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRollupRepository(db)
+
+	lag := time.Minute
+	watermark := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+	next := watermark.Add(5 * time.Minute)
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    int
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryRollupLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery(repository.GetRollupWatermarkQuery).
+					WithArgs("daily", lag.Seconds()).
+					WillReturnRows(sqlmock.NewRows([]string{"watermark", "next"}).AddRow(watermark, next))
+				mock.ExpectExec(repository.CreateRollupDirtyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(repository.CollectDirtyFromWatermarkQuery).
+					WithArgs(watermark).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				expectRecompute(mock, 3)
+				mock.ExpectExec(repository.UpdateRollupWatermarkQuery).
+					WithArgs("daily", next).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: 3,
+		},
+		{
+			name: "Nothing Changed",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryRollupLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery(repository.GetRollupWatermarkQuery).
+					WithArgs("daily", lag.Seconds()).
+					WillReturnRows(sqlmock.NewRows([]string{"watermark", "next"}).AddRow(watermark, next))
+				mock.ExpectExec(repository.CreateRollupDirtyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(repository.CollectDirtyFromWatermarkQuery).
+					WithArgs(watermark).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectRecompute(mock, 0)
+				mock.ExpectExec(repository.UpdateRollupWatermarkQuery).
+					WithArgs("daily", next).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: 0,
+		},
+		{
+			name: "Lock Held By Another Replica",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryRollupLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expected: 0,
+		},
+		{
+			name: "Recompute Error Keeps Watermark",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.TryRollupLockQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+				mock.ExpectQuery(repository.GetRollupWatermarkQuery).
+					WithArgs("daily", lag.Seconds()).
+					WillReturnRows(sqlmock.NewRows([]string{"watermark", "next"}).AddRow(watermark, next))
+				mock.ExpectExec(repository.CreateRollupDirtyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(repository.CollectDirtyFromWatermarkQuery).
+					WithArgs(watermark).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(repository.CountDirtyQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec(repository.DeleteDirtyPvzRollupQuery).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.Refresh(context.Background(), lag)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBackfillRollups(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRollupRepository(db)
+
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(repository.RollupLockQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(repository.CreateRollupDirtyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(repository.CollectDirtyForRangeQuery).
+		WithArgs(from, to).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	expectRecompute(mock, 12)
+	mock.ExpectCommit()
+
+	got, err := repo.Backfill(context.Background(), from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckRollups(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRollupRepository(db)
+
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	columns := []string{"day", "pickup_point_id", "product_type", "rollup_receptions", "raw_receptions", "rollup_products", "raw_products", "durations_mismatch"}
+
+	t.Run("Mismatches Found", func(t *testing.T) {
+		mock.ExpectQuery(repository.CheckPvzRollupQuery).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(day, pvzID, "", 2, 2, 5, 4, false))
+		mock.ExpectQuery(repository.CheckProductTypeRollupQuery).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(day, pvzID, "обувь", 1, 1, 3, 2, true))
+
+		got, err := repo.Check(context.Background(), from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Mismatch{
+			{Day: day, PickupPointID: pvzID, RollupReceptions: 2, RawReceptions: 2, RollupProducts: 5, RawProducts: 4},
+			{Day: day, PickupPointID: pvzID, ProductType: "обувь", RollupReceptions: 1, RawReceptions: 1, RollupProducts: 3, RawProducts: 2, DurationsMismatch: true},
+		}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Consistent", func(t *testing.T) {
+		mock.ExpectQuery(repository.CheckPvzRollupQuery).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectQuery(repository.CheckProductTypeRollupQuery).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		got, err := repo.Check(context.Background(), from, to)
+
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mock.ExpectQuery(repository.CheckPvzRollupQuery).
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.Check(context.Background(), from, to)
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+var (
+	receptionKindEnum    = regexp.MustCompile(`CREATE TYPE reception_kind AS ENUM \(([^)]*)\)`)
+	quotedValue          = regexp.MustCompile(`'([^']*)'`)
+	receptionKindLiteral = regexp.MustCompile(`kind\s*=\s*'([^']*)'`)
+)
+
+// receptionKinds читает значения enum reception_kind из миграции. sqlmock не выполняет запросы,
+// поэтому литерал не из enum иначе обнаружится только в Postgres
+func receptionKinds(t *testing.T) map[string]bool {
+	data, err := os.ReadFile("../../../db/migrations/000004__transfers.up.sql")
+	require.NoError(t, err)
+
+	enum := receptionKindEnum.FindSubmatch(data)
+	require.NotNil(t, enum, "reception_kind enum not found")
+
+	kinds := make(map[string]bool)
+	for _, value := range quotedValue.FindAllSubmatch(enum[1], -1) {
+		kinds[string(value[1])] = true
+	}
+	return kinds
+}
+
+// assertReceptionKinds проверяет, что запрос фильтрует приемки по kind и только существующими значениями
+func assertReceptionKinds(t *testing.T, query string) {
+	kinds := receptionKinds(t)
+
+	literals := receptionKindLiteral.FindAllStringSubmatch(query, -1)
+	require.NotEmpty(t, literals, "query does not filter by reception kind")
+	for _, literal := range literals {
+		assert.True(t, kinds[literal[1]], "unknown reception kind %q", literal[1])
+	}
+}
+
+func TestRollupQueriesReceptionKind(t *testing.T) {
+	queries := map[string]string{
+		"insert pvz rollup":          repository.InsertPvzRollupQuery,
+		"insert product type rollup": repository.InsertProductTypeRollupQuery,
+		"dirty from watermark":       repository.CollectDirtyFromWatermarkQuery,
+		"dirty for range":            repository.CollectDirtyForRangeQuery,
+		"check pvz rollup":           repository.CheckPvzRollupQuery,
+		"check product type rollup":  repository.CheckProductTypeRollupQuery,
+	}
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			assertReceptionKinds(t, query)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	repository "github.com/nik-mLb/avito_task/internal/repository/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSessions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSessionRepository(db)
+	userID := uuid.New()
+	sessionID := uuid.New()
+	createdAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	lastSeenAt := createdAt.Add(time.Hour)
+	columns := []string{"id", "user_id", "device", "ip", "user_agent", "created_at", "last_seen_at"}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListSessionsQuery).
+			WithArgs(userID, float64(86400)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(sessionID, userID, "terminal-1", "192.0.2.1", "scanner/1.0", createdAt, lastSeenAt))
+
+		sessions, err := repo.ListSessions(context.Background(), userID, 24*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []session.Session{{
+			ID:         sessionID,
+			UserID:     userID,
+			Device:     "terminal-1",
+			IP:         "192.0.2.1",
+			UserAgent:  "scanner/1.0",
+			CreatedAt:  createdAt,
+			LastSeenAt: lastSeenAt,
+		}}, sessions)
+	})
+
+	t.Run("no sessions", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListSessionsQuery).
+			WithArgs(userID, float64(86400)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		sessions, err := repo.ListSessions(context.Background(), userID, 24*time.Hour)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, sessions)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListSessionsQuery).
+			WithArgs(userID, float64(86400)).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.ListSessions(context.Background(), userID, 24*time.Hour)
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSessionRepository(db)
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectExec(repository.RevokeSessionQuery).
+			WithArgs(sessionID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.RevokeSession(context.Background(), userID, sessionID))
+	})
+
+	t.Run("not found or already revoked", func(t *testing.T) {
+		mock.ExpectExec(repository.RevokeSessionQuery).
+			WithArgs(sessionID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RevokeSession(context.Background(), userID, sessionID)
+
+		assert.ErrorIs(t, err, errs.ErrSessionNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
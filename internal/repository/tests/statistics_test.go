@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+	repository "github.com/nik-mLb/avito_task/internal/repository/statistics"
+)
+
+func TestGetStats(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewStatisticsRepository(db)
+
+	startDate := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+	columns := []string{"key", "count", "sum", "avg_products", "avg", "p50", "p90", "p95"}
+
+	avg, p50, p90, p95 := 3600.0, 3000.0, 7000.0, 7200.0
+
+	tests := []struct {
+		name        string
+		filter      models.Filter
+		mock        func()
+		expected    []models.Group
+		expectedErr error
+	}{
+		{
+			name:   "By City",
+			filter: models.Filter{GroupBy: models.GroupByCity, StartDate: &startDate, EndDate: &endDate},
+			mock: func() {
+				mock.ExpectQuery(repository.StatsByCityQuery).
+					WithArgs(&startDate, &endDate).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("Казань", 2, 10, 5.0, avg, p50, p90, p95).
+						AddRow("Москва", 1, 0, 0.0, nil, nil, nil, nil))
+			},
+			expected: []models.Group{
+				{
+					Key:                  "Казань",
+					Receptions:           2,
+					Products:             10,
+					ProductsPerReception: 5,
+					DurationSeconds:      models.Duration{Avg: &avg, P50: &p50, P90: &p90, P95: &p95},
+				},
+				{
+					Key:        "Москва",
+					Receptions: 1,
+				},
+			},
+		},
+		{
+			name:   "By Product Type Without Dates",
+			filter: models.Filter{GroupBy: models.GroupByProductType},
+			mock: func() {
+				mock.ExpectQuery(repository.StatsByProductTypeQuery).
+					WithArgs(nil, nil).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: []models.Group{},
+		},
+		{
+			name:   "Database Error",
+			filter: models.Filter{GroupBy: models.GroupByWeek},
+			mock: func() {
+				mock.ExpectQuery(repository.StatsByWeekQuery).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.GetStats(context.Background(), tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+	historyrepo "github.com/nik-mLb/avito_task/internal/repository/history"
+	productrepo "github.com/nik-mLb/avito_task/internal/repository/product"
+	repository "github.com/nik-mLb/avito_task/internal/repository/transfer"
+)
+
+var transferColumns = []string{
+	"id", "from_pickup_point_id", "to_pickup_point_id", "status", "created_by", "created_at",
+	"dispatched_at", "accepted_at", "reception_id",
+}
+
+func TestCreateTransfer(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTransferRepository(db)
+
+	transferID := uuid.MustParse("3f2504e0-4f89-11d3-9a0c-0305e82c3301")
+	fromPvz := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	toPvz := uuid.MustParse("66666666-7777-8888-9999-000000000000")
+	adminID := uuid.MustParse("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	productID := uuid.MustParse("0f8fad5b-d9cb-469f-a165-70867728950e")
+	receptionID := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	ids := pq.StringArray{productID.String()}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *models.Transfer
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.LockTransferableProductsQuery).
+					WithArgs(ids, fromPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(productID))
+				mock.ExpectQuery(repository.CheckProductsInTransferQuery).
+					WithArgs(ids).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(repository.CreateTransferQuery).
+					WithArgs(transferID, fromPvz, toPvz, adminID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "created", adminID, now, nil, nil, nil))
+				mock.ExpectExec(repository.CreateTransferProductsQuery).
+					WithArgs(transferID, ids).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery(repository.GetTransferProductsQuery).
+					WithArgs(pq.StringArray{transferID.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "id", "reception_id", "product_type", "reception_date"}).
+						AddRow(transferID, productID, receptionID, "обувь", now))
+			},
+			expected: &models.Transfer{
+				ID:                transferID,
+				FromPickupPointID: fromPvz,
+				ToPickupPointID:   toPvz,
+				Status:            models.StatusCreated,
+				CreatedBy:         adminID,
+				CreatedAt:         now,
+				Products: []product.Product{
+					{ID: productID, ReceptionID: receptionID, ProductType: product.Shoes, ReceptionDate: now},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Product Not At Source",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.LockTransferableProductsQuery).
+					WithArgs(ids, fromPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrTransferProductsUnavailable,
+		},
+		{
+			name: "Product Already In Transfer",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.LockTransferableProductsQuery).
+					WithArgs(ids, fromPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(productID))
+				mock.ExpectQuery(repository.CheckProductsInTransferQuery).
+					WithArgs(ids).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expected:    nil,
+			expectedErr: errs.ErrTransferProductsUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.CreateTransfer(context.Background(), transferID, fromPvz, toPvz, adminID, []uuid.UUID{productID})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAcceptTransfer(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTransferRepository(db)
+
+	transferID := uuid.MustParse("3f2504e0-4f89-11d3-9a0c-0305e82c3301")
+	fromPvz := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	toPvz := uuid.MustParse("66666666-7777-8888-9999-000000000000")
+	adminID := uuid.MustParse("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	receptionID := uuid.MustParse("9a080ac9-7577-4e9c-97ab-2a0de0e55fad")
+	sourceReception1 := uuid.MustParse("b1b1b1b1-0000-0000-0000-000000000001")
+	sourceReception2 := uuid.MustParse("b1b1b1b1-0000-0000-0000-000000000002")
+	productID1 := uuid.MustParse("c1c1c1c1-0000-0000-0000-000000000001")
+	productID2 := uuid.MustParse("c1c1c1c1-0000-0000-0000-000000000002")
+	productID3 := uuid.MustParse("c1c1c1c1-0000-0000-0000-000000000003")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "dispatched", adminID, now, now, nil, nil))
+				mock.ExpectQuery(repository.CountTransferProductsQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(productrepo.LockPickupPointCapacityQuery).
+					WithArgs(toPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(10, "hard"))
+				mock.ExpectQuery(productrepo.CountPickupPointProductsQuery).
+					WithArgs(toPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+				mock.ExpectExec(repository.CreateTransferReceptionQuery).
+					WithArgs(receptionID, toPvz).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(repository.MoveTransferProductsQuery).
+					WithArgs(receptionID, transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "product_type"}).
+						AddRow(productID1, sourceReception1, "обувь").
+						AddRow(productID2, sourceReception1, "одежда").
+						AddRow(productID3, sourceReception2, "электроника"))
+				// Каждый товар оставляет запись в истории своей исходной приемки
+				mock.ExpectExec(historyrepo.CreateEventQuery).
+					WithArgs(sourceReception1, string(history.ProductTransferred), uuid.NullUUID{UUID: productID1, Valid: true},
+						sql.NullString{String: "обувь", Valid: true}, sqlmock.AnyArg(),
+						sql.NullString{String: "transfer " + transferID.String() + " to pickup point " + toPvz.String(), Valid: true}).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectHistoryWrite(mock, sourceReception1, history.ProductTransferred)
+				expectHistoryWrite(mock, sourceReception2, history.ProductTransferred)
+				mock.ExpectQuery(repository.AcceptTransferQuery).
+					WithArgs(transferID, receptionID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "accepted", adminID, now, now, now, receptionID))
+				expectHistoryWrite(mock, receptionID, history.TransferAccepted)
+				expectOutboxWrite(mock, toPvz, history.TransferAccepted)
+				mock.ExpectCommit()
+				mock.ExpectQuery(repository.GetTransferProductsQuery).
+					WithArgs(pq.StringArray{transferID.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "id", "reception_id", "product_type", "reception_date"}))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Destination Full",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "dispatched", adminID, now, now, nil, nil))
+				mock.ExpectQuery(repository.CountTransferProductsQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(productrepo.LockPickupPointCapacityQuery).
+					WithArgs(toPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "capacity_mode"}).AddRow(10, "hard"))
+				mock.ExpectQuery(productrepo.CountPickupPointProductsQuery).
+					WithArgs(toPvz).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(8))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrPickupPointFull,
+		},
+		{
+			name: "Not Dispatched",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "created", adminID, now, nil, nil, nil))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferStatusConflict,
+		},
+		{
+			name: "Another Pickup Point",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, fromPvz, "dispatched", adminID, now, now, nil, nil))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferWrongPickupPoint,
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.AcceptTransfer(context.Background(), transferID, toPvz, receptionID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.StatusAccepted, got.Status)
+				assert.Equal(t, &receptionID, got.ReceptionID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDispatchTransfer(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTransferRepository(db)
+
+	transferID := uuid.MustParse("3f2504e0-4f89-11d3-9a0c-0305e82c3301")
+	fromPvz := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	toPvz := uuid.MustParse("66666666-7777-8888-9999-000000000000")
+	adminID := uuid.MustParse("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		pvzID       uuid.UUID
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name:  "Success",
+			pvzID: fromPvz,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "created", adminID, now, nil, nil, nil))
+				mock.ExpectQuery(repository.DispatchTransferQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "dispatched", adminID, now, now, nil, nil))
+				mock.ExpectCommit()
+				mock.ExpectQuery(repository.GetTransferProductsQuery).
+					WithArgs(pq.StringArray{transferID.String()}).
+					WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "id", "reception_id", "product_type", "reception_date"}))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Destination Pickup Point",
+			pvzID: toPvz,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "created", adminID, now, nil, nil, nil))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferWrongPickupPoint,
+		},
+		{
+			name:  "Already Dispatched",
+			pvzID: fromPvz,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(repository.GetTransferForUpdateQuery).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferColumns).
+						AddRow(transferID, fromPvz, toPvz, "dispatched", adminID, now, now, nil, nil))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferStatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.DispatchTransfer(context.Background(), transferID, tt.pvzID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.StatusDispatched, got.Status)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	user "github.com/nik-mLb/avito_task/internal/models/user"
+	repository "github.com/nik-mLb/avito_task/internal/repository/user"
+	"github.com/stretchr/testify/assert"
+)
+
+var userColumns = []string{"id", "email", "role", "active", "created_at", "pickup_point_id", "totp_enabled"}
+
+func TestListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db)
+	createdAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	t.Run("filters and pagination", func(t *testing.T) {
+		active := true
+		mock.ExpectQuery(repository.ListUsersQuery).
+			WithArgs(`100\%\_`, "worker", sql.NullBool{Bool: true, Valid: true}, 20, 40).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(userID, "a100%_@example.com", "worker", true, createdAt, nil, false))
+
+		users, err := repo.ListUsers(context.Background(), user.UserFilter{Query: "100%_", Role: "worker", Active: &active, Page: 3, Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []user.User{{ID: userID, Email: "a100%_@example.com", Role: "worker", Active: true, CreatedAt: createdAt}}, users)
+	})
+
+	t.Run("pickup point scope", func(t *testing.T) {
+		pvzID := uuid.New()
+		mock.ExpectQuery(repository.ListUsersQuery).
+			WithArgs("", "worker", sql.NullBool{}, 20, 0).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(userID, "w@example.com", "worker", true, createdAt, pvzID, false))
+
+		users, err := repo.ListUsers(context.Background(), user.UserFilter{Role: "worker", Page: 1, Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, &pvzID, users[0].PickupPointID)
+	})
+
+	t.Run("no filters", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListUsersQuery).
+			WithArgs("", "", sql.NullBool{}, 10, 0).
+			WillReturnRows(sqlmock.NewRows(userColumns))
+
+		users, err := repo.ListUsers(context.Background(), user.UserFilter{Page: 1, Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+		assert.NotNil(t, users)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(repository.ListUsersQuery).
+			WithArgs("", "", sql.NullBool{}, 10, 0).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.ListUsers(context.Background(), user.UserFilter{Page: 1, Limit: 10})
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db)
+	userID := uuid.New()
+
+	t.Run("found", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetUserByIDQuery).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(userID, "test@example.com", "admin", true, time.Now(), nil, false))
+
+		u, err := repo.GetUserByID(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", u.Role)
+		assert.True(t, u.Active)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(repository.GetUserByIDQuery).
+			WithArgs(userID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetUserByID(context.Background(), userID)
+
+		assert.ErrorIs(t, err, errs.ErrUserNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateRoleAndSetActive(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db)
+	userID := uuid.New()
+
+	t.Run("update role", func(t *testing.T) {
+		mock.ExpectQuery(repository.UpdateRoleQuery).
+			WithArgs(userID, "admin").
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(userID, "test@example.com", "admin", true, time.Now(), nil, false))
+
+		u, err := repo.UpdateRole(context.Background(), userID, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", u.Role)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		mock.ExpectQuery(repository.SetActiveQuery).
+			WithArgs(userID, false).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow(userID, "test@example.com", "worker", false, time.Now(), nil, false))
+
+		u, err := repo.SetActive(context.Background(), userID, false)
+
+		assert.NoError(t, err)
+		assert.False(t, u.Active)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(repository.SetActiveQuery).
+			WithArgs(userID, true).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.SetActive(context.Background(), userID, true)
+
+		assert.ErrorIs(t, err, errs.ErrUserNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
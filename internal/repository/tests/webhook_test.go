@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	repository "github.com/nik-mLb/avito_task/internal/repository/webhook"
+)
+
+var (
+	subscriptionColumns = []string{"id", "url", "event_types", "pickup_point_id", "created_by", "created_at"}
+	deliveryColumns     = []string{
+		"id", "subscription_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+		"last_status_code", "last_error", "redelivery_of", "created_at", "delivered_at",
+	}
+)
+
+func TestCreateSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+
+	pvzID := uuid.New()
+	sub := models.Subscription{
+		ID:            uuid.New(),
+		URL:           "https://partner.example/hook",
+		EventTypes:    []history.EventType{history.ReceptionClosed},
+		PickupPointID: &pvzID,
+		Secret:        "secret",
+		CreatedBy:     uuid.New(),
+	}
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *models.Subscription
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectQuery(repository.CreateSubscriptionQuery).
+					WithArgs(sub.ID, sub.URL, pq.StringArray{"reception_closed"}, uuid.NullUUID{UUID: pvzID, Valid: true}, sub.Secret, sub.CreatedBy).
+					WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+						AddRow(sub.ID, sub.URL, "{reception_closed}", pvzID, sub.CreatedBy, now))
+			},
+			expected: &models.Subscription{
+				ID:            sub.ID,
+				URL:           sub.URL,
+				EventTypes:    []history.EventType{history.ReceptionClosed},
+				PickupPointID: &pvzID,
+				CreatedBy:     sub.CreatedBy,
+				CreatedAt:     now,
+			},
+		},
+		{
+			name: "Pickup point not found",
+			mock: func() {
+				mock.ExpectQuery(repository.CreateSubscriptionQuery).
+					WithArgs(sub.ID, sub.URL, pq.StringArray{"reception_closed"}, uuid.NullUUID{UUID: pvzID, Valid: true}, sub.Secret, sub.CreatedBy).
+					WillReturnError(&pq.Error{Code: "23503"})
+			},
+			expectedErr: errs.ErrPickupPointNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			result, err := repo.CreateSubscription(context.Background(), sub)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(repository.DeleteSubscriptionQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteSubscription(context.Background(), id))
+
+	mock.ExpectExec(repository.DeleteSubscriptionQuery).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteSubscription(context.Background(), id), errs.ErrWebhookNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnqueueEvent(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	pvzID := uuid.New()
+	payload := []byte(`{"event":"reception_closed"}`)
+
+	mock.ExpectExec(repository.EnqueueDeliveriesQuery).
+		WithArgs("reception_closed", pvzID, payload).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err := repo.EnqueueEvent(context.Background(), "reception_closed", pvzID, payload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimDueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	subID := uuid.New()
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	payload := `{"event":"reception_closed"}`
+
+	mock.ExpectQuery(repository.ClaimDueDeliveriesQuery).
+		WithArgs(20, float64(120)).
+		WillReturnRows(sqlmock.NewRows(append(deliveryColumns, "url", "secret")).
+			AddRow(7, subID, "reception_closed", []byte(payload), "pending", 3, now, 500, "unexpected status 500", nil, now, nil,
+				"https://partner.example/hook", "secret"))
+
+	targets, err := repo.ClaimDueDeliveries(context.Background(), 20, 2*time.Minute)
+
+	assert.NoError(t, err)
+	code := 500
+	assert.Equal(t, []models.Target{{
+		Delivery: models.Delivery{
+			ID:             7,
+			SubscriptionID: subID,
+			EventType:      "reception_closed",
+			Payload:        json.RawMessage(payload),
+			Status:         models.DeliveryPending,
+			Attempts:       3,
+			NextAttemptAt:  now,
+			LastStatusCode: &code,
+			LastError:      "unexpected status 500",
+			CreatedAt:      now,
+		},
+		URL:    "https://partner.example/hook",
+		Secret: "secret",
+	}}, targets)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDeliveryAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	code := 503
+
+	mock.ExpectExec(repository.MarkDeliveredQuery).WithArgs(int64(1), 200).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(repository.RetryDeliveryQuery).
+		WithArgs(int64(2), sql.NullInt64{Int64: 503, Valid: true}, "unexpected status 503", float64(60)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(repository.FailDeliveryQuery).
+		WithArgs(int64(3), sql.NullInt64{}, "connection refused").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkDelivered(context.Background(), 1, 200))
+	assert.NoError(t, repo.RetryDelivery(context.Background(), 2, &code, "unexpected status 503", time.Minute))
+	assert.NoError(t, repo.FailDelivery(context.Background(), 3, nil, "connection refused"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	subID := uuid.New()
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []models.Delivery
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckSubscriptionExistsQuery).
+					WithArgs(subID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(repository.ListDeliveriesQuery).
+					WithArgs(subID, 100).
+					WillReturnRows(sqlmock.NewRows(deliveryColumns).
+						AddRow(2, subID, "reception_closed", []byte(`{}`), "delivered", 1, now, 200, nil, 1, now, now))
+			},
+			expected: []models.Delivery{{
+				ID:             2,
+				SubscriptionID: subID,
+				EventType:      "reception_closed",
+				Payload:        json.RawMessage(`{}`),
+				Status:         models.DeliveryDelivered,
+				Attempts:       1,
+				NextAttemptAt:  now,
+				LastStatusCode: func() *int { c := 200; return &c }(),
+				RedeliveryOf:   func() *int64 { id := int64(1); return &id }(),
+				CreatedAt:      now,
+				DeliveredAt:    &now,
+			}},
+		},
+		{
+			name: "Subscription not found",
+			mock: func() {
+				mock.ExpectQuery(repository.CheckSubscriptionExistsQuery).
+					WithArgs(subID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errs.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			result, err := repo.ListDeliveries(context.Background(), subID, 100)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	subID := uuid.New()
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(repository.RedeliverQuery).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(deliveryColumns).
+			AddRow(9, subID, "reception_closed", []byte(`{}`), "pending", 0, now, nil, nil, 5, now, nil))
+
+	delivery, err := repo.Redeliver(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), delivery.ID)
+	assert.Equal(t, int64(5), *delivery.RedeliveryOf)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+
+	mock.ExpectQuery(repository.RedeliverQuery).
+		WithArgs(int64(6)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.Redeliver(context.Background(), 6)
+	assert.ErrorIs(t, err, errs.ErrWebhookDeliveryNotFound)
+
+	mock.ExpectQuery(repository.RedeliverQuery).
+		WithArgs(int64(7)).
+		WillReturnError(errors.New("db error"))
+
+	_, err = repo.Redeliver(context.Background(), 7)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
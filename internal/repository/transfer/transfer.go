@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+	historyrepo "github.com/nik-mLb/avito_task/internal/repository/history"
+	outbox "github.com/nik-mLb/avito_task/internal/repository/outbox"
+	productrepo "github.com/nik-mLb/avito_task/internal/repository/product"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// Переместить можно только товары из закрытых приемок ПВЗ-отправителя
+	LockTransferableProductsQuery = `
+		SELECT p.id
+		FROM product p
+		JOIN reception r ON r.id = p.reception_id
+		WHERE p.id = ANY($1::uuid[]) AND r.pickup_point_id = $2 AND r.status = 'close'
+		FOR UPDATE OF p`
+
+	CheckProductsInTransferQuery = `
+		SELECT EXISTS (
+			SELECT 1 FROM transfer_product tp
+			JOIN transfer t ON t.id = tp.transfer_id
+			WHERE tp.product_id = ANY($1::uuid[]) AND t.status <> 'accepted'
+		)`
+
+	CreateTransferQuery = `
+		INSERT INTO transfer (id, from_pickup_point_id, to_pickup_point_id, status, created_by)
+		VALUES ($1, $2, $3, 'created', $4)
+		RETURNING id, from_pickup_point_id, to_pickup_point_id, status, created_by, created_at,
+			dispatched_at, accepted_at, reception_id`
+
+	CreateTransferProductsQuery = `
+		INSERT INTO transfer_product (transfer_id, product_id)
+		SELECT $1, unnest($2::uuid[])`
+
+	GetTransferForUpdateQuery = `
+		SELECT id, from_pickup_point_id, to_pickup_point_id, status, created_by, created_at,
+			dispatched_at, accepted_at, reception_id
+		FROM transfer
+		WHERE id = $1
+		FOR UPDATE`
+
+	DispatchTransferQuery = `
+		UPDATE transfer
+		SET status = 'dispatched', dispatched_at = now()
+		WHERE id = $1
+		RETURNING id, from_pickup_point_id, to_pickup_point_id, status, created_by, created_at,
+			dispatched_at, accepted_at, reception_id`
+
+	CountTransferProductsQuery = `
+		SELECT count(*) FROM transfer_product
+		WHERE transfer_id = $1`
+
+	CreateTransferReceptionQuery = `
+		INSERT INTO reception (id, pickup_point_id, status, kind, closed_at, close_reason)
+		VALUES ($1, $2, 'close', 'transfer', now(), 'transfer')`
+
+	// src - строка товара до обновления, из нее берется приемка, в которой товар был раньше
+	MoveTransferProductsQuery = `
+		UPDATE product p
+		SET reception_id = $1
+		FROM product src
+		WHERE src.id = p.id
+			AND p.id IN (SELECT product_id FROM transfer_product WHERE transfer_id = $2)
+		RETURNING p.id, src.reception_id, p.product_type`
+
+	AcceptTransferQuery = `
+		UPDATE transfer
+		SET status = 'accepted', accepted_at = now(), reception_id = $2
+		WHERE id = $1
+		RETURNING id, from_pickup_point_id, to_pickup_point_id, status, created_by, created_at,
+			dispatched_at, accepted_at, reception_id`
+
+	ListTransfersQuery = `
+		SELECT id, from_pickup_point_id, to_pickup_point_id, status, created_by, created_at,
+			dispatched_at, accepted_at, reception_id
+		FROM transfer
+		WHERE (from_pickup_point_id = $1 OR to_pickup_point_id = $1)
+		AND ($2::transfer_status IS NULL OR status = $2)
+		ORDER BY created_at DESC`
+
+	GetTransferProductsQuery = `
+		SELECT tp.transfer_id, p.id, p.reception_id, p.product_type, p.reception_date
+		FROM transfer_product tp
+		JOIN product p ON p.id = tp.product_id
+		WHERE tp.transfer_id = ANY($1::uuid[])
+		ORDER BY p.reception_date`
+)
+
+// pqForeignKeyViolation код ошибки Postgres при нарушении внешнего ключа
+const pqForeignKeyViolation = "23503"
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTransfer(row rowScanner) (*models.Transfer, error) {
+	var (
+		t           models.Transfer
+		status      string
+		dispatched  sql.NullTime
+		accepted    sql.NullTime
+		receptionID uuid.NullUUID
+	)
+
+	err := row.Scan(&t.ID, &t.FromPickupPointID, &t.ToPickupPointID, &status, &t.CreatedBy, &t.CreatedAt,
+		&dispatched, &accepted, &receptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Status = models.Status(status)
+	if dispatched.Valid {
+		t.DispatchedAt = &dispatched.Time
+	}
+	if accepted.Valid {
+		t.AcceptedAt = &accepted.Time
+	}
+	if receptionID.Valid {
+		t.ReceptionID = &receptionID.UUID
+	}
+	t.Products = []product.Product{}
+
+	return &t, nil
+}
+
+func uuidArray(ids []uuid.UUID) pq.StringArray {
+	arr := make(pq.StringArray, 0, len(ids))
+	for _, id := range ids {
+		arr = append(arr, id.String())
+	}
+	return arr
+}
+
+func (r *TransferRepository) CreateTransfer(ctx context.Context, transferID, fromPvzID, toPvzID, createdBy uuid.UUID, productIDs []uuid.UUID) (*models.Transfer, error) {
+	const op = "TransferRepository.CreateTransfer"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
+		"from_pvz_id": fromPvzID,
+		"to_pvz_id":   toPvzID,
+		"products":    len(productIDs),
+	})
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	ids := uuidArray(productIDs)
+
+	rows, err := tx.QueryContext(ctx, LockTransferableProductsQuery, ids, fromPvzID)
+	if err != nil {
+		logger.WithError(err).Error("lock products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if found != len(productIDs) {
+		logger.WithField("found", found).Warn("products are not available at source pickup point")
+		return nil, errs.ErrTransferProductsUnavailable
+	}
+
+	var inTransfer bool
+	if err := tx.QueryRowContext(ctx, CheckProductsInTransferQuery, ids).Scan(&inTransfer); err != nil {
+		logger.WithError(err).Error("check products in transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if inTransfer {
+		logger.Warn("products already belong to an unfinished transfer")
+		return nil, errs.ErrTransferProductsUnavailable
+	}
+
+	transfer, err := scanTransfer(tx.QueryRowContext(ctx, CreateTransferQuery, transferID, fromPvzID, toPvzID, createdBy))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+			logger.Warn("destination pickup point not found")
+			return nil, errs.ErrPickupPointNotFound
+		}
+		logger.WithError(err).Error("create transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, CreateTransferProductsQuery, transferID, ids); err != nil {
+		logger.WithError(err).Error("create transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.loadProducts(ctx, []*models.Transfer{transfer}); err != nil {
+		logger.WithError(err).Error("load transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transfer, nil
+}
+
+// DispatchTransfer отправляет перемещение. Отправить его может только ПВЗ-отправитель
+func (r *TransferRepository) DispatchTransfer(ctx context.Context, transferID, pvzID uuid.UUID) (*models.Transfer, error) {
+	const op = "TransferRepository.DispatchTransfer"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("transfer_id", transferID).WithField("pvz_id", pvzID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	current, err := r.lockTransferInStatus(ctx, tx, transferID, models.StatusCreated)
+	if err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) || errors.Is(err, errs.ErrTransferStatusConflict) {
+			logger.WithError(err).Warn("transfer cannot be dispatched")
+			return nil, err
+		}
+		logger.WithError(err).Error("get transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if current.FromPickupPointID != pvzID {
+		logger.Warn("transfer is dispatched not by its source pickup point")
+		return nil, errs.ErrTransferWrongPickupPoint
+	}
+
+	transfer, err := scanTransfer(tx.QueryRowContext(ctx, DispatchTransferQuery, transferID))
+	if err != nil {
+		logger.WithError(err).Error("dispatch transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.loadProducts(ctx, []*models.Transfer{transfer}); err != nil {
+		logger.WithError(err).Error("load transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transfer, nil
+}
+
+// AcceptTransfer принимает перемещение в ПВЗ-получатель: создает закрытую приемку вида transfer,
+// переносит в нее товары, сохраняя их идентификаторы, и пишет событие в историю приемки и outbox.
+// В историю исходных приемок для каждого товара пишется product_transferred.
+// Принять перемещение может только ПВЗ-получатель, и только если в нем хватает места под все товары
+func (r *TransferRepository) AcceptTransfer(ctx context.Context, transferID, pvzID, receptionID uuid.UUID) (*models.Transfer, error) {
+	const op = "TransferRepository.AcceptTransfer"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("transfer_id", transferID).WithField("pvz_id", pvzID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("begin transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	current, err := r.lockTransferInStatus(ctx, tx, transferID, models.StatusDispatched)
+	if err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) || errors.Is(err, errs.ErrTransferStatusConflict) {
+			logger.WithError(err).Warn("transfer cannot be accepted")
+			return nil, err
+		}
+		logger.WithError(err).Error("get transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if current.ToPickupPointID != pvzID {
+		logger.Warn("transfer is accepted not by its destination pickup point")
+		return nil, errs.ErrTransferWrongPickupPoint
+	}
+
+	var incoming int64
+	if err := tx.QueryRowContext(ctx, CountTransferProductsQuery, transferID).Scan(&incoming); err != nil {
+		logger.WithError(err).Error("count transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Вместимость проверяется так же, как при добавлении товара, под блокировкой ПВЗ-получателя
+	if err := productrepo.CheckCapacity(ctx, tx, pvzID, incoming); err != nil {
+		if errors.Is(err, errs.ErrPickupPointFull) {
+			logger.WithField("products", incoming).Warn("destination pickup point is full")
+			return nil, err
+		}
+		logger.WithError(err).Error("check capacity")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, CreateTransferReceptionQuery, receptionID, current.ToPickupPointID); err != nil {
+		logger.WithError(err).Error("create transfer reception")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	moved, err := moveTransferProducts(ctx, tx, transferID, receptionID)
+	if err != nil {
+		logger.WithError(err).Error("move products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// В истории исходных приемок видно, куда ушли товары
+	for _, p := range moved {
+		productID := p.ID
+		err := historyrepo.Write(ctx, tx, history.Event{
+			ReceptionID: p.ReceptionID,
+			Type:        history.ProductTransferred,
+			ProductID:   &productID,
+			ProductType: string(p.ProductType),
+			Details:     fmt.Sprintf("transfer %s to pickup point %s", transferID, current.ToPickupPointID),
+		})
+		if err != nil {
+			logger.WithError(err).Error("write source reception history")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	transfer, err := scanTransfer(tx.QueryRowContext(ctx, AcceptTransferQuery, transferID, receptionID))
+	if err != nil {
+		logger.WithError(err).Error("accept transfer")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	event := history.Event{
+		ReceptionID: receptionID,
+		Type:        history.TransferAccepted,
+		Details:     fmt.Sprintf("transfer %s from pickup point %s", transferID, current.FromPickupPointID),
+	}
+	if err := historyrepo.Write(ctx, tx, event); err != nil {
+		logger.WithError(err).Error("write reception history")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = outbox.Write(ctx, tx, feed.Event{
+		Type:          event.Type,
+		PickupPointID: current.ToPickupPointID,
+		ReceptionID:   receptionID,
+		Details:       event.Details,
+	})
+	if err != nil {
+		logger.WithError(err).Error("write outbox event")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.WithError(err).Error("commit transaction")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.loadProducts(ctx, []*models.Transfer{transfer}); err != nil {
+		logger.WithError(err).Error("load transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transfer, nil
+}
+
+// moveTransferProducts переносит товары перемещения в приемку receptionID
+// и возвращает их с приемками, в которых они были до переноса
+func moveTransferProducts(ctx context.Context, tx *sql.Tx, transferID, receptionID uuid.UUID) ([]product.Product, error) {
+	rows, err := tx.QueryContext(ctx, MoveTransferProductsQuery, receptionID, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moved []product.Product
+	for rows.Next() {
+		var p product.Product
+		if err := rows.Scan(&p.ID, &p.ReceptionID, &p.ProductType); err != nil {
+			return nil, err
+		}
+		moved = append(moved, p)
+	}
+	return moved, rows.Err()
+}
+
+// ListTransfers возвращает входящие и исходящие перемещения ПВЗ, status == nil означает любой статус
+func (r *TransferRepository) ListTransfers(ctx context.Context, pvzID uuid.UUID, status *models.Status) ([]models.Transfer, error) {
+	const op = "TransferRepository.ListTransfers"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
+
+	var statusArg sql.NullString
+	if status != nil {
+		statusArg = sql.NullString{String: string(*status), Valid: true}
+	}
+
+	rows, err := r.db.QueryContext(ctx, ListTransfersQuery, pvzID, statusArg)
+	if err != nil {
+		logger.WithError(err).Error("query transfers")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var transfers []*models.Transfer
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			logger.WithError(err).Error("scan transfer")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.loadProducts(ctx, transfers); err != nil {
+		logger.WithError(err).Error("load transfer products")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	output := make([]models.Transfer, 0, len(transfers))
+	for _, t := range transfers {
+		output = append(output, *t)
+	}
+
+	return output, nil
+}
+
+// lockTransferInStatus блокирует перемещение до конца транзакции и проверяет, что оно в статусе status
+func (r *TransferRepository) lockTransferInStatus(ctx context.Context, tx *sql.Tx, transferID uuid.UUID, status models.Status) (*models.Transfer, error) {
+	current, err := scanTransfer(tx.QueryRowContext(ctx, GetTransferForUpdateQuery, transferID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrTransferNotFound
+		}
+		return nil, err
+	}
+	if current.Status != status {
+		return nil, errs.ErrTransferStatusConflict
+	}
+	return current, nil
+}
+
+func (r *TransferRepository) loadProducts(ctx context.Context, transfers []*models.Transfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.Transfer, len(transfers))
+	ids := make([]uuid.UUID, 0, len(transfers))
+	for _, t := range transfers {
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, GetTransferProductsQuery, uuidArray(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			transferID uuid.UUID
+			p          product.Product
+		)
+		if err := rows.Scan(&transferID, &p.ID, &p.ReceptionID, &p.ProductType, &p.ReceptionDate); err != nil {
+			return err
+		}
+		if t, ok := byID[transferID]; ok {
+			t.Products = append(t.Products, p)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// Пустые $1 и $2 и NULL в $3 не ограничивают выборку
+	ListUsersQuery = `
+		SELECT id, email, role, active, created_at, pickup_point_id, totp_enabled
+		FROM "user"
+		WHERE ($1 = '' OR email ILIKE '%' || $1 || '%')
+			AND ($2 = '' OR role = $2)
+			AND ($3::boolean IS NULL OR active = $3)
+		ORDER BY created_at, id
+		LIMIT $4 OFFSET $5`
+
+	GetUserByIDQuery = `
+		SELECT id, email, role, active, created_at, pickup_point_id, totp_enabled
+		FROM "user"
+		WHERE id = $1`
+
+	// Смена роли отзывает токены пользователя: в них записана старая роль
+	UpdateRoleQuery = `
+		UPDATE "user"
+		SET role = $2,
+			tokens_valid_after = CASE WHEN role <> $2 THEN now() ELSE tokens_valid_after END
+		WHERE id = $1
+		RETURNING id, email, role, active, created_at, pickup_point_id, totp_enabled`
+
+	// Деактивация отзывает токены, поэтому после повторной активации старые токены не оживают
+	SetActiveQuery = `
+		UPDATE "user"
+		SET active = $2,
+			tokens_valid_after = CASE WHEN active AND NOT $2 THEN now() ELSE tokens_valid_after END
+		WHERE id = $1
+		RETURNING id, email, role, active, created_at, pickup_point_id, totp_enabled`
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var (
+		u     models.User
+		pvzID uuid.NullUUID
+	)
+	if err := row.Scan(&u.ID, &u.Email, &u.Role, &u.Active, &u.CreatedAt, &pvzID, &u.TwoFactorEnabled); err != nil {
+		return nil, err
+	}
+	if pvzID.Valid {
+		u.PickupPointID = &pvzID.UUID
+	}
+	return &u, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы поиск шел по буквальной подстроке
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *UserRepository) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	const op = "UserRepository.ListUsers"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	active := sql.NullBool{}
+	if filter.Active != nil {
+		active = sql.NullBool{Bool: *filter.Active, Valid: true}
+	}
+	offset := (filter.Page - 1) * filter.Limit
+
+	rows, err := r.db.QueryContext(ctx, ListUsersQuery, escapeLike(filter.Query), filter.Role, active, filter.Limit, offset)
+	if err != nil {
+		logger.WithError(err).Error("failed to query users")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			logger.WithError(err).Error("failed to scan user")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, *u)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	const op = "UserRepository.GetUserByID"
+	return r.queryUser(ctx, op, GetUserByIDQuery, id)
+}
+
+func (r *UserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role string) (*models.User, error) {
+	const op = "UserRepository.UpdateRole"
+	return r.queryUser(ctx, op, UpdateRoleQuery, id, role)
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.User, error) {
+	const op = "UserRepository.SetActive"
+	return r.queryUser(ctx, op, SetActiveQuery, id, active)
+}
+
+// queryUser выполняет запрос, возвращающий одного пользователя, и переводит отсутствие строки в ErrUserNotFound
+func (r *UserRepository) queryUser(ctx context.Context, op, query string, id uuid.UUID, args ...any) (*models.User, error) {
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", id)
+
+	u, err := scanUser(r.db.QueryRowContext(ctx, query, append([]any{id}, args...)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("user not found")
+			return nil, errs.ErrUserNotFound
+		}
+		logger.WithError(err).Error("failed to query user")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return u, nil
+}
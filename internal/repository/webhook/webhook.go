@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	CreateSubscriptionQuery = `
+		INSERT INTO webhook_subscription (id, url, event_types, pickup_point_id, secret, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, url, event_types, pickup_point_id, created_by, created_at`
+
+	ListSubscriptionsQuery = `
+		SELECT id, url, event_types, pickup_point_id, created_by, created_at
+		FROM webhook_subscription
+		ORDER BY created_at, id`
+
+	DeleteSubscriptionQuery = `
+		DELETE FROM webhook_subscription WHERE id = $1`
+
+	CheckSubscriptionExistsQuery = `
+		SELECT EXISTS (SELECT 1 FROM webhook_subscription WHERE id = $1)`
+
+	// Одна строка в очереди на каждую подписку, которой подходит событие
+	EnqueueDeliveriesQuery = `
+		INSERT INTO webhook_delivery (subscription_id, event_type, payload)
+		SELECT id, $1, $3
+		FROM webhook_subscription
+		WHERE (cardinality(event_types) = 0 OR $1 = ANY(event_types))
+		AND (pickup_point_id IS NULL OR pickup_point_id = $2)`
+
+	// Забирает готовые к отправке доставки и сдвигает их next_attempt_at на время аренды:
+	// другие экземпляры пропускают заблокированные строки, а если процесс упадет посреди отправки,
+	// доставка вернется в очередь после окончания аренды
+	ClaimDueDeliveriesQuery = `
+		WITH due AS (
+			SELECT id
+			FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_delivery d
+		SET attempts = d.attempts + 1, next_attempt_at = now() + $2 * interval '1 second'
+		FROM due, webhook_subscription s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING d.id, d.subscription_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.redelivery_of, d.created_at, d.delivered_at, s.url, s.secret`
+
+	MarkDeliveredQuery = `
+		UPDATE webhook_delivery
+		SET status = 'delivered', delivered_at = now(), last_status_code = $2, last_error = NULL
+		WHERE id = $1`
+
+	RetryDeliveryQuery = `
+		UPDATE webhook_delivery
+		SET next_attempt_at = now() + $4 * interval '1 second', last_status_code = $2, last_error = $3
+		WHERE id = $1`
+
+	FailDeliveryQuery = `
+		UPDATE webhook_delivery
+		SET status = 'failed', last_status_code = $2, last_error = $3
+		WHERE id = $1`
+
+	ListDeliveriesQuery = `
+		SELECT id, subscription_id, event_type, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, redelivery_of, created_at, delivered_at
+		FROM webhook_delivery
+		WHERE subscription_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	// Повторная отправка создает новую доставку, чтобы не терять историю исходной
+	RedeliverQuery = `
+		INSERT INTO webhook_delivery (subscription_id, event_type, payload, redelivery_of)
+		SELECT subscription_id, event_type, payload, id
+		FROM webhook_delivery
+		WHERE id = $1
+		RETURNING id, subscription_id, event_type, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, redelivery_of, created_at, delivered_at`
+)
+
+// pqForeignKeyViolation код ошибки Postgres при нарушении внешнего ключа
+const pqForeignKeyViolation = "23503"
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (*models.Subscription, error) {
+	var (
+		s          models.Subscription
+		eventTypes pq.StringArray
+		pvzID      uuid.NullUUID
+	)
+
+	if err := row.Scan(&s.ID, &s.URL, &eventTypes, &pvzID, &s.CreatedBy, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	s.EventTypes = make([]history.EventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		s.EventTypes = append(s.EventTypes, history.EventType(t))
+	}
+	if pvzID.Valid {
+		s.PickupPointID = &pvzID.UUID
+	}
+
+	return &s, nil
+}
+
+func scanDelivery(row rowScanner, extra ...any) (*models.Delivery, error) {
+	var (
+		d            models.Delivery
+		status       string
+		code         sql.NullInt64
+		lastError    sql.NullString
+		redeliveryOf sql.NullInt64
+		deliveredAt  sql.NullTime
+		payload      []byte
+	)
+
+	dest := []any{&d.ID, &d.SubscriptionID, &d.EventType, &payload, &status, &d.Attempts, &d.NextAttemptAt,
+		&code, &lastError, &redeliveryOf, &d.CreatedAt, &deliveredAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	d.Status = models.DeliveryStatus(status)
+	if code.Valid {
+		c := int(code.Int64)
+		d.LastStatusCode = &c
+	}
+	d.LastError = lastError.String
+	if redeliveryOf.Valid {
+		d.RedeliveryOf = &redeliveryOf.Int64
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &d, nil
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub models.Subscription) (*models.Subscription, error) {
+	const op = "WebhookRepository.CreateSubscription"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("webhook_id", sub.ID)
+
+	eventTypes := make(pq.StringArray, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+
+	var pvzID uuid.NullUUID
+	if sub.PickupPointID != nil {
+		pvzID = uuid.NullUUID{UUID: *sub.PickupPointID, Valid: true}
+	}
+
+	created, err := scanSubscription(r.db.QueryRowContext(ctx, CreateSubscriptionQuery,
+		sub.ID, sub.URL, eventTypes, pvzID, sub.Secret, sub.CreatedBy))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+			logger.Warn("pickup point not found")
+			return nil, errs.ErrPickupPointNotFound
+		}
+		logger.WithError(err).Error("failed to create subscription")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	const op = "WebhookRepository.ListSubscriptions"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	rows, err := r.db.QueryContext(ctx, ListSubscriptionsQuery)
+	if err != nil {
+		logger.WithError(err).Error("failed to query subscriptions")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	subs := []models.Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			logger.WithError(err).Error("failed to scan subscription")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		subs = append(subs, *sub)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subs, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	const op = "WebhookRepository.DeleteSubscription"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("webhook_id", id)
+
+	res, err := r.db.ExecContext(ctx, DeleteSubscriptionQuery, id)
+	if err != nil {
+		logger.WithError(err).Error("failed to delete subscription")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		logger.Warn("subscription not found")
+		return errs.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueEvent ставит событие в очередь всем подходящим подпискам и возвращает число созданных доставок
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, eventType string, pvzID uuid.UUID, payload []byte) (int64, error) {
+	const op = "WebhookRepository.EnqueueEvent"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("event_type", eventType).WithField("pvz_id", pvzID)
+
+	res, err := r.db.ExecContext(ctx, EnqueueDeliveriesQuery, eventType, pvzID, payload)
+	if err != nil {
+		logger.WithError(err).Error("failed to enqueue deliveries")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("failed to get affected rows")
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// ClaimDueDeliveries забирает до limit доставок, время которых пришло, и засчитывает им попытку
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.Target, error) {
+	const op = "WebhookRepository.ClaimDueDeliveries"
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	rows, err := r.db.QueryContext(ctx, ClaimDueDeliveriesQuery, limit, lease.Seconds())
+	if err != nil {
+		logger.WithError(err).Error("failed to claim deliveries")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var targets []models.Target
+	for rows.Next() {
+		var target models.Target
+		d, err := scanDelivery(rows, &target.URL, &target.Secret)
+		if err != nil {
+			logger.WithError(err).Error("failed to scan delivery")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		target.Delivery = *d
+		targets = append(targets, target)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return targets, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	const op = "WebhookRepository.MarkDelivered"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("delivery_id", id)
+
+	if _, err := r.db.ExecContext(ctx, MarkDeliveredQuery, id, statusCode); err != nil {
+		logger.WithError(err).Error("failed to mark delivery as delivered")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RetryDelivery записывает неудачную попытку и откладывает следующую на delay
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id int64, statusCode *int, lastError string, delay time.Duration) error {
+	const op = "WebhookRepository.RetryDelivery"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("delivery_id", id)
+
+	if _, err := r.db.ExecContext(ctx, RetryDeliveryQuery, id, nullInt(statusCode), lastError, delay.Seconds()); err != nil {
+		logger.WithError(err).Error("failed to schedule retry")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FailDelivery записывает последнюю неудачную попытку и больше не отправляет доставку
+func (r *WebhookRepository) FailDelivery(ctx context.Context, id int64, statusCode *int, lastError string) error {
+	const op = "WebhookRepository.FailDelivery"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("delivery_id", id)
+
+	if _, err := r.db.ExecContext(ctx, FailDeliveryQuery, id, nullInt(statusCode), lastError); err != nil {
+		logger.WithError(err).Error("failed to mark delivery as failed")
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.Delivery, error) {
+	const op = "WebhookRepository.ListDeliveries"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("webhook_id", subscriptionID)
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, CheckSubscriptionExistsQuery, subscriptionID).Scan(&exists); err != nil {
+		logger.WithError(err).Error("failed to check subscription")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		logger.Warn("subscription not found")
+		return nil, errs.ErrWebhookNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, ListDeliveriesQuery, subscriptionID, limit)
+	if err != nil {
+		logger.WithError(err).Error("failed to query deliveries")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			logger.WithError(err).Error("failed to scan delivery")
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("rows iteration error")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) Redeliver(ctx context.Context, deliveryID int64) (*models.Delivery, error) {
+	const op = "WebhookRepository.Redeliver"
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("delivery_id", deliveryID)
+
+	d, err := scanDelivery(r.db.QueryRowContext(ctx, RedeliverQuery, deliveryID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("delivery not found")
+			return nil, errs.ErrWebhookDeliveryNotFound
+		}
+		logger.WithError(err).Error("failed to create redelivery")
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+func nullInt(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
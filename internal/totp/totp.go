@@ -0,0 +1,95 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Параметры совпадают со значениями по умолчанию приложений-аутентификаторов
+const (
+	Digits = 6
+	Period = 30 * time.Second
+	// secretSize - 160 бит, как рекомендует RFC 4226 для HMAC-SHA1
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret возвращает случайный секрет в base32 без выравнивания
+func GenerateSecret() (string, error) {
+	raw := make([]byte, secretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+	return encoding.EncodeToString(raw), nil
+}
+
+// Step - номер 30-секундного интервала, в который попадает t
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code вычисляет код для шага по RFC 6238 (HMAC-SHA1, 6 цифр)
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decode totp secret: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+// Match проверяет код в окне ±skew шагов вокруг t и возвращает совпавший шаг.
+// Шаг нужен вызывающему, чтобы не принять тот же код повторно
+func Match(secret, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI - ссылка otpauth://, которую приложение-аутентификатор считывает из QR-кода
+func ProvisioningURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
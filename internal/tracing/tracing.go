@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName - имя, под которым приложение создает свои спаны
+const tracerName = "github.com/nik-mLb/avito_task"
+
+// Shutdown выгружает накопленные спаны и останавливает экспортер
+type Shutdown func(ctx context.Context) error
+
+// Setup настраивает глобальную трассировку. Exporter - none, stdout или otlp (endpoint - host:port
+// OTLP-коллектора по HTTP). При none спаны не создаются, но контекст трассы из traceparent
+// по-прежнему передается дальше и попадает в логи
+func Setup(ctx context.Context, exporter, endpoint, serviceName string) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		spanExporter sdktrace.SpanExporter
+		err          error
+	)
+	switch exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		spanExporter, err = otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithInsecure(),
+		)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start открывает дочерний спан с именем операции, например "ReceptionUsecase.CreateReception"
+func Start(ctx context.Context, op string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, op)
+}
+
+// OpenPostgres открывает подключение к PostgreSQL, в котором каждый запрос к БД пишется отдельным спаном
+// с текстом запроса. Запросы вне трассы (например, открытие соединений пулом) спанов не создают
+func OpenPostgres(dsn string) (*sql.DB, error) {
+	return otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+}
@@ -3,9 +3,14 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 	response "github.com/nik-mLb/avito_task/internal/transport/utils"
@@ -13,9 +18,15 @@ import (
 
 //go:generate mockgen -source=auth.go -destination=../../usecase/mocks/auth_usecase_mock.go -package=mocks AuthUsecase
 type AuthUsecase interface {
-	Authenticate(ctx context.Context, email, password string) (string, error)
-	Register(ctx context.Context, email, password, role string) (string, error)
+	Authenticate(ctx context.Context, email, password string, client session.Client) (string, error)
+	Register(ctx context.Context, email, password, role, invitation string, client session.Client) (string, error)
 	DummyLogin(role string) (string, error)
+	UnlockLogin(ctx context.Context, email, ip string) error
+	VerifyLogin(ctx context.Context, challenge, code string, client session.Client) (string, []string, error)
+	SetupLoginTwoFactor(ctx context.Context, challenge string) (*models.TwoFactorSetup, error)
+	SetupTwoFactor(ctx context.Context, userID string) (*models.TwoFactorSetup, error)
+	EnableTwoFactor(ctx context.Context, userID, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID, code string) error
 }
 
 type AuthHandler struct {
@@ -26,6 +37,15 @@ func New(uc AuthUsecase) *AuthHandler {
 	return &AuthHandler{uc: uc}
 }
 
+// clientInfo - данные клиента для сессии, которую создает вход
+func clientInfo(r *http.Request, device string) session.Client {
+	return session.Client{
+		Device:    device,
+		IP:        response.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
 func (h *AuthHandler) DummyLogin(w http.ResponseWriter, r *http.Request) {
 	const op = "AuthHandler.DummyLogin"
 	logger := logctx.GetLogger(r.Context()).WithField("op", op)
@@ -60,10 +80,31 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.uc.Authenticate(r.Context(), req.Email, req.Password)
+	token, err := h.uc.Authenticate(r.Context(), req.Email, req.Password, clientInfo(r, req.Device))
 	if err != nil {
+		var required *errs.TwoFactorRequiredError
+		if errors.As(err, &required) {
+			// Пароль верный, токен выдается после второго шага
+			response.SendJSONResponse(r.Context(), w, http.StatusAccepted, dto.TwoFactorChallengeResponse{
+				Challenge: required.Challenge,
+				Enroll:    required.Enroll,
+			})
+			return
+		}
+
 		logger.WithError(err).Warn("authentication failed")
-		response.SendError(r.Context(), w, http.StatusUnauthorized, "Incorrect data")
+		var locked *errs.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			response.SendError(r.Context(), w, http.StatusTooManyRequests, "Too many login attempts")
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			response.SendError(r.Context(), w, http.StatusUnauthorized, "Incorrect data")
+		case errors.Is(err, errs.ErrUserDeactivated):
+			response.SendError(r.Context(), w, http.StatusForbidden, "User is deactivated")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to login")
+		}
 		return
 	}
 
@@ -90,12 +131,33 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.uc.Register(r.Context(), req.Email, req.Password, req.Role)
+	token, err := h.uc.Register(r.Context(), req.Email, req.Password, req.Role, req.Invitation, clientInfo(r, req.Device))
 	if err != nil {
+		var required *errs.TwoFactorRequiredError
+		if errors.As(err, &required) {
+			// Пользователь создан, токен выдается после настройки TOTP на втором шаге входа
+			response.SendJSONResponse(r.Context(), w, http.StatusAccepted, dto.TwoFactorChallengeResponse{
+				Challenge: required.Challenge,
+				Enroll:    required.Enroll,
+			})
+			return
+		}
+
 		logger.WithError(err).Warn("registration failed")
-		switch err {
-		case errs.ErrRoleNotAllowed:
+		switch {
+		case errors.Is(err, errs.ErrRoleNotAllowed):
 			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid role")
+		case errors.Is(err, errs.ErrInvalidEmail):
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid email")
+		case errors.Is(err, errs.ErrWeakPassword):
+			// Текст ошибки политики объясняет клиенту, какое требование не выполнено
+			response.SendError(r.Context(), w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, errs.ErrUserAlreadyExists):
+			response.SendError(r.Context(), w, http.StatusConflict, "User already exists")
+		case errors.Is(err, errs.ErrInvalidInvitation):
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid or expired invitation")
+		case errors.Is(err, errs.ErrRegistrationClosed):
+			response.SendError(r.Context(), w, http.StatusForbidden, "Registration is by invitation only")
 		default:
 			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed registration")
 		}
@@ -112,4 +174,30 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	respTok := dto.TokenResponse{Token: token}
 
 	response.SendJSONResponse(r.Context(), w, http.StatusCreated, respTok)
-}
\ No newline at end of file
+}
+
+// UnlockLogin снимает блокировку входа по email и/или IP (только для администратора)
+func (h *AuthHandler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.UnlockLogin"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.UnlockLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode unlock request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	if err := h.uc.UnlockLogin(r.Context(), req.Email, req.IP); err != nil {
+		logger.WithError(err).Warn("failed to unlock login")
+		switch err {
+		case errs.ErrInvalidUnlockRequest:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Email or ip is required")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to unlock login")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+// VerifyLogin - второй шаг входа: challenge из ответа /login и код TOTP или код восстановления
+func (h *AuthHandler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.VerifyLogin"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode two-factor login request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	token, recoveryCodes, err := h.uc.VerifyLogin(r.Context(), req.Challenge, req.Code, clientInfo(r, req.Device))
+	if err != nil {
+		logger.WithError(err).Warn("two-factor login failed")
+		h.sendTwoFactorError(r.Context(), w, err, "Failed to login")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "token",
+		Value:    token,
+		HttpOnly: true,
+		Path:     "/",
+	})
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, dto.TwoFactorLoginResponse{
+		Token:         token,
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// SetupLoginTwoFactor выдает секрет TOTP пользователю, которому его нужно настроить перед входом
+func (h *AuthHandler) SetupLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.SetupLoginTwoFactor"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.TwoFactorSetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode two-factor setup request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	setup, err := h.uc.SetupLoginTwoFactor(r.Context(), req.Challenge)
+	if err != nil {
+		logger.WithError(err).Warn("failed to set up two-factor authentication")
+		h.sendTwoFactorError(r.Context(), w, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, setup)
+}
+
+func (h *AuthHandler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.SetupTwoFactor"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	setup, err := h.uc.SetupTwoFactor(r.Context(), userID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to set up two-factor authentication")
+		h.sendTwoFactorError(r.Context(), w, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, setup)
+}
+
+func (h *AuthHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.EnableTwoFactor"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode two-factor code request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	codes, err := h.uc.EnableTwoFactor(r.Context(), userID, req.Code)
+	if err != nil {
+		logger.WithError(err).Warn("failed to enable two-factor authentication")
+		h.sendTwoFactorError(r.Context(), w, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const op = "AuthHandler.DisableTwoFactor"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode two-factor code request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	if err := h.uc.DisableTwoFactor(r.Context(), userID, req.Code); err != nil {
+		logger.WithError(err).Warn("failed to disable two-factor authentication")
+		h.sendTwoFactorError(r.Context(), w, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) sendTwoFactorError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, errs.ErrInvalidLoginChallenge):
+		response.SendError(ctx, w, http.StatusUnauthorized, "Invalid or expired login challenge")
+	case errors.Is(err, errs.ErrInvalidOTP):
+		response.SendError(ctx, w, http.StatusUnauthorized, "Invalid code")
+	case errors.Is(err, errs.ErrUserDeactivated):
+		response.SendError(ctx, w, http.StatusForbidden, "User is deactivated")
+	case errors.Is(err, errs.ErrTwoFactorMandatory):
+		response.SendError(ctx, w, http.StatusForbidden, "Two-factor authentication is mandatory for the role")
+	case errors.Is(err, errs.ErrTwoFactorAlreadyEnabled):
+		response.SendError(ctx, w, http.StatusConflict, "Two-factor authentication is already enabled")
+	case errors.Is(err, errs.ErrTwoFactorNotSetUp):
+		response.SendError(ctx, w, http.StatusConflict, "Two-factor authentication is not set up")
+	case errors.Is(err, errs.ErrUserNotFound):
+		response.SendError(ctx, w, http.StatusNotFound, "User not found")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -4,6 +4,7 @@ import(
 	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	product "github.com/nik-mLb/avito_task/internal/models/product"
 	reception "github.com/nik-mLb/avito_task/internal/models/reception"
+	"github.com/nik-mLb/avito_task/internal/permission"
 )
 
 //для auth
@@ -15,15 +16,94 @@ type DummyLoginRequest struct {
 	Role string `json:"role"`
 }
 
+// LoginRequest - device необязателен и показывается в списке сессий, например имя терминала
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Device   string `json:"device"`
 }
 
+// RegisterRequest - при регистрации по приглашению email и роль берутся из приглашения
 type RegisterRequest struct {
-	Email    string `json:"email"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	Role       string `json:"role"`
+	Invitation string `json:"invitation"`
+	Device     string `json:"device"`
+}
+
+type UnlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
 	Password string `json:"password"`
-	Role     string `json:"role"`
+}
+
+type InvitationRequest struct {
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	PickupPointID string `json:"pvzId"`
+}
+
+// TwoFactorChallengeResponse - ответ /login, когда нужен второй шаг. Enroll - TOTP еще не настроен
+type TwoFactorChallengeResponse struct {
+	Challenge string `json:"challenge"`
+	Enroll    bool   `json:"enroll"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+	Device    string `json:"device"`
+}
+
+// TwoFactorLoginResponse - коды восстановления есть, только если TOTP включен на этом входе
+type TwoFactorLoginResponse struct {
+	Token         string   `json:"token"`
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
+type TwoFactorSetupRequest struct {
+	Challenge string `json:"challenge"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type PermissionsResponse struct {
+	Role        string                  `json:"role"`
+	Permissions []permission.Permission `json:"permissions"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
 }
 
 
@@ -36,6 +116,11 @@ type PickupPointListResponse struct {
 	Receptions []ReceptionWithProducts `json:"receptions"`
 }
 
+type CapacityRequest struct {
+	Capacity *int   `json:"capacity"`
+	Mode     string `json:"mode"`
+}
+
 type ReceptionWithProducts struct {
 	Reception reception.Reception `json:"reception"`
 	Products  []product.Product `json:"products"`
@@ -46,11 +131,49 @@ type ReceptionRequest struct {
 	PickupPointID string `json:"pvzId"`
 }
 
+type ReopenReceptionRequest struct {
+	Reason string `json:"reason"`
+}
+
 type ProductRequest struct {
 	Type  string `json:"type"`
 	PickupPointID string `json:"pvzId"`
 }
 
+type TransferRequest struct {
+	FromPickupPointID string   `json:"fromPvzId"`
+	ToPickupPointID   string   `json:"toPvzId"`
+	ProductIDs        []string `json:"productIds"`
+}
+
+type WebhookRequest struct {
+	URL           string   `json:"url"`
+	EventTypes    []string `json:"eventTypes"`
+	PickupPointID string   `json:"pvzId"`
+	Secret        string   `json:"secret"`
+}
+
+// ScannerCommand - команда терминала в WebSocket-сессии
+type ScannerCommand struct {
+	Seq         uint64 `json:"seq"`
+	Type        string `json:"type"`
+	ProductType string `json:"productType,omitempty"`
+}
+
+// ScannerAck - подтверждение команды с тем же seq
+type ScannerAck struct {
+	Seq       uint64               `json:"seq"`
+	OK        bool                 `json:"ok"`
+	Error     *ScannerError        `json:"error,omitempty"`
+	Product   *product.Product     `json:"product,omitempty"`
+	Reception *reception.Reception `json:"reception,omitempty"`
+}
+
+type ScannerError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type ErrorResponse struct {
 	Message string `json:"message"`
 }
\ No newline at end of file
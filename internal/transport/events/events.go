@@ -0,0 +1,176 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nik-mLb/avito_task/internal/eventbus"
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=events.go -destination=../../usecase/mocks/event_subscriber_mock.go -package=mocks EventSubscriber
+type EventSubscriber interface {
+	Subscribe(lastEventID uint64, filter models.Filter) *eventbus.Subscription
+}
+
+var eventTypes = map[history.EventType]bool{
+	history.ReceptionOpened:     true,
+	history.ProductAdded:        true,
+	history.ProductDeleted:      true,
+	history.ReceptionClosed:     true,
+	history.ReceptionAutoClosed: true,
+	history.ReceptionReopened:   true,
+	history.TransferAccepted:    true,
+}
+
+type EventsHandler struct {
+	bus       EventSubscriber
+	heartbeat time.Duration
+}
+
+func NewEventsHandler(bus EventSubscriber, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{
+		bus:       bus,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream отдает ленту событий приемок в формате Server-Sent Events.
+// Клиент может сузить ленту параметрами pvzId и type (через запятую), пользователь с ПВЗ в токене
+// видит только события своего ПВЗ. При переподключении клиент получает пропущенные события
+// по заголовку Last-Event-ID (или параметру lastEventId).
+// Если часть пропущенных событий уже вытеснена из буфера, перед ними отправляется событие reset
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	const op = "EventsHandler.Stream"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	query := r.URL.Query()
+
+	var filter models.Filter
+	if pvzID := query.Get("pvzId"); pvzID != "" {
+		uuidPvzID, err := uuid.Parse(pvzID)
+		if err != nil {
+			logger.WithError(err).Warn("invalid pvzId")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid pvzId")
+			return
+		}
+		filter.PickupPointID = &uuidPvzID
+	}
+
+	// Пользователь, ограниченный одним ПВЗ, получает события только этого ПВЗ
+	if scope, ok := authctx.GetPickupPointID(r.Context()); ok {
+		if filter.PickupPointID != nil && !authctx.PickupPointAllowed(r.Context(), filter.PickupPointID.String()) {
+			logger.WithField("pvz_id", filter.PickupPointID).Warn("pickup point is outside of user scope")
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+			return
+		}
+		uuidScope, err := uuid.Parse(scope)
+		if err != nil {
+			logger.WithError(err).Warn("invalid pickup point scope")
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+			return
+		}
+		filter.PickupPointID = &uuidScope
+	}
+
+	if types := query.Get("type"); types != "" {
+		filter.Types = make(map[history.EventType]bool)
+		for _, t := range strings.Split(types, ",") {
+			eventType := history.EventType(strings.TrimSpace(t))
+			if !eventTypes[eventType] {
+				logger.WithField("type", t).Warn("invalid event type")
+				response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid event type")
+				return
+			}
+			filter.Types[eventType] = true
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			logger.WithError(err).Warn("invalid Last-Event-ID")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		lastID = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.Error("response writer does not support flushing")
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "Streaming unsupported")
+		return
+	}
+
+	sub := h.bus.Subscribe(lastID, filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if sub.Missed {
+		if _, err := io.WriteString(w, "event: reset\ndata: {}\n\n"); err != nil {
+			return
+		}
+	}
+	for _, event := range sub.Replay {
+		if err := writeEvent(w, event); err != nil {
+			logger.WithError(err).Warn("failed to write event")
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				logger.Warn("subscriber dropped by event bus")
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				logger.WithError(err).Warn("failed to write event")
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=invitation.go -destination=../../usecase/mocks/invitation_usecase_mock.go -package=mocks InvitationUsecase
+type InvitationUsecase interface {
+	CreateInvitation(ctx context.Context, actorID, email, role, pvzID string) (*models.Invitation, error)
+	ListInvitations(ctx context.Context) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, invitationID string) error
+}
+
+type InvitationHandler struct {
+	uc InvitationUsecase
+}
+
+func NewInvitationHandler(uc InvitationUsecase) *InvitationHandler {
+	return &InvitationHandler{uc: uc}
+}
+
+func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	const op = "InvitationHandler.CreateInvitation"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.InvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	inv, err := h.uc.CreateInvitation(r.Context(), userID, req.Email, req.Role, req.PickupPointID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to create invitation")
+		h.sendInvitationError(r.Context(), w, err, "Failed to create invitation")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusCreated, inv)
+}
+
+func (h *InvitationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	const op = "InvitationHandler.ListInvitations"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	invitations, err := h.uc.ListInvitations(r.Context())
+	if err != nil {
+		logger.WithError(err).Warn("failed to list invitations")
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get invitations")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, invitations)
+}
+
+func (h *InvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	const op = "InvitationHandler.RevokeInvitation"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	if err := h.uc.RevokeInvitation(r.Context(), mux.Vars(r)["invitationId"]); err != nil {
+		logger.WithError(err).Warn("failed to revoke invitation")
+		h.sendInvitationError(r.Context(), w, err, "Failed to revoke invitation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *InvitationHandler) sendInvitationError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case errs.ErrInvalidEmail:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid email")
+	case errs.ErrRoleNotAllowed:
+		response.SendError(ctx, w, http.StatusBadRequest, "Role not allowed")
+	case errs.ErrInvalidPickupPointID:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid pickup point id")
+	case errs.ErrInvalidInvitationID:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid invitation id")
+	case errs.ErrPickupPointNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Pickup point not found")
+	case errs.ErrInvitationNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Invitation not found")
+	case errs.ErrUserAlreadyExists:
+		response.SendError(ctx, w, http.StatusConflict, "User already exists")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/jwt"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+type KeySource interface {
+	PublicKeys() []jwt.PublicKey
+}
+
+type JWKSHandler struct {
+	keys KeySource
+}
+
+func NewJWKSHandler(keys KeySource) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS отдает открытые ключи для проверки токенов (RFC 7517). Ответ можно кешировать:
+// новый ключ публикуется заранее, до даты активации
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	const op = "JWKSHandler.JWKS"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	resp := dto.JWKSResponse{Keys: []dto.JWK{}}
+	for _, key := range h.keys.PublicKeys() {
+		jwk := dto.JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+		switch pub := key.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			logger.WithField("kid", key.ID).Error("unsupported public key type")
+			continue
+		}
+		resp.Keys = append(resp.Keys, jwk)
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, resp)
+}
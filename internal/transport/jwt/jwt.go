@@ -1,7 +1,12 @@
 package jwt
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"errors"
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -12,45 +17,171 @@ import (
 type JWTClaims struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
+	// Dummy отмечает токены, выданные /dummyLogin без проверки пароля
+	Dummy bool `json:"dummy,omitempty"`
+	// SessionID - сессия, созданная при входе. Токены без нее выданы до появления сессий
+	SessionID string `json:"sid,omitempty"`
+	// PickupPointID - ПВЗ, которым ограничен пользователь. Пустое значение - без ограничения
+	PickupPointID string `json:"pvz_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// PublicKey открытый ключ для проверки токенов сторонними сервисами
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+type signingKey struct {
+	id         string
+	private    crypto.PrivateKey
+	public     crypto.PublicKey
+	activeFrom time.Time
+	// retireAt - после этого момента ключом не подписано ни одного действующего токена
+	retireAt time.Time
+}
+
 type Tokenator struct {
+	method        jwt.SigningMethod
 	sign          string
+	keys          []signingKey
 	tokenLifeSpan time.Duration
 }
 
-func NewTokenator(conf *config.JWTConfig) *Tokenator {
-	return &Tokenator{
-		sign:          conf.Signature,
-		tokenLifeSpan: conf.TokenLifeSpan,
+func NewTokenator(conf *config.JWTConfig) (*Tokenator, error) {
+	t := &Tokenator{tokenLifeSpan: conf.TokenLifeSpan}
+
+	switch conf.Algorithm {
+	case "", "HS256":
+		t.method = jwt.SigningMethodHS256
+		t.sign = conf.Signature
+		return t, nil
+	case "RS256":
+		t.method = jwt.SigningMethodRS256
+	case "EdDSA":
+		t.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", conf.Algorithm)
+	}
+
+	if len(conf.Keys) == 0 {
+		return nil, fmt.Errorf("no keys configured for %s", conf.Algorithm)
+	}
+	for _, k := range conf.Keys {
+		key, err := loadKey(t.method, k)
+		if err != nil {
+			return nil, err
+		}
+		t.keys = append(t.keys, key)
+	}
+
+	// Ключ уходит на покой, когда его сменил следующий, и истекли подписанные им токены
+	sort.Slice(t.keys, func(i, j int) bool { return t.keys[i].activeFrom.Before(t.keys[j].activeFrom) })
+	for i := 0; i+1 < len(t.keys); i++ {
+		t.keys[i].retireAt = t.keys[i+1].activeFrom.Add(t.tokenLifeSpan)
+	}
+
+	return t, nil
+}
+
+func loadKey(method jwt.SigningMethod, k config.JWTKey) (signingKey, error) {
+	data, err := os.ReadFile(k.Path)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("read JWT key %s: %w", k.ID, err)
+	}
+
+	key := signingKey{id: k.ID, activeFrom: k.ActiveFrom}
+	switch method {
+	case jwt.SigningMethodRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("parse JWT key %s: %w", k.ID, err)
+		}
+		key.private, key.public = private, &private.PublicKey
+	case jwt.SigningMethodEdDSA:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("parse JWT key %s: %w", k.ID, err)
+		}
+		key.private, key.public = private, private.(ed25519.PrivateKey).Public()
+	}
+
+	return key, nil
+}
+
+// currentKey возвращает последний активный ключ подписи
+func (t *Tokenator) currentKey(now time.Time) (signingKey, bool) {
+	for i := len(t.keys) - 1; i >= 0; i-- {
+		if !t.keys[i].activeFrom.After(now) {
+			return t.keys[i], true
+		}
 	}
+	return signingKey{}, false
+}
+
+// verificationKey ищет ключ по kid среди ключей, которые еще не ушли на покой
+func (t *Tokenator) verificationKey(kid string, now time.Time) (crypto.PublicKey, bool) {
+	for _, key := range t.keys {
+		if key.id == kid && (key.retireAt.IsZero() || now.Before(key.retireAt)) {
+			return key.public, true
+		}
+	}
+	return nil, false
+}
+
+// CreateJWT выдает токен сессии sessionID. Непустой pvzID ограничивает пользователя этим ПВЗ
+func (t *Tokenator) CreateJWT(userID, role, sessionID, pvzID string) (string, error) {
+	return t.createJWT(userID, role, sessionID, pvzID, false)
+}
+
+// CreateDummyJWT выдает токен с отметкой dummy, чтобы его можно было отличить от настоящего
+func (t *Tokenator) CreateDummyJWT(userID, role string) (string, error) {
+	return t.createJWT(userID, role, "", "", true)
 }
 
-func (t *Tokenator) CreateJWT(userID, role string) (string, error) {
+func (t *Tokenator) createJWT(userID, role, sessionID, pvzID string, dummy bool) (string, error) {
 	now := time.Now()
 	expiration := now.Add(t.tokenLifeSpan)
 
 	claims := JWTClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:        userID,
+		Role:          role,
+		Dummy:         dummy,
+		SessionID:     sessionID,
+		PickupPointID: pvzID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiration),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(t.sign))
+	token := jwt.NewWithClaims(t.method, claims)
+	if t.method == jwt.SigningMethodHS256 {
+		return token.SignedString([]byte(t.sign))
+	}
+
+	key, ok := t.currentKey(now)
+	if !ok {
+		return "", errors.New("no active JWT signing key")
+	}
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
 }
 
 func (t *Tokenator) ParseJWT(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+		if t.method == jwt.SigningMethodHS256 {
+			return []byte(t.sign), nil
 		}
-		return []byte(t.sign), nil
-	})
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := t.verificationKey(kid, time.Now())
+		if !ok {
+			return nil, errors.New("unknown key id")
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{t.method.Alg()}))
 
 	if err != nil {
 		return nil, errs.ErrInvalidToken
@@ -61,4 +192,18 @@ func (t *Tokenator) ParseJWT(tokenString string) (*JWTClaims, error) {
 	}
 
 	return nil, errs.ErrInvalidToken
-}
\ No newline at end of file
+}
+
+// PublicKeys возвращает ключи для JWKS: действующие, запланированные и еще проверяющие токены.
+// Для HS256 открытых ключей нет
+func (t *Tokenator) PublicKeys() []PublicKey {
+	now := time.Now()
+	keys := make([]PublicKey, 0, len(t.keys))
+	for _, key := range t.keys {
+		if !key.retireAt.IsZero() && !now.Before(key.retireAt) {
+			continue
+		}
+		keys = append(keys, PublicKey{ID: key.id, Algorithm: t.method.Alg(), Key: key.public})
+	}
+	return keys
+}
@@ -0,0 +1,64 @@
+package authctx
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/nik-mLb/avito_task/internal/models/domains"
+)
+
+func WithUser(ctx context.Context, userID, role string) context.Context {
+	ctx = context.WithValue(ctx, domains.UserIDKey{}, userID)
+	return context.WithValue(ctx, domains.RoleKey{}, role)
+}
+
+func GetUserID(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(domains.UserIDKey{}).(string)
+	return userID, ok
+}
+
+func GetRole(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(domains.RoleKey{}).(string)
+	return role, ok
+}
+
+// WithSession сохраняет сессию токена. У токенов без сессии ее в контексте нет
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, domains.SessionIDKey{}, sessionID)
+}
+
+func GetSessionID(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(domains.SessionIDKey{}).(string)
+	return sessionID, ok
+}
+
+// WithPickupPoint сохраняет ПВЗ, которым ограничен пользователь. Без него пользователь работает со всеми ПВЗ
+func WithPickupPoint(ctx context.Context, pvzID string) context.Context {
+	return context.WithValue(ctx, domains.PickupPointIDKey{}, pvzID)
+}
+
+func GetPickupPointID(ctx context.Context) (string, bool) {
+	pvzID, ok := ctx.Value(domains.PickupPointIDKey{}).(string)
+	return pvzID, ok
+}
+
+// PickupPointAllowed сообщает, может ли пользователь из контекста работать с ПВЗ pvzID
+func PickupPointAllowed(ctx context.Context, pvzID string) bool {
+	scope, ok := GetPickupPointID(ctx)
+	return !ok || scope == pvzID
+}
+
+// GetUserUUID возвращает идентификатор пользователя, если он есть в контексте и является UUID
+func GetUserUUID(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := GetUserID(ctx)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -2,21 +2,22 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	"github.com/nik-mLb/avito_task/internal/transport/jwt"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
 
-// Ключи для хранения в контексте
-type contextKey string
-
-const (
-	userIDKey contextKey = "userID"
-	roleKey   contextKey = "role"
-)
+// TokenChecker проверяет, не отозван ли действительный по подписи токен или его сессия
+type TokenChecker interface {
+	CheckToken(ctx context.Context, claims *jwt.JWTClaims) error
+}
 
 // AuthMiddleware создает middleware для проверки аутентификации
-func AuthMiddleware(tokenator *jwt.Tokenator) func(http.Handler) http.Handler {
+func AuthMiddleware(tokenator *jwt.Tokenator, checker TokenChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Получаем токен из куки
@@ -33,9 +34,35 @@ func AuthMiddleware(tokenator *jwt.Tokenator) func(http.Handler) http.Handler {
 				return
 			}
 
+			if err := checker.CheckToken(r.Context(), claims); err != nil {
+				switch {
+				case errors.Is(err, errs.ErrTokenRevoked):
+					http.Error(w, "Token revoked", http.StatusUnauthorized)
+					return
+				case errors.Is(err, errs.ErrUserDeactivated):
+					http.Error(w, "User is deactivated", http.StatusUnauthorized)
+					return
+				}
+				logctx.GetLogger(r.Context()).WithError(err).Error("failed to check token")
+				http.Error(w, "Failed to check token", http.StatusInternalServerError)
+				return
+			}
+
+			if claims.Dummy {
+				logctx.GetLogger(r.Context()).
+					WithField("user_id", claims.UserID).
+					WithField("role", claims.Role).
+					Warn("request with dummy token")
+			}
+
 			// Добавляем данные в контекст
-			ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
-			ctx = context.WithValue(ctx, roleKey, claims.Role)
+			ctx := authctx.WithUser(r.Context(), claims.UserID, claims.Role)
+			if claims.SessionID != "" {
+				ctx = authctx.WithSession(ctx, claims.SessionID)
+			}
+			if claims.PickupPointID != "" {
+				ctx = authctx.WithPickupPoint(ctx, claims.PickupPointID)
+			}
 
 			// Передаем запрос дальше
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -5,16 +5,26 @@ import (
 	"github.com/nik-mLb/avito_task/internal/models/domains"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
 	return context.WithValue(ctx, domains.LoggerKey{}, logger)
 }
 
+// GetLogger возвращает логгер запроса. Внутри спана к записям добавляются trace_id и span_id
 func GetLogger(ctx context.Context) *logrus.Entry {
-	if logger, ok := ctx.Value(domains.LoggerKey{}).(*logrus.Entry); ok {
-		return logger
+	logger, ok := ctx.Value(domains.LoggerKey{}).(*logrus.Entry)
+	if !ok {
+		logger = logrus.NewEntry(logrus.New())
 	}
 
-	return logrus.NewEntry(logrus.New())
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return logger.WithFields(logrus.Fields{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+	}
+
+	return logger
 }
\ No newline at end of file
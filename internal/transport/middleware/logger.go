@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func LogRequest(logger *logrus.Logger, next http.Handler) http.Handler {
@@ -25,6 +26,9 @@ func LogRequest(logger *logrus.Logger, next http.Handler) http.Handler {
 			"remote_addr": r.RemoteAddr,
 			"path":        r.URL.Path,
 		})
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			middlewareLogger = middlewareLogger.WithField("trace_id", sc.TraceID().String())
+		}
 
 		// Логгер для передачи в контекст (только request_id)
 		contextLogger := logrus.NewEntry(logger).WithField("request_id", reqID) // Важно: создаём новый Entry
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/nik-mLb/avito_task/internal/permission"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+// PermissionMiddleware пропускает запрос, только если у роли из токена есть право required.
+// Должен стоять после AuthMiddleware
+func PermissionMiddleware(policy *permission.Policy, required permission.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := authctx.GetRole(r.Context())
+			if !ok {
+				response.SendError(r.Context(), w, http.StatusInternalServerError, "Role not found in context")
+				return
+			}
+
+			if !policy.Allowed(role, required) {
+				logctx.GetLogger(r.Context()).
+					WithField("role", role).
+					WithField("permission", required).
+					Warn("permission denied")
+				response.SendError(r.Context(), w, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nik-mLb/avito_task/internal/ratelimit"
+	"github.com/nik-mLb/avito_task/internal/transport/jwt"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов по токен-корзинам. Субъект - пользователь
+// из JWT, а без действительного токена - IP клиента. Токен только читается: проверку доступа
+// по-прежнему выполняет AuthMiddleware. Если хранилище недоступно, запрос пропускается
+func RateLimitMiddleware(store ratelimit.Store, policy ratelimit.Policy, tokenator *jwt.Tokenator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const op = "RateLimitMiddleware"
+			logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+			subject, role := "ip:"+response.ClientIP(r), ""
+			if cookie, err := r.Cookie("token"); err == nil {
+				if claims, err := tokenator.ParseJWT(cookie.Value); err == nil {
+					subject, role = "user:"+claims.UserID, claims.Role
+				}
+			}
+
+			var buckets []ratelimit.Bucket
+			if limit := policy.SubjectLimit(role); limit.Enabled() {
+				buckets = append(buckets, ratelimit.Bucket{Key: subject, Limit: limit})
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if path, err := route.GetPathTemplate(); err == nil {
+					name := r.Method + " " + path
+					if limit := policy.Routes[name]; limit.Enabled() {
+						buckets = append(buckets, ratelimit.Bucket{Key: "route:" + name + ":" + subject, Limit: limit})
+					}
+				}
+			}
+
+			// Токены списываются из всех корзин сразу или ни из одной, поэтому запрос,
+			// отклоненный лимитом маршрута, не расходует общий лимит субъекта
+			var results []ratelimit.Result
+			if len(buckets) > 0 {
+				var err error
+				results, err = store.Take(r.Context(), buckets...)
+				if err != nil {
+					logger.WithError(err).Error("failed to take rate limit token")
+					results = nil
+				}
+			}
+
+			// В заголовки попадает самое строгое из ограничений: отклонившее запрос
+			// или с наименьшим остатком
+			var (
+				binding      ratelimit.Result
+				bindingLimit ratelimit.Limit
+				found        bool
+			)
+			for i, result := range results {
+				if !found || (binding.Allowed && (!result.Allowed || result.Remaining < binding.Remaining)) {
+					binding, bindingLimit, found = result, buckets[i].Limit, true
+				}
+			}
+
+			if found {
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(bindingLimit.Requests))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(binding.Remaining))
+				w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(binding.Reset)))
+			}
+			if found && !binding.Allowed {
+				logger.WithField("subject", subject).Warn("rate limit exceeded")
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(binding.RetryAfter)))
+				response.SendError(r.Context(), w, http.StatusTooManyRequests, "Too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, но не меньше одной
+func ceilSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
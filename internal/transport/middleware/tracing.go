@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceRequest открывает серверный спан на каждый запрос. Родительская трасса берется из заголовка
+// traceparent. Спан называется по шаблону маршрута ("GET /pvz/{pvzId}/occupancy"), а не по пути,
+// чтобы идентификаторы из URL не плодили имена спанов
+func TraceRequest(next http.Handler) http.Handler {
+	withRoute := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := routeTemplate(r); route != "" {
+			trace.SpanFromContext(r.Context()).SetAttributes(semconv.HTTPRoute(route))
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(withRoute, "http.request",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if route := routeTemplate(r); route != "" {
+				return r.Method + " " + route
+			}
+			return r.Method
+		}),
+	)
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if path, err := route.GetPathTemplate(); err == nil {
+			return path
+		}
+	}
+	return ""
+}
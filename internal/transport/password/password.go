@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=password.go -destination=../../usecase/mocks/password_usecase_mock.go -package=mocks PasswordUsecase
+type PasswordUsecase interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+type PasswordHandler struct {
+	uc PasswordUsecase
+}
+
+func NewPasswordHandler(uc PasswordUsecase) *PasswordHandler {
+	return &PasswordHandler{uc: uc}
+}
+
+// ForgotPassword отвечает 202 независимо от того, зарегистрирован ли email
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "PasswordHandler.ForgotPassword"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode forgot password request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	if err := h.uc.ForgotPassword(r.Context(), req.Email); err != nil {
+		logger.WithError(err).Warn("failed to request password reset")
+		switch {
+		case errors.Is(err, errs.ErrInvalidEmail):
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid email")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to request password reset")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "PasswordHandler.ResetPassword"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("failed to decode reset password request")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	if err := h.uc.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		logger.WithError(err).Warn("failed to reset password")
+		switch {
+		case errors.Is(err, errs.ErrInvalidResetToken):
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid or expired token")
+		case errors.Is(err, errs.ErrWeakPassword):
+			response.SendError(r.Context(), w, http.StatusBadRequest, err.Error())
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to reset password")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
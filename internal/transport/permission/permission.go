@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/nik-mLb/avito_task/internal/permission"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+type PermissionHandler struct {
+	policy *permission.Policy
+}
+
+func NewPermissionHandler(policy *permission.Policy) *PermissionHandler {
+	return &PermissionHandler{policy: policy}
+}
+
+// Permissions возвращает роль из токена и ее права по текущей политике
+func (h *PermissionHandler) Permissions(w http.ResponseWriter, r *http.Request) {
+	role, ok := authctx.GetRole(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "Role not found in context")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, dto.PermissionsResponse{
+		Role:        role,
+		Permissions: h.policy.Permissions(role),
+	})
+}
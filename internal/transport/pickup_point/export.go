@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	// exportFlushEvery - через сколько строк отдавать накопленное клиенту
+	exportFlushEvery = 500
+
+	// utf8BOM нужен Excel, чтобы распознать кириллицу в CSV как UTF-8
+	utf8BOM = "\xef\xbb\xbf"
+)
+
+var exportCSVHeader = []string{
+	"pvz_id", "city", "pvz_registration_date",
+	"reception_id", "reception_date", "reception_status",
+	"product_id", "product_type", "product_date",
+}
+
+// exportWriter пишет строки выгрузки в конкретном формате
+type exportWriter interface {
+	contentType() string
+	fileExtension() string
+	begin() error
+	write(row pickup.ExportRow) error
+	flush() error
+}
+
+// negotiateExportFormat выбирает формат: ?format= важнее Accept, по умолчанию CSV.
+// Пустая строка означает неподдерживаемый формат
+func negotiateExportFormat(r *http.Request) string {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		switch format {
+		case exportFormatCSV, exportFormatNDJSON:
+			return format
+		default:
+			return ""
+		}
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return exportFormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			return exportFormatNDJSON
+		}
+	}
+
+	return exportFormatCSV
+}
+
+func newExportWriter(format string, w io.Writer) exportWriter {
+	if format == exportFormatNDJSON {
+		return &ndjsonExportWriter{enc: json.NewEncoder(w)}
+	}
+	return &csvExportWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+type csvExportWriter struct {
+	w   io.Writer
+	csv *csv.Writer
+}
+
+func (c *csvExportWriter) contentType() string   { return "text/csv; charset=utf-8" }
+func (c *csvExportWriter) fileExtension() string { return "csv" }
+
+func (c *csvExportWriter) begin() error {
+	if _, err := io.WriteString(c.w, utf8BOM); err != nil {
+		return err
+	}
+	return c.csv.Write(exportCSVHeader)
+}
+
+func (c *csvExportWriter) write(row pickup.ExportRow) error {
+	record := []string{
+		row.PickupPoint.ID.String(),
+		row.PickupPoint.City,
+		row.PickupPoint.RegistrationDate,
+		row.Reception.ID.String(),
+		row.Reception.ReceptionDate.Format(time.RFC3339),
+		row.Reception.Status,
+		"", "", "",
+	}
+	if row.Product != nil {
+		record[6] = row.Product.ID.String()
+		record[7] = string(row.Product.ProductType)
+		record[8] = row.Product.ReceptionDate.Format(time.RFC3339)
+	}
+	return c.csv.Write(record)
+}
+
+func (c *csvExportWriter) flush() error {
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) contentType() string   { return "application/x-ndjson; charset=utf-8" }
+func (n *ndjsonExportWriter) fileExtension() string { return "ndjson" }
+func (n *ndjsonExportWriter) begin() error          { return nil }
+func (n *ndjsonExportWriter) flush() error          { return nil }
+
+// write кодирует строку в одну линию: json.Encoder сам завершает ее переводом строки
+func (n *ndjsonExportWriter) write(row pickup.ExportRow) error {
+	return n.enc.Encode(row)
+}
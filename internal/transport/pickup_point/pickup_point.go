@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/gorilla/mux"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
@@ -18,6 +19,10 @@ import (
 type PickupPointUsecase interface {
 	CreatePickupPoint(ctx context.Context, city string) (*pickup.PickupPoint, error)
 	GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error)
+	StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int, fn func(dto.PickupPointListResponse) error) error
+	GetOccupancy(ctx context.Context, pvzID string) (*pickup.Occupancy, error)
+	SetCapacity(ctx context.Context, pvzID string, capacity *int, mode string) (*pickup.Occupancy, error)
+	ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, fn func(pickup.ExportRow) error) error
 }
 
 type PickupPointHandler struct {
@@ -61,57 +66,210 @@ func (h *PickupPointHandler) GetPickupPointsWithReceptions(w http.ResponseWriter
 	query := r.URL.Query()
 
 	// Парсим даты
-	var startDate, endDate *time.Time
-	if startStr := query.Get("startDate"); startStr != "" {
-		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
-			startDate = &t
-		} else {
-			logger.WithField("startDate", startStr).WithError(err).Warn("invalid startDate")
+	startDate, endDate := parseDateFilters(r)
+
+	// Парсим пагинацию. Без параметров отдается первая страница из 10 ПВЗ, диапазон проверяет usecase
+	page, limit := 1, 10
+	var err error
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil {
+			logger.WithError(err).Warn("invalid page")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid page or limit")
+			return
 		}
 	}
-	if endStr := query.Get("endDate"); endStr != "" {
-		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
-			endDate = &t
-		} else {
-			logger.WithField("endDate", endStr).WithError(err).Warn("invalid endDate")
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			logger.WithError(err).Warn("invalid limit")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid page or limit")
+			return
+		}
+	}
+
+	// Отдаем ПВЗ по мере чтения из БД, не собирая весь список в памяти
+	stream := response.NewJSONArrayStream(w, http.StatusOK)
+	err = h.uc.StreamPickupPointsWithReceptions(r.Context(), startDate, endDate, page, limit, func(item dto.PickupPointListResponse) error {
+		return stream.Write(item)
+	})
+	if err != nil {
+		if !stream.Started() {
+			if err == errs.ErrInvalidPagination {
+				logger.WithError(err).Warn("invalid pagination")
+				response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid page or limit")
+				return
+			}
+			if err == errs.ErrPickupPointForbidden {
+				logger.WithError(err).Warn("invalid pickup point scope")
+				response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+				return
+			}
+			logger.WithError(err).Error("failed to get pickup points with receptions")
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get PickupPoints")
+			return
+		}
+		// Статус уже отправлен, остается только оборвать ответ
+		logger.WithError(err).Error("pickup points stream interrupted")
+		return
+	}
+
+	if err := stream.Close(); err != nil {
+		logger.WithError(err).Error("failed to finish pickup points stream")
+	}
+}
+
+func (h *PickupPointHandler) GetOccupancy(w http.ResponseWriter, r *http.Request) {
+	const op = "PickupPointHandler.GetOccupancy"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	occupancy, err := h.uc.GetOccupancy(r.Context(), mux.Vars(r)["pvzId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to get occupancy")
+		switch err {
+		case errs.ErrInvalidPickupPointID:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid pvz id")
+		case errs.ErrPickupPointNotFound:
+			response.SendError(r.Context(), w, http.StatusNotFound, "Pickup point not found")
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get occupancy")
+		}
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, occupancy)
+}
+
+func (h *PickupPointHandler) SetCapacity(w http.ResponseWriter, r *http.Request) {
+	const op = "PickupPointHandler.SetCapacity"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	var req dto.CapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	occupancy, err := h.uc.SetCapacity(r.Context(), mux.Vars(r)["pvzId"], req.Capacity, req.Mode)
+	if err != nil {
+		logger.WithError(err).Warn("failed to set capacity")
+		switch err {
+		case errs.ErrInvalidPickupPointID:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid pvz id")
+		case errs.ErrInvalidCapacity:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid capacity settings")
+		case errs.ErrPickupPointNotFound:
+			response.SendError(r.Context(), w, http.StatusNotFound, "Pickup point not found")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to set capacity")
 		}
+		return
 	}
 
-	// Парсим пагинацию
-	page, _ := strconv.Atoi(query.Get("page"))
-	if page < 1 {
-		page = 1
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, occupancy)
+}
+
+// ExportPickupPoints выгружает ПВЗ с приемками и товарами в CSV или NDJSON, отдавая данные по мере чтения из БД
+func (h *PickupPointHandler) ExportPickupPoints(w http.ResponseWriter, r *http.Request) {
+	const op = "PickupPointHandler.ExportPickupPoints"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	format := negotiateExportFormat(r)
+	if format == "" {
+		logger.WithField("format", r.URL.Query().Get("format")).Warn("unsupported export format")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Unsupported export format")
+		return
 	}
 
-	limit, _ := strconv.Atoi(query.Get("limit"))
-	if limit < 1 || limit > 30 {
-		limit = 10
+	startDate, endDate := parseDateFilters(r)
+	writer := newExportWriter(format, w)
+	flusher, _ := w.(http.Flusher)
+
+	// Заголовки отправляются с первой строкой, чтобы ошибку запроса к БД еще можно было вернуть кодом 500
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", writer.contentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="pvz_export.`+writer.fileExtension()+`"`)
+		w.WriteHeader(http.StatusOK)
+		return writer.begin()
+	}
+	flush := func() error {
+		if err := writer.flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
 	}
 
-	// Получаем данные
-	result, err := h.uc.GetPickupPointsWithReceptions(r.Context(), startDate, endDate, page, limit)
+	written := 0
+	err := h.uc.ExportPickupPoints(r.Context(), startDate, endDate, func(row pickup.ExportRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.write(row); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
 	if err != nil {
-		logger.WithError(err).Error("failed to get pickup points with receptions")
-		response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get PickupPoints")
+		if !started {
+			if err == errs.ErrPickupPointForbidden {
+				logger.WithError(err).Warn("invalid pickup point scope")
+				response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+				return
+			}
+			logger.WithError(err).Error("failed to export pickup points")
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to export PickupPoints")
+			return
+		}
+		// Статус уже отправлен, остается только оборвать выгрузку
+		logger.WithError(err).WithField("rows", written).Error("export interrupted")
 		return
 	}
 
-	// Преобразуем в ответ
-	resp := make([]dto.PickupPointListResponse, 0, len(result))
-	for _, item := range result {
-		receptions := make([]dto.ReceptionWithProducts, 0, len(item.Receptions))
-		for _, rec := range item.Receptions {
-			receptions = append(receptions, dto.ReceptionWithProducts{
-				Reception: rec.Reception,
-				Products:  rec.Products,
-			})
+	if !started {
+		if err := start(); err != nil {
+			logger.WithError(err).Error("failed to write export header")
+			return
 		}
+	}
+	if err := flush(); err != nil {
+		logger.WithError(err).Error("failed to flush export")
+		return
+	}
+
+	logger.WithField("rows", written).WithField("format", format).Info("pickup points exported")
+}
+
+// parseDateFilters разбирает startDate и endDate в RFC3339; некорректные значения игнорируются
+func parseDateFilters(r *http.Request) (startDate, endDate *time.Time) {
+	logger := logctx.GetLogger(r.Context())
+	query := r.URL.Query()
 
-		resp = append(resp, dto.PickupPointListResponse{
-			PickupPoint: item.PickupPoint,
-			Receptions:  receptions,
-		})
+	if startStr := query.Get("startDate"); startStr != "" {
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			startDate = &t
+		} else {
+			logger.WithField("startDate", startStr).WithError(err).Warn("invalid startDate")
+		}
+	}
+	if endStr := query.Get("endDate"); endStr != "" {
+		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+			endDate = &t
+		} else {
+			logger.WithField("endDate", endStr).WithError(err).Warn("invalid endDate")
+		}
 	}
 
-	response.SendJSONResponse(r.Context(), w, http.StatusOK, resp)
+	return startDate, endDate
 }
@@ -42,8 +42,12 @@ func (h *ProductHandler) AddProduct(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.WithError(err).Warn("failed to add product")
 		switch err {
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
 		case errs.ErrNoActiveReception:
 			response.SendError(r.Context(), w, http.StatusBadRequest, "No active reception found")
+		case errs.ErrPickupPointFull:
+			response.SendError(r.Context(), w, http.StatusConflict, "Pickup point capacity exceeded")
 		default:
 			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to add product")
 		}
@@ -64,6 +68,8 @@ func (h *ProductHandler) DeleteLastProduct(w http.ResponseWriter, r *http.Reques
     if err != nil {
 		logger.WithError(err).Warn("failed to delete last product")
 		switch err {
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
 		case errs.ErrNoProductsToDelete:
 			response.SendError(r.Context(), w, http.StatusBadRequest, "No active reception found or no products to delete")
 		default:
@@ -7,8 +7,10 @@ import (
 
 	"github.com/gorilla/mux"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 	models "github.com/nik-mLb/avito_task/internal/models/reception"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 	response "github.com/nik-mLb/avito_task/internal/transport/utils"
 )
@@ -17,6 +19,8 @@ import (
 type ReceptionUsecase interface {
 	CreateReception(ctx context.Context, pvzID string) (*models.Reception, error)
 	CloseReception(ctx context.Context, pvzID string) (*models.Reception, error)
+	ReopenReception(ctx context.Context, receptionID, userID, reason string) (*models.Reception, error)
+	GetReceptionHistory(ctx context.Context, receptionID string) ([]history.Event, error)
 }
 
 type ReceptionHandler struct {
@@ -42,8 +46,12 @@ func (h *ReceptionHandler) CreateReception(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		logger.WithError(err).Warn("failed to create reception")
 		switch err {
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
 		case errs.ErrActiveReceptionExists:
 			response.SendError(r.Context(), w, http.StatusBadRequest, "Active reception already exists")
+		case errs.ErrPickupPointNotFound:
+			response.SendError(r.Context(), w, http.StatusNotFound, "Pickup point not found")
 		default:
 			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to create reception")
 		}
@@ -64,6 +72,8 @@ func (h *ReceptionHandler) CloseReception(w http.ResponseWriter, r *http.Request
     if err != nil {
 		logger.WithError(err).Warn("failed to close reception")
 		switch err {
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
 		case errs.ErrNoActiveReceptionToClose:
 			response.SendError(r.Context(), w, http.StatusBadRequest, "No active reception to close")
 		default:
@@ -73,4 +83,71 @@ func (h *ReceptionHandler) CloseReception(w http.ResponseWriter, r *http.Request
 	}
 
 	response.SendJSONResponse(r.Context(), w, http.StatusOK, reception)
+}
+
+func (h *ReceptionHandler) ReopenReception(w http.ResponseWriter, r *http.Request) {
+	const op = "ReceptionHandler.ReopenReception"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	receptionID := mux.Vars(r)["receptionId"]
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.ReopenReceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	reception, err := h.uc.ReopenReception(r.Context(), receptionID, userID, req.Reason)
+	if err != nil {
+		logger.WithError(err).Warn("failed to reopen reception")
+		switch err {
+		case errs.ErrInvalidReceptionID:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid reception id")
+		case errs.ErrReopenReasonRequired:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Reason is required")
+		case errs.ErrReceptionNotFound:
+			response.SendError(r.Context(), w, http.StatusNotFound, "Reception not found")
+		case errs.ErrReceptionNotClosed:
+			response.SendError(r.Context(), w, http.StatusConflict, "Reception is not closed")
+		case errs.ErrReopenWindowExpired:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Reopen window has expired")
+		case errs.ErrNewerReceptionExists:
+			response.SendError(r.Context(), w, http.StatusConflict, "Pickup point has a newer reception")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to reopen reception")
+		}
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, reception)
+}
+
+func (h *ReceptionHandler) GetReceptionHistory(w http.ResponseWriter, r *http.Request) {
+	const op = "ReceptionHandler.GetReceptionHistory"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	events, err := h.uc.GetReceptionHistory(r.Context(), mux.Vars(r)["receptionId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to get reception history")
+		switch err {
+		case errs.ErrInvalidReceptionID:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid reception id")
+		case errs.ErrReceptionNotFound:
+			response.SendError(r.Context(), w, http.StatusNotFound, "Reception not found")
+		case errs.ErrPickupPointForbidden:
+			response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get reception history")
+		}
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, events)
 }
\ No newline at end of file
@@ -0,0 +1,334 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+	reception "github.com/nik-mLb/avito_task/internal/models/reception"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen -source=scanner.go -destination=../../usecase/mocks/scanner_usecase_mock.go -package=mocks ScannerProductUsecase,ScannerReceptionUsecase
+type ScannerProductUsecase interface {
+	AddProduct(ctx context.Context, pvzID, productType string) (*product.Product, error)
+	DeleteLastProduct(ctx context.Context, pvzID string) error
+}
+
+type ScannerReceptionUsecase interface {
+	CloseReception(ctx context.Context, pvzID string) (*reception.Reception, error)
+}
+
+// Команды терминала
+const (
+	CommandScan  = "scan"
+	CommandUndo  = "undo"
+	CommandClose = "close"
+)
+
+// Коды ошибок в подтверждениях
+const (
+	CodeInvalidMessage     = "invalid_message"
+	CodeStaleSeq           = "stale_seq"
+	CodeUnknownCommand     = "unknown_command"
+	CodeInvalidProductType = "invalid_product_type"
+	CodeNoActiveReception  = "no_active_reception"
+	CodePickupPointFull    = "pvz_full"
+	CodeNoProductsToDelete = "no_products_to_delete"
+	CodeInternal           = "internal_error"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+
+	// resumeTTL - сколько хранится последнее подтверждение терминала после обрыва сессии
+	resumeTTL = 10 * time.Minute
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type ScannerHandler struct {
+	products   ScannerProductUsecase
+	receptions ScannerReceptionUsecase
+	acks       *ackStore
+}
+
+func NewScannerHandler(products ScannerProductUsecase, receptions ScannerReceptionUsecase) *ScannerHandler {
+	return &ScannerHandler{
+		products:   products,
+		receptions: receptions,
+		acks:       newAckStore(resumeTTL),
+	}
+}
+
+// session - WebSocket-сессия терминала, привязанная к одному ПВЗ
+type session struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	key     ackKey
+	pvzID   string
+	lastSeq uint64
+	lastAck *dto.ScannerAck
+	logger  *logrus.Entry
+}
+
+// Session открывает WebSocket-сессию сканера для ПВЗ из параметра pvzId. Пользователь, привязанный к другому ПВЗ, получает 403.
+// Команды выполняются строго по очереди, на каждую отправляется подтверждение с тем же seq.
+// Последнее подтверждение хранится по пользователю и ПВЗ еще resumeTTL после обрыва связи: повтор
+// последней команды (тот же seq) и в новой сессии не выполняет ее заново, а возвращает сохраненное подтверждение.
+// Поэтому в пределах resumeTTL seq продолжает расти и после переподключения
+func (h *ScannerHandler) Session(w http.ResponseWriter, r *http.Request) {
+	const op = "ScannerHandler.Session"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	uuidPvzID, err := uuid.Parse(r.URL.Query().Get("pvzId"))
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzId")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid pvzId")
+		return
+	}
+	pvzID := uuidPvzID.String()
+	if !authctx.PickupPointAllowed(r.Context(), pvzID) {
+		logger.WithField("pvz_id", pvzID).Warn("pickup point is outside of user scope")
+		response.SendError(r.Context(), w, http.StatusForbidden, "Access to this pickup point is denied")
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader сам отправил ответ с ошибкой
+		logger.WithError(err).Warn("failed to upgrade connection")
+		return
+	}
+	defer conn.Close()
+
+	userID, _ := authctx.GetUserID(r.Context())
+	s := &session{
+		conn:   conn,
+		key:    ackKey{userID: userID, pvzID: pvzID},
+		pvzID:  pvzID,
+		logger: logger.WithField("pvz_id", pvzID),
+	}
+	s.lastSeq, s.lastAck = h.acks.load(s.key)
+	s.logger.WithField("last_seq", s.lastSeq).Info("scanner session opened")
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.keepAlive(done)
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger.Info("scanner session closed by client")
+			} else {
+				s.logger.WithError(err).Warn("scanner session interrupted")
+			}
+			return
+		}
+
+		var cmd dto.ScannerCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			s.logger.WithError(err).Warn("invalid scanner message")
+			if err := s.send(errorAck(0, CodeInvalidMessage, "Invalid message")); err != nil {
+				return
+			}
+			continue
+		}
+
+		ack, closeSession := h.handle(r.Context(), s, cmd)
+		if err := s.send(ack); err != nil {
+			s.logger.WithError(err).Warn("failed to send ack")
+			return
+		}
+		if closeSession {
+			s.closeNormally("reception closed")
+			return
+		}
+	}
+}
+
+// handle выполняет команду и возвращает подтверждение; closeSession - приемка закрыта и сессию пора завершить
+func (h *ScannerHandler) handle(ctx context.Context, s *session, cmd dto.ScannerCommand) (ack *dto.ScannerAck, closeSession bool) {
+	logger := s.logger.WithField("seq", cmd.Seq).WithField("type", cmd.Type)
+
+	if cmd.Seq == 0 {
+		return errorAck(0, CodeInvalidMessage, "seq is required"), false
+	}
+	if cmd.Seq == s.lastSeq && s.lastAck != nil {
+		logger.Info("duplicate command, resending ack")
+		return s.lastAck, false
+	}
+	if cmd.Seq < s.lastSeq {
+		return errorAck(cmd.Seq, CodeStaleSeq, fmt.Sprintf("seq must be greater than %d", s.lastSeq)), false
+	}
+
+	switch cmd.Type {
+	case CommandScan:
+		p, err := h.products.AddProduct(ctx, s.pvzID, cmd.ProductType)
+		if err != nil {
+			logger.WithError(err).Warn("failed to add product")
+			ack = commandErrorAck(cmd.Seq, err)
+		} else {
+			ack = &dto.ScannerAck{Seq: cmd.Seq, OK: true, Product: p}
+		}
+	case CommandUndo:
+		if err := h.products.DeleteLastProduct(ctx, s.pvzID); err != nil {
+			logger.WithError(err).Warn("failed to delete last product")
+			ack = commandErrorAck(cmd.Seq, err)
+		} else {
+			ack = &dto.ScannerAck{Seq: cmd.Seq, OK: true}
+		}
+	case CommandClose:
+		rec, err := h.receptions.CloseReception(ctx, s.pvzID)
+		if err != nil {
+			logger.WithError(err).Warn("failed to close reception")
+			ack = commandErrorAck(cmd.Seq, err)
+		} else {
+			ack = &dto.ScannerAck{Seq: cmd.Seq, OK: true, Reception: rec}
+			closeSession = true
+		}
+	default:
+		return errorAck(cmd.Seq, CodeUnknownCommand, "Unknown command"), false
+	}
+
+	s.lastSeq = cmd.Seq
+	s.lastAck = ack
+	h.acks.save(s.key, cmd.Seq, ack)
+	return ack, closeSession
+}
+
+func commandErrorAck(seq uint64, err error) *dto.ScannerAck {
+	switch {
+	case errors.Is(err, errs.ErrInvalidProductType):
+		return errorAck(seq, CodeInvalidProductType, "Invalid product type")
+	case errors.Is(err, errs.ErrNoActiveReception), errors.Is(err, errs.ErrNoActiveReceptionToClose):
+		return errorAck(seq, CodeNoActiveReception, "No active reception found")
+	case errors.Is(err, errs.ErrPickupPointFull):
+		return errorAck(seq, CodePickupPointFull, "Pickup point capacity exceeded")
+	case errors.Is(err, errs.ErrNoProductsToDelete):
+		return errorAck(seq, CodeNoProductsToDelete, "No products to delete")
+	default:
+		return errorAck(seq, CodeInternal, "Internal error")
+	}
+}
+
+func errorAck(seq uint64, code, message string) *dto.ScannerAck {
+	return &dto.ScannerAck{
+		Seq:   seq,
+		Error: &dto.ScannerError{Code: code, Message: message},
+	}
+}
+
+func (s *session) send(ack *dto.ScannerAck) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return s.conn.WriteJSON(ack)
+}
+
+func (s *session) closeNormally(reason string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+	s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+	s.logger.Info("scanner session closed")
+}
+
+// keepAlive пингует терминал, чтобы обнаружить оборванное соединение
+func (s *session) keepAlive(done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.writeMu.Lock()
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			s.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ackKey - терминал, которому принадлежит подтверждение
+type ackKey struct {
+	userID string
+	pvzID  string
+}
+
+type ackEntry struct {
+	seq     uint64
+	ack     *dto.ScannerAck
+	expires time.Time
+}
+
+// ackStore хранит последнее подтверждение каждого терминала в памяти процесса
+type ackStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[ackKey]ackEntry
+}
+
+func newAckStore(ttl time.Duration) *ackStore {
+	return &ackStore{
+		ttl:     ttl,
+		entries: make(map[ackKey]ackEntry),
+	}
+}
+
+// load возвращает последнее подтверждение терминала, если оно еще не истекло
+func (st *ackStore) load(key ackKey) (uint64, *dto.ScannerAck) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	entry, ok := st.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return 0, nil
+	}
+	return entry.seq, entry.ack
+}
+
+// save запоминает подтверждение и заодно удаляет истекшие записи других терминалов
+func (st *ackStore) save(key ackKey, seq uint64, ack *dto.ScannerAck) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range st.entries {
+		if now.After(entry.expires) {
+			delete(st.entries, k)
+		}
+	}
+	st.entries[key] = ackEntry{seq: seq, ack: ack, expires: now.Add(st.ttl)}
+}
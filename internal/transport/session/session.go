@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=session.go -destination=../../usecase/mocks/session_usecase_mock.go -package=mocks SessionUsecase
+type SessionUsecase interface {
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+}
+
+type SessionHandler struct {
+	uc SessionUsecase
+}
+
+func NewSessionHandler(uc SessionUsecase) *SessionHandler {
+	return &SessionHandler{uc: uc}
+}
+
+// ListMySessions - сессии текущего пользователя, сессия запроса отмечена current
+func (h *SessionHandler) ListMySessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	h.listSessions(w, r, "SessionHandler.ListMySessions", userID)
+}
+
+// RevokeMySession завершает одну из своих сессий, в том числе текущую
+func (h *SessionHandler) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	h.revokeSession(w, r, "SessionHandler.RevokeMySession", userID)
+}
+
+func (h *SessionHandler) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	h.listSessions(w, r, "SessionHandler.ListUserSessions", mux.Vars(r)["userId"])
+}
+
+func (h *SessionHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	h.revokeSession(w, r, "SessionHandler.RevokeUserSession", mux.Vars(r)["userId"])
+}
+
+func (h *SessionHandler) listSessions(w http.ResponseWriter, r *http.Request, op, userID string) {
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	currentSessionID, _ := authctx.GetSessionID(r.Context())
+	sessions, err := h.uc.ListSessions(r.Context(), userID, currentSessionID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to list sessions")
+		h.sendSessionError(r.Context(), w, err, "Failed to get sessions")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) revokeSession(w http.ResponseWriter, r *http.Request, op, userID string) {
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	if err := h.uc.RevokeSession(r.Context(), userID, mux.Vars(r)["sessionId"]); err != nil {
+		logger.WithError(err).Warn("failed to revoke session")
+		h.sendSessionError(r.Context(), w, err, "Failed to revoke session")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SessionHandler) sendSessionError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case errs.ErrInvalidUserID:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid user id")
+	case errs.ErrInvalidSessionID:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid session id")
+	case errs.ErrSessionNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Session not found")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
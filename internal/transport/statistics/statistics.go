@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=statistics.go -destination=../../usecase/mocks/statistics_usecase_mock.go -package=mocks StatisticsUsecase
+type StatisticsUsecase interface {
+	GetStats(ctx context.Context, groupBy string, startDate, endDate *time.Time) (*models.Report, error)
+}
+
+type StatisticsHandler struct {
+	uc StatisticsUsecase
+}
+
+func NewStatisticsHandler(uc StatisticsUsecase) *StatisticsHandler {
+	return &StatisticsHandler{uc: uc}
+}
+
+func (h *StatisticsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	const op = "StatisticsHandler.GetStats"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	query := r.URL.Query()
+
+	// В отличие от GET /pvz некорректная дата не игнорируется: отчет по неверному диапазону хуже ошибки
+	var startDate, endDate *time.Time
+	if startStr := query.Get("startDate"); startStr != "" {
+		t, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			logger.WithField("startDate", startStr).WithError(err).Warn("invalid startDate")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid startDate")
+			return
+		}
+		startDate = &t
+	}
+	if endStr := query.Get("endDate"); endStr != "" {
+		t, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			logger.WithField("endDate", endStr).WithError(err).Warn("invalid endDate")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid endDate")
+			return
+		}
+		endDate = &t
+	}
+
+	report, err := h.uc.GetStats(r.Context(), query.Get("groupBy"), startDate, endDate)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get stats")
+		switch err {
+		case errs.ErrInvalidStatsGroupBy:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid groupBy")
+		case errs.ErrInvalidDateRange:
+			response.SendError(r.Context(), w, http.StatusBadRequest, "startDate must not be after endDate")
+		default:
+			response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get stats")
+		}
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, report)
+}
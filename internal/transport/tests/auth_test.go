@@ -3,16 +3,19 @@ package tests
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	auth "github.com/nik-mLb/avito_task/internal/transport/auth"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
 	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
 )
 
 func TestAuthHandler_DummyLogin(t *testing.T) {
@@ -80,13 +83,14 @@ func TestAuthHandler_DummyLogin(t *testing.T) {
 
 func TestAuthHandler_Login(t *testing.T) {
 	tests := []struct {
-		name           string
-		requestBody    string
-		mockReturn     string
-		mockError      error
-		expectedStatus int
-		expectedBody   string
-		expectCookie   bool
+		name             string
+		requestBody      string
+		mockReturn       string
+		mockError        error
+		expectedStatus   int
+		expectedBody     string
+		expectCookie     bool
+		expectRetryAfter string
 	}{
 		{
 			name:           "successful login",
@@ -101,11 +105,40 @@ func TestAuthHandler_Login(t *testing.T) {
 			name:           "invalid credentials",
 			requestBody:    `{"login": "test@example.com", "password": "wrong"}`,
 			mockReturn:     "",
-			mockError:      errors.New("invalid credentials"),
+			mockError:      errs.ErrInvalidCredentials,
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   `{"message":"Incorrect data"}`,
 			expectCookie:   false,
 		},
+		{
+			name:             "login locked",
+			requestBody:      `{"login": "test@example.com", "password": "password"}`,
+			mockError:        &errs.LoginLockedError{RetryAfter: 1500 * time.Millisecond},
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedBody:     `{"message":"Too many login attempts"}`,
+			expectRetryAfter: "2",
+		},
+		{
+			name:           "deactivated user",
+			requestBody:    `{"login": "test@example.com", "password": "password"}`,
+			mockError:      errs.ErrUserDeactivated,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"User is deactivated"}`,
+		},
+		{
+			name:           "second factor required",
+			requestBody:    `{"login": "admin@example.com", "password": "password"}`,
+			mockError:      &errs.TwoFactorRequiredError{Challenge: "challenge", Enroll: true},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"challenge":"challenge","enroll":true}`,
+		},
+		{
+			name:           "internal error",
+			requestBody:    `{"login": "test@example.com", "password": "password"}`,
+			mockError:      errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to login"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,7 +154,7 @@ func TestAuthHandler_Login(t *testing.T) {
 				var req dto.LoginRequest
 				if err := json.Unmarshal([]byte(tt.requestBody), &req); err == nil {
 					mockUsecase.EXPECT().
-						Authenticate(gomock.Any(), req.Email, req.Password).
+						Authenticate(gomock.Any(), req.Email, req.Password, session.Client{IP: "192.0.2.1"}).
 						Return(tt.mockReturn, tt.mockError).
 						Times(1)
 				}
@@ -136,6 +169,9 @@ func TestAuthHandler_Login(t *testing.T) {
 			if resp.StatusCode != tt.expectedStatus {
 				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
 			}
+			if got := resp.Header.Get("Retry-After"); got != tt.expectRetryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tt.expectRetryAfter, got)
+			}
 
 			body := strings.TrimSpace(w.Body.String())
 			if body != tt.expectedBody {
@@ -190,6 +226,56 @@ func TestAuthHandler_Register(t *testing.T) {
 			expectedBody:   `{"message":"Invalid role"}`,
 			expectCookie:   false,
 		},
+		{
+			name:           "invalid email",
+			requestBody:    `{"email": "not-an-email", "password": "password1", "role": "worker"}`,
+			mockError:      errs.ErrInvalidEmail,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid email"}`,
+		},
+		{
+			name:           "weak password",
+			requestBody:    `{"email": "new@example.com", "password": "short", "role": "worker"}`,
+			mockError:      fmt.Errorf("%w: must be at least 8 characters long", errs.ErrWeakPassword),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"password does not meet the policy: must be at least 8 characters long"}`,
+		},
+		{
+			name:           "email already registered",
+			requestBody:    `{"email": "taken@example.com", "password": "password1", "role": "worker"}`,
+			mockError:      errs.ErrUserAlreadyExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"User already exists"}`,
+		},
+		{
+			name:           "registration by invitation",
+			requestBody:    `{"password": "password1", "invitation": "invite-token"}`,
+			mockReturn:     "reg_token",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"token":"reg_token"}`,
+			expectCookie:   true,
+		},
+		{
+			name:           "invalid invitation",
+			requestBody:    `{"password": "password1", "invitation": "used-token"}`,
+			mockError:      errs.ErrInvalidInvitation,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid or expired invitation"}`,
+		},
+		{
+			name:           "self-registration disabled",
+			requestBody:    `{"email": "new@example.com", "password": "password1", "role": "admin"}`,
+			mockError:      errs.ErrRegistrationClosed,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Registration is by invitation only"}`,
+		},
+		{
+			name:           "second factor enrollment required",
+			requestBody:    `{"email": "boss@example.com", "password": "password1", "role": "admin"}`,
+			mockError:      &errs.TwoFactorRequiredError{Challenge: "challenge", Enroll: true},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"challenge":"challenge","enroll":true}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -207,7 +293,7 @@ func TestAuthHandler_Register(t *testing.T) {
 			// Устанавливаем ожидания для мока, если usecase должен вызываться
 			if tt.mockError != nil || tt.mockReturn != "" {
 				mockUsecase.EXPECT().
-					Register(gomock.Any(), reqBody.Email, reqBody.Password, reqBody.Role).
+					Register(gomock.Any(), reqBody.Email, reqBody.Password, reqBody.Role, reqBody.Invitation, session.Client{IP: "192.0.2.1"}).
 					Return(tt.mockReturn, tt.mockError).
 					Times(1)
 			}
@@ -238,4 +324,73 @@ func TestAuthHandler_Register(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestAuthHandler_UnlockLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "unlocked",
+			requestBody:    `{"email": "test@example.com", "ip": "192.0.2.1"}`,
+			callUsecase:    true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "nothing to unlock",
+			requestBody:    `{}`,
+			callUsecase:    true,
+			mockError:      errs.ErrInvalidUnlockRequest,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Email or ip is required"}`,
+		},
+		{
+			name:           "internal error",
+			requestBody:    `{"email": "test@example.com"}`,
+			callUsecase:    true,
+			mockError:      errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to unlock login"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockAuthUsecase(ctrl)
+			h := auth.New(mockUsecase)
+
+			if tt.callUsecase {
+				var reqBody dto.UnlockLoginRequest
+				json.Unmarshal([]byte(tt.requestBody), &reqBody)
+				mockUsecase.EXPECT().
+					UnlockLogin(gomock.Any(), reqBody.Email, reqBody.IP).
+					Return(tt.mockError)
+			}
+
+			req := httptest.NewRequest("POST", "/users/unlock", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			h.UnlockLogin(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
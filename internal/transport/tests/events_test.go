@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nik-mLb/avito_task/internal/eventbus"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	events "github.com/nik-mLb/avito_task/internal/transport/events"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsHandler_Stream(t *testing.T) {
+	pvzID := uuid.New()
+	otherPvzID := uuid.New()
+
+	newBus := func(size int) *eventbus.Bus {
+		bus := eventbus.New(size)
+		bus.Publish(context.Background(), feed.Event{ID: 1, Type: history.ReceptionOpened, PickupPointID: pvzID})
+		bus.Publish(context.Background(), feed.Event{ID: 2, Type: history.ReceptionOpened, PickupPointID: otherPvzID})
+		bus.Publish(context.Background(), feed.Event{ID: 3, Type: history.ProductAdded, PickupPointID: pvzID})
+		bus.Publish(context.Background(), feed.Event{ID: 4, Type: history.ReceptionClosed, PickupPointID: pvzID})
+		return bus
+	}
+
+	tests := []struct {
+		name           string
+		bufferSize     int
+		query          string
+		lastEventID    string
+		scope          string
+		expectedStatus int
+		expectedIDs    []string
+		expectedReset  bool
+		expectedBody   string
+	}{
+		{
+			name:           "replay after Last-Event-ID",
+			bufferSize:     10,
+			lastEventID:    "2",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 3", "id: 4"},
+		},
+		{
+			name:           "replay filtered by pvz and type",
+			bufferSize:     10,
+			query:          "?pvzId=" + pvzID.String() + "&type=product_added,reception_closed",
+			lastEventID:    "1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 3", "id: 4"},
+		},
+		{
+			name:           "lastEventId query parameter",
+			bufferSize:     10,
+			query:          "?lastEventId=3",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 4"},
+		},
+		{
+			name:           "evicted events trigger reset",
+			bufferSize:     2,
+			lastEventID:    "1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 3", "id: 4"},
+			expectedReset:  true,
+		},
+		{
+			name:           "id not yet received triggers reset",
+			bufferSize:     10,
+			lastEventID:    "100",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 1", "id: 2", "id: 3", "id: 4"},
+			expectedReset:  true,
+		},
+		{
+			name:           "scoped user gets only own pickup point",
+			bufferSize:     10,
+			lastEventID:    "1",
+			scope:          pvzID.String(),
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"id: 3", "id: 4"},
+		},
+		{
+			name:           "scoped user cannot subscribe to another pickup point",
+			bufferSize:     10,
+			query:          "?pvzId=" + otherPvzID.String(),
+			scope:          pvzID.String(),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Access to this pickup point is denied"}`,
+		},
+		{
+			name:           "invalid pvzId",
+			bufferSize:     10,
+			query:          "?pvzId=invalid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid pvzId"}`,
+		},
+		{
+			name:           "invalid event type",
+			bufferSize:     10,
+			query:          "?type=product_added,unknown",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid event type"}`,
+		},
+		{
+			name:           "invalid Last-Event-ID",
+			bufferSize:     10,
+			lastEventID:    "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid Last-Event-ID"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := events.NewEventsHandler(newBus(tt.bufferSize), time.Minute)
+
+			// Контекст уже отменен: обработчик отдает буфер и сразу завершает поток
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if tt.scope != "" {
+				ctx = authctx.WithPickupPoint(ctx, tt.scope)
+			}
+
+			req := httptest.NewRequest("GET", "/events/stream"+tt.query, nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.Stream(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+				return
+			}
+
+			assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			body := rr.Body.String()
+			assert.Equal(t, tt.expectedReset, strings.HasPrefix(body, "event: reset\n"))
+
+			var ids []string
+			for _, line := range strings.Split(body, "\n") {
+				if strings.HasPrefix(line, "id: ") {
+					ids = append(ids, line)
+				}
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestEventsHandler_StreamKeepsOutboxIDs(t *testing.T) {
+	pvzID := uuid.New()
+	bus := eventbus.New(10)
+	// Номера outbox идут с пропусками, событие 8 закоммичено позже 9, а 9 пришло повторно
+	bus.Publish(context.Background(), feed.Event{ID: 7, Type: history.ReceptionOpened, PickupPointID: pvzID})
+	bus.Publish(context.Background(), feed.Event{ID: 9, Type: history.ProductAdded, PickupPointID: pvzID})
+	bus.Publish(context.Background(), feed.Event{ID: 8, Type: history.ProductAdded, PickupPointID: pvzID})
+	bus.Publish(context.Background(), feed.Event{ID: 9, Type: history.ProductAdded, PickupPointID: pvzID})
+	handler := events.NewEventsHandler(bus, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/events/stream", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "7")
+	rr := httptest.NewRecorder()
+
+	handler.Stream(rr, req)
+
+	body := rr.Body.String()
+	assert.False(t, strings.HasPrefix(body, "event: reset\n"))
+	assert.Equal(t, 2, strings.Count(body, "id: "))
+	assert.Less(t, strings.Index(body, "id: 9\n"), strings.Index(body, "id: 8\n"))
+}
+
+// flushRecorder сообщает о каждом Flush, чтобы тест мог дождаться отправки событий
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+}
+
+func (r *flushRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.flushed <- struct{}{}
+}
+
+func TestEventsHandler_StreamLive(t *testing.T) {
+	pvzID := uuid.New()
+	bus := eventbus.New(10)
+	handler := events.NewEventsHandler(bus, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := httptest.NewRequest("GET", "/events/stream?pvzId="+pvzID.String(), nil).WithContext(ctx)
+	rr := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{}, 1)}
+
+	done := make(chan struct{})
+	go func() {
+		handler.Stream(rr, req)
+		close(done)
+	}()
+
+	waitFlush := func() {
+		select {
+		case <-rr.flushed:
+		case <-time.After(time.Second):
+			t.Fatal("stream was not flushed")
+		}
+	}
+
+	// Первый Flush происходит после подписки, дальше события уже доходят до клиента
+	waitFlush()
+	bus.Publish(context.Background(), feed.Event{ID: 1, Type: history.ProductAdded, PickupPointID: uuid.New()})
+	bus.Publish(context.Background(), feed.Event{ID: 2, Type: history.ReceptionClosed, PickupPointID: pvzID})
+	waitFlush()
+
+	cancel()
+	<-done
+
+	body := rr.Body.String()
+	assert.Equal(t, 1, strings.Count(body, "id: "))
+	assert.Contains(t, body, "id: 2\nevent: reception_closed\n")
+	assert.Contains(t, body, `"pvzId":"`+pvzID.String()+`"`)
+}
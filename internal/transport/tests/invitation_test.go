@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	invitationt "github.com/nik-mLb/avito_task/internal/transport/invitation"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvitationHandler_CreateInvitation(t *testing.T) {
+	adminID := uuid.New().String()
+	pvzID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "created",
+			requestBody:    `{"email":"new@example.com","role":"worker","pvzId":"` + pvzID + `"}`,
+			callUsecase:    true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "role not allowed",
+			requestBody:    `{"email":"new@example.com","role":"worker","pvzId":"` + pvzID + `"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrRoleNotAllowed,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Role not allowed"}`,
+		},
+		{
+			name:           "pickup point not found",
+			requestBody:    `{"email":"new@example.com","role":"worker","pvzId":"` + pvzID + `"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrPickupPointNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Pickup point not found"}`,
+		},
+		{
+			name:           "email already registered",
+			requestBody:    `{"email":"new@example.com","role":"worker","pvzId":"` + pvzID + `"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrUserAlreadyExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"User already exists"}`,
+		},
+		{
+			name:           "internal server error",
+			requestBody:    `{"email":"new@example.com","role":"worker","pvzId":"` + pvzID + `"}`,
+			callUsecase:    true,
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to create invitation"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockInvitationUsecase(ctrl)
+			h := invitationt.NewInvitationHandler(mockUsecase)
+
+			if tt.callUsecase {
+				var inv *models.Invitation
+				if tt.mockError == nil {
+					inv = &models.Invitation{ID: uuid.New(), Email: "new@example.com", Role: "worker"}
+				}
+				mockUsecase.EXPECT().
+					CreateInvitation(gomock.Any(), adminID, "new@example.com", "worker", pvzID).
+					Return(inv, tt.mockError)
+			}
+
+			req := httptest.NewRequest("POST", "/invitations", strings.NewReader(tt.requestBody))
+			req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+			w := httptest.NewRecorder()
+
+			h.CreateInvitation(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInvitationHandler_RevokeInvitation(t *testing.T) {
+	id := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "revoked", expectedStatus: http.StatusNoContent},
+		{name: "invalid id", mockError: errs.ErrInvalidInvitationID, expectedStatus: http.StatusBadRequest},
+		{name: "not found", mockError: errs.ErrInvitationNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal server error", mockError: errors.New("some error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockInvitationUsecase(ctrl)
+			h := invitationt.NewInvitationHandler(mockUsecase)
+
+			mockUsecase.EXPECT().RevokeInvitation(gomock.Any(), id).Return(tt.mockError)
+
+			req := httptest.NewRequest("DELETE", "/invitations/"+id, nil)
+			req = mux.SetURLVars(req, map[string]string{"invitationId": id})
+			w := httptest.NewRecorder()
+
+			h.RevokeInvitation(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
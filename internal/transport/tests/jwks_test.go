@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nik-mLb/avito_task/config"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	jwks "github.com/nik-mLb/avito_task/internal/transport/jwks"
+	"github.com/nik-mLb/avito_task/internal/transport/jwt"
+)
+
+// writePEM сохраняет закрытый ключ в PKCS #8 и возвращает путь к файлу
+func writePEM(t *testing.T, name string, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), name+".pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func tokenKID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := new(gojwt.Parser).ParseUnverified(token, &jwt.JWTClaims{})
+	require.NoError(t, err)
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestTokenator_KeyRotation(t *testing.T) {
+	newKey := func(name string) string {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		return writePEM(t, name, key)
+	}
+	oldPath, currentPath, nextPath := newKey("old"), newKey("current"), newKey("next")
+	now := time.Now()
+
+	newTokenator := func(keys ...config.JWTKey) *jwt.Tokenator {
+		tokenator, err := jwt.NewTokenator(&config.JWTConfig{Algorithm: "RS256", TokenLifeSpan: 24 * time.Hour, Keys: keys})
+		require.NoError(t, err)
+		return tokenator
+	}
+
+	// Токен, выданный до ротации старым ключом
+	oldToken, err := newTokenator(config.JWTKey{ID: "old", Path: oldPath}).CreateJWT("user-1", "worker", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "old", tokenKID(t, oldToken))
+
+	rotated := newTokenator(
+		config.JWTKey{ID: "next", Path: nextPath, ActiveFrom: now.Add(time.Hour)},
+		config.JWTKey{ID: "old", Path: oldPath, ActiveFrom: now.Add(-48 * time.Hour)},
+		config.JWTKey{ID: "current", Path: currentPath, ActiveFrom: now.Add(-time.Hour)},
+	)
+
+	t.Run("new tokens are signed with the current key", func(t *testing.T) {
+		token, err := rotated.CreateJWT("user-2", "admin", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "current", tokenKID(t, token))
+
+		claims, err := rotated.ParseJWT(token)
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", claims.UserID)
+		assert.Equal(t, "admin", claims.Role)
+	})
+
+	t.Run("previous key verifies until its tokens expire", func(t *testing.T) {
+		claims, err := rotated.ParseJWT(oldToken)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.UserID)
+	})
+
+	t.Run("retired key no longer verifies", func(t *testing.T) {
+		retired := newTokenator(
+			config.JWTKey{ID: "old", Path: oldPath, ActiveFrom: now.Add(-72 * time.Hour)},
+			config.JWTKey{ID: "current", Path: currentPath, ActiveFrom: now.Add(-30 * time.Hour)},
+		)
+
+		_, err := retired.ParseJWT(oldToken)
+		assert.Error(t, err)
+
+		var ids []string
+		for _, key := range retired.PublicKeys() {
+			ids = append(ids, key.ID)
+		}
+		assert.Equal(t, []string{"current"}, ids)
+	})
+
+	t.Run("unknown kid and other algorithms are rejected", func(t *testing.T) {
+		_, err := newTokenator(config.JWTKey{ID: "current", Path: currentPath}).ParseJWT(oldToken)
+		assert.Error(t, err)
+
+		hmac, _ := jwt.NewTokenator(&config.JWTConfig{Signature: "secret", TokenLifeSpan: time.Hour})
+		hmacToken, err := hmac.CreateJWT("user-1", "admin", "", "")
+		require.NoError(t, err)
+		_, err = rotated.ParseJWT(hmacToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("jwks publishes scheduled and verifying keys", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+		w := httptest.NewRecorder()
+
+		jwks.NewJWKSHandler(rotated).JWKS(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+		var resp dto.JWKSResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Keys, 3)
+		for i, kid := range []string{"old", "current", "next"} {
+			assert.Equal(t, kid, resp.Keys[i].Kid)
+			assert.Equal(t, "RSA", resp.Keys[i].Kty)
+			assert.Equal(t, "RS256", resp.Keys[i].Alg)
+			assert.Equal(t, "AQAB", resp.Keys[i].E)
+			assert.NotEmpty(t, resp.Keys[i].N)
+		}
+	})
+}
+
+func TestTokenator_EdDSA(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tokenator, err := jwt.NewTokenator(&config.JWTConfig{
+		Algorithm:     "EdDSA",
+		TokenLifeSpan: time.Hour,
+		Keys:          []config.JWTKey{{ID: "ed-1", Path: writePEM(t, "ed", private)}},
+	})
+	require.NoError(t, err)
+
+	token, err := tokenator.CreateJWT("user-1", "worker", "", "")
+	require.NoError(t, err)
+	parsed, _, err := new(gojwt.Parser).ParseUnverified(token, &jwt.JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+	assert.Equal(t, "ed-1", parsed.Header["kid"])
+
+	claims, err := tokenator.ParseJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "worker", claims.Role)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	jwks.NewJWKSHandler(tokenator).JWKS(w, req)
+
+	var resp dto.JWKSResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Keys, 1)
+	assert.Equal(t, dto.JWK{Kty: "OKP", Kid: "ed-1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519",
+		X: gojwt.EncodeSegment(public)}, resp.Keys[0])
+}
+
+func TestTokenator_Config(t *testing.T) {
+	t.Run("hs256 publishes no keys", func(t *testing.T) {
+		tokenator, err := jwt.NewTokenator(&config.JWTConfig{Signature: "secret", TokenLifeSpan: time.Hour})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+		w := httptest.NewRecorder()
+		jwks.NewJWKSHandler(tokenator).JWKS(w, req)
+
+		assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+	})
+
+	t.Run("missing key file", func(t *testing.T) {
+		_, err := jwt.NewTokenator(&config.JWTConfig{
+			Algorithm: "RS256",
+			Keys:      []config.JWTKey{{ID: "k1", Path: filepath.Join(t.TempDir(), "missing.pem")}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("key of another type", func(t *testing.T) {
+		_, private, _ := ed25519.GenerateKey(rand.Reader)
+		_, err := jwt.NewTokenator(&config.JWTConfig{
+			Algorithm: "RS256",
+			Keys:      []config.JWTKey{{ID: "k1", Path: writePEM(t, "ed", private)}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("no active key yet", func(t *testing.T) {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		tokenator, err := jwt.NewTokenator(&config.JWTConfig{
+			Algorithm:     "RS256",
+			TokenLifeSpan: time.Hour,
+			Keys:          []config.JWTKey{{ID: "k1", Path: writePEM(t, "rsa", key), ActiveFrom: time.Now().Add(time.Hour)}},
+		})
+		require.NoError(t, err)
+
+		_, err = tokenator.CreateJWT("user-1", "worker", "", "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	password "github.com/nik-mLb/avito_task/internal/transport/password"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordHandler_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*mocks.MockPasswordUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "accepted",
+			requestBody: `{"email":"test@example.com"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ForgotPassword(gomock.Any(), "test@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `{"email":`,
+			mockSetup:      func(m *mocks.MockPasswordUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:        "invalid email",
+			requestBody: `{"email":"not-an-email"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ForgotPassword(gomock.Any(), "not-an-email").Return(errs.ErrInvalidEmail)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid email"}`,
+		},
+		{
+			name:        "usecase error",
+			requestBody: `{"email":"test@example.com"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ForgotPassword(gomock.Any(), "test@example.com").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to request password reset"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockPasswordUsecase(ctrl)
+			tt.mockSetup(mockUsecase)
+			h := password.NewPasswordHandler(mockUsecase)
+
+			req := httptest.NewRequest("POST", "/password/forgot", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			h.ForgotPassword(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPasswordHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*mocks.MockPasswordUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"token":"reset-token","password":"newpassword1"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "newpassword1").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `token`,
+			mockSetup:      func(m *mocks.MockPasswordUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:        "invalid or expired token",
+			requestBody: `{"token":"used-token","password":"newpassword1"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ResetPassword(gomock.Any(), "used-token", "newpassword1").Return(errs.ErrInvalidResetToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid or expired token"}`,
+		},
+		{
+			name:        "weak password",
+			requestBody: `{"token":"reset-token","password":"short"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "short").
+					Return(fmt.Errorf("%w: too short", errs.ErrWeakPassword))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "usecase error",
+			requestBody: `{"token":"reset-token","password":"newpassword1"}`,
+			mockSetup: func(m *mocks.MockPasswordUsecase) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "newpassword1").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to reset password"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockPasswordUsecase(ctrl)
+			tt.mockSetup(mockUsecase)
+			h := password.NewPasswordHandler(mockUsecase)
+
+			req := httptest.NewRequest("POST", "/password/reset", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			h.ResetPassword(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
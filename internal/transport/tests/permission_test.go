@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nik-mLb/avito_task/internal/permission"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	permissiont "github.com/nik-mLb/avito_task/internal/transport/permission"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		policy, err := permission.NewPolicy(permission.DefaultRoles)
+		require.NoError(t, err)
+
+		assert.True(t, policy.Allowed("admin", permission.PickupPointCreate))
+		assert.False(t, policy.Allowed("worker", permission.PickupPointCreate))
+		assert.True(t, policy.Allowed("worker", permission.ProductDelete))
+		assert.False(t, policy.Allowed("auditor", permission.StatsRead))
+	})
+
+	t.Run("unknown permission", func(t *testing.T) {
+		_, err := permission.NewPolicy(map[string][]permission.Permission{"auditor": {"stats:write"}})
+
+		assert.ErrorContains(t, err, `unknown permission "stats:write"`)
+	})
+
+	t.Run("permissions are sorted", func(t *testing.T) {
+		policy, err := permission.NewPolicy(map[string][]permission.Permission{
+			"auditor": {permission.StatsRead, permission.EventsRead, permission.StatsRead},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []permission.Permission{permission.EventsRead, permission.StatsRead}, policy.Permissions("auditor"))
+		assert.Empty(t, policy.Permissions("unknown"))
+	})
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	policy, err := permission.NewPolicy(map[string][]permission.Permission{
+		"admin":   {permission.StatsRead},
+		"auditor": {permission.StatsRead, permission.EventsRead},
+	})
+	require.NoError(t, err)
+
+	handler := middleware.PermissionMiddleware(policy, permission.StatsRead)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	tests := []struct {
+		name           string
+		role           string
+		withUser       bool
+		expectedStatus int
+	}{
+		{name: "allowed", role: "auditor", withUser: true, expectedStatus: http.StatusOK},
+		{name: "denied", role: "worker", withUser: true, expectedStatus: http.StatusForbidden},
+		{name: "no role in context", expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/stats", nil)
+			if tt.withUser {
+				req = req.WithContext(authctx.WithUser(req.Context(), "user-id", tt.role))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestPermissionHandler_Permissions(t *testing.T) {
+	policy, err := permission.NewPolicy(map[string][]permission.Permission{
+		"auditor": {permission.StatsRead, permission.EventsRead},
+	})
+	require.NoError(t, err)
+	h := permissiont.NewPermissionHandler(policy)
+
+	t.Run("known role", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/me/permissions", nil)
+		req = req.WithContext(authctx.WithUser(req.Context(), "user-id", "auditor"))
+		w := httptest.NewRecorder()
+
+		h.Permissions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"role":"auditor","permissions":["events:read","stats:read"]}`, w.Body.String())
+	})
+
+	t.Run("role without permissions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/me/permissions", nil)
+		req = req.WithContext(authctx.WithUser(req.Context(), "user-id", "guest"))
+		w := httptest.NewRecorder()
+
+		h.Permissions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"role":"guest","permissions":[]}`, w.Body.String())
+	})
+}
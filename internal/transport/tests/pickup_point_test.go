@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	pickup_point "github.com/nik-mLb/avito_task/internal/models/pickup_point"
 	product "github.com/nik-mLb/avito_task/internal/models/product"
@@ -20,6 +22,7 @@ import (
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
 	pickup "github.com/nik-mLb/avito_task/internal/transport/pickup_point"
 	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func normalizeJSONTime(jsonStr string) string {
@@ -225,6 +228,27 @@ func TestPickupPointHandler_GetPickupPointsWithReceptions(t *testing.T) {
             expectedStatus: http.StatusOK,
             expectedBody:   `[]`,
         },
+        {
+            name: "limit is not a number",
+            queryParams: map[string]string{
+                "limit": "many",
+            },
+            mockReturn:     nil,
+            mockError:      nil,
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   `{"message":"Invalid page or limit"}`,
+        },
+        {
+            name: "limit out of range",
+            queryParams: map[string]string{
+                "page":  "1",
+                "limit": "20000",
+            },
+            mockReturn:     nil,
+            mockError:      errs.ErrInvalidPagination,
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   `{"message":"Invalid page or limit"}`,
+        },
         {
             name: "internal server error",
             queryParams: map[string]string{
@@ -276,8 +300,18 @@ func TestPickupPointHandler_GetPickupPointsWithReceptions(t *testing.T) {
             // Setup mock expectation if we expect the usecase to be called
             if tt.mockError != nil || tt.mockReturn != nil {
                 mockUsecase.EXPECT().
-                    GetPickupPointsWithReceptions(gomock.Any(), expectedStartDate, expectedEndDate, expectedPage, expectedLimit).
-                    Return(tt.mockReturn, tt.mockError).
+                    StreamPickupPointsWithReceptions(gomock.Any(), expectedStartDate, expectedEndDate, expectedPage, expectedLimit, gomock.Any()).
+                    DoAndReturn(func(_ context.Context, _, _ *time.Time, _, _ int, fn func(dto.PickupPointListResponse) error) error {
+                        if tt.mockError != nil {
+                            return tt.mockError
+                        }
+                        for _, item := range tt.mockReturn {
+                            if err := fn(item); err != nil {
+                                return err
+                            }
+                        }
+                        return nil
+                    }).
                     Times(1)
             }
 
@@ -302,4 +336,180 @@ func TestPickupPointHandler_GetPickupPointsWithReceptions(t *testing.T) {
             compareJSON(t, tt.expectedBody, body)
         })
     }
-}
\ No newline at end of file
+}
+func TestPickupPointHandler_GetOccupancy(t *testing.T) {
+	pvzID := uuid.New()
+	capacity, available := 10, 4
+	occupancy := &pickup_point.Occupancy{
+		PickupPointID: pvzID,
+		Capacity:      &capacity,
+		Mode:          pickup_point.CapacityHard,
+		Occupied:      6,
+		Available:     &available,
+		ByType:        []pickup_point.TypeOccupancy{{ProductType: "обувь", Count: 6}},
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     *pickup_point.Occupancy
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			mockReturn:     occupancy,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"pvzId":"` + pvzID.String() + `","capacity":10,"mode":"hard","occupied":6,"available":4,"byType":[{"type":"обувь","count":6}]}`,
+		},
+		{
+			name:           "not found",
+			mockError:      errs.ErrPickupPointNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Pickup point not found"}`,
+		},
+		{
+			name:           "pickup point outside of user scope",
+			mockError:      errs.ErrPickupPointForbidden,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Access to this pickup point is denied"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockPickupPointUsecase(ctrl)
+			h := pickup.NewPickupPointHandler(mockUsecase)
+
+			mockUsecase.EXPECT().
+				GetOccupancy(gomock.Any(), pvzID.String()).
+				Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest("GET", "/pvz/"+pvzID.String()+"/occupancy", nil)
+			req = mux.SetURLVars(req, map[string]string{"pvzId": pvzID.String()})
+			w := httptest.NewRecorder()
+
+			h.GetOccupancy(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestPickupPointHandler_ExportPickupPoints(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	now := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+
+	rows := []pickup_point.ExportRow{
+		{
+			PickupPoint: pickup_point.PickupPoint{ID: pvzID, City: "Санкт-Петербург", RegistrationDate: "2025-04-01T00:00:00Z"},
+			Reception:   reception.Reception{ID: receptionID, ReceptionDate: now, PickupPointID: pvzID, Status: "close"},
+			Product:     &product.Product{ID: productID, ReceptionDate: now, ReceptionID: receptionID, ProductType: product.Electronics},
+		},
+	}
+	csvBody := "\xef\xbb\xbf" +
+		"pvz_id,city,pvz_registration_date,reception_id,reception_date,reception_status,product_id,product_type,product_date\n" +
+		pvzID.String() + ",Санкт-Петербург,2025-04-01T00:00:00Z," + receptionID.String() + ",2025-04-20T08:00:00Z,close," +
+		productID.String() + ",электроника,2025-04-20T08:00:00Z\n"
+	ndjsonBody := `{"pvz":{"id":"` + pvzID.String() + `","city":"Санкт-Петербург","registrationDate":"2025-04-01T00:00:00Z"},` +
+		`"reception":{"id":"` + receptionID.String() + `","dateTime":"2025-04-20T08:00:00Z","pvzId":"` + pvzID.String() + `","status":"close"},` +
+		`"product":{"id":"` + productID.String() + `","dateTime":"2025-04-20T08:00:00Z","receptionId":"` + receptionID.String() + `","type":"электроника"}}` + "\n"
+
+	tests := []struct {
+		name                string
+		url                 string
+		accept              string
+		callUsecase         bool
+		mockError           error
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "csv by default",
+			url:                 "/pvz/export",
+			callUsecase:         true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        csvBody,
+		},
+		{
+			name:                "ndjson from Accept",
+			url:                 "/pvz/export",
+			accept:              "application/x-ndjson",
+			callUsecase:         true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson; charset=utf-8",
+			expectedBody:        ndjsonBody,
+		},
+		{
+			name:                "query format overrides Accept",
+			url:                 "/pvz/export?format=csv",
+			accept:              "application/x-ndjson",
+			callUsecase:         true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        csvBody,
+		},
+		{
+			name:                "unsupported format",
+			url:                 "/pvz/export?format=xlsx",
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"message":"Unsupported export format"}`,
+		},
+		{
+			name:                "error before first row",
+			url:                 "/pvz/export",
+			callUsecase:         true,
+			mockError:           errors.New("some error"),
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"message":"Failed to export PickupPoints"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockPickupPointUsecase(ctrl)
+			handler := pickup.NewPickupPointHandler(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					ExportPickupPoints(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ *time.Time, fn func(pickup_point.ExportRow) error) error {
+						if tt.mockError != nil {
+							return tt.mockError
+						}
+						for _, row := range rows {
+							if err := fn(row); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ExportPickupPoints(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
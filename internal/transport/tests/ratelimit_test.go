@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nik-mLb/avito_task/config"
+	"github.com/nik-mLb/avito_task/internal/ratelimit"
+	"github.com/nik-mLb/avito_task/internal/transport/jwt"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	take := func(key string, limit ratelimit.Limit) ratelimit.Result {
+		results, err := store.Take(ctx, ratelimit.Bucket{Key: key, Limit: limit})
+		assert.NoError(t, err)
+		return results[0]
+	}
+
+	first := take("user:1", limit)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+
+	second := take("user:1", limit)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	third := take("user:1", limit)
+	assert.False(t, third.Allowed)
+	// Токен возвращается раз в 30 секунд
+	assert.InDelta(t, 30*time.Second, third.RetryAfter, float64(time.Second))
+	assert.InDelta(t, time.Minute, third.Reset, float64(time.Second))
+
+	other := take("user:2", limit)
+	assert.True(t, other.Allowed)
+
+	t.Run("bucket refills", func(t *testing.T) {
+		fast := ratelimit.Limit{Requests: 1, Period: 20 * time.Millisecond}
+
+		result := take("ip:192.0.2.1", fast)
+		assert.True(t, result.Allowed)
+		result = take("ip:192.0.2.1", fast)
+		assert.False(t, result.Allowed)
+
+		time.Sleep(25 * time.Millisecond)
+
+		result = take("ip:192.0.2.1", fast)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("rejected bucket keeps the others untouched", func(t *testing.T) {
+		single := ratelimit.Limit{Requests: 1, Period: time.Minute}
+		take("route:user:3", single)
+
+		results, err := store.Take(ctx,
+			ratelimit.Bucket{Key: "user:3", Limit: limit},
+			ratelimit.Bucket{Key: "route:user:3", Limit: single})
+		assert.NoError(t, err)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, 2, results[0].Remaining)
+		assert.False(t, results[1].Allowed)
+
+		assert.Equal(t, 1, take("user:3", limit).Remaining)
+	})
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, ...ratelimit.Bucket) ([]ratelimit.Result, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tokenator, _ := jwt.NewTokenator(&config.JWTConfig{Signature: "test-secret-key-123", TokenLifeSpan: time.Hour})
+	policy := ratelimit.Policy{
+		Default:   ratelimit.Limit{Requests: 3, Period: time.Minute},
+		Anonymous: ratelimit.Limit{Requests: 1, Period: time.Minute},
+		Roles:     map[string]ratelimit.Limit{"admin": {Requests: 5, Period: time.Minute}},
+		Routes:    map[string]ratelimit.Limit{"POST /products/{id}": {Requests: 2, Period: time.Minute}},
+	}
+
+	newRouter := func(store ratelimit.Store) *mux.Router {
+		router := mux.NewRouter()
+		router.Use(middleware.RateLimitMiddleware(store, policy, tokenator))
+		ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+		router.HandleFunc("/pvz", ok).Methods("GET")
+		router.HandleFunc("/products/{id}", ok).Methods("POST")
+		return router
+	}
+
+	send := func(router http.Handler, method, path, token, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	workerToken, _ := tokenator.CreateJWT("11111111-1111-1111-1111-111111111111", "worker", "", "")
+	otherToken, _ := tokenator.CreateJWT("22222222-2222-2222-2222-222222222222", "worker", "", "")
+	adminToken, _ := tokenator.CreateJWT("33333333-3333-3333-3333-333333333333", "admin", "", "")
+
+	t.Run("anonymous requests are limited by ip", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+
+		w := send(router, "GET", "/pvz", "", "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		w = send(router, "GET", "/pvz", "", "192.0.2.1:2000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.JSONEq(t, `{"message":"Too many requests"}`, w.Body.String())
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+		w = send(router, "GET", "/pvz", "", "192.0.2.2:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Недействительный токен не дает отдельной корзины
+		w = send(router, "GET", "/pvz", "invalid", "192.0.2.2:1000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("users are limited independently of ip", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+
+		for i := 0; i < 3; i++ {
+			w := send(router, "GET", "/pvz", workerToken, "192.0.2.1:1000")
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		w := send(router, "GET", "/pvz", workerToken, "192.0.2.9:1000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		w = send(router, "GET", "/pvz", otherToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("role limit", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+
+		w := send(router, "GET", "/pvz", adminToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "4", w.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("route limit applies on top of the user limit", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+
+		w := send(router, "POST", "/products/1", adminToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+		// Лимит маршрута считается по шаблону пути, а не по конкретному id
+		w = send(router, "POST", "/products/2", adminToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = send(router, "POST", "/products/3", adminToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+		w = send(router, "GET", "/pvz", adminToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("route rejection does not spend the user limit", func(t *testing.T) {
+		router := newRouter(ratelimit.NewMemoryStore())
+
+		for i := 0; i < 2; i++ {
+			w := send(router, "POST", "/products/1", workerToken, "192.0.2.1:1000")
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		for i := 0; i < 3; i++ {
+			w := send(router, "POST", "/products/1", workerToken, "192.0.2.1:1000")
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		}
+
+		// Из трех запросов в общий лимит worker засчитаны только два пропущенных
+		w := send(router, "GET", "/pvz", workerToken, "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("store failure lets requests through", func(t *testing.T) {
+		router := newRouter(failingStore{})
+
+		w := send(router, "GET", "/pvz", "", "192.0.2.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
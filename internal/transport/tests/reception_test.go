@@ -13,8 +13,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 	models "github.com/nik-mLb/avito_task/internal/models/reception"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 	reception "github.com/nik-mLb/avito_task/internal/transport/reception"
 	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
 )
@@ -51,6 +53,14 @@ func TestReceptionHandler_CreateReception(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `{"message":"Active reception already exists"}`,
 		},
+		{
+			name:           "pickup point outside of user scope",
+			requestBody:    `{"pvzId":"` + testReception.PickupPointID.String() + `"}`,
+			mockReturn:     nil,
+			mockError:      errs.ErrPickupPointForbidden,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Access to this pickup point is denied"}`,
+		},
 		{
 			name:           "internal server error",
 			requestBody:    `{"pvzId":"` + testReception.PickupPointID.String() + `"}`,
@@ -169,4 +179,192 @@ func TestReceptionHandler_CloseReception(t *testing.T) {
             }
 		})
 	}
-}
\ No newline at end of file
+}
+func TestReceptionHandler_ReopenReception(t *testing.T) {
+	testReception := &models.Reception{
+		ID:            uuid.New(),
+		ReceptionDate: time.Date(2025, 4, 1, 12, 30, 0, 0, time.UTC),
+		PickupPointID: uuid.New(),
+		Status:        "in_progress",
+	}
+	adminID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockReturn     *models.Reception
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful reopen",
+			requestBody:    `{"reason":"pallet left on the dock"}`,
+			callUsecase:    true,
+			mockReturn:     testReception,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"` + testReception.ID.String() + `","dateTime":"` + testReception.ReceptionDate.Format(time.RFC3339) + `","pvzId":"` + testReception.PickupPointID.String() + `","status":"in_progress"}`,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "window expired",
+			requestBody:    `{"reason":"late"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrReopenWindowExpired,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Reopen window has expired"}`,
+		},
+		{
+			name:           "reception not closed",
+			requestBody:    `{"reason":"late"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrReceptionNotClosed,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Reception is not closed"}`,
+		},
+		{
+			name:           "newer reception exists",
+			requestBody:    `{"reason":"late"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrNewerReceptionExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Pickup point has a newer reception"}`,
+		},
+		{
+			name:           "reception not found",
+			requestBody:    `{"reason":"late"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrReceptionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Reception not found"}`,
+		},
+		{
+			name:           "internal server error",
+			requestBody:    `{"reason":"late"}`,
+			callUsecase:    true,
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to reopen reception"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockReceptionUsecase(ctrl)
+			h := reception.NewReceptionHandler(mockUsecase)
+
+			if tt.callUsecase {
+				var body dto.ReopenReceptionRequest
+				_ = json.Unmarshal([]byte(tt.requestBody), &body)
+				mockUsecase.EXPECT().
+					ReopenReception(gomock.Any(), testReception.ID.String(), adminID, body.Reason).
+					Return(tt.mockReturn, tt.mockError).
+					Times(1)
+			}
+
+			req := httptest.NewRequest("POST", "/receptions/"+testReception.ID.String()+"/reopen", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"receptionId": testReception.ID.String()})
+			req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+			w := httptest.NewRecorder()
+
+			h.ReopenReception(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			body := strings.TrimSpace(w.Body.String())
+			if normalizeJSONTime(body) != normalizeJSONTime(tt.expectedBody) {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestReceptionHandler_GetReceptionHistory(t *testing.T) {
+	receptionID := uuid.New()
+	actorID := uuid.New()
+	createdAt := time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockReturn     []history.Event
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "successful history",
+			mockReturn: []history.Event{
+				{ID: 1, ReceptionID: receptionID, Type: history.ReceptionOpened, ActorID: &actorID, CreatedAt: createdAt},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"receptionId":"` + receptionID.String() + `","type":"reception_opened","actorId":"` + actorID.String() + `","createdAt":"2025-04-20T08:00:00Z"}]`,
+		},
+		{
+			name:           "invalid reception id",
+			mockError:      errs.ErrInvalidReceptionID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid reception id"}`,
+		},
+		{
+			name:           "reception not found",
+			mockError:      errs.ErrReceptionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Reception not found"}`,
+		},
+		{
+			name:           "reception of another pickup point",
+			mockError:      errs.ErrPickupPointForbidden,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Access to this pickup point is denied"}`,
+		},
+		{
+			name:           "internal server error",
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to get reception history"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockReceptionUsecase(ctrl)
+			h := reception.NewReceptionHandler(mockUsecase)
+
+			mockUsecase.EXPECT().
+				GetReceptionHistory(gomock.Any(), receptionID.String()).
+				Return(tt.mockReturn, tt.mockError).
+				Times(1)
+
+			req := httptest.NewRequest("GET", "/receptions/"+receptionID.String()+"/history", nil)
+			req = mux.SetURLVars(req, map[string]string{"receptionId": receptionID.String()})
+			w := httptest.NewRecorder()
+
+			h.GetReceptionHistory(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			body := strings.TrimSpace(w.Body.String())
+			if body != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
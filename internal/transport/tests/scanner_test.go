@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	product "github.com/nik-mLb/avito_task/internal/models/product"
+	reception "github.com/nik-mLb/avito_task/internal/models/reception"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	scanner "github.com/nik-mLb/avito_task/internal/transport/scanner"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerHandler_Session(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProducts := mocks.NewMockScannerProductUsecase(ctrl)
+	mockReceptions := mocks.NewMockScannerReceptionUsecase(ctrl)
+	handler := scanner.NewScannerHandler(mockProducts, mockReceptions)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.Session))
+	defer server.Close()
+
+	pvzID := uuid.New().String()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "?pvzId=" + pvzID
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	addedProduct := &product.Product{ID: uuid.New(), ProductType: product.Electronics}
+	closedReception := &reception.Reception{ID: uuid.New(), Status: "close"}
+
+	gomock.InOrder(
+		mockProducts.EXPECT().AddProduct(gomock.Any(), pvzID, "электроника").Return(addedProduct, nil),
+		mockProducts.EXPECT().AddProduct(gomock.Any(), pvzID, "мебель").
+			Return(nil, fmt.Errorf("%w: мебель", errs.ErrInvalidProductType)),
+		mockProducts.EXPECT().AddProduct(gomock.Any(), pvzID, "обувь").Return(nil, errs.ErrPickupPointFull),
+		mockProducts.EXPECT().DeleteLastProduct(gomock.Any(), pvzID).Return(nil),
+		mockProducts.EXPECT().DeleteLastProduct(gomock.Any(), pvzID).Return(errs.ErrNoProductsToDelete),
+		mockProducts.EXPECT().AddProduct(gomock.Any(), pvzID, "одежда").Return(nil, errors.New("db error")),
+		mockReceptions.EXPECT().CloseReception(gomock.Any(), pvzID).Return(closedReception, nil),
+	)
+
+	steps := []struct {
+		name        string
+		message     string
+		expectedSeq uint64
+		expectedOK  bool
+		expectedErr string
+	}{
+		{name: "scan", message: `{"seq":1,"type":"scan","productType":"электроника"}`, expectedSeq: 1, expectedOK: true},
+		{name: "duplicate seq is not executed again", message: `{"seq":1,"type":"scan","productType":"электроника"}`, expectedSeq: 1, expectedOK: true},
+		{name: "invalid product type", message: `{"seq":2,"type":"scan","productType":"мебель"}`, expectedSeq: 2, expectedErr: scanner.CodeInvalidProductType},
+		{name: "pvz full", message: `{"seq":3,"type":"scan","productType":"обувь"}`, expectedSeq: 3, expectedErr: scanner.CodePickupPointFull},
+		{name: "stale seq", message: `{"seq":2,"type":"undo"}`, expectedSeq: 2, expectedErr: scanner.CodeStaleSeq},
+		{name: "undo", message: `{"seq":4,"type":"undo"}`, expectedSeq: 4, expectedOK: true},
+		{name: "nothing to undo", message: `{"seq":5,"type":"undo"}`, expectedSeq: 5, expectedErr: scanner.CodeNoProductsToDelete},
+		{name: "internal error", message: `{"seq":6,"type":"scan","productType":"одежда"}`, expectedSeq: 6, expectedErr: scanner.CodeInternal},
+		{name: "unknown command", message: `{"seq":7,"type":"print"}`, expectedSeq: 7, expectedErr: scanner.CodeUnknownCommand},
+		{name: "missing seq", message: `{"type":"undo"}`, expectedSeq: 0, expectedErr: scanner.CodeInvalidMessage},
+		{name: "invalid json", message: `not json`, expectedSeq: 0, expectedErr: scanner.CodeInvalidMessage},
+		{name: "close", message: `{"seq":8,"type":"close"}`, expectedSeq: 8, expectedOK: true},
+	}
+
+	for _, step := range steps {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(step.message)), step.name)
+
+		var ack dto.ScannerAck
+		require.NoError(t, conn.ReadJSON(&ack), step.name)
+
+		assert.Equal(t, step.expectedSeq, ack.Seq, step.name)
+		assert.Equal(t, step.expectedOK, ack.OK, step.name)
+		if step.expectedErr != "" {
+			require.NotNil(t, ack.Error, step.name)
+			assert.Equal(t, step.expectedErr, ack.Error.Code, step.name)
+		} else {
+			assert.Nil(t, ack.Error, step.name)
+		}
+	}
+
+	// После закрытия приемки сервер завершает сессию
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "expected normal close, got %v", err)
+}
+
+func TestScannerHandler_SessionResumesAfterReconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProducts := mocks.NewMockScannerProductUsecase(ctrl)
+	handler := scanner.NewScannerHandler(mockProducts, mocks.NewMockScannerReceptionUsecase(ctrl))
+
+	userID := uuid.New().String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.Session(w, r.WithContext(authctx.WithUser(r.Context(), userID, "employee")))
+	}))
+	defer server.Close()
+
+	pvzID := uuid.New().String()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "?pvzId=" + pvzID
+
+	addedProduct := &product.Product{ID: uuid.New(), ProductType: product.Shoes}
+	mockProducts.EXPECT().AddProduct(gomock.Any(), pvzID, "обувь").Return(addedProduct, nil).Times(1)
+
+	send := func(conn *websocket.Conn, message string) dto.ScannerAck {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		var ack dto.ScannerAck
+		require.NoError(t, conn.ReadJSON(&ack))
+		return ack
+	}
+
+	first, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	ack := send(first, `{"seq":5,"type":"scan","productType":"обувь"}`)
+	assert.True(t, ack.OK)
+	first.Close()
+
+	// Терминал не получил подтверждение и повторяет команду в новой сессии
+	second, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer second.Close()
+
+	resent := send(second, `{"seq":5,"type":"scan","productType":"обувь"}`)
+	assert.True(t, resent.OK)
+	require.NotNil(t, resent.Product)
+	assert.Equal(t, addedProduct.ID, resent.Product.ID)
+
+	stale := send(second, `{"seq":1,"type":"undo"}`)
+	require.NotNil(t, stale.Error)
+	assert.Equal(t, scanner.CodeStaleSeq, stale.Error.Code)
+}
+
+func TestScannerHandler_SessionInvalidPvzID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := scanner.NewScannerHandler(mocks.NewMockScannerProductUsecase(ctrl), mocks.NewMockScannerReceptionUsecase(ctrl))
+
+	req := httptest.NewRequest("GET", "/scanner/session?pvzId=invalid", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Session(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.JSONEq(t, `{"message":"Invalid pvzId"}`, rr.Body.String())
+}
+
+func TestScannerHandler_SessionForeignPickupPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := scanner.NewScannerHandler(mocks.NewMockScannerProductUsecase(ctrl), mocks.NewMockScannerReceptionUsecase(ctrl))
+
+	req := httptest.NewRequest("GET", "/scanner/session?pvzId="+uuid.New().String(), nil)
+	req = req.WithContext(authctx.WithPickupPoint(req.Context(), uuid.New().String()))
+	rr := httptest.NewRecorder()
+
+	handler.Session(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.JSONEq(t, `{"message":"Access to this pickup point is denied"}`, rr.Body.String())
+}
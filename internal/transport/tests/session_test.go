@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	sessiont "github.com/nik-mLb/avito_task/internal/transport/session"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionHandler_ListMySessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	mockUsecase := mocks.NewMockSessionUsecase(ctrl)
+	h := sessiont.NewSessionHandler(mockUsecase)
+
+	mockUsecase.EXPECT().ListSessions(gomock.Any(), userID.String(), sessionID.String()).
+		Return([]models.Session{{ID: sessionID, UserID: userID, Device: "terminal-1", Current: true}}, nil)
+
+	req := httptest.NewRequest("GET", "/me/sessions", nil)
+	ctx := authctx.WithUser(req.Context(), userID.String(), "worker")
+	req = req.WithContext(authctx.WithSession(ctx, sessionID.String()))
+	w := httptest.NewRecorder()
+
+	h.ListMySessions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"current":true`)
+	assert.Contains(t, w.Body.String(), `"device":"terminal-1"`)
+}
+
+func TestSessionHandler_RevokeMySession(t *testing.T) {
+	userID := uuid.New().String()
+	sessionID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "revoked", expectedStatus: http.StatusNoContent},
+		{
+			name:           "invalid session id",
+			mockError:      errs.ErrInvalidSessionID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid session id"}`,
+		},
+		{
+			name:           "not found",
+			mockError:      errs.ErrSessionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Session not found"}`,
+		},
+		{
+			name:           "internal server error",
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to revoke session"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockSessionUsecase(ctrl)
+			h := sessiont.NewSessionHandler(mockUsecase)
+
+			mockUsecase.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(tt.mockError)
+
+			req := httptest.NewRequest("DELETE", "/me/sessions/"+sessionID, nil)
+			req = mux.SetURLVars(req, map[string]string{"sessionId": sessionID})
+			req = req.WithContext(authctx.WithUser(req.Context(), userID, "worker"))
+			w := httptest.NewRecorder()
+
+			h.RevokeMySession(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessionHandler_UserSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+	sessionID := uuid.New().String()
+	mockUsecase := mocks.NewMockSessionUsecase(ctrl)
+	h := sessiont.NewSessionHandler(mockUsecase)
+
+	t.Run("list", func(t *testing.T) {
+		mockUsecase.EXPECT().ListSessions(gomock.Any(), userID, "").Return([]models.Session{}, nil)
+
+		req := httptest.NewRequest("GET", "/users/"+userID+"/sessions", nil)
+		req = mux.SetURLVars(req, map[string]string{"userId": userID})
+		req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+		w := httptest.NewRecorder()
+
+		h.ListUserSessions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		mockUsecase.EXPECT().ListSessions(gomock.Any(), "not-a-uuid", "").Return(nil, errs.ErrInvalidUserID)
+
+		req := httptest.NewRequest("GET", "/users/not-a-uuid/sessions", nil)
+		req = mux.SetURLVars(req, map[string]string{"userId": "not-a-uuid"})
+		w := httptest.NewRecorder()
+
+		h.ListUserSessions(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mockUsecase.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(nil)
+
+		req := httptest.NewRequest("DELETE", "/users/"+userID+"/sessions/"+sessionID, nil)
+		req = mux.SetURLVars(req, map[string]string{"userId": userID, "sessionId": sessionID})
+		req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+		w := httptest.NewRecorder()
+
+		h.RevokeUserSession(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
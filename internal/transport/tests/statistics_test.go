@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+	statistics "github.com/nik-mLb/avito_task/internal/transport/statistics"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+)
+
+func TestStatisticsHandler_GetStats(t *testing.T) {
+	avg := 1800.0
+
+	tests := []struct {
+		name           string
+		query          string
+		callUsecase    bool
+		mockReturn     *models.Report
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful stats",
+			query:       "?groupBy=productType",
+			callUsecase: true,
+			mockReturn: &models.Report{
+				GroupBy: models.GroupByProductType,
+				Groups: []models.Group{
+					{Key: "обувь", Receptions: 2, Products: 6, ProductsPerReception: 3, DurationSeconds: models.Duration{Avg: &avg}},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"groupBy":"productType","groups":[{"key":"обувь","receptions":2,"products":6,"productsPerReception":3,"durationSeconds":{"avg":1800,"p50":null,"p90":null,"p95":null}}]}`,
+		},
+		{
+			name:           "invalid startDate",
+			query:          "?startDate=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid startDate"}`,
+		},
+		{
+			name:           "invalid grouping",
+			query:          "?groupBy=month",
+			callUsecase:    true,
+			mockError:      errs.ErrInvalidStatsGroupBy,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid groupBy"}`,
+		},
+		{
+			name:           "internal server error",
+			query:          "",
+			callUsecase:    true,
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to get stats"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockStatisticsUsecase(ctrl)
+			h := statistics.NewStatisticsHandler(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					GetStats(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(tt.mockReturn, tt.mockError).
+					Times(1)
+			}
+
+			req := httptest.NewRequest("GET", "/stats"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.GetStats(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			body := strings.TrimSpace(w.Body.String())
+			if body != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+func TestTraceRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	logger, hook := logtest.NewNullLogger()
+
+	router := mux.NewRouter()
+	router.Use(middleware.TraceRequest)
+	router.Use(func(next http.Handler) http.Handler {
+		return middleware.LogRequest(logger, next)
+	})
+	router.HandleFunc("/pvz/{pvzId}", func(w http.ResponseWriter, r *http.Request) {
+		const op = "PickupPointUsecase.GetOccupancy"
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		logctx.GetLogger(ctx).WithField("op", op).Info("inside usecase")
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest("GET", "/pvz/123", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	usecaseSpan, serverSpan := spans[0], spans[1]
+
+	// Серверный спан продолжает входящую трассу и назван по шаблону маршрута
+	assert.Equal(t, "GET /pvz/{pvzId}", serverSpan.Name())
+	assert.Equal(t, traceID, serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, parentSpanID, serverSpan.Parent().SpanID().String())
+	assert.True(t, serverSpan.Parent().IsRemote())
+
+	assert.Equal(t, "PickupPointUsecase.GetOccupancy", usecaseSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), usecaseSpan.Parent().SpanID())
+
+	// Во всех записях о запросе есть trace_id, а внутри спана usecase - еще и его span_id
+	entries := hook.AllEntries()
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		assert.Equal(t, traceID, entry.Data["trace_id"], entry.Message)
+	}
+	var usecaseEntry *logrus.Entry
+	for _, entry := range entries {
+		if entry.Message == "inside usecase" {
+			usecaseEntry = entry
+		}
+	}
+	require.NotNil(t, usecaseEntry)
+	assert.Equal(t, usecaseSpan.SpanContext().SpanID().String(), usecaseEntry.Data["span_id"])
+	assert.Equal(t, "PickupPointUsecase.GetOccupancy", usecaseEntry.Data["op"])
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	transfer "github.com/nik-mLb/avito_task/internal/transport/transfer"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+)
+
+func TestTransferHandler_CreateTransfer(t *testing.T) {
+	adminID := uuid.New().String()
+	fromPvz := uuid.New().String()
+	toPvz := uuid.New().String()
+	productID := uuid.New().String()
+	body := `{"fromPvzId":"` + fromPvz + `","toPvzId":"` + toPvz + `","productIds":["` + productID + `"]}`
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockReturn     *models.Transfer
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful creation",
+			requestBody:    body,
+			callUsecase:    true,
+			mockReturn:     &models.Transfer{ID: uuid.New(), Status: models.StatusCreated},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "products unavailable",
+			requestBody:    body,
+			callUsecase:    true,
+			mockError:      errs.ErrTransferProductsUnavailable,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "internal server error",
+			requestBody:    body,
+			callUsecase:    true,
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockTransferUsecase(ctrl)
+			h := transfer.NewTransferHandler(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					CreateTransfer(gomock.Any(), adminID, fromPvz, toPvz, []string{productID}).
+					Return(tt.mockReturn, tt.mockError).
+					Times(1)
+			}
+
+			req := httptest.NewRequest("POST", "/transfers", strings.NewReader(tt.requestBody))
+			req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+			w := httptest.NewRecorder()
+
+			h.CreateTransfer(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestTransferHandler_AcceptTransfer(t *testing.T) {
+	transferID := uuid.New().String()
+	pvzID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockReturn     *models.Transfer
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful accept",
+			mockReturn:     &models.Transfer{ID: uuid.MustParse(transferID), Status: models.StatusAccepted},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not dispatched",
+			mockError:      errs.ErrTransferStatusConflict,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Transfer is not in the required status"}`,
+		},
+		{
+			name:           "not found",
+			mockError:      errs.ErrTransferNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Transfer not found"}`,
+		},
+		{
+			name:           "destination is full",
+			mockError:      errs.ErrPickupPointFull,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Pickup point capacity exceeded"}`,
+		},
+		{
+			name:           "another pickup point",
+			mockError:      errs.ErrTransferWrongPickupPoint,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Transfer does not belong to this pickup point"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockTransferUsecase(ctrl)
+			h := transfer.NewTransferHandler(mockUsecase)
+
+			mockUsecase.EXPECT().
+				AcceptTransfer(gomock.Any(), pvzID, transferID).
+				Return(tt.mockReturn, tt.mockError).
+				Times(1)
+
+			req := httptest.NewRequest("POST", "/pvz/"+pvzID+"/transfers/"+transferID+"/accept", nil)
+			req = mux.SetURLVars(req, map[string]string{"pvzId": pvzID, "transferId": transferID})
+			w := httptest.NewRecorder()
+
+			h.AcceptTransfer(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tt.expectedBody {
+				t.Errorf("expected body %s, got %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	auth "github.com/nik-mLb/avito_task/internal/transport/auth"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthHandler_VerifyLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockToken      string
+		mockCodes      []string
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+		expectCookie   bool
+	}{
+		{
+			name:           "success",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockToken:      "auth_token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"auth_token"}`,
+			expectCookie:   true,
+		},
+		{
+			name:           "enrollment",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockToken:      "auth_token",
+			mockCodes:      []string{"AAAA-BBBB-CCCC-DDDD"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"auth_token","recoveryCodes":["AAAA-BBBB-CCCC-DDDD"]}`,
+			expectCookie:   true,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "invalid code",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrInvalidOTP,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"Invalid code"}`,
+		},
+		{
+			name:           "expired challenge",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrInvalidLoginChallenge,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"Invalid or expired login challenge"}`,
+		},
+		{
+			name:           "not set up",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrTwoFactorNotSetUp,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Two-factor authentication is not set up"}`,
+		},
+		{
+			name:           "internal error",
+			requestBody:    `{"challenge":"challenge","code":"123456","device":"terminal-1"}`,
+			callUsecase:    true,
+			mockError:      errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to login"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockAuthUsecase(ctrl)
+			h := auth.New(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					VerifyLogin(gomock.Any(), "challenge", "123456", session.Client{Device: "terminal-1", IP: "192.0.2.1", UserAgent: "scanner/1.0"}).
+					Return(tt.mockToken, tt.mockCodes, tt.mockError)
+			}
+
+			req := httptest.NewRequest("POST", "/login/2fa", strings.NewReader(tt.requestBody))
+			req.Header.Set("User-Agent", "scanner/1.0")
+			w := httptest.NewRecorder()
+
+			h.VerifyLogin(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectCookie, len(w.Result().Cookies()) > 0)
+		})
+	}
+}
+
+func TestAuthHandler_SetupLoginTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mocks.NewMockAuthUsecase(ctrl)
+	h := auth.New(mockUsecase)
+
+	mockUsecase.EXPECT().SetupLoginTwoFactor(gomock.Any(), "challenge").
+		Return(&models.TwoFactorSetup{Secret: "SECRET", URI: "otpauth://totp/x"}, nil)
+
+	req := httptest.NewRequest("POST", "/login/2fa/setup", strings.NewReader(`{"challenge":"challenge"}`))
+	w := httptest.NewRecorder()
+
+	h.SetupLoginTwoFactor(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"secret":"SECRET","uri":"otpauth://totp/x"}`, w.Body.String())
+}
+
+func TestAuthHandler_ManageTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New().String()
+	mockUsecase := mocks.NewMockAuthUsecase(ctrl)
+	h := auth.New(mockUsecase)
+
+	send := func(handler http.HandlerFunc, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req = req.WithContext(authctx.WithUser(req.Context(), userID, "worker"))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("setup", func(t *testing.T) {
+		mockUsecase.EXPECT().SetupTwoFactor(gomock.Any(), userID).
+			Return(&models.TwoFactorSetup{Secret: "SECRET", URI: "otpauth://totp/x"}, nil)
+
+		w := send(h.SetupTwoFactor, "/me/2fa/setup", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("setup when enabled", func(t *testing.T) {
+		mockUsecase.EXPECT().SetupTwoFactor(gomock.Any(), userID).Return(nil, errs.ErrTwoFactorAlreadyEnabled)
+
+		w := send(h.SetupTwoFactor, "/me/2fa/setup", "")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		mockUsecase.EXPECT().EnableTwoFactor(gomock.Any(), userID, "123456").
+			Return([]string{"AAAA-BBBB-CCCC-DDDD"}, nil)
+
+		w := send(h.EnableTwoFactor, "/me/2fa/enable", `{"code":"123456"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"recoveryCodes":["AAAA-BBBB-CCCC-DDDD"]}`, w.Body.String())
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		mockUsecase.EXPECT().DisableTwoFactor(gomock.Any(), userID, "123456").Return(nil)
+
+		w := send(h.DisableTwoFactor, "/me/2fa/disable", `{"code":"123456"}`)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("disable when mandatory", func(t *testing.T) {
+		mockUsecase.EXPECT().DisableTwoFactor(gomock.Any(), userID, "123456").Return(errs.ErrTwoFactorMandatory)
+
+		w := send(h.DisableTwoFactor, "/me/2fa/disable", `{"code":"123456"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	usert "github.com/nik-mLb/avito_task/internal/transport/user"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	active := false
+
+	tests := []struct {
+		name           string
+		url            string
+		callUsecase    bool
+		expectedFilter models.UserFilter
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "defaults",
+			url:            "/users",
+			callUsecase:    true,
+			expectedFilter: models.UserFilter{Page: 1, Limit: 20},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "filters and pagination",
+			url:            "/users?query=ivan&role=worker&active=false&page=3&limit=50",
+			callUsecase:    true,
+			expectedFilter: models.UserFilter{Query: "ivan", Role: "worker", Active: &active, Page: 3, Limit: 50},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid active",
+			url:            "/users?active=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "unknown role",
+			url:            "/users?role=client",
+			callUsecase:    true,
+			expectedFilter: models.UserFilter{Role: "client", Page: 1, Limit: 20},
+			mockError:      errs.ErrRoleNotAllowed,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Role not allowed"}`,
+		},
+		{
+			name:           "internal server error",
+			url:            "/users",
+			callUsecase:    true,
+			expectedFilter: models.UserFilter{Page: 1, Limit: 20},
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to get users"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockUserUsecase(ctrl)
+			h := usert.NewUserHandler(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					ListUsers(gomock.Any(), tt.expectedFilter).
+					Return([]models.User{}, tt.mockError)
+			}
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+
+			h.ListUsers(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_GetUser(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		mockReturn     *models.User
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "found", mockReturn: &models.User{ID: userID, Role: "worker", Active: true}, expectedStatus: http.StatusOK},
+		{name: "invalid id", mockError: errs.ErrInvalidUserID, expectedStatus: http.StatusBadRequest},
+		{name: "not found", mockError: errs.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal server error", mockError: errors.New("some error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockUserUsecase(ctrl)
+			h := usert.NewUserHandler(mockUsecase)
+
+			mockUsecase.EXPECT().GetUser(gomock.Any(), userID.String()).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest("GET", "/users/"+userID.String(), nil)
+			req = mux.SetURLVars(req, map[string]string{"userId": userID.String()})
+			w := httptest.NewRecorder()
+
+			h.GetUser(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_ChangeRole(t *testing.T) {
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "changed",
+			requestBody:    `{"role":"admin"}`,
+			callUsecase:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "role not allowed",
+			requestBody:    `{"role":"admin"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrRoleNotAllowed,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Role not allowed"}`,
+		},
+		{
+			name:           "own account",
+			requestBody:    `{"role":"admin"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrSelfModification,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Cannot change own role or status"}`,
+		},
+		{
+			name:           "not found",
+			requestBody:    `{"role":"admin"}`,
+			callUsecase:    true,
+			mockError:      errs.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"User not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockUserUsecase(ctrl)
+			h := usert.NewUserHandler(mockUsecase)
+
+			if tt.callUsecase {
+				var user *models.User
+				if tt.mockError == nil {
+					user = &models.User{Role: "admin", Active: true}
+				}
+				mockUsecase.EXPECT().ChangeRole(gomock.Any(), adminID, userID, "admin").Return(user, tt.mockError)
+			}
+
+			req := httptest.NewRequest("PUT", "/users/"+userID+"/role", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"userId": userID})
+			req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+			w := httptest.NewRecorder()
+
+			h.ChangeRole(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_DeactivateAndReactivate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockUsecase := mocks.NewMockUserUsecase(ctrl)
+	h := usert.NewUserHandler(mockUsecase)
+
+	send := func(handler http.HandlerFunc, action string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/users/"+userID+"/"+action, nil)
+		req = mux.SetURLVars(req, map[string]string{"userId": userID})
+		req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("deactivate", func(t *testing.T) {
+		mockUsecase.EXPECT().SetActive(gomock.Any(), adminID, userID, false).
+			Return(&models.User{Role: "worker"}, nil)
+
+		w := send(h.Deactivate, "deactivate")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"active":false`)
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		mockUsecase.EXPECT().SetActive(gomock.Any(), adminID, userID, true).
+			Return(&models.User{Role: "worker", Active: true}, nil)
+
+		w := send(h.Reactivate, "reactivate")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"active":true`)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		mockUsecase.EXPECT().SetActive(gomock.Any(), adminID, userID, false).
+			Return(nil, errs.ErrSelfModification)
+
+		w := send(h.Deactivate, "deactivate")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
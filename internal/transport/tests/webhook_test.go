@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	webhook "github.com/nik-mLb/avito_task/internal/transport/webhook"
+	"github.com/nik-mLb/avito_task/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookHandler_CreateSubscription(t *testing.T) {
+	adminID := uuid.New().String()
+	pvzID := uuid.New().String()
+	body := `{"url":"https://partner.example/hook","eventTypes":["reception_closed"],"pvzId":"` + pvzID + `"}`
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		callUsecase    bool
+		mockReturn     *models.Subscription
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful creation",
+			requestBody:    body,
+			callUsecase:    true,
+			mockReturn:     &models.Subscription{ID: uuid.New(), Secret: "generated"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "invalid subscription",
+			requestBody:    body,
+			callUsecase:    true,
+			mockError:      errs.ErrInvalidWebhook,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid request"}`,
+		},
+		{
+			name:           "pickup point not found",
+			requestBody:    body,
+			callUsecase:    true,
+			mockError:      errs.ErrPickupPointNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Pickup point not found"}`,
+		},
+		{
+			name:           "internal server error",
+			requestBody:    body,
+			callUsecase:    true,
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Failed to create webhook"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockWebhookUsecase(ctrl)
+			h := webhook.NewWebhookHandler(mockUsecase)
+
+			if tt.callUsecase {
+				mockUsecase.EXPECT().
+					CreateSubscription(gomock.Any(), adminID, "https://partner.example/hook", []string{"reception_closed"}, pvzID, "").
+					Return(tt.mockReturn, tt.mockError)
+			}
+
+			req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(tt.requestBody))
+			req = req.WithContext(authctx.WithUser(req.Context(), adminID, "admin"))
+			w := httptest.NewRecorder()
+
+			h.CreateSubscription(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_DeleteSubscription(t *testing.T) {
+	webhookID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "deleted", expectedStatus: http.StatusNoContent},
+		{name: "invalid id", mockError: errs.ErrInvalidWebhook, expectedStatus: http.StatusBadRequest},
+		{name: "not found", mockError: errs.ErrWebhookNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal server error", mockError: errors.New("some error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockWebhookUsecase(ctrl)
+			h := webhook.NewWebhookHandler(mockUsecase)
+
+			mockUsecase.EXPECT().DeleteSubscription(gomock.Any(), webhookID).Return(tt.mockError)
+
+			req := httptest.NewRequest("DELETE", "/webhooks/"+webhookID, nil)
+			req = mux.SetURLVars(req, map[string]string{"webhookId": webhookID})
+			w := httptest.NewRecorder()
+
+			h.DeleteSubscription(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := mocks.NewMockWebhookUsecase(ctrl)
+	h := webhook.NewWebhookHandler(mockUsecase)
+	webhookID := uuid.New().String()
+
+	mockUsecase.EXPECT().
+		ListDeliveries(gomock.Any(), webhookID).
+		Return([]models.Delivery{{ID: 1, Status: models.DeliveryFailed, Attempts: 8, LastError: "unexpected status 500"}}, nil)
+
+	req := httptest.NewRequest("GET", "/webhooks/"+webhookID+"/deliveries", nil)
+	req = mux.SetURLVars(req, map[string]string{"webhookId": webhookID})
+	w := httptest.NewRecorder()
+
+	h.ListDeliveries(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"failed"`)
+	assert.Contains(t, w.Body.String(), `"lastError":"unexpected status 500"`)
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockReturn     *models.Delivery
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "redelivery queued",
+			mockReturn:     &models.Delivery{ID: 6, Status: models.DeliveryPending},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "delivery not found",
+			mockError:      errs.ErrWebhookDeliveryNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Delivery not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := mocks.NewMockWebhookUsecase(ctrl)
+			h := webhook.NewWebhookHandler(mockUsecase)
+
+			mockUsecase.EXPECT().Redeliver(gomock.Any(), "5").Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest("POST", "/webhooks/deliveries/5/redeliver", nil)
+			req = mux.SetURLVars(req, map[string]string{"deliveryId": "5"})
+			w := httptest.NewRecorder()
+
+			h.Redeliver(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=transfer.go -destination=../../usecase/mocks/transfer_usecase_mock.go -package=mocks TransferUsecase
+type TransferUsecase interface {
+	CreateTransfer(ctx context.Context, userID, fromPvzID, toPvzID string, productIDs []string) (*models.Transfer, error)
+	DispatchTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error)
+	AcceptTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error)
+	ListTransfers(ctx context.Context, pvzID, status string) ([]models.Transfer, error)
+}
+
+type TransferHandler struct {
+	uc TransferUsecase
+}
+
+func NewTransferHandler(uc TransferUsecase) *TransferHandler {
+	return &TransferHandler{uc: uc}
+}
+
+func (h *TransferHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	const op = "TransferHandler.CreateTransfer"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	transfer, err := h.uc.CreateTransfer(r.Context(), userID, req.FromPickupPointID, req.ToPickupPointID, req.ProductIDs)
+	if err != nil {
+		logger.WithError(err).Warn("failed to create transfer")
+		h.sendTransferError(r.Context(), w, err, "Failed to create transfer")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusCreated, transfer)
+}
+
+func (h *TransferHandler) DispatchTransfer(w http.ResponseWriter, r *http.Request) {
+	const op = "TransferHandler.DispatchTransfer"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	transfer, err := h.uc.DispatchTransfer(r.Context(), mux.Vars(r)["pvzId"], mux.Vars(r)["transferId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to dispatch transfer")
+		h.sendTransferError(r.Context(), w, err, "Failed to dispatch transfer")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) AcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	const op = "TransferHandler.AcceptTransfer"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	transfer, err := h.uc.AcceptTransfer(r.Context(), mux.Vars(r)["pvzId"], mux.Vars(r)["transferId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to accept transfer")
+		h.sendTransferError(r.Context(), w, err, "Failed to accept transfer")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	const op = "TransferHandler.ListTransfers"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	transfers, err := h.uc.ListTransfers(r.Context(), mux.Vars(r)["pvzId"], r.URL.Query().Get("status"))
+	if err != nil {
+		logger.WithError(err).Warn("failed to list transfers")
+		h.sendTransferError(r.Context(), w, err, "Failed to get transfers")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, transfers)
+}
+
+func (h *TransferHandler) sendTransferError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case errs.ErrPickupPointForbidden:
+		response.SendError(ctx, w, http.StatusForbidden, "Access to this pickup point is denied")
+	case errs.ErrInvalidTransferRequest:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid request")
+	case errs.ErrTransferSamePickupPoint:
+		response.SendError(ctx, w, http.StatusBadRequest, "Source and destination pickup points are the same")
+	case errs.ErrTransferProductsUnavailable:
+		response.SendError(ctx, w, http.StatusBadRequest, "Some products are not available for transfer")
+	case errs.ErrPickupPointNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Pickup point not found")
+	case errs.ErrPickupPointFull:
+		response.SendError(ctx, w, http.StatusConflict, "Pickup point capacity exceeded")
+	case errs.ErrTransferNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Transfer not found")
+	case errs.ErrTransferStatusConflict:
+		response.SendError(ctx, w, http.StatusConflict, "Transfer is not in the required status")
+	case errs.ErrTransferWrongPickupPoint:
+		response.SendError(ctx, w, http.StatusForbidden, "Transfer does not belong to this pickup point")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
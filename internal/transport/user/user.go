@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=user.go -destination=../../usecase/mocks/user_usecase_mock.go -package=mocks UserUsecase
+type UserUsecase interface {
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	ChangeRole(ctx context.Context, actorID, userID, role string) (*models.User, error)
+	SetActive(ctx context.Context, actorID, userID string, active bool) (*models.User, error)
+}
+
+type UserHandler struct {
+	uc UserUsecase
+}
+
+func NewUserHandler(uc UserUsecase) *UserHandler {
+	return &UserHandler{uc: uc}
+}
+
+// ListUsers поддерживает фильтры query (подстрока email), role и active и пагинацию page и limit
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	const op = "UserHandler.ListUsers"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	query := r.URL.Query()
+	filter := models.UserFilter{
+		Query: query.Get("query"),
+		Role:  query.Get("role"),
+	}
+
+	if raw := query.Get("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			logger.WithError(err).Warn("invalid active filter")
+			response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+		filter.Active = &active
+	}
+
+	filter.Page, _ = strconv.Atoi(query.Get("page"))
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	users, err := h.uc.ListUsers(r.Context(), filter)
+	if err != nil {
+		logger.WithError(err).Warn("failed to list users")
+		h.sendUserError(r.Context(), w, err, "Failed to get users")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, users)
+}
+
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	const op = "UserHandler.GetUser"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	user, err := h.uc.GetUser(r.Context(), mux.Vars(r)["userId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to get user")
+		h.sendUserError(r.Context(), w, err, "Failed to get user")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, user)
+}
+
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	const op = "UserHandler.ChangeRole"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	actorID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	user, err := h.uc.ChangeRole(r.Context(), actorID, mux.Vars(r)["userId"], req.Role)
+	if err != nil {
+		logger.WithError(err).Warn("failed to change role")
+		h.sendUserError(r.Context(), w, err, "Failed to change role")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *UserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	const op = "UserHandler.SetActive"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op).WithField("active", active)
+
+	actorID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	user, err := h.uc.SetActive(r.Context(), actorID, mux.Vars(r)["userId"], active)
+	if err != nil {
+		logger.WithError(err).Warn("failed to change user status")
+		h.sendUserError(r.Context(), w, err, "Failed to change user status")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, user)
+}
+
+func (h *UserHandler) sendUserError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, errs.ErrInvalidUserID):
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid user id")
+	case errors.Is(err, errs.ErrRoleNotAllowed):
+		response.SendError(ctx, w, http.StatusBadRequest, "Role not allowed")
+	case errors.Is(err, errs.ErrUserNotFound):
+		response.SendError(ctx, w, http.StatusNotFound, "User not found")
+	case errors.Is(err, errs.ErrSelfModification):
+		response.SendError(ctx, w, http.StatusConflict, "Cannot change own role or status")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
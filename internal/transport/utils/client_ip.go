@@ -0,0 +1,16 @@
+package response
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP возвращает адрес клиента из соединения. Заголовки прокси не учитываются,
+// так как клиент может подставить в них любое значение
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,86 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// streamFlushEvery - через сколько элементов отдавать накопленное клиенту
+const streamFlushEvery = 50
+
+// JSONArrayStream пишет JSON-массив поэлементно, не собирая его в памяти.
+// Статус и заголовки отправляются вместе с первым элементом (или при Close для пустого массива),
+// поэтому пока Started возвращает false, вместо потока еще можно отправить SendError
+type JSONArrayStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	status  int
+	started bool
+	count   int
+}
+
+func NewJSONArrayStream(w http.ResponseWriter, status int) *JSONArrayStream {
+	flusher, _ := w.(http.Flusher)
+	return &JSONArrayStream{
+		w:       w,
+		flusher: flusher,
+		status:  status,
+	}
+}
+
+func (s *JSONArrayStream) Started() bool {
+	return s.started
+}
+
+func (s *JSONArrayStream) Write(item any) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if !s.started {
+		s.start()
+		sep = "["
+	}
+	if _, err := io.WriteString(s.w, sep); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+
+	s.count++
+	if s.count%streamFlushEvery == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+// Close закрывает массив; для пустого потока отправляет []
+func (s *JSONArrayStream) Close() error {
+	closing := "]"
+	if !s.started {
+		s.start()
+		closing = "[]"
+	}
+	if _, err := io.WriteString(s.w, closing); err != nil {
+		return err
+	}
+
+	s.flush()
+	return nil
+}
+
+func (s *JSONArrayStream) start() {
+	s.started = true
+	s.w.Header().Set("Content-Type", "application/json")
+	s.w.WriteHeader(s.status)
+}
+
+func (s *JSONArrayStream) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
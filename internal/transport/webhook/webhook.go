@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	response "github.com/nik-mLb/avito_task/internal/transport/utils"
+)
+
+//go:generate mockgen -source=webhook.go -destination=../../usecase/mocks/webhook_usecase_mock.go -package=mocks WebhookUsecase
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, userID, url string, eventTypes []string, pvzID, secret string) (*models.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.Subscription, error)
+	DeleteSubscription(ctx context.Context, webhookID string) error
+	ListDeliveries(ctx context.Context, webhookID string) ([]models.Delivery, error)
+	Redeliver(ctx context.Context, deliveryID string) (*models.Delivery, error)
+}
+
+type WebhookHandler struct {
+	uc WebhookUsecase
+}
+
+func NewWebhookHandler(uc WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	const op = "WebhookHandler.CreateSubscription"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	userID, ok := authctx.GetUserID(r.Context())
+	if !ok {
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "User not found in context")
+		return
+	}
+
+	var req dto.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.WithError(err).Warn("invalid request body")
+		response.SendError(r.Context(), w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	sub, err := h.uc.CreateSubscription(r.Context(), userID, req.URL, req.EventTypes, req.PickupPointID, req.Secret)
+	if err != nil {
+		logger.WithError(err).Warn("failed to create webhook")
+		h.sendWebhookError(r.Context(), w, err, "Failed to create webhook")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	const op = "WebhookHandler.ListSubscriptions"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	subs, err := h.uc.ListSubscriptions(r.Context())
+	if err != nil {
+		logger.WithError(err).Warn("failed to list webhooks")
+		response.SendError(r.Context(), w, http.StatusInternalServerError, "Failed to get webhooks")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	const op = "WebhookHandler.DeleteSubscription"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	if err := h.uc.DeleteSubscription(r.Context(), mux.Vars(r)["webhookId"]); err != nil {
+		logger.WithError(err).Warn("failed to delete webhook")
+		h.sendWebhookError(r.Context(), w, err, "Failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	const op = "WebhookHandler.ListDeliveries"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	deliveries, err := h.uc.ListDeliveries(r.Context(), mux.Vars(r)["webhookId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to list deliveries")
+		h.sendWebhookError(r.Context(), w, err, "Failed to get deliveries")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	const op = "WebhookHandler.Redeliver"
+	logger := logctx.GetLogger(r.Context()).WithField("op", op)
+
+	delivery, err := h.uc.Redeliver(r.Context(), mux.Vars(r)["deliveryId"])
+	if err != nil {
+		logger.WithError(err).Warn("failed to redeliver")
+		h.sendWebhookError(r.Context(), w, err, "Failed to redeliver")
+		return
+	}
+
+	response.SendJSONResponse(r.Context(), w, http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) sendWebhookError(ctx context.Context, w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case errs.ErrInvalidWebhook:
+		response.SendError(ctx, w, http.StatusBadRequest, "Invalid request")
+	case errs.ErrPickupPointNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Pickup point not found")
+	case errs.ErrWebhookNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Webhook not found")
+	case errs.ErrWebhookDeliveryNotFound:
+		response.SendError(ctx, w, http.StatusNotFound, "Delivery not found")
+	default:
+		response.SendError(ctx, w, http.StatusInternalServerError, fallback)
+	}
+}
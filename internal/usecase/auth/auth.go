@@ -2,79 +2,420 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
 	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/tracing"
 	"github.com/nik-mLb/avito_task/internal/transport/jwt"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var (
-	allowedRoles = map[string]bool{
-		"worker":          true,
-		"admin": 		   true,
-	}
-)
+// RoleSet - роли, которые можно назначить пользователю. Это роли из политики прав (ROLE_PERMISSIONS)
+type RoleSet interface {
+	HasRole(role string) bool
+}
 
 //go:generate mockgen -source=auth.go -destination=../../repository/mocks/auth_repository_mock.go -package=mocks AuthRepository
 type AuthRepository interface {
 	CreateUser(ctx context.Context, email string, passwordHash []byte, role string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetLoginThrottle(ctx context.Context, email, ip string, window time.Duration) (models.LoginThrottle, error)
+	RecordLoginFailure(ctx context.Context, scope models.LoginScope, key string, maxFailures int, window, lockout time.Duration) error
+	ResetLoginFailures(ctx context.Context, scope models.LoginScope, key string) error
+	GetTokenState(ctx context.Context, userID uuid.UUID) (*models.TokenState, error)
+	CreateUserFromInvitation(ctx context.Context, tokenHash, passwordHash []byte) (*models.User, error)
+	CreateLoginChallenge(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error
+	UseLoginChallenge(ctx context.Context, tokenHash []byte, maxAttempts int) (*models.TwoFactor, error)
+	DeleteLoginChallenge(ctx context.Context, tokenHash []byte) error
+	GetTwoFactor(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error)
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) (bool, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes [][]byte) error
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID) error
+	CreateSession(ctx context.Context, userID uuid.UUID, client session.Client) (uuid.UUID, error)
+	TouchSession(ctx context.Context, sessionID, userID uuid.UUID, interval time.Duration) (bool, error)
+}
+
+// LoginPolicy защита входа от перебора. Неудачи считаются отдельно по email и по IP в пределах Window.
+// Каждая неудача удваивает задержку перед проверкой пароля (от DelayBase до DelayMax),
+// а после MaxFailures (MaxIPFailures для IP) вход блокируется на LockoutDuration
+type LoginPolicy struct {
+	MaxFailures     int
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	DelayBase       time.Duration
+	DelayMax        time.Duration
+}
+
+func (p LoginPolicy) delay(failures int) time.Duration {
+	if failures <= 0 || p.DelayBase <= 0 {
+		return 0
+	}
+
+	delay := p.DelayBase
+	for i := 1; i < failures && delay < p.DelayMax; i++ {
+		delay *= 2
+	}
+	if delay > p.DelayMax {
+		delay = p.DelayMax
+	}
+	return delay
+}
+
+// RegistrationPolicy - кто может зарегистрироваться. Без SelfRegistration учетные записи
+// создаются только по приглашениям. При самостоятельной регистрации роль должна быть из Roles
+type RegistrationPolicy struct {
+	SelfRegistration bool
+	Roles            RoleSet
+}
+
+// hashToken - одноразовые токены (приглашения, второй шаг входа) хранятся и ищутся по SHA-256
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// dummyPasswordHash сравнивается с паролем, когда пользователь не найден,
+// чтобы ответ для несуществующего email занимал столько же времени
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password for timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// PasswordPolicy требования к паролю при регистрации
+type PasswordPolicy struct {
+	MinLength      int
+	RequireLetter  bool
+	RequireDigit   bool
+	RequireUpper   bool
+	RequireSpecial bool
+}
+
+// maxPasswordBytes - bcrypt учитывает только первые 72 байта пароля
+const maxPasswordBytes = 72
+
+// Validate возвращает ErrWeakPassword с описанием первого нарушенного требования
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", errs.ErrWeakPassword, p.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: must be at most %d bytes long", errs.ErrWeakPassword, maxPasswordBytes)
+	}
+
+	var hasLetter, hasDigit, hasUpper, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+			hasUpper = hasUpper || unicode.IsUpper(r)
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+
+	switch {
+	case p.RequireLetter && !hasLetter:
+		return fmt.Errorf("%w: must contain a letter", errs.ErrWeakPassword)
+	case p.RequireDigit && !hasDigit:
+		return fmt.Errorf("%w: must contain a digit", errs.ErrWeakPassword)
+	case p.RequireUpper && !hasUpper:
+		return fmt.Errorf("%w: must contain an uppercase letter", errs.ErrWeakPassword)
+	case p.RequireSpecial && !hasSpecial:
+		return fmt.Errorf("%w: must contain a special character", errs.ErrWeakPassword)
+	}
+
+	return nil
 }
 
 type AuthUsecase struct {
-	repo      AuthRepository
-	tokenator *jwt.Tokenator
+	repo               AuthRepository
+	tokenator          *jwt.Tokenator
+	passwordPolicy     PasswordPolicy
+	loginPolicy        LoginPolicy
+	registrationPolicy RegistrationPolicy
+	twoFactorPolicy    TwoFactorPolicy
 }
 
-func New(repo AuthRepository, tokenator *jwt.Tokenator) *AuthUsecase {
+func New(repo AuthRepository, tokenator *jwt.Tokenator, passwordPolicy PasswordPolicy, loginPolicy LoginPolicy, registrationPolicy RegistrationPolicy, twoFactorPolicy TwoFactorPolicy) *AuthUsecase {
 	return &AuthUsecase{
-		repo:      repo,
-		tokenator: tokenator,
+		repo:               repo,
+		tokenator:          tokenator,
+		passwordPolicy:     passwordPolicy,
+		loginPolicy:        loginPolicy,
+		registrationPolicy: registrationPolicy,
+		twoFactorPolicy:    twoFactorPolicy,
+	}
+}
+
+// NormalizeEmail проверяет синтаксис адреса и приводит его к нижнему регистру.
+// Допускается только голый адрес без имени и угловых скобок, домен должен содержать точку
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || len(email) > 254 {
+		return "", errs.ErrInvalidEmail
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errs.ErrInvalidEmail
+	}
+
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], email[at+1:]
+	if len(local) > 64 || !strings.Contains(domain, ".") {
+		return "", errs.ErrInvalidEmail
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", errs.ErrInvalidEmail
+		}
 	}
+
+	return strings.ToLower(email), nil
 }
 
-func (uc *AuthUsecase) Authenticate(ctx context.Context, email, password string) (string, error) {
+// Authenticate проверяет пароль с учетом блокировок и задержек по email и IP.
+// Для неизвестного email и неверного пароля возвращается одна и та же ErrInvalidCredentials.
+// Если нужен второй фактор, вместо токена возвращается TwoFactorRequiredError с challenge для VerifyLogin.
+// Каждый вход создает сессию клиента client
+func (uc *AuthUsecase) Authenticate(ctx context.Context, email, password string, client session.Client) (string, error) {
 	const op = "AuthUsecase.Authenticate"
-	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email)
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	email = strings.ToLower(strings.TrimSpace(email))
+	ip := client.IP
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email).WithField("ip", ip)
+
+	throttle, err := uc.repo.GetLoginThrottle(ctx, email, ip, uc.loginPolicy.Window)
+	if err != nil {
+		logger.WithError(err).Error("failed to get login throttle")
+		return "", err
+	}
+	if throttle.LockedFor > 0 {
+		logger.WithField("locked_for", throttle.LockedFor).Warn("login is locked")
+		return "", &errs.LoginLockedError{RetryAfter: throttle.LockedFor}
+	}
+
+	if delay := uc.loginPolicy.delay(throttle.Failures); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
 
 	user, err := uc.repo.GetUserByEmail(ctx, email)
 	if err != nil {
 		logger.WithError(err).Warn("failed to get user by email")
 		return "", err
 	}
-	if user == nil {
-		logger.Warn("user not found")
-		return "", errors.New("user not found")
+
+	passwordHash := dummyPasswordHash()
+	if user != nil {
+		passwordHash = user.PasswordHash
+	}
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(password)); err != nil || user == nil {
+		logger.WithField("user_found", user != nil).Warn("invalid credentials")
+		uc.recordLoginFailure(ctx, email, ip)
+		return "", errs.ErrInvalidCredentials
+	}
+
+	// Статус проверяется после пароля, чтобы по ответу нельзя было узнать о деактивации без пароля
+	if !user.Active {
+		logger.Warn("user is deactivated")
+		return "", errs.ErrUserDeactivated
 	}
 
-	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
-		logger.Warn("invalid password")
-		return "", errors.New("invalid password")
+	if user.TwoFactorEnabled || uc.twoFactorPolicy.RequiredRoles[user.Role] {
+		challenge, err := uc.createLoginChallenge(ctx, user.ID)
+		if err != nil {
+			logger.WithError(err).Error("failed to create login challenge")
+			return "", err
+		}
+		logger.WithField("enroll", !user.TwoFactorEnabled).Info("second factor required")
+		return "", &errs.TwoFactorRequiredError{Challenge: challenge, Enroll: !user.TwoFactorEnabled}
 	}
 
-	token, err := uc.tokenator.CreateJWT(user.ID.String(), user.Role)
+	token, err := uc.issueToken(ctx, user.ID, user.Role, user.PickupPointID, client)
 	if err != nil {
 		logger.WithError(err).Error("failed to create JWT")
 		return "", err
 	}
+	uc.resetLoginFailures(ctx, email)
 
 	return token, nil
 }
 
-func (uc *AuthUsecase) Register(ctx context.Context, email, password, role string) (string, error) {
+// resetLoginFailures сбрасывает счетчик неудач по email. Вызывается только после выдачи токена:
+// верный пароль без второго фактора не должен обнулять попытки подобрать код
+func (uc *AuthUsecase) resetLoginFailures(ctx context.Context, email string) {
+	if err := uc.repo.ResetLoginFailures(ctx, models.LoginScopeEmail, email); err != nil {
+		logctx.GetLogger(ctx).WithError(err).Warn("failed to reset login failures")
+	}
+}
+
+// recordLoginFailure учитывает неудачу по email и IP. Ошибка записи только логируется
+func (uc *AuthUsecase) recordLoginFailure(ctx context.Context, email, ip string) {
+	p := uc.loginPolicy
+	keys := []struct {
+		scope       models.LoginScope
+		key         string
+		maxFailures int
+	}{
+		{scope: models.LoginScopeEmail, key: email, maxFailures: p.MaxFailures},
+		{scope: models.LoginScopeIP, key: ip, maxFailures: p.MaxIPFailures},
+	}
+
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+		if err := uc.repo.RecordLoginFailure(ctx, k.scope, k.key, k.maxFailures, p.Window, p.LockoutDuration); err != nil {
+			logctx.GetLogger(ctx).WithError(err).
+				WithField("scope", k.scope).
+				Error("failed to record login failure")
+		}
+	}
+}
+
+// UnlockLogin снимает блокировку и сбрасывает счетчик неудач для email и/или IP
+func (uc *AuthUsecase) UnlockLogin(ctx context.Context, email, ip string) error {
+	const op = "AuthUsecase.UnlockLogin"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	email = strings.ToLower(strings.TrimSpace(email))
+	ip = strings.TrimSpace(ip)
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("email", email).WithField("ip", ip)
+
+	if email == "" && ip == "" {
+		logger.Warn("nothing to unlock")
+		return errs.ErrInvalidUnlockRequest
+	}
+
+	if email != "" {
+		if err := uc.repo.ResetLoginFailures(ctx, models.LoginScopeEmail, email); err != nil {
+			logger.WithError(err).Error("failed to unlock email")
+			return err
+		}
+	}
+	if ip != "" {
+		if err := uc.repo.ResetLoginFailures(ctx, models.LoginScopeIP, ip); err != nil {
+			logger.WithError(err).Error("failed to unlock ip")
+			return err
+		}
+	}
+
+	logger.Info("login unlocked")
+	return nil
+}
+
+// CheckToken отклоняет токены, выданные до смены пароля или роли, токены отозванных сессий,
+// удаленных и деактивированных пользователей.
+// Токены /dummyLogin не привязаны к пользователю и не проверяются
+func (uc *AuthUsecase) CheckToken(ctx context.Context, claims *jwt.JWTClaims) error {
+	const op = "AuthUsecase.CheckToken"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", claims.UserID)
+
+	if claims.Dummy {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		logger.Warn("token has invalid user id")
+		return errs.ErrTokenRevoked
+	}
+
+	state, err := uc.repo.GetTokenState(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get token state")
+		return err
+	}
+	if state == nil {
+		logger.Warn("token of unknown user")
+		return errs.ErrTokenRevoked
+	}
+	if !state.Active {
+		logger.Warn("token of deactivated user")
+		return errs.ErrUserDeactivated
+	}
+
+	// iat хранится с точностью до секунды
+	if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(state.ValidAfter.Truncate(time.Second)) {
+		logger.Warn("token issued before revocation")
+		return errs.ErrTokenRevoked
+	}
+
+	// Токены без сессии выданы до их появления и действуют до истечения срока
+	if claims.SessionID != "" {
+		if err := uc.checkSession(ctx, userID, claims.SessionID); err != nil {
+			if errors.Is(err, errs.ErrTokenRevoked) {
+				logger.WithField("session_id", claims.SessionID).Warn("session revoked")
+			} else {
+				logger.WithError(err).Error("failed to check session")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Register создает пользователя и выдает ему токен. С приглашением email, роль и ПВЗ берутся из него,
+// а email и роль из запроса игнорируются. Без приглашения регистрация доступна, только если
+// включена самостоятельная регистрация. Токен выдается в новой сессии клиента client.
+// Если роль требует второго фактора, вместо токена возвращается TwoFactorRequiredError с Enroll
+func (uc *AuthUsecase) Register(ctx context.Context, email, password, role, invitation string, client session.Client) (string, error) {
 	const op = "AuthUsecase.Register"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	invited := invitation != ""
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
-		"email": email,
-		"role":  role,
+		"email":   email,
+		"role":    role,
+		"invited": invited,
 	})
 
-	if !allowedRoles[role] {
-		logger.Warn("role not allowed")
-		return "", errs.ErrCityNotAllowed
+	if !invited {
+		if !uc.registrationPolicy.SelfRegistration {
+			logger.Warn("self-registration is disabled")
+			return "", errs.ErrRegistrationClosed
+		}
+
+		if !uc.registrationPolicy.Roles.HasRole(role) {
+			logger.Warn("role not allowed")
+			return "", errs.ErrRoleNotAllowed
+		}
+
+		var err error
+		email, err = NormalizeEmail(email)
+		if err != nil {
+			logger.Warn("invalid email")
+			return "", err
+		}
+	}
+
+	if err := uc.passwordPolicy.Validate(password); err != nil {
+		logger.WithError(err).Warn("password rejected by policy")
+		return "", err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -83,13 +424,36 @@ func (uc *AuthUsecase) Register(ctx context.Context, email, password, role strin
 		return "", err
 	}
 
-	user, err := uc.repo.CreateUser(ctx, email, hashedPassword, role)
+	var user *models.User
+	if invited {
+		user, err = uc.repo.CreateUserFromInvitation(ctx, hashToken(invitation), hashedPassword)
+	} else {
+		user, err = uc.repo.CreateUser(ctx, email, hashedPassword, role)
+	}
 	if err != nil {
-		logger.WithError(err).Error("failed to create user")
+		switch {
+		case errors.Is(err, errs.ErrUserAlreadyExists):
+			logger.Warn("email already registered")
+		case errors.Is(err, errs.ErrInvalidInvitation):
+			logger.Warn("invitation is invalid or expired")
+		default:
+			logger.WithError(err).Error("failed to create user")
+		}
 		return "", err
 	}
 
-	token, err := uc.tokenator.CreateJWT(user.ID.String(), user.Role)
+	// Роли с обязательным вторым фактором получают токен только после настройки TOTP, как и при входе
+	if uc.twoFactorPolicy.RequiredRoles[user.Role] {
+		challenge, err := uc.createLoginChallenge(ctx, user.ID)
+		if err != nil {
+			logger.WithError(err).Error("failed to create login challenge")
+			return "", err
+		}
+		logger.Info("second factor enrollment required")
+		return "", &errs.TwoFactorRequiredError{Challenge: challenge, Enroll: true}
+	}
+
+	token, err := uc.issueToken(ctx, user.ID, user.Role, user.PickupPointID, client)
 	if err != nil {
 		logger.WithError(err).Error("failed to create JWT after registration")
 		return "", err
@@ -102,7 +466,7 @@ func (uc *AuthUsecase) DummyLogin(role string) (string, error) {
 	const op = "AuthUsecase.DummyLogin"
 	logger := logctx.GetLogger(context.Background()).WithField("op", op).WithField("role", role)
 
-	token, err := uc.tokenator.CreateJWT(uuid.New().String(), role)
+	token, err := uc.tokenator.CreateDummyJWT(uuid.New().String(), role)
 	if err != nil {
 		logger.WithError(err).Error("failed to create dummy token")
 		return "", err
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// sessionTouchInterval - как часто обновляется время последней активности сессии
+	sessionTouchInterval = time.Minute
+	// Клиент передает эти значения сам, поэтому их длина ограничена
+	maxDeviceLength    = 128
+	maxUserAgentLength = 512
+)
+
+// issueToken создает сессию входа и выдает токен с ее id. ПВЗ пользователя, если он есть, записывается в токен
+func (uc *AuthUsecase) issueToken(ctx context.Context, userID uuid.UUID, role string, pvzID *uuid.UUID, client session.Client) (string, error) {
+	client.Device = truncate(client.Device, maxDeviceLength)
+	client.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+
+	sessionID, err := uc.repo.CreateSession(ctx, userID, client)
+	if err != nil {
+		return "", err
+	}
+
+	logctx.GetLogger(ctx).WithField("user_id", userID).WithField("session_id", sessionID).Info("session created")
+	var pvz string
+	if pvzID != nil {
+		pvz = pvzID.String()
+	}
+	return uc.tokenator.CreateJWT(userID.String(), role, sessionID.String(), pvz)
+}
+
+// checkSession проверяет, что сессия токена не отозвана, и отмечает ее активность
+func (uc *AuthUsecase) checkSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errs.ErrTokenRevoked
+	}
+
+	active, err := uc.repo.TouchSession(ctx, id, userID, sessionTouchInterval)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errs.ErrTokenRevoked
+	}
+	return nil
+}
+
+// truncate обрезает строку до max байт, не разрывая символ UTF-8
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/totp"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+const (
+	// challengeMaxAttempts - сколько кодов можно проверить по одному challenge
+	challengeMaxAttempts = 5
+	// totpSkew - сколько соседних 30-секундных шагов принимается из-за расхождения часов
+	totpSkew = 1
+	// recoveryCodeCount - сколько кодов восстановления выдается при включении TOTP
+	recoveryCodeCount = 10
+)
+
+// TwoFactorPolicy настройки TOTP. Для ролей из RequiredRoles вход без второго фактора невозможен:
+// пользователь без TOTP настраивает его на втором шаге входа. Issuer показывается в аутентификаторе
+type TwoFactorPolicy struct {
+	RequiredRoles map[string]bool
+	Issuer        string
+	ChallengeTTL  time.Duration
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// createLoginChallenge выдает токен второго шага входа, в БД сохраняется только его хеш
+func (uc *AuthUsecase) createLoginChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate login challenge: %w", err)
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := uc.repo.CreateLoginChallenge(ctx, userID, hashToken(challenge), uc.twoFactorPolicy.ChallengeTTL); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// VerifyLogin - второй шаг входа. Принимает код TOTP или код восстановления и выдает токен.
+// Если TOTP обязателен, но еще не включен, код подтверждает настройку из SetupLoginTwoFactor,
+// и вместе с токеном возвращаются коды восстановления. Сессия создается для клиента client
+func (uc *AuthUsecase) VerifyLogin(ctx context.Context, challenge, code string, client session.Client) (string, []string, error) {
+	const op = "AuthUsecase.VerifyLogin"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tf, err := uc.useLoginChallenge(ctx, challenge)
+	if err != nil {
+		logger.WithError(err).Warn("login challenge rejected")
+		return "", nil, err
+	}
+	logger = logger.WithField("user_id", tf.UserID)
+
+	var recoveryCodes []string
+	if tf.Enabled {
+		err = uc.checkSecondFactor(ctx, tf, code)
+	} else {
+		recoveryCodes, err = uc.enableTwoFactor(ctx, tf, code)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("second factor rejected")
+		// Неверный код учитывается в тех же счетчиках, что и неверный пароль
+		if errors.Is(err, errs.ErrInvalidOTP) {
+			uc.recordLoginFailure(ctx, tf.Email, client.IP)
+		}
+		return "", nil, err
+	}
+
+	if err := uc.repo.DeleteLoginChallenge(ctx, hashToken(challenge)); err != nil {
+		logger.WithError(err).Warn("failed to delete login challenge")
+	}
+
+	token, err := uc.issueToken(ctx, tf.UserID, tf.Role, tf.PickupPointID, client)
+	if err != nil {
+		logger.WithError(err).Error("failed to create JWT")
+		return "", nil, err
+	}
+	uc.resetLoginFailures(ctx, tf.Email)
+
+	logger.Info("second factor verified")
+	return token, recoveryCodes, nil
+}
+
+// SetupLoginTwoFactor начинает настройку TOTP во время входа, когда он обязателен для роли
+func (uc *AuthUsecase) SetupLoginTwoFactor(ctx context.Context, challenge string) (*models.TwoFactorSetup, error) {
+	const op = "AuthUsecase.SetupLoginTwoFactor"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	tf, err := uc.useLoginChallenge(ctx, challenge)
+	if err != nil {
+		logger.WithError(err).Warn("login challenge rejected")
+		return nil, err
+	}
+
+	return uc.setupTwoFactor(ctx, tf)
+}
+
+// SetupTwoFactor начинает настройку TOTP для вошедшего пользователя. TOTP включается после EnableTwoFactor
+func (uc *AuthUsecase) SetupTwoFactor(ctx context.Context, userID string) (*models.TwoFactorSetup, error) {
+	const op = "AuthUsecase.SetupTwoFactor"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tf, err := uc.getTwoFactor(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get two-factor state")
+		return nil, err
+	}
+
+	return uc.setupTwoFactor(ctx, tf)
+}
+
+// EnableTwoFactor подтверждает настройку первым кодом и возвращает коды восстановления
+func (uc *AuthUsecase) EnableTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	const op = "AuthUsecase.EnableTwoFactor"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tf, err := uc.getTwoFactor(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get two-factor state")
+		return nil, err
+	}
+	if tf.Enabled {
+		logger.Warn("two-factor authentication is already enabled")
+		return nil, errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	codes, err := uc.enableTwoFactor(ctx, tf, code)
+	if err != nil {
+		logger.WithError(err).Warn("failed to enable two-factor authentication")
+		return nil, err
+	}
+
+	logger.Info("two-factor authentication enabled")
+	return codes, nil
+}
+
+// DisableTwoFactor выключает TOTP после проверки кода. Для ролей с обязательным TOTP это запрещено
+func (uc *AuthUsecase) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	const op = "AuthUsecase.DisableTwoFactor"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	tf, err := uc.getTwoFactor(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get two-factor state")
+		return err
+	}
+	if !tf.Enabled {
+		logger.Warn("two-factor authentication is not enabled")
+		return errs.ErrTwoFactorNotSetUp
+	}
+	if uc.twoFactorPolicy.RequiredRoles[tf.Role] {
+		logger.WithField("role", tf.Role).Warn("two-factor authentication is mandatory")
+		return errs.ErrTwoFactorMandatory
+	}
+
+	if err := uc.checkSecondFactor(ctx, tf, code); err != nil {
+		logger.WithError(err).Warn("second factor rejected")
+		return err
+	}
+
+	if err := uc.repo.DisableTwoFactor(ctx, tf.UserID); err != nil {
+		logger.WithError(err).Error("failed to disable two-factor authentication")
+		return err
+	}
+
+	logger.Info("two-factor authentication disabled")
+	return nil
+}
+
+func (uc *AuthUsecase) useLoginChallenge(ctx context.Context, challenge string) (*models.TwoFactor, error) {
+	if challenge == "" {
+		return nil, errs.ErrInvalidLoginChallenge
+	}
+
+	tf, err := uc.repo.UseLoginChallenge(ctx, hashToken(challenge), challengeMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.Active {
+		return nil, errs.ErrUserDeactivated
+	}
+	return tf, nil
+}
+
+func (uc *AuthUsecase) getTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errs.ErrInvalidUserID
+	}
+	return uc.repo.GetTwoFactor(ctx, id)
+}
+
+// setupTwoFactor создает новый секрет, заменяя неподтвержденный
+func (uc *AuthUsecase) setupTwoFactor(ctx context.Context, tf *models.TwoFactor) (*models.TwoFactorSetup, error) {
+	if tf.Enabled {
+		return nil, errs.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.SetTOTPSecret(ctx, tf.UserID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(uc.twoFactorPolicy.Issuer, tf.Email, secret),
+	}, nil
+}
+
+// enableTwoFactor проверяет первый код по сохраненному секрету и включает TOTP с новыми кодами восстановления
+func (uc *AuthUsecase) enableTwoFactor(ctx context.Context, tf *models.TwoFactor, code string) ([]string, error) {
+	if tf.Secret == "" {
+		return nil, errs.ErrTwoFactorNotSetUp
+	}
+
+	step, ok := totp.Match(tf.Secret, normalizeCode(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, errs.ErrInvalidOTP
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.EnableTwoFactor(ctx, tf.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// checkSecondFactor принимает код TOTP (6 цифр) или код восстановления. Каждый из них срабатывает один раз
+func (uc *AuthUsecase) checkSecondFactor(ctx context.Context, tf *models.TwoFactor, code string) error {
+	code = normalizeCode(code)
+
+	if isTOTPCode(code) {
+		step, ok := totp.Match(tf.Secret, code, time.Now(), totpSkew)
+		if !ok || step <= tf.LastStep {
+			return errs.ErrInvalidOTP
+		}
+		used, err := uc.repo.UseTOTPStep(ctx, tf.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errs.ErrInvalidOTP
+		}
+		return nil
+	}
+
+	used, err := uc.repo.UseRecoveryCode(ctx, tf.UserID, hashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errs.ErrInvalidOTP
+	}
+	logctx.GetLogger(ctx).WithField("user_id", tf.UserID).Warn("recovery code used")
+	return nil
+}
+
+// normalizeCode убирает пробелы и дефисы, которые пользователь мог скопировать вместе с кодом
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCodes возвращает коды вида XXXX-XXXX-XXXX-XXXX (80 бит) и их хеши для БД
+func generateRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([][]byte, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		code := recoveryEncoding.EncodeToString(raw)
+		codes = append(codes, code[0:4]+"-"+code[4:8]+"-"+code[8:12]+"-"+code[12:16])
+		hashes = append(hashes, hashToken(code))
+	}
+
+	return codes, hashes, nil
+}
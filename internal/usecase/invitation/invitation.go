@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	mail "github.com/nik-mLb/avito_task/internal/models/mail"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	authuc "github.com/nik-mLb/avito_task/internal/usecase/auth"
+)
+
+//go:generate mockgen -source=invitation.go -destination=../../repository/mocks/invitation_repository_mock.go -package=mocks -mock_names=Mailer=MockInvitationMailer InvitationRepository,Mailer
+type InvitationRepository interface {
+	EmailRegistered(ctx context.Context, email string) (bool, error)
+	CreateInvitation(ctx context.Context, inv models.Invitation, tokenHash []byte, ttl time.Duration) (*models.Invitation, error)
+	ListInvitations(ctx context.Context) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) error
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mail.Message) error
+}
+
+// InvitePolicy настройки приглашений. Ссылка в письме - RegisterURL с параметром invitation,
+// пригласить можно с любой ролью из Roles
+type InvitePolicy struct {
+	TokenTTL    time.Duration
+	RegisterURL string
+	Roles       authuc.RoleSet
+}
+
+type InvitationUsecase struct {
+	repo   InvitationRepository
+	mailer Mailer
+	policy InvitePolicy
+}
+
+func NewInvitationUsecase(repo InvitationRepository, mailer Mailer, policy InvitePolicy) *InvitationUsecase {
+	return &InvitationUsecase{
+		repo:   repo,
+		mailer: mailer,
+		policy: policy,
+	}
+}
+
+// hashInvitationToken - в БД хранится только SHA-256 токена, при регистрации он хешируется так же
+func hashInvitationToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// CreateInvitation создает приглашение и отправляет ссылку с токеном на email.
+// Токен возвращается только в письме, в ответе и в БД его нет. Письмо отправляется в фоне
+func (uc *InvitationUsecase) CreateInvitation(ctx context.Context, actorID, email, role, pvzID string) (*models.Invitation, error) {
+	const op = "InvitationUsecase.CreateInvitation"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("role", role).WithField("pvz_id", pvzID)
+
+	uuidActorID, err := uuid.Parse(actorID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid actor id")
+		return nil, errs.ErrInvalidUserID
+	}
+
+	email, err = authuc.NormalizeEmail(email)
+	if err != nil {
+		logger.Warn("invalid email")
+		return nil, err
+	}
+	logger = logger.WithField("email", email)
+
+	if !uc.policy.Roles.HasRole(role) {
+		logger.Warn("role not allowed")
+		return nil, errs.ErrRoleNotAllowed
+	}
+
+	inv := models.Invitation{
+		ID:        uuid.New(),
+		Email:     email,
+		Role:      role,
+		CreatedBy: uuidActorID,
+	}
+	if pvzID != "" {
+		uuidPvzID, err := uuid.Parse(pvzID)
+		if err != nil {
+			logger.WithError(err).Warn("invalid pvzID")
+			return nil, errs.ErrInvalidPickupPointID
+		}
+		inv.PickupPointID = &uuidPvzID
+	}
+
+	registered, err := uc.repo.EmailRegistered(ctx, email)
+	if err != nil {
+		logger.WithError(err).Error("failed to check email")
+		return nil, err
+	}
+	if registered {
+		logger.Warn("email already registered")
+		return nil, errs.ErrUserAlreadyExists
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		logger.WithError(err).Error("failed to generate invitation token")
+		return nil, fmt.Errorf("generate invitation token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	created, err := uc.repo.CreateInvitation(ctx, inv, hashInvitationToken(token), uc.policy.TokenTTL)
+	if err != nil {
+		logger.WithError(err).Warn("failed to create invitation")
+		return nil, err
+	}
+
+	msg := mail.Message{
+		To:      email,
+		Subject: "Приглашение в сервис ПВЗ",
+		Body: fmt.Sprintf("Вас пригласили в сервис ПВЗ с ролью %s. Чтобы создать учетную запись, перейдите по ссылке:\n%s?invitation=%s\n\n"+
+			"Ссылка действует %s и только один раз.\n",
+			role, uc.policy.RegisterURL, url.QueryEscape(token), uc.policy.TokenTTL),
+	}
+
+	go func(ctx context.Context) {
+		if err := uc.mailer.Send(ctx, msg); err != nil {
+			logger.WithError(err).Error("failed to send invitation mail")
+			return
+		}
+		logger.Info("invitation mail sent")
+	}(context.WithoutCancel(ctx))
+
+	logger.WithField("invitation_id", created.ID).Info("invitation created")
+	return created, nil
+}
+
+func (uc *InvitationUsecase) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	const op = "InvitationUsecase.ListInvitations"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	invitations, err := uc.repo.ListInvitations(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed to list invitations")
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation удаляет неиспользованное приглашение, ссылка из письма перестает работать
+func (uc *InvitationUsecase) RevokeInvitation(ctx context.Context, invitationID string) error {
+	const op = "InvitationUsecase.RevokeInvitation"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("invitation_id", invitationID)
+
+	id, err := uuid.Parse(invitationID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid invitation id")
+		return errs.ErrInvalidInvitationID
+	}
+
+	if err := uc.repo.RevokeInvitation(ctx, id); err != nil {
+		logger.WithError(err).Warn("failed to revoke invitation")
+		return err
+	}
+
+	logger.Info("invitation revoked")
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	models0 "github.com/nik-mLb/avito_task/internal/models/user"
 )
 
 // MockAuthUsecase is a mock of AuthUsecase interface.
@@ -35,18 +37,32 @@ func (m *MockAuthUsecase) EXPECT() *MockAuthUsecaseMockRecorder {
 }
 
 // Authenticate mocks base method.
-func (m *MockAuthUsecase) Authenticate(ctx context.Context, email, password string) (string, error) {
+func (m *MockAuthUsecase) Authenticate(ctx context.Context, email, password string, client models.Client) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate", ctx, email, password)
+	ret := m.ctrl.Call(m, "Authenticate", ctx, email, password, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Authenticate indicates an expected call of Authenticate.
-func (mr *MockAuthUsecaseMockRecorder) Authenticate(ctx, email, password interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) Authenticate(ctx, email, password, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthUsecase)(nil).Authenticate), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthUsecase)(nil).Authenticate), ctx, email, password, client)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockAuthUsecase) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockAuthUsecaseMockRecorder) DisableTwoFactor(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockAuthUsecase)(nil).DisableTwoFactor), ctx, userID, code)
 }
 
 // DummyLogin mocks base method.
@@ -64,17 +80,92 @@ func (mr *MockAuthUsecaseMockRecorder) DummyLogin(role interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockAuthUsecase)(nil).DummyLogin), role)
 }
 
+// EnableTwoFactor mocks base method.
+func (m *MockAuthUsecase) EnableTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockAuthUsecaseMockRecorder) EnableTwoFactor(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockAuthUsecase)(nil).EnableTwoFactor), ctx, userID, code)
+}
+
 // Register mocks base method.
-func (m *MockAuthUsecase) Register(ctx context.Context, email, password, role string) (string, error) {
+func (m *MockAuthUsecase) Register(ctx context.Context, email, password, role, invitation string, client models.Client) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, email, password, role)
+	ret := m.ctrl.Call(m, "Register", ctx, email, password, role, invitation, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockAuthUsecaseMockRecorder) Register(ctx, email, password, role interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) Register(ctx, email, password, role, invitation, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthUsecase)(nil).Register), ctx, email, password, role, invitation, client)
+}
+
+// SetupLoginTwoFactor mocks base method.
+func (m *MockAuthUsecase) SetupLoginTwoFactor(ctx context.Context, challenge string) (*models0.TwoFactorSetup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupLoginTwoFactor", ctx, challenge)
+	ret0, _ := ret[0].(*models0.TwoFactorSetup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetupLoginTwoFactor indicates an expected call of SetupLoginTwoFactor.
+func (mr *MockAuthUsecaseMockRecorder) SetupLoginTwoFactor(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupLoginTwoFactor", reflect.TypeOf((*MockAuthUsecase)(nil).SetupLoginTwoFactor), ctx, challenge)
+}
+
+// SetupTwoFactor mocks base method.
+func (m *MockAuthUsecase) SetupTwoFactor(ctx context.Context, userID string) (*models0.TwoFactorSetup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(*models0.TwoFactorSetup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetupTwoFactor indicates an expected call of SetupTwoFactor.
+func (mr *MockAuthUsecaseMockRecorder) SetupTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupTwoFactor", reflect.TypeOf((*MockAuthUsecase)(nil).SetupTwoFactor), ctx, userID)
+}
+
+// UnlockLogin mocks base method.
+func (m *MockAuthUsecase) UnlockLogin(ctx context.Context, email, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockLogin", ctx, email, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockLogin indicates an expected call of UnlockLogin.
+func (mr *MockAuthUsecaseMockRecorder) UnlockLogin(ctx, email, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockLogin", reflect.TypeOf((*MockAuthUsecase)(nil).UnlockLogin), ctx, email, ip)
+}
+
+// VerifyLogin mocks base method.
+func (m *MockAuthUsecase) VerifyLogin(ctx context.Context, challenge, code string, client models.Client) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLogin", ctx, challenge, code, client)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VerifyLogin indicates an expected call of VerifyLogin.
+func (mr *MockAuthUsecaseMockRecorder) VerifyLogin(ctx, challenge, code, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthUsecase)(nil).Register), ctx, email, password, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLogin", reflect.TypeOf((*MockAuthUsecase)(nil).VerifyLogin), ctx, challenge, code, client)
 }
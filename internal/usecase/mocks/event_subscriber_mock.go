@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: events.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	eventbus "github.com/nik-mLb/avito_task/internal/eventbus"
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+)
+
+// MockEventSubscriber is a mock of EventSubscriber interface.
+type MockEventSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSubscriberMockRecorder
+}
+
+// MockEventSubscriberMockRecorder is the mock recorder for MockEventSubscriber.
+type MockEventSubscriberMockRecorder struct {
+	mock *MockEventSubscriber
+}
+
+// NewMockEventSubscriber creates a new mock instance.
+func NewMockEventSubscriber(ctrl *gomock.Controller) *MockEventSubscriber {
+	mock := &MockEventSubscriber{ctrl: ctrl}
+	mock.recorder = &MockEventSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSubscriber) EXPECT() *MockEventSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventSubscriber) Subscribe(lastEventID uint64, filter models.Filter) *eventbus.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", lastEventID, filter)
+	ret0, _ := ret[0].(*eventbus.Subscription)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventSubscriberMockRecorder) Subscribe(lastEventID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventSubscriber)(nil).Subscribe), lastEventID, filter)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invitation.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+)
+
+// MockInvitationUsecase is a mock of InvitationUsecase interface.
+type MockInvitationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationUsecaseMockRecorder
+}
+
+// MockInvitationUsecaseMockRecorder is the mock recorder for MockInvitationUsecase.
+type MockInvitationUsecaseMockRecorder struct {
+	mock *MockInvitationUsecase
+}
+
+// NewMockInvitationUsecase creates a new mock instance.
+func NewMockInvitationUsecase(ctrl *gomock.Controller) *MockInvitationUsecase {
+	mock := &MockInvitationUsecase{ctrl: ctrl}
+	mock.recorder = &MockInvitationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationUsecase) EXPECT() *MockInvitationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateInvitation mocks base method.
+func (m *MockInvitationUsecase) CreateInvitation(ctx context.Context, actorID, email, role, pvzID string) (*models.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, actorID, email, role, pvzID)
+	ret0, _ := ret[0].(*models.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockInvitationUsecaseMockRecorder) CreateInvitation(ctx, actorID, email, role, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockInvitationUsecase)(nil).CreateInvitation), ctx, actorID, email, role, pvzID)
+}
+
+// ListInvitations mocks base method.
+func (m *MockInvitationUsecase) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx)
+	ret0, _ := ret[0].([]models.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockInvitationUsecaseMockRecorder) ListInvitations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockInvitationUsecase)(nil).ListInvitations), ctx)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockInvitationUsecase) RevokeInvitation(ctx context.Context, invitationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, invitationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockInvitationUsecaseMockRecorder) RevokeInvitation(ctx, invitationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockInvitationUsecase)(nil).RevokeInvitation), ctx, invitationID)
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: password.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordUsecase is a mock of PasswordUsecase interface.
+type MockPasswordUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordUsecaseMockRecorder
+}
+
+// MockPasswordUsecaseMockRecorder is the mock recorder for MockPasswordUsecase.
+type MockPasswordUsecaseMockRecorder struct {
+	mock *MockPasswordUsecase
+}
+
+// NewMockPasswordUsecase creates a new mock instance.
+func NewMockPasswordUsecase(ctrl *gomock.Controller) *MockPasswordUsecase {
+	mock := &MockPasswordUsecase{ctrl: ctrl}
+	mock.recorder = &MockPasswordUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordUsecase) EXPECT() *MockPasswordUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ForgotPassword mocks base method.
+func (m *MockPasswordUsecase) ForgotPassword(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockPasswordUsecaseMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockPasswordUsecase)(nil).ForgotPassword), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockPasswordUsecase) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockPasswordUsecaseMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockPasswordUsecase)(nil).ResetPassword), ctx, token, password)
+}
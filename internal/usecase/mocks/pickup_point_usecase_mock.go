@@ -52,6 +52,35 @@ func (mr *MockPickupPointUsecaseMockRecorder) CreatePickupPoint(ctx, city interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePickupPoint", reflect.TypeOf((*MockPickupPointUsecase)(nil).CreatePickupPoint), ctx, city)
 }
 
+// ExportPickupPoints mocks base method.
+func (m *MockPickupPointUsecase) ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, fn func(models.ExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPickupPoints", ctx, startDate, endDate, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPickupPoints indicates an expected call of ExportPickupPoints.
+func (mr *MockPickupPointUsecaseMockRecorder) ExportPickupPoints(ctx, startDate, endDate, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPickupPoints", reflect.TypeOf((*MockPickupPointUsecase)(nil).ExportPickupPoints), ctx, startDate, endDate, fn)
+}
+
+// GetOccupancy mocks base method.
+func (m *MockPickupPointUsecase) GetOccupancy(ctx context.Context, pvzID string) (*models.Occupancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOccupancy", ctx, pvzID)
+	ret0, _ := ret[0].(*models.Occupancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOccupancy indicates an expected call of GetOccupancy.
+func (mr *MockPickupPointUsecaseMockRecorder) GetOccupancy(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOccupancy", reflect.TypeOf((*MockPickupPointUsecase)(nil).GetOccupancy), ctx, pvzID)
+}
+
 // GetPickupPointsWithReceptions mocks base method.
 func (m *MockPickupPointUsecase) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error) {
 	m.ctrl.T.Helper()
@@ -66,3 +95,32 @@ func (mr *MockPickupPointUsecaseMockRecorder) GetPickupPointsWithReceptions(ctx,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupPointsWithReceptions", reflect.TypeOf((*MockPickupPointUsecase)(nil).GetPickupPointsWithReceptions), ctx, startDate, endDate, page, limit)
 }
+
+// SetCapacity mocks base method.
+func (m *MockPickupPointUsecase) SetCapacity(ctx context.Context, pvzID string, capacity *int, mode string) (*models.Occupancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCapacity", ctx, pvzID, capacity, mode)
+	ret0, _ := ret[0].(*models.Occupancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCapacity indicates an expected call of SetCapacity.
+func (mr *MockPickupPointUsecaseMockRecorder) SetCapacity(ctx, pvzID, capacity, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCapacity", reflect.TypeOf((*MockPickupPointUsecase)(nil).SetCapacity), ctx, pvzID, capacity, mode)
+}
+
+// StreamPickupPointsWithReceptions mocks base method.
+func (m *MockPickupPointUsecase) StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int, fn func(dto.PickupPointListResponse) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPickupPointsWithReceptions", ctx, startDate, endDate, page, limit, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPickupPointsWithReceptions indicates an expected call of StreamPickupPointsWithReceptions.
+func (mr *MockPickupPointUsecaseMockRecorder) StreamPickupPointsWithReceptions(ctx, startDate, endDate, page, limit, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPickupPointsWithReceptions", reflect.TypeOf((*MockPickupPointUsecase)(nil).StreamPickupPointsWithReceptions), ctx, startDate, endDate, page, limit, fn)
+}
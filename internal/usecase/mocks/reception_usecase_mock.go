@@ -9,7 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
-	models "github.com/nik-mLb/avito_task/internal/models/reception"
+	models "github.com/nik-mLb/avito_task/internal/models/history"
+	models0 "github.com/nik-mLb/avito_task/internal/models/reception"
 )
 
 // MockReceptionUsecase is a mock of ReceptionUsecase interface.
@@ -36,10 +37,10 @@ func (m *MockReceptionUsecase) EXPECT() *MockReceptionUsecaseMockRecorder {
 }
 
 // CloseReception mocks base method.
-func (m *MockReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*models.Reception, error) {
+func (m *MockReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*models0.Reception, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CloseReception", ctx, pvzID)
-	ret0, _ := ret[0].(*models.Reception)
+	ret0, _ := ret[0].(*models0.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -51,10 +52,10 @@ func (mr *MockReceptionUsecaseMockRecorder) CloseReception(ctx, pvzID interface{
 }
 
 // CreateReception mocks base method.
-func (m *MockReceptionUsecase) CreateReception(ctx context.Context, pvzID string) (*models.Reception, error) {
+func (m *MockReceptionUsecase) CreateReception(ctx context.Context, pvzID string) (*models0.Reception, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateReception", ctx, pvzID)
-	ret0, _ := ret[0].(*models.Reception)
+	ret0, _ := ret[0].(*models0.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -64,3 +65,33 @@ func (mr *MockReceptionUsecaseMockRecorder) CreateReception(ctx, pvzID interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReception", reflect.TypeOf((*MockReceptionUsecase)(nil).CreateReception), ctx, pvzID)
 }
+
+// GetReceptionHistory mocks base method.
+func (m *MockReceptionUsecase) GetReceptionHistory(ctx context.Context, receptionID string) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionHistory", ctx, receptionID)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionHistory indicates an expected call of GetReceptionHistory.
+func (mr *MockReceptionUsecaseMockRecorder) GetReceptionHistory(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionHistory", reflect.TypeOf((*MockReceptionUsecase)(nil).GetReceptionHistory), ctx, receptionID)
+}
+
+// ReopenReception mocks base method.
+func (m *MockReceptionUsecase) ReopenReception(ctx context.Context, receptionID, userID, reason string) (*models0.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenReception", ctx, receptionID, userID, reason)
+	ret0, _ := ret[0].(*models0.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenReception indicates an expected call of ReopenReception.
+func (mr *MockReceptionUsecaseMockRecorder) ReopenReception(ctx, receptionID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenReception", reflect.TypeOf((*MockReceptionUsecase)(nil).ReopenReception), ctx, receptionID, userID, reason)
+}
@@ -0,0 +1,104 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scanner.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/product"
+	models0 "github.com/nik-mLb/avito_task/internal/models/reception"
+)
+
+// MockScannerProductUsecase is a mock of ScannerProductUsecase interface.
+type MockScannerProductUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockScannerProductUsecaseMockRecorder
+}
+
+// MockScannerProductUsecaseMockRecorder is the mock recorder for MockScannerProductUsecase.
+type MockScannerProductUsecaseMockRecorder struct {
+	mock *MockScannerProductUsecase
+}
+
+// NewMockScannerProductUsecase creates a new mock instance.
+func NewMockScannerProductUsecase(ctrl *gomock.Controller) *MockScannerProductUsecase {
+	mock := &MockScannerProductUsecase{ctrl: ctrl}
+	mock.recorder = &MockScannerProductUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScannerProductUsecase) EXPECT() *MockScannerProductUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AddProduct mocks base method.
+func (m *MockScannerProductUsecase) AddProduct(ctx context.Context, pvzID, productType string) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProduct", ctx, pvzID, productType)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProduct indicates an expected call of AddProduct.
+func (mr *MockScannerProductUsecaseMockRecorder) AddProduct(ctx, pvzID, productType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockScannerProductUsecase)(nil).AddProduct), ctx, pvzID, productType)
+}
+
+// DeleteLastProduct mocks base method.
+func (m *MockScannerProductUsecase) DeleteLastProduct(ctx context.Context, pvzID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLastProduct", ctx, pvzID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLastProduct indicates an expected call of DeleteLastProduct.
+func (mr *MockScannerProductUsecaseMockRecorder) DeleteLastProduct(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLastProduct", reflect.TypeOf((*MockScannerProductUsecase)(nil).DeleteLastProduct), ctx, pvzID)
+}
+
+// MockScannerReceptionUsecase is a mock of ScannerReceptionUsecase interface.
+type MockScannerReceptionUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockScannerReceptionUsecaseMockRecorder
+}
+
+// MockScannerReceptionUsecaseMockRecorder is the mock recorder for MockScannerReceptionUsecase.
+type MockScannerReceptionUsecaseMockRecorder struct {
+	mock *MockScannerReceptionUsecase
+}
+
+// NewMockScannerReceptionUsecase creates a new mock instance.
+func NewMockScannerReceptionUsecase(ctrl *gomock.Controller) *MockScannerReceptionUsecase {
+	mock := &MockScannerReceptionUsecase{ctrl: ctrl}
+	mock.recorder = &MockScannerReceptionUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScannerReceptionUsecase) EXPECT() *MockScannerReceptionUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CloseReception mocks base method.
+func (m *MockScannerReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*models0.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseReception", ctx, pvzID)
+	ret0, _ := ret[0].(*models0.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseReception indicates an expected call of CloseReception.
+func (mr *MockScannerReceptionUsecaseMockRecorder) CloseReception(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockScannerReceptionUsecase)(nil).CloseReception), ctx, pvzID)
+}
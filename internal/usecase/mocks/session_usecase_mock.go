@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: session.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+)
+
+// MockSessionUsecase is a mock of SessionUsecase interface.
+type MockSessionUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionUsecaseMockRecorder
+}
+
+// MockSessionUsecaseMockRecorder is the mock recorder for MockSessionUsecase.
+type MockSessionUsecaseMockRecorder struct {
+	mock *MockSessionUsecase
+}
+
+// NewMockSessionUsecase creates a new mock instance.
+func NewMockSessionUsecase(ctrl *gomock.Controller) *MockSessionUsecase {
+	mock := &MockSessionUsecase{ctrl: ctrl}
+	mock.recorder = &MockSessionUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionUsecase) EXPECT() *MockSessionUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ListSessions mocks base method.
+func (m *MockSessionUsecase) ListSessions(ctx context.Context, userID, currentSessionID string) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockSessionUsecaseMockRecorder) ListSessions(ctx, userID, currentSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockSessionUsecase)(nil).ListSessions), ctx, userID, currentSessionID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockSessionUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockSessionUsecaseMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockSessionUsecase)(nil).RevokeSession), ctx, userID, sessionID)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: statistics.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+)
+
+// MockStatisticsUsecase is a mock of StatisticsUsecase interface.
+type MockStatisticsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatisticsUsecaseMockRecorder
+}
+
+// MockStatisticsUsecaseMockRecorder is the mock recorder for MockStatisticsUsecase.
+type MockStatisticsUsecaseMockRecorder struct {
+	mock *MockStatisticsUsecase
+}
+
+// NewMockStatisticsUsecase creates a new mock instance.
+func NewMockStatisticsUsecase(ctrl *gomock.Controller) *MockStatisticsUsecase {
+	mock := &MockStatisticsUsecase{ctrl: ctrl}
+	mock.recorder = &MockStatisticsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatisticsUsecase) EXPECT() *MockStatisticsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockStatisticsUsecase) GetStats(ctx context.Context, groupBy string, startDate, endDate *time.Time) (*models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, groupBy, startDate, endDate)
+	ret0, _ := ret[0].(*models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockStatisticsUsecaseMockRecorder) GetStats(ctx, groupBy, startDate, endDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockStatisticsUsecase)(nil).GetStats), ctx, groupBy, startDate, endDate)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transfer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+)
+
+// MockTransferUsecase is a mock of TransferUsecase interface.
+type MockTransferUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferUsecaseMockRecorder
+}
+
+// MockTransferUsecaseMockRecorder is the mock recorder for MockTransferUsecase.
+type MockTransferUsecaseMockRecorder struct {
+	mock *MockTransferUsecase
+}
+
+// NewMockTransferUsecase creates a new mock instance.
+func NewMockTransferUsecase(ctrl *gomock.Controller) *MockTransferUsecase {
+	mock := &MockTransferUsecase{ctrl: ctrl}
+	mock.recorder = &MockTransferUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferUsecase) EXPECT() *MockTransferUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AcceptTransfer mocks base method.
+func (m *MockTransferUsecase) AcceptTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptTransfer", ctx, pvzID, transferID)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptTransfer indicates an expected call of AcceptTransfer.
+func (mr *MockTransferUsecaseMockRecorder) AcceptTransfer(ctx, pvzID, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptTransfer", reflect.TypeOf((*MockTransferUsecase)(nil).AcceptTransfer), ctx, pvzID, transferID)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockTransferUsecase) CreateTransfer(ctx context.Context, userID, fromPvzID, toPvzID string, productIDs []string) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, userID, fromPvzID, toPvzID, productIDs)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockTransferUsecaseMockRecorder) CreateTransfer(ctx, userID, fromPvzID, toPvzID, productIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockTransferUsecase)(nil).CreateTransfer), ctx, userID, fromPvzID, toPvzID, productIDs)
+}
+
+// DispatchTransfer mocks base method.
+func (m *MockTransferUsecase) DispatchTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchTransfer", ctx, pvzID, transferID)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DispatchTransfer indicates an expected call of DispatchTransfer.
+func (mr *MockTransferUsecaseMockRecorder) DispatchTransfer(ctx, pvzID, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchTransfer", reflect.TypeOf((*MockTransferUsecase)(nil).DispatchTransfer), ctx, pvzID, transferID)
+}
+
+// ListTransfers mocks base method.
+func (m *MockTransferUsecase) ListTransfers(ctx context.Context, pvzID, status string) ([]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", ctx, pvzID, status)
+	ret0, _ := ret[0].([]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockTransferUsecaseMockRecorder) ListTransfers(ctx, pvzID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockTransferUsecase)(nil).ListTransfers), ctx, pvzID, status)
+}
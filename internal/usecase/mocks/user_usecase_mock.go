@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: user.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+)
+
+// MockUserUsecase is a mock of UserUsecase interface.
+type MockUserUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserUsecaseMockRecorder
+}
+
+// MockUserUsecaseMockRecorder is the mock recorder for MockUserUsecase.
+type MockUserUsecaseMockRecorder struct {
+	mock *MockUserUsecase
+}
+
+// NewMockUserUsecase creates a new mock instance.
+func NewMockUserUsecase(ctrl *gomock.Controller) *MockUserUsecase {
+	mock := &MockUserUsecase{ctrl: ctrl}
+	mock.recorder = &MockUserUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserUsecase) EXPECT() *MockUserUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ChangeRole mocks base method.
+func (m *MockUserUsecase) ChangeRole(ctx context.Context, actorID, userID, role string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRole", ctx, actorID, userID, role)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeRole indicates an expected call of ChangeRole.
+func (mr *MockUserUsecaseMockRecorder) ChangeRole(ctx, actorID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRole", reflect.TypeOf((*MockUserUsecase)(nil).ChangeRole), ctx, actorID, userID, role)
+}
+
+// GetUser mocks base method.
+func (m *MockUserUsecase) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUserUsecaseMockRecorder) GetUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserUsecase)(nil).GetUser), ctx, userID)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserUsecase) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, filter)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserUsecaseMockRecorder) ListUsers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserUsecase)(nil).ListUsers), ctx, filter)
+}
+
+// SetActive mocks base method.
+func (m *MockUserUsecase) SetActive(ctx context.Context, actorID, userID string, active bool) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, actorID, userID, active)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockUserUsecaseMockRecorder) SetActive(ctx, actorID, userID, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockUserUsecase)(nil).SetActive), ctx, actorID, userID, active)
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+)
+
+// MockWebhookUsecase is a mock of WebhookUsecase interface.
+type MockWebhookUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookUsecaseMockRecorder
+}
+
+// MockWebhookUsecaseMockRecorder is the mock recorder for MockWebhookUsecase.
+type MockWebhookUsecaseMockRecorder struct {
+	mock *MockWebhookUsecase
+}
+
+// NewMockWebhookUsecase creates a new mock instance.
+func NewMockWebhookUsecase(ctrl *gomock.Controller) *MockWebhookUsecase {
+	mock := &MockWebhookUsecase{ctrl: ctrl}
+	mock.recorder = &MockWebhookUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookUsecase) EXPECT() *MockWebhookUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateSubscription mocks base method.
+func (m *MockWebhookUsecase) CreateSubscription(ctx context.Context, userID, url string, eventTypes []string, pvzID, secret string) (*models.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, userID, url, eventTypes, pvzID, secret)
+	ret0, _ := ret[0].(*models.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockWebhookUsecaseMockRecorder) CreateSubscription(ctx, userID, url, eventTypes, pvzID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookUsecase)(nil).CreateSubscription), ctx, userID, url, eventTypes, pvzID, secret)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhookUsecase) DeleteSubscription(ctx context.Context, webhookID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhookUsecaseMockRecorder) DeleteSubscription(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookUsecase)(nil).DeleteSubscription), ctx, webhookID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookUsecase) ListDeliveries(ctx context.Context, webhookID string) ([]models.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, webhookID)
+	ret0, _ := ret[0].([]models.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookUsecaseMockRecorder) ListDeliveries(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookUsecase)(nil).ListDeliveries), ctx, webhookID)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockWebhookUsecase) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]models.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockWebhookUsecaseMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhookUsecase)(nil).ListSubscriptions), ctx)
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhookUsecase) Redeliver(ctx context.Context, deliveryID string) (*models.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, deliveryID)
+	ret0, _ := ret[0].(*models.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookUsecaseMockRecorder) Redeliver(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookUsecase)(nil).Redeliver), ctx, deliveryID)
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	models "github.com/nik-mLb/avito_task/internal/models/feed"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=outbox.go -destination=../../repository/mocks/outbox_repository_mock.go -package=mocks OutboxRepository
+type OutboxRepository interface {
+	Dispatch(ctx context.Context, limit int, handle func(context.Context, models.Event) error) (int, error)
+}
+
+// OutboxFeedRepository читает outbox без захвата строк, поэтому каждая реплика видит все события
+type OutboxFeedRepository interface {
+	LastID(ctx context.Context) (uint64, error)
+	ListAfter(ctx context.Context, afterID uint64, gaps []uint64, limit int) ([]models.Event, error)
+}
+
+// EventSink - получатель доменных событий из outbox. Событие может прийти повторно,
+// поэтому получатель должен быть готов к дубликатам (например, по Event.ID)
+type EventSink interface {
+	Handle(ctx context.Context, event models.Event) error
+}
+
+type Dispatcher struct {
+	repo      OutboxRepository
+	batchSize int
+	sinks     []EventSink
+}
+
+func NewDispatcher(repo OutboxRepository, batchSize int, sinks ...EventSink) *Dispatcher {
+	return &Dispatcher{
+		repo:      repo,
+		batchSize: batchSize,
+		sinks:     sinks,
+	}
+}
+
+// DispatchPending передает очередную порцию событий всем получателям и возвращает число разосланных.
+// Событие считается разосланным, только если его приняли все получатели
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	const op = "Dispatcher.DispatchPending"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	count, err := d.repo.Dispatch(ctx, d.batchSize, d.handle)
+	if err != nil {
+		logger.WithError(err).Error("failed to dispatch outbox events")
+		return 0, err
+	}
+
+	if count > 0 {
+		logger.WithField("count", count).Debug("outbox events dispatched")
+	}
+
+	return count, nil
+}
+
+func (d *Dispatcher) handle(ctx context.Context, event models.Event) error {
+	for i, sink := range d.sinks {
+		if err := sink.Handle(ctx, event); err != nil {
+			return fmt.Errorf("sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Fanout передает все события outbox локальным получателям реплики (ленте событий).
+// В отличие от Dispatcher строки не захватываются: у каждой реплики свой курсор по id.
+// Номера из outbox могут коммититься не по порядку, поэтому пропущенные номера перечитываются
+// в течение gapTimeout; позже закоммиченное событие приходит после событий с большим id
+type Fanout struct {
+	repo       OutboxFeedRepository
+	batchSize  int
+	gapTimeout time.Duration
+	sinks      []EventSink
+
+	mu      sync.Mutex
+	started bool
+	cursor  uint64
+	gaps    map[uint64]time.Time
+}
+
+func NewFanout(repo OutboxFeedRepository, batchSize int, gapTimeout time.Duration, sinks ...EventSink) *Fanout {
+	return &Fanout{
+		repo:       repo,
+		batchSize:  batchSize,
+		gapTimeout: gapTimeout,
+		sinks:      sinks,
+		gaps:       make(map[uint64]time.Time),
+	}
+}
+
+// Poll передает получателям новые события и возвращает их число. Первый вызов только
+// запоминает последний номер: реплика рассылает события, появившиеся после ее запуска.
+// Ошибки получателей не повторяются - события ленты живут только в памяти реплики
+func (f *Fanout) Poll(ctx context.Context) (int, error) {
+	const op = "Fanout.Poll"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.started {
+		lastID, err := f.repo.LastID(ctx)
+		if err != nil {
+			logger.WithError(err).Error("failed to get last outbox id")
+			return 0, err
+		}
+		f.cursor, f.started = lastID, true
+		return 0, nil
+	}
+
+	now := time.Now()
+	gaps := make([]uint64, 0, len(f.gaps))
+	for id, since := range f.gaps {
+		if now.Sub(since) >= f.gapTimeout {
+			delete(f.gaps, id)
+			continue
+		}
+		gaps = append(gaps, id)
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+
+	events, err := f.repo.ListAfter(ctx, f.cursor, gaps, f.batchSize)
+	if err != nil {
+		logger.WithError(err).Error("failed to list outbox events")
+		return 0, err
+	}
+
+	for _, event := range events {
+		if _, ok := f.gaps[event.ID]; ok {
+			delete(f.gaps, event.ID)
+		} else if event.ID > f.cursor {
+			from := f.cursor + 1
+			if event.ID-from > uint64(f.batchSize) {
+				from = event.ID - uint64(f.batchSize)
+			}
+			for id := from; id < event.ID; id++ {
+				f.gaps[id] = now
+			}
+			f.cursor = event.ID
+		} else {
+			continue
+		}
+
+		for i, sink := range f.sinks {
+			if err := sink.Handle(ctx, event); err != nil {
+				logger.WithError(err).WithField("outbox_id", event.ID).Warnf("sink %d failed to handle outbox event", i)
+			}
+		}
+	}
+
+	return len(events), nil
+}
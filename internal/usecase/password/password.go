@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	mail "github.com/nik-mLb/avito_task/internal/models/mail"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	authuc "github.com/nik-mLb/avito_task/internal/usecase/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//go:generate mockgen -source=password.go -destination=../../repository/mocks/password_repository_mock.go -package=mocks PasswordRepository,Mailer
+type PasswordRepository interface {
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreatePasswordReset(ctx context.Context, userID uuid.UUID, tokenHash []byte, ttl time.Duration) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash []byte) (uuid.UUID, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mail.Message) error
+}
+
+// ResetPolicy настройки сброса пароля. Ссылка в письме - ResetURL с параметром token
+type ResetPolicy struct {
+	TokenTTL time.Duration
+	ResetURL string
+}
+
+type PasswordUsecase struct {
+	repo           PasswordRepository
+	mailer         Mailer
+	passwordPolicy authuc.PasswordPolicy
+	resetPolicy    ResetPolicy
+}
+
+func NewPasswordUsecase(repo PasswordRepository, mailer Mailer, passwordPolicy authuc.PasswordPolicy, resetPolicy ResetPolicy) *PasswordUsecase {
+	return &PasswordUsecase{
+		repo:           repo,
+		mailer:         mailer,
+		passwordPolicy: passwordPolicy,
+		resetPolicy:    resetPolicy,
+	}
+}
+
+// hashResetToken - в БД хранится только SHA-256 токена, поэтому утечка таблицы не дает сбросить пароль
+func hashResetToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// ForgotPassword создает токен сброса и отправляет его письмом. Для неизвестного email
+// ничего не делает и тоже возвращает nil, чтобы ответ не выдавал, зарегистрирован ли адрес.
+// Письмо отправляется в фоне: время ответа не зависит от почтового сервера
+func (uc *PasswordUsecase) ForgotPassword(ctx context.Context, email string) error {
+	const op = "PasswordUsecase.ForgotPassword"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	email, err := authuc.NormalizeEmail(email)
+	if err != nil {
+		logger.Warn("invalid email")
+		return err
+	}
+	logger = logger.WithField("email", email)
+
+	user, err := uc.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		logger.WithError(err).Error("failed to get user by email")
+		return err
+	}
+	if user == nil || !user.Active {
+		logger.WithField("user_found", user != nil).Info("password reset is not available for email")
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		logger.WithError(err).Error("failed to generate reset token")
+		return fmt.Errorf("generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := uc.repo.CreatePasswordReset(ctx, user.ID, hashResetToken(token), uc.resetPolicy.TokenTTL); err != nil {
+		logger.WithError(err).Error("failed to create password reset")
+		return err
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Чтобы задать новый пароль, перейдите по ссылке:\n%s?token=%s\n\n"+
+			"Ссылка действует %s и только один раз. Если вы не запрашивали сброс, просто проигнорируйте письмо.\n",
+			uc.resetPolicy.ResetURL, url.QueryEscape(token), uc.resetPolicy.TokenTTL),
+	}
+
+	go func(ctx context.Context) {
+		if err := uc.mailer.Send(ctx, msg); err != nil {
+			logger.WithError(err).Error("failed to send password reset mail")
+			return
+		}
+		logger.Info("password reset mail sent")
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// ResetPassword погашает токен сброса и задает новый пароль. Все ранее выданные токены пользователя отзываются
+func (uc *PasswordUsecase) ResetPassword(ctx context.Context, token, password string) error {
+	const op = "PasswordUsecase.ResetPassword"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	if token == "" {
+		logger.Warn("empty reset token")
+		return errs.ErrInvalidResetToken
+	}
+
+	if err := uc.passwordPolicy.Validate(password); err != nil {
+		logger.WithError(err).Warn("weak password")
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.WithError(err).Error("failed to hash password")
+		return err
+	}
+
+	userID, err := uc.repo.ResetPassword(ctx, hashResetToken(token), passwordHash)
+	if err != nil {
+		logger.WithError(err).Warn("failed to reset password")
+		return err
+	}
+
+	logger.WithField("user_id", userID).Info("password reset")
+	return nil
+}
@@ -2,11 +2,15 @@ package usecase
 
 import (
 	"context"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	models "github.com/nik-mLb/avito_task/internal/models/pickup_point"
+	"github.com/nik-mLb/avito_task/internal/tracing"
 	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
 
@@ -21,7 +25,11 @@ var (
 //go:generate mockgen -source=pickup_point.go -destination=../../repository/mocks/pickup_point_repository_mock.go -package=mocks PickupPointRepository
 type PickupPointRepository interface {
 	CreatePickupPoint(ctx context.Context, city string) (*models.PickupPoint, error)
-	GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error)
+	GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int) ([]dto.PickupPointListResponse, error)
+	StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, page, limit int, fn func(dto.PickupPointListResponse) error) error
+	GetOccupancy(ctx context.Context, pvzID uuid.UUID) (*models.Occupancy, error)
+	SetCapacity(ctx context.Context, pvzID uuid.UUID, capacity *int, mode models.CapacityMode) error
+	ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, pvzID *uuid.UUID, fn func(models.ExportRow) error) error
 }
 
 type PickupPointUsecase struct {
@@ -34,6 +42,8 @@ func NewPickupPointUsecase(repo PickupPointRepository) *PickupPointUsecase {
 
 func (uc *PickupPointUsecase) CreatePickupPoint(ctx context.Context, city string) (*models.PickupPoint, error) {
 	const op = "PickupPointUsecase.CreatePickupPoint"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("city", city)
 
 	if !allowedCities[city] {
@@ -52,6 +62,8 @@ func (uc *PickupPointUsecase) CreatePickupPoint(ctx context.Context, city string
 
 func (uc *PickupPointUsecase) GetPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]dto.PickupPointListResponse, error) {
     const op = "PickupPointUsecase.GetPickupPointsWithReceptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
 		"startDate": startDate,
 		"endDate":   endDate,
@@ -66,11 +78,154 @@ func (uc *PickupPointUsecase) GetPickupPointsWithReceptions(ctx context.Context,
         limit = 10
     }
 
-    list, err := uc.repo.GetPickupPointsWithReceptions(ctx, startDate, endDate, page, limit)
+	scope, err := pickupPointScope(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pickup point scope")
+		return nil, err
+	}
+
+    list, err := uc.repo.GetPickupPointsWithReceptions(ctx, startDate, endDate, scope, page, limit)
 	if err != nil {
 		logger.WithError(err).Error("failed to get pickup points with receptions")
 		return nil, err
 	}
 
 	return list, nil
-}
\ No newline at end of file
+}
+
+// maxStreamLimit - наибольший размер страницы потоковой выдачи ПВЗ. Ответ не собирается в памяти,
+// поэтому лимит намного больше, чем у обычной выдачи
+const maxStreamLimit = 10000
+
+// StreamPickupPointsWithReceptions как GetPickupPointsWithReceptions, но передает ПВЗ в fn по одному.
+// Некорректные page и limit не заменяются значениями по умолчанию, а возвращают ErrInvalidPagination
+func (uc *PickupPointUsecase) StreamPickupPointsWithReceptions(ctx context.Context, startDate, endDate *time.Time, page, limit int, fn func(dto.PickupPointListResponse) error) error {
+	const op = "PickupPointUsecase.StreamPickupPointsWithReceptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"page":      page,
+		"limit":     limit,
+	})
+
+	if page < 1 || limit < 1 || limit > maxStreamLimit {
+		logger.Warn("invalid pagination")
+		return errs.ErrInvalidPagination
+	}
+
+	scope, err := pickupPointScope(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pickup point scope")
+		return err
+	}
+
+	if err := uc.repo.StreamPickupPointsWithReceptions(ctx, startDate, endDate, scope, page, limit, fn); err != nil {
+		logger.WithError(err).Error("failed to stream pickup points with receptions")
+		return err
+	}
+
+	return nil
+}
+
+func (uc *PickupPointUsecase) GetOccupancy(ctx context.Context, pvzID string) (*models.Occupancy, error) {
+	const op = "PickupPointUsecase.GetOccupancy"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
+
+	uuidPvzID, err := uuid.Parse(pvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzID")
+		return nil, errs.ErrInvalidPickupPointID
+	}
+
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
+	occupancy, err := uc.repo.GetOccupancy(ctx, uuidPvzID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get occupancy")
+		return nil, err
+	}
+
+	return occupancy, nil
+}
+
+// SetCapacity меняет вместимость ПВЗ и возвращает актуальную заполненность
+func (uc *PickupPointUsecase) SetCapacity(ctx context.Context, pvzID string, capacity *int, mode string) (*models.Occupancy, error) {
+	const op = "PickupPointUsecase.SetCapacity"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("mode", mode)
+
+	uuidPvzID, err := uuid.Parse(pvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzID")
+		return nil, errs.ErrInvalidPickupPointID
+	}
+
+	// Колонка capacity - INT, большие значения Postgres не примет
+	if capacity != nil && (*capacity < 0 || *capacity > math.MaxInt32) {
+		logger.WithField("capacity", *capacity).Warn("capacity out of range")
+		return nil, errs.ErrInvalidCapacity
+	}
+
+	capacityMode := models.CapacityMode(mode)
+	switch capacityMode {
+	case "":
+		capacityMode = models.CapacityHard
+	case models.CapacityHard, models.CapacitySoft:
+		// valid mode
+	default:
+		logger.Warn("invalid capacity mode")
+		return nil, errs.ErrInvalidCapacity
+	}
+
+	if err := uc.repo.SetCapacity(ctx, uuidPvzID, capacity, capacityMode); err != nil {
+		logger.WithError(err).Error("failed to set capacity")
+		return nil, err
+	}
+
+	return uc.GetOccupancy(ctx, pvzID)
+}
+
+func (uc *PickupPointUsecase) ExportPickupPoints(ctx context.Context, startDate, endDate *time.Time, fn func(models.ExportRow) error) error {
+	const op = "PickupPointUsecase.ExportPickupPoints"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
+		"startDate": startDate,
+		"endDate":   endDate,
+	})
+
+	scope, err := pickupPointScope(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pickup point scope")
+		return err
+	}
+
+	if err := uc.repo.ExportPickupPoints(ctx, startDate, endDate, scope, fn); err != nil {
+		logger.WithError(err).Error("failed to export pickup points")
+		return err
+	}
+
+	return nil
+}
+
+// pickupPointScope возвращает ПВЗ, которым ограничен пользователь, или nil, если он видит все ПВЗ.
+// Выдачи и выгрузка для такого пользователя сужаются до его ПВЗ
+func pickupPointScope(ctx context.Context) (*uuid.UUID, error) {
+	scope, ok := authctx.GetPickupPointID(ctx)
+	if !ok {
+		return nil, nil
+	}
+	pvzID, err := uuid.Parse(scope)
+	if err != nil {
+		return nil, errs.ErrPickupPointForbidden
+	}
+	return &pvzID, nil
+}
@@ -5,14 +5,17 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	models "github.com/nik-mLb/avito_task/internal/models/product"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
 
 //go:generate mockgen -source=product.go -destination=../../repository/mocks/product_repository_mock.go -package=mocks ProductRepository
 type ProductRepository interface {
 	AddProduct(ctx context.Context, pvzID uuid.UUID, productType string) (*models.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error
+	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) (*models.Product, error)
 }
 
 type ProductUsecase struct {
@@ -20,11 +23,15 @@ type ProductUsecase struct {
 }
 
 func NewProductUsecase(repo ProductRepository) *ProductUsecase {
-	return &ProductUsecase{repo: repo}
+	return &ProductUsecase{
+		repo: repo,
+	}
 }
 
 func (uc *ProductUsecase) AddProduct(ctx context.Context, pvzID, productType string) (*models.Product, error) {
 	const op = "ProductUsecase.AddProduct"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
 		"pvz_id":      pvzID,
 		"productType": productType,
@@ -36,12 +43,17 @@ func (uc *ProductUsecase) AddProduct(ctx context.Context, pvzID, productType str
 		return nil, fmt.Errorf("invalid pvzId: %w", err)
 	}
 
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
 	switch models.ProductType(productType) {
 	case models.Electronics, models.Clothing, models.Shoes:
 		// valid type
 	default:
 		logger.Warn("invalid product type")
-		return nil, fmt.Errorf("invalid product type: %s", productType)
+		return nil, fmt.Errorf("%w: %s", errs.ErrInvalidProductType, productType)
 	}
 
 	product, err := uc.repo.AddProduct(ctx, uuidPvzID, productType)
@@ -55,6 +67,8 @@ func (uc *ProductUsecase) AddProduct(ctx context.Context, pvzID, productType str
 
 func (uc *ProductUsecase) DeleteLastProduct(ctx context.Context, pvzID string) error {
 	const op = "ProductUsecase.DeleteLastProduct"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
 
 	uuidPvzID, err := uuid.Parse(pvzID)
@@ -63,7 +77,12 @@ func (uc *ProductUsecase) DeleteLastProduct(ctx context.Context, pvzID string) e
 		return fmt.Errorf("invalid pvzId: %w", err)
 	}
 
-	err = uc.repo.DeleteLastProduct(ctx, uuidPvzID)
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return errs.ErrPickupPointForbidden
+	}
+
+	_, err = uc.repo.DeleteLastProduct(ctx, uuidPvzID)
 	if err != nil {
 		logger.WithError(err).Error("failed to delete last product")
 		return err
@@ -2,10 +2,17 @@ package usecase
 
 import (
 	"context"
+	"expvar"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 	models "github.com/nik-mLb/avito_task/internal/models/reception"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
 )
 
@@ -13,18 +20,36 @@ import (
 type ReceptionRepository interface {
 	CreateReception(ctx context.Context, receptionID uuid.UUID, pvzID uuid.UUID) (*models.Reception, error)
 	CloseReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
+	CloseStaleReceptions(ctx context.Context, idleTimeout time.Duration, reason string) ([]models.Reception, error)
+	ReopenReception(ctx context.Context, receptionID, reopenedBy uuid.UUID, reason string, window time.Duration) (*models.Reception, error)
 }
 
+type ReceptionHistoryRepository interface {
+	GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]history.Event, error)
+	GetReceptionPickupPoint(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error)
+}
+
+// autoClosedTotal количество приемок, закрытых автоматически
+var autoClosedTotal = expvar.NewInt("receptions_auto_closed_total")
+
 type ReceptionUsecase struct {
-	repo ReceptionRepository
+	repo         ReceptionRepository
+	history      ReceptionHistoryRepository
+	reopenWindow time.Duration
 }
 
-func NewReceptionUsecase(repo ReceptionRepository) *ReceptionUsecase {
-	return &ReceptionUsecase{repo: repo}
+func NewReceptionUsecase(repo ReceptionRepository, history ReceptionHistoryRepository, reopenWindow time.Duration) *ReceptionUsecase {
+	return &ReceptionUsecase{
+		repo:         repo,
+		history:      history,
+		reopenWindow: reopenWindow,
+	}
 }
 
 func (uc *ReceptionUsecase) CreateReception(ctx context.Context, pvzID string) (*models.Reception, error) {
 	const op = "ReceptionUsecase.CreateReception"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
 
 	uuidPvzID, err := uuid.Parse(pvzID)
@@ -33,6 +58,11 @@ func (uc *ReceptionUsecase) CreateReception(ctx context.Context, pvzID string) (
 		return nil, err
 	}
 
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
 	receptionID := uuid.New()
 
 	reception, err := uc.repo.CreateReception(ctx, receptionID, uuidPvzID)
@@ -46,6 +76,8 @@ func (uc *ReceptionUsecase) CreateReception(ctx context.Context, pvzID string) (
 
 func (uc *ReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*models.Reception, error) {
     const op = "ReceptionUsecase.CloseReception"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID)
 
 	uuidPvzID, err := uuid.Parse(pvzID)
@@ -54,6 +86,11 @@ func (uc *ReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*
 		return nil, fmt.Errorf("invalid pvzId: %w", err)
 	}
 
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
 	reception, err := uc.repo.CloseReception(ctx, uuidPvzID)
 	if err != nil {
 		logger.WithError(err).Error("failed to close reception")
@@ -61,4 +98,103 @@ func (uc *ReceptionUsecase) CloseReception(ctx context.Context, pvzID string) (*
 	}
 
 	return reception, nil
+}
+
+// CloseStaleReceptions закрывает приемки без активности дольше idleTimeout
+func (uc *ReceptionUsecase) CloseStaleReceptions(ctx context.Context, idleTimeout time.Duration) (int, error) {
+	const op = "ReceptionUsecase.CloseStaleReceptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("idle_timeout", idleTimeout)
+
+	reason := fmt.Sprintf("auto: idle for more than %s", idleTimeout)
+
+	closed, err := uc.repo.CloseStaleReceptions(ctx, idleTimeout, reason)
+	if err != nil {
+		logger.WithError(err).Error("failed to close stale receptions")
+		return 0, err
+	}
+
+	for _, reception := range closed {
+		logger.WithField("reception_id", reception.ID).
+			WithField("pvz_id", reception.PickupPointID).
+			WithField("reason", reason).
+			Info("reception closed automatically")
+	}
+	autoClosedTotal.Add(int64(len(closed)))
+
+	return len(closed), nil
+}
+
+// ReopenReception повторно открывает закрытую приемку от имени администратора
+func (uc *ReceptionUsecase) ReopenReception(ctx context.Context, receptionID, userID, reason string) (*models.Reception, error) {
+	const op = "ReceptionUsecase.ReopenReception"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
+		"reception_id": receptionID,
+		"user_id":      userID,
+	})
+
+	uuidReceptionID, err := uuid.Parse(receptionID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid receptionID")
+		return nil, errs.ErrInvalidReceptionID
+	}
+
+	uuidUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid userID")
+		return nil, fmt.Errorf("invalid userId: %w", err)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		logger.Warn("empty reopen reason")
+		return nil, errs.ErrReopenReasonRequired
+	}
+
+	reception, err := uc.repo.ReopenReception(ctx, uuidReceptionID, uuidUserID, reason, uc.reopenWindow)
+	if err != nil {
+		logger.WithError(err).Error("failed to reopen reception")
+		return nil, err
+	}
+
+	logger.WithField("reason", reason).Info("reception reopened")
+
+	return reception, nil
+}
+
+func (uc *ReceptionUsecase) GetReceptionHistory(ctx context.Context, receptionID string) ([]history.Event, error) {
+	const op = "ReceptionUsecase.GetReceptionHistory"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("reception_id", receptionID)
+
+	uuidReceptionID, err := uuid.Parse(receptionID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid receptionID")
+		return nil, errs.ErrInvalidReceptionID
+	}
+
+	// ПВЗ приемки нужен только пользователю, ограниченному одним ПВЗ
+	if _, scoped := authctx.GetPickupPointID(ctx); scoped {
+		pvzID, err := uc.history.GetReceptionPickupPoint(ctx, uuidReceptionID)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get reception pickup point")
+			return nil, err
+		}
+		if !authctx.PickupPointAllowed(ctx, pvzID.String()) {
+			logger.WithField("pvz_id", pvzID).Warn("pickup point is outside of user scope")
+			return nil, errs.ErrPickupPointForbidden
+		}
+	}
+
+	events, err := uc.history.GetReceptionHistory(ctx, uuidReceptionID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get reception history")
+		return nil, err
+	}
+
+	return events, nil
 }
\ No newline at end of file
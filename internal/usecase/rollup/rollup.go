@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/rollup"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=rollup.go -destination=../../repository/mocks/rollup_repository_mock.go -package=mocks RollupRepository
+type RollupRepository interface {
+	Refresh(ctx context.Context, lag time.Duration) (int, error)
+	Backfill(ctx context.Context, from, to time.Time) (int, error)
+	Check(ctx context.Context, from, to time.Time) ([]models.Mismatch, error)
+}
+
+type RollupUsecase struct {
+	repo RollupRepository
+	lag  time.Duration
+}
+
+func NewRollupUsecase(repo RollupRepository, lag time.Duration) *RollupUsecase {
+	return &RollupUsecase{
+		repo: repo,
+		lag:  lag,
+	}
+}
+
+// Refresh пересчитывает агрегаты, затронутые изменениями после watermark
+func (uc *RollupUsecase) Refresh(ctx context.Context) (int, error) {
+	const op = "RollupUsecase.Refresh"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	count, err := uc.repo.Refresh(ctx, uc.lag)
+	if err != nil {
+		logger.WithError(err).Error("failed to refresh rollups")
+		return 0, err
+	}
+
+	if count > 0 {
+		logger.WithField("days", count).Info("rollups refreshed")
+	}
+
+	return count, nil
+}
+
+// Backfill пересчитывает агрегаты за дни from..to включительно
+func (uc *RollupUsecase) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	const op = "RollupUsecase.Backfill"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("from", from).WithField("to", to)
+
+	if from.After(to) {
+		logger.Warn("from is after to")
+		return 0, errs.ErrInvalidDateRange
+	}
+
+	count, err := uc.repo.Backfill(ctx, from, to)
+	if err != nil {
+		logger.WithError(err).Error("failed to backfill rollups")
+		return 0, err
+	}
+
+	logger.WithField("days", count).Info("rollups backfilled")
+	return count, nil
+}
+
+// Check сверяет агрегаты за дни from..to с сырыми данными
+func (uc *RollupUsecase) Check(ctx context.Context, from, to time.Time) ([]models.Mismatch, error) {
+	const op = "RollupUsecase.Check"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("from", from).WithField("to", to)
+
+	if from.After(to) {
+		logger.Warn("from is after to")
+		return nil, errs.ErrInvalidDateRange
+	}
+
+	mismatches, err := uc.repo.Check(ctx, from, to)
+	if err != nil {
+		logger.WithError(err).Error("failed to check rollups")
+		return nil, err
+	}
+
+	if len(mismatches) > 0 {
+		logger.WithField("mismatches", len(mismatches)).Warn("rollups differ from raw data")
+	}
+
+	return mismatches, nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=session.go -destination=../../repository/mocks/session_repository_mock.go -package=mocks SessionRepository
+type SessionRepository interface {
+	ListSessions(ctx context.Context, userID uuid.UUID, ttl time.Duration) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+}
+
+type SessionUsecase struct {
+	repo SessionRepository
+	// tokenTTL - срок жизни токена: более старые сессии уже не действуют и не показываются
+	tokenTTL time.Duration
+}
+
+func NewSessionUsecase(repo SessionRepository, tokenTTL time.Duration) *SessionUsecase {
+	return &SessionUsecase{
+		repo:     repo,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// ListSessions возвращает действующие сессии пользователя, отмечая среди них currentSessionID
+func (uc *SessionUsecase) ListSessions(ctx context.Context, userID, currentSessionID string) ([]models.Session, error) {
+	const op = "SessionUsecase.ListSessions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid user id")
+		return nil, errs.ErrInvalidUserID
+	}
+
+	sessions, err := uc.repo.ListSessions(ctx, id, uc.tokenTTL)
+	if err != nil {
+		logger.WithError(err).Error("failed to list sessions")
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession завершает сессию пользователя. Можно завершить и текущую сессию
+func (uc *SessionUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	const op = "SessionUsecase.RevokeSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID).WithField("session_id", sessionID)
+
+	uuidUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid user id")
+		return errs.ErrInvalidUserID
+	}
+
+	uuidSessionID, err := uuid.Parse(sessionID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid session id")
+		return errs.ErrInvalidSessionID
+	}
+
+	if err := uc.repo.RevokeSession(ctx, uuidUserID, uuidSessionID); err != nil {
+		if errors.Is(err, errs.ErrSessionNotFound) {
+			logger.Warn("session not found")
+		} else {
+			logger.WithError(err).Error("failed to revoke session")
+		}
+		return err
+	}
+
+	logger.Info("session revoked")
+	return nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/statistics"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=statistics.go -destination=../../repository/mocks/statistics_repository_mock.go -package=mocks StatisticsRepository
+type StatisticsRepository interface {
+	GetStats(ctx context.Context, filter models.Filter) ([]models.Group, error)
+}
+
+type StatisticsUsecase struct {
+	repo StatisticsRepository
+}
+
+func NewStatisticsUsecase(repo StatisticsRepository) *StatisticsUsecase {
+	return &StatisticsUsecase{repo: repo}
+}
+
+// GetStats строит отчет по приемкам; пустой groupBy означает группировку по ПВЗ
+func (uc *StatisticsUsecase) GetStats(ctx context.Context, groupBy string, startDate, endDate *time.Time) (*models.Report, error) {
+	const op = "StatisticsUsecase.GetStats"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).
+		WithField("group_by", groupBy).
+		WithField("start_date", startDate).
+		WithField("end_date", endDate)
+
+	group := models.GroupBy(groupBy)
+	switch group {
+	case "":
+		group = models.GroupByPickupPoint
+	case models.GroupByPickupPoint, models.GroupByCity, models.GroupByDay, models.GroupByWeek, models.GroupByProductType:
+	default:
+		logger.Warn("invalid grouping")
+		return nil, errs.ErrInvalidStatsGroupBy
+	}
+
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		logger.Warn("startDate is after endDate")
+		return nil, errs.ErrInvalidDateRange
+	}
+
+	groups, err := uc.repo.GetStats(ctx, models.Filter{
+		GroupBy:   group,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to get stats")
+		return nil, err
+	}
+
+	return &models.Report{
+		GroupBy:   group,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Groups:    groups,
+	}, nil
+}
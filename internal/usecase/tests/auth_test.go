@@ -2,18 +2,23 @@ package tests
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/nik-mLb/avito_task/config"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
 	user "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/permission"
 	"github.com/nik-mLb/avito_task/internal/repository/mocks"
 	"github.com/nik-mLb/avito_task/internal/transport/jwt"
 	usecase "github.com/nik-mLb/avito_task/internal/usecase/auth"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 )
@@ -23,7 +28,47 @@ func createTestTokenator() *jwt.Tokenator {
 		Signature:   "test-secret-key-123",
 		TokenLifeSpan: 24 * time.Hour,
 	}
-	return jwt.NewTokenator(cfg)
+	tokenator, _ := jwt.NewTokenator(cfg)
+	return tokenator
+}
+
+var testPasswordPolicy = usecase.PasswordPolicy{
+	MinLength:     8,
+	RequireLetter: true,
+	RequireDigit:  true,
+}
+
+var testLoginPolicy = usecase.LoginPolicy{
+	MaxFailures:     3,
+	MaxIPFailures:   10,
+	Window:          15 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+	DelayBase:       time.Millisecond,
+	DelayMax:        4 * time.Millisecond,
+}
+
+var testClient = session.Client{IP: "192.0.2.1", UserAgent: "scanner/1.0"}
+
+// testRoles - стандартные роли и supervisor, которая есть только в конфиге
+var testRoles = func() *permission.Policy {
+	roles := map[string][]permission.Permission{"supervisor": {permission.ReceptionRead}}
+	for role, perms := range permission.DefaultRoles {
+		roles[role] = perms
+	}
+	policy, err := permission.NewPolicy(roles)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}()
+
+// testRegistrationPolicy разрешает самостоятельную регистрацию, чтобы проверять ее без приглашений
+var testRegistrationPolicy = usecase.RegistrationPolicy{SelfRegistration: true, Roles: testRoles}
+
+var testTwoFactorPolicy = usecase.TwoFactorPolicy{
+	RequiredRoles: map[string]bool{"admin": true},
+	Issuer:        "PVZ Service",
+	ChallengeTTL:  5 * time.Minute,
 }
 
 func TestAuthUsecase_Authenticate(t *testing.T) {
@@ -33,7 +78,20 @@ func TestAuthUsecase_Authenticate(t *testing.T) {
 	mockRepo := mocks.NewMockAuthRepository(ctrl)
 	mockTokenator := createTestTokenator()
 
-	uc := usecase.New(mockRepo, mockTokenator)
+	uc := usecase.New(mockRepo, mockTokenator, testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	const ip = "192.0.2.1"
+	client := session.Client{Device: "terminal-1", IP: ip, UserAgent: "scanner/1.0"}
+
+	// expectFailure ожидает учет неудачной попытки по email и по IP
+	expectFailure := func(email string) {
+		mockRepo.EXPECT().
+			RecordLoginFailure(gomock.Any(), user.LoginScopeEmail, email, 3, 15*time.Minute, 15*time.Minute).
+			Return(nil)
+		mockRepo.EXPECT().
+			RecordLoginFailure(gomock.Any(), user.LoginScopeIP, ip, 10, 15*time.Minute, 15*time.Minute).
+			Return(nil)
+	}
 
 	t.Run("successful authentication", func(t *testing.T) {
 		email := "test@example.com"
@@ -46,29 +104,149 @@ func TestAuthUsecase_Authenticate(t *testing.T) {
 			Email:        email,
 			PasswordHash: hashedPassword,
 			Role:         "worker",
+			Active:       true,
 		}
 
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{Failures: 2}, nil)
 		mockRepo.EXPECT().
 			GetUserByEmail(gomock.Any(), email).
 			Return(mockUser, nil)
+		mockRepo.EXPECT().
+			ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, email).
+			Return(nil)
+		sessionID := uuid.New()
+		mockRepo.EXPECT().
+			CreateSession(gomock.Any(), userID, client).
+			Return(sessionID, nil)
 
-		token, err := uc.Authenticate(context.Background(), email, password)
+		token, err := uc.Authenticate(context.Background(), " Test@Example.com", password, client)
 
 		assert.NoError(t, err)
-		assert.NotEmpty(t, token)
+		claims, err := mockTokenator.ParseJWT(token)
+		require.NoError(t, err)
+		assert.Equal(t, sessionID.String(), claims.SessionID)
+		assert.Empty(t, claims.PickupPointID)
+	})
+
+	t.Run("pickup point from invitation goes to token", func(t *testing.T) {
+		email := "scoped@example.com"
+		password := "password123"
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		userID := uuid.New()
+		pvzID := uuid.New()
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
+		mockRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), email).
+			Return(&user.User{ID: userID, Email: email, PasswordHash: hashedPassword, Role: "worker", Active: true, PickupPointID: &pvzID}, nil)
+		mockRepo.EXPECT().
+			ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, email).
+			Return(nil)
+		mockRepo.EXPECT().
+			CreateSession(gomock.Any(), userID, client).
+			Return(uuid.New(), nil)
+
+		token, err := uc.Authenticate(context.Background(), email, password, client)
+
+		require.NoError(t, err)
+		claims, err := mockTokenator.ParseJWT(token)
+		require.NoError(t, err)
+		assert.Equal(t, pvzID.String(), claims.PickupPointID)
+	})
+
+	t.Run("long device and user agent are truncated", func(t *testing.T) {
+		email := "test@example.com"
+		password := "password123"
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		userID := uuid.New()
+		long := session.Client{Device: strings.Repeat("д", 100), IP: ip, UserAgent: strings.Repeat("a", 1000)}
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
+		mockRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), email).
+			Return(&user.User{ID: userID, Email: email, PasswordHash: hashedPassword, Role: "worker", Active: true}, nil)
+		mockRepo.EXPECT().
+			ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, email).
+			Return(nil)
+		mockRepo.EXPECT().
+			CreateSession(gomock.Any(), userID, session.Client{Device: strings.Repeat("д", 64), IP: ip, UserAgent: strings.Repeat("a", 512)}).
+			Return(uuid.New(), nil)
+
+		_, err := uc.Authenticate(context.Background(), email, password, long)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("second factor required", func(t *testing.T) {
+		email := "admin@example.com"
+		password := "password123"
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		userID := uuid.New()
+		var storedHash []byte
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
+		mockRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), email).
+			Return(&user.User{ID: userID, Email: email, PasswordHash: hashedPassword, Role: "admin", Active: true}, nil)
+		mockRepo.EXPECT().
+			CreateLoginChallenge(gomock.Any(), userID, gomock.Any(), 5*time.Minute).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, tokenHash []byte, _ time.Duration) error {
+				storedHash = tokenHash
+				return nil
+			})
+
+		token, err := uc.Authenticate(context.Background(), email, password, client)
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorRequired)
+		assert.Empty(t, token)
+		var required *errs.TwoFactorRequiredError
+		require.ErrorAs(t, err, &required)
+		// TOTP у администратора еще не включен, поэтому его нужно настроить
+		assert.True(t, required.Enroll)
+		sum := sha256.Sum256([]byte(required.Challenge))
+		assert.Equal(t, sum[:], storedHash)
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		email := "test@example.com"
+		password := "password123"
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
+		mockRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), email).
+			Return(&user.User{ID: uuid.New(), Email: email, PasswordHash: hashedPassword, Role: "worker"}, nil)
+
+		token, err := uc.Authenticate(context.Background(), email, password, client)
+
+		assert.ErrorIs(t, err, errs.ErrUserDeactivated)
+		assert.Empty(t, token)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
 		email := "notfound@example.com"
 
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
 		mockRepo.EXPECT().
 			GetUserByEmail(gomock.Any(), email).
 			Return(nil, nil)
+		expectFailure(email)
 
-		token, err := uc.Authenticate(context.Background(), email, "anypassword")
+		token, err := uc.Authenticate(context.Background(), email, "anypassword", client)
 
-		assert.Error(t, err)
-		assert.Equal(t, "user not found", err.Error())
+		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
 		assert.Empty(t, token)
 	})
 
@@ -84,25 +262,63 @@ func TestAuthUsecase_Authenticate(t *testing.T) {
 			Role:         "worker",
 		}
 
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
 		mockRepo.EXPECT().
 			GetUserByEmail(gomock.Any(), email).
 			Return(mockUser, nil)
+		expectFailure(email)
 
-		token, err := uc.Authenticate(context.Background(), email, "wrongpassword")
+		token, err := uc.Authenticate(context.Background(), email, "wrongpassword", client)
 
-		assert.Error(t, err)
-		assert.Equal(t, "invalid password", err.Error())
+		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
+		assert.Empty(t, token)
+	})
+
+	t.Run("login is locked", func(t *testing.T) {
+		email := "test@example.com"
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{Failures: 3, LockedFor: 90 * time.Second}, nil)
+
+		token, err := uc.Authenticate(context.Background(), email, "password123", client)
+
+		assert.ErrorIs(t, err, errs.ErrLoginLocked)
+		var locked *errs.LoginLockedError
+		if assert.ErrorAs(t, err, &locked) {
+			assert.Equal(t, 90*time.Second, locked.RetryAfter)
+		}
+		assert.Empty(t, token)
+	})
+
+	t.Run("delay is interrupted by context", func(t *testing.T) {
+		email := "test@example.com"
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{Failures: 1}, nil)
+
+		token, err := uc.Authenticate(ctx, email, "password123", client)
+
+		assert.ErrorIs(t, err, context.Canceled)
 		assert.Empty(t, token)
 	})
 
 	t.Run("repository error", func(t *testing.T) {
 		email := "error@example.com"
 
+		mockRepo.EXPECT().
+			GetLoginThrottle(gomock.Any(), email, ip, 15*time.Minute).
+			Return(user.LoginThrottle{}, nil)
 		mockRepo.EXPECT().
 			GetUserByEmail(gomock.Any(), email).
 			Return(nil, errors.New("database error"))
 
-		token, err := uc.Authenticate(context.Background(), email, "anypassword")
+		token, err := uc.Authenticate(context.Background(), email, "anypassword", client)
 
 		assert.Error(t, err)
 		assert.Equal(t, "database error", err.Error())
@@ -110,6 +326,113 @@ func TestAuthUsecase_Authenticate(t *testing.T) {
 	})
 }
 
+func TestAuthUsecase_UnlockLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	t.Run("email and ip", func(t *testing.T) {
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, "test@example.com").Return(nil)
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeIP, "192.0.2.1").Return(nil)
+
+		assert.NoError(t, uc.UnlockLogin(context.Background(), "Test@Example.com", "192.0.2.1"))
+	})
+
+	t.Run("nothing to unlock", func(t *testing.T) {
+		assert.ErrorIs(t, uc.UnlockLogin(context.Background(), " ", ""), errs.ErrInvalidUnlockRequest)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeIP, "192.0.2.1").Return(expectedErr)
+
+		assert.ErrorIs(t, uc.UnlockLogin(context.Background(), "", "192.0.2.1"), expectedErr)
+	})
+}
+
+func TestAuthUsecase_CheckToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	tokenator := createTestTokenator()
+	uc := usecase.New(mockRepo, tokenator, testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	userID := uuid.New()
+	token, _ := tokenator.CreateJWT(userID.String(), "employee", "", "")
+	claims, _ := tokenator.ParseJWT(token)
+
+	t.Run("valid token", func(t *testing.T) {
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).
+			Return(&user.TokenState{ValidAfter: claims.IssuedAt.Time.Add(-time.Minute), Active: true}, nil)
+
+		assert.NoError(t, uc.CheckToken(context.Background(), claims))
+	})
+
+	t.Run("password never changed", func(t *testing.T) {
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(&user.TokenState{Active: true}, nil)
+
+		assert.NoError(t, uc.CheckToken(context.Background(), claims))
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(&user.TokenState{}, nil)
+
+		assert.ErrorIs(t, uc.CheckToken(context.Background(), claims), errs.ErrUserDeactivated)
+	})
+
+	t.Run("token issued before password change", func(t *testing.T) {
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).
+			Return(&user.TokenState{ValidAfter: claims.IssuedAt.Time.Add(time.Minute), Active: true}, nil)
+
+		assert.ErrorIs(t, uc.CheckToken(context.Background(), claims), errs.ErrTokenRevoked)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(nil, nil)
+
+		assert.ErrorIs(t, uc.CheckToken(context.Background(), claims), errs.ErrTokenRevoked)
+	})
+
+	t.Run("active session", func(t *testing.T) {
+		sessionID := uuid.New()
+		sessionToken, _ := tokenator.CreateJWT(userID.String(), "employee", sessionID.String(), "")
+		sessionClaims, _ := tokenator.ParseJWT(sessionToken)
+
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(&user.TokenState{Active: true}, nil)
+		mockRepo.EXPECT().TouchSession(gomock.Any(), sessionID, userID, time.Minute).Return(true, nil)
+
+		assert.NoError(t, uc.CheckToken(context.Background(), sessionClaims))
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		sessionID := uuid.New()
+		sessionToken, _ := tokenator.CreateJWT(userID.String(), "employee", sessionID.String(), "")
+		sessionClaims, _ := tokenator.ParseJWT(sessionToken)
+
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(&user.TokenState{Active: true}, nil)
+		mockRepo.EXPECT().TouchSession(gomock.Any(), sessionID, userID, time.Minute).Return(false, nil)
+
+		assert.ErrorIs(t, uc.CheckToken(context.Background(), sessionClaims), errs.ErrTokenRevoked)
+	})
+
+	t.Run("dummy token is not checked", func(t *testing.T) {
+		dummy, _ := tokenator.CreateDummyJWT(uuid.NewString(), "moderator")
+		dummyClaims, _ := tokenator.ParseJWT(dummy)
+
+		assert.NoError(t, uc.CheckToken(context.Background(), dummyClaims))
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().GetTokenState(gomock.Any(), userID).Return(nil, expectedErr)
+
+		assert.ErrorIs(t, uc.CheckToken(context.Background(), claims), expectedErr)
+	})
+}
+
 func TestAuthUsecase_Register(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -117,7 +440,7 @@ func TestAuthUsecase_Register(t *testing.T) {
 	mockRepo := mocks.NewMockAuthRepository(ctrl)
 	mockTokenator := createTestTokenator()
 
-	uc := usecase.New(mockRepo, mockTokenator)
+	uc := usecase.New(mockRepo, mockTokenator, testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
 
 	t.Run("successful registration", func(t *testing.T) {
 		email := "new@example.com"
@@ -134,22 +457,127 @@ func TestAuthUsecase_Register(t *testing.T) {
 		mockRepo.EXPECT().
 			CreateUser(gomock.Any(), email, gomock.Any(), role).
 			Return(mockUser, nil)
+		mockRepo.EXPECT().
+			CreateSession(gomock.Any(), userID, testClient).
+			Return(uuid.New(), nil)
 
-		token, err := uc.Register(context.Background(), email, password, role)
+		token, err := uc.Register(context.Background(), email, password, role, "", testClient)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 	})
 
+	t.Run("role from permission policy", func(t *testing.T) {
+		userID := uuid.New()
+
+		mockRepo.EXPECT().
+			CreateUser(gomock.Any(), "lead@example.com", gomock.Any(), "supervisor").
+			Return(&user.User{ID: userID, Email: "lead@example.com", Role: "supervisor"}, nil)
+		mockRepo.EXPECT().
+			CreateSession(gomock.Any(), userID, testClient).
+			Return(uuid.New(), nil)
+
+		token, err := uc.Register(context.Background(), "lead@example.com", "password123", "supervisor", "", testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+	})
+
+	t.Run("role with required second factor", func(t *testing.T) {
+		userID := uuid.New()
+
+		mockRepo.EXPECT().
+			CreateUser(gomock.Any(), "boss@example.com", gomock.Any(), "admin").
+			Return(&user.User{ID: userID, Email: "boss@example.com", Role: "admin"}, nil)
+		mockRepo.EXPECT().
+			CreateLoginChallenge(gomock.Any(), userID, gomock.Any(), 5*time.Minute).
+			Return(nil)
+
+		token, err := uc.Register(context.Background(), "boss@example.com", "password123", "admin", "", testClient)
+
+		assert.Empty(t, token)
+		var required *errs.TwoFactorRequiredError
+		require.ErrorAs(t, err, &required)
+		assert.True(t, required.Enroll)
+		assert.NotEmpty(t, required.Challenge)
+	})
+
 	t.Run("invalid role", func(t *testing.T) {
 		email := "new@example.com"
 		password := "password123"
 		role := "invalid-role"
 
-		token, err := uc.Register(context.Background(), email, password, role)
+		token, err := uc.Register(context.Background(), email, password, role, "", testClient)
 
 		assert.Error(t, err)
-		assert.Equal(t, errs.ErrCityNotAllowed, err)
+		assert.Equal(t, errs.ErrRoleNotAllowed, err)
+		assert.Empty(t, token)
+	})
+
+	t.Run("email is normalized", func(t *testing.T) {
+		mockRepo.EXPECT().
+			CreateUser(gomock.Any(), "new.user@example.com", gomock.Any(), "worker").
+			Return(&user.User{ID: uuid.New(), Email: "new.user@example.com", Role: "worker"}, nil)
+		mockRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), testClient).Return(uuid.New(), nil)
+
+		token, err := uc.Register(context.Background(), "  New.User@Example.COM ", "password123", "worker", "", testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+	})
+
+	invalidEmails := []string{
+		"",
+		"plainaddress",
+		"@example.com",
+		"user@",
+		"user@localhost",
+		"user@example..com",
+		"user@-example.com",
+		"User <user@example.com>",
+		"user@exa mple.com",
+	}
+	for _, email := range invalidEmails {
+		t.Run("invalid email "+email, func(t *testing.T) {
+			token, err := uc.Register(context.Background(), email, "password123", "worker", "", testClient)
+
+			assert.ErrorIs(t, err, errs.ErrInvalidEmail)
+			assert.Empty(t, token)
+		})
+	}
+
+	weakPasswords := []struct {
+		name     string
+		password string
+		policy   usecase.PasswordPolicy
+	}{
+		{name: "empty", password: "", policy: testPasswordPolicy},
+		{name: "too short", password: "pass1", policy: testPasswordPolicy},
+		{name: "no digit", password: "password", policy: testPasswordPolicy},
+		{name: "no letter", password: "12345678", policy: testPasswordPolicy},
+		{name: "longer than bcrypt limit", password: strings.Repeat("a1", 37), policy: testPasswordPolicy},
+		{name: "no uppercase", password: "password1", policy: usecase.PasswordPolicy{MinLength: 8, RequireUpper: true}},
+		{name: "no special", password: "Password1", policy: usecase.PasswordPolicy{MinLength: 8, RequireSpecial: true}},
+	}
+	for _, tt := range weakPasswords {
+		t.Run("weak password "+tt.name, func(t *testing.T) {
+			uc := usecase.New(mockRepo, mockTokenator, tt.policy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+			token, err := uc.Register(context.Background(), "new@example.com", tt.password, "worker", "", testClient)
+
+			assert.ErrorIs(t, err, errs.ErrWeakPassword)
+			assert.Empty(t, token)
+		})
+	}
+
+	t.Run("email already registered", func(t *testing.T) {
+		mockRepo.EXPECT().
+			CreateUser(gomock.Any(), "taken@example.com", gomock.Any(), "worker").
+			Return(nil, errs.ErrUserAlreadyExists)
+
+		token, err := uc.Register(context.Background(), "taken@example.com", "password123", "worker", "", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrUserAlreadyExists)
 		assert.Empty(t, token)
 	})
 
@@ -162,13 +590,60 @@ func TestAuthUsecase_Register(t *testing.T) {
 			CreateUser(gomock.Any(), email, gomock.Any(), role).
 			Return(nil, errors.New("database error"))
 
-		token, err := uc.Register(context.Background(), email, password, role)
+		token, err := uc.Register(context.Background(), email, password, role, "", testClient)
 
 		assert.Error(t, err)
 		assert.Equal(t, "database error", err.Error())
 		assert.Empty(t, token)
 	})
 
+	t.Run("self-registration disabled", func(t *testing.T) {
+		uc := usecase.New(mockRepo, mockTokenator, testPasswordPolicy, testLoginPolicy, usecase.RegistrationPolicy{}, testTwoFactorPolicy)
+
+		token, err := uc.Register(context.Background(), "new@example.com", "password123", "admin", "", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrRegistrationClosed)
+		assert.Empty(t, token)
+	})
+
+	t.Run("registration by invitation", func(t *testing.T) {
+		uc := usecase.New(mockRepo, mockTokenator, testPasswordPolicy, testLoginPolicy, usecase.RegistrationPolicy{}, testTwoFactorPolicy)
+		userID := uuid.New()
+		sum := sha256.Sum256([]byte("invite-token"))
+
+		// Email и роль из запроса игнорируются, пользователь получает роль из приглашения
+		mockRepo.EXPECT().
+			CreateUserFromInvitation(gomock.Any(), sum[:], gomock.Any()).
+			Return(&user.User{ID: userID, Email: "invited@example.com", Role: "worker"}, nil)
+		mockRepo.EXPECT().CreateSession(gomock.Any(), userID, testClient).Return(uuid.New(), nil)
+
+		token, err := uc.Register(context.Background(), "", "password123", "admin", "invite-token", testClient)
+
+		require.NoError(t, err)
+		claims, err := mockTokenator.ParseJWT(token)
+		require.NoError(t, err)
+		assert.Equal(t, userID.String(), claims.UserID)
+		assert.Equal(t, "worker", claims.Role)
+	})
+
+	t.Run("invalid invitation", func(t *testing.T) {
+		mockRepo.EXPECT().
+			CreateUserFromInvitation(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errs.ErrInvalidInvitation)
+
+		token, err := uc.Register(context.Background(), "", "password123", "", "used-token", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidInvitation)
+		assert.Empty(t, token)
+	})
+
+	t.Run("weak password with invitation", func(t *testing.T) {
+		token, err := uc.Register(context.Background(), "", "short", "", "invite-token", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+		assert.Empty(t, token)
+	})
+
 	t.Run("password hashing error", func(t *testing.T) {
 		// Этот тест сложно реализовать, так как bcrypt.GenerateFromPassword
 		// обычно не возвращает ошибок при нормальных условиях
@@ -183,7 +658,7 @@ func TestAuthUsecase_DummyLogin(t *testing.T) {
 	mockRepo := mocks.NewMockAuthRepository(ctrl)
 	mockTokenator := createTestTokenator()
 
-	uc := usecase.New(mockRepo, mockTokenator)
+	uc := usecase.New(mockRepo, mockTokenator, testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
 
 	t.Run("successful dummy login", func(t *testing.T) {
 		role := "admin"
@@ -192,6 +667,10 @@ func TestAuthUsecase_DummyLogin(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
+
+		claims, err := mockTokenator.ParseJWT(token)
+		assert.NoError(t, err)
+		assert.True(t, claims.Dummy)
 	})
 
 	t.Run("invalid role - should still work as Tokenator doesn't validate roles", func(t *testing.T) {
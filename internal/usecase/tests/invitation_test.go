@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/invitation"
+	mail "github.com/nik-mLb/avito_task/internal/models/mail"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/invitation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testInvitePolicy = usecase.InvitePolicy{
+	TokenTTL:    72 * time.Hour,
+	RegisterURL: "https://pvz.example.com/register",
+	Roles:       testRoles,
+}
+
+func TestInvitationUsecase_CreateInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockInvitationRepository(ctrl)
+	mockMailer := mocks.NewMockInvitationMailer(ctrl)
+	uc := usecase.NewInvitationUsecase(mockRepo, mockMailer, testInvitePolicy)
+	adminID := uuid.New()
+	pvzID := uuid.New()
+
+	t.Run("created and sent", func(t *testing.T) {
+		var storedHash []byte
+		sent := make(chan mail.Message, 1)
+
+		mockRepo.EXPECT().EmailRegistered(gomock.Any(), "new@example.com").Return(false, nil)
+		mockRepo.EXPECT().CreateInvitation(gomock.Any(), gomock.Any(), gomock.Any(), 72*time.Hour).
+			DoAndReturn(func(_ context.Context, inv models.Invitation, tokenHash []byte, _ time.Duration) (*models.Invitation, error) {
+				assert.Equal(t, "new@example.com", inv.Email)
+				assert.Equal(t, "worker", inv.Role)
+				assert.Equal(t, &pvzID, inv.PickupPointID)
+				assert.Equal(t, adminID, inv.CreatedBy)
+				storedHash = tokenHash
+				return &inv, nil
+			})
+		mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mail.Message) error {
+				sent <- msg
+				return nil
+			})
+
+		inv, err := uc.CreateInvitation(context.Background(), adminID.String(), " New@Example.com ", "worker", pvzID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", inv.Email)
+
+		var msg mail.Message
+		select {
+		case msg = <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("invitation mail was not sent")
+		}
+		assert.Equal(t, "new@example.com", msg.To)
+
+		// В письме ссылка с токеном, а в БД - только его хеш
+		match := regexp.MustCompile(`https://pvz\.example\.com/register\?invitation=(\S+)`).FindStringSubmatch(msg.Body)
+		require.Len(t, match, 2)
+		token, err := url.QueryUnescape(match[1])
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte(token))
+		assert.Equal(t, sum[:], storedHash)
+	})
+
+	t.Run("email already registered", func(t *testing.T) {
+		mockRepo.EXPECT().EmailRegistered(gomock.Any(), "taken@example.com").Return(true, nil)
+
+		_, err := uc.CreateInvitation(context.Background(), adminID.String(), "taken@example.com", "worker", "")
+
+		assert.ErrorIs(t, err, errs.ErrUserAlreadyExists)
+	})
+
+	t.Run("role not allowed", func(t *testing.T) {
+		_, err := uc.CreateInvitation(context.Background(), adminID.String(), "new@example.com", "superuser", "")
+
+		assert.ErrorIs(t, err, errs.ErrRoleNotAllowed)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		_, err := uc.CreateInvitation(context.Background(), adminID.String(), "not-an-email", "worker", "")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidEmail)
+	})
+
+	t.Run("invalid pvz id", func(t *testing.T) {
+		_, err := uc.CreateInvitation(context.Background(), adminID.String(), "new@example.com", "worker", "not-a-uuid")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidPickupPointID)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().EmailRegistered(gomock.Any(), "new@example.com").Return(false, nil)
+		mockRepo.EXPECT().CreateInvitation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, expectedErr)
+
+		_, err := uc.CreateInvitation(context.Background(), adminID.String(), "new@example.com", "admin", "")
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestInvitationUsecase_RevokeInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockInvitationRepository(ctrl)
+	uc := usecase.NewInvitationUsecase(mockRepo, mocks.NewMockInvitationMailer(ctrl), testInvitePolicy)
+	id := uuid.New()
+
+	t.Run("revoked", func(t *testing.T) {
+		mockRepo.EXPECT().RevokeInvitation(gomock.Any(), id).Return(nil)
+
+		assert.NoError(t, uc.RevokeInvitation(context.Background(), id.String()))
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		assert.ErrorIs(t, uc.RevokeInvitation(context.Background(), "not-a-uuid"), errs.ErrInvalidInvitationID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().RevokeInvitation(gomock.Any(), id).Return(errs.ErrInvitationNotFound)
+
+		assert.ErrorIs(t, uc.RevokeInvitation(context.Background(), id.String()), errs.ErrInvitationNotFound)
+	})
+}
@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nik-mLb/avito_task/internal/eventsink"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	mocks "github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/outbox"
+)
+
+func TestDispatcher_DispatchPending(t *testing.T) {
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	event := feed.Event{ID: 7, Type: history.ReceptionOpened, PickupPointID: pvzID}
+	line := `{"id":7,"type":"reception_opened","pvzId":"` + pvzID.String() +
+		`","receptionId":"00000000-0000-0000-0000-000000000000","at":"0001-01-01T00:00:00Z"}` + "\n"
+
+	// dispatch имитирует репозиторий: передает событие и считает его разосланным, если получатели его приняли
+	dispatch := func(_ context.Context, _ int, handle func(context.Context, feed.Event) error) (int, error) {
+		if err := handle(context.Background(), event); err != nil {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	t.Run("event is written to every sink", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		fileSink, err := eventsink.NewFileSink(path)
+		assert.NoError(t, err)
+		defer fileSink.Close()
+
+		var buf bytes.Buffer
+		mockRepo := mocks.NewMockOutboxRepository(ctrl)
+		d := usecase.NewDispatcher(mockRepo, 50, eventsink.NewWriterSink(&buf), fileSink)
+
+		mockRepo.EXPECT().Dispatch(gomock.Any(), 50, gomock.Any()).DoAndReturn(dispatch)
+
+		count, err := d.DispatchPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, line, buf.String())
+
+		written, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, line, string(written))
+	})
+
+	t.Run("event is not acknowledged when a sink fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxRepository(ctrl)
+		mockSink := mocks.NewMockEventSink(ctrl)
+		var buf bytes.Buffer
+		d := usecase.NewDispatcher(mockRepo, 50, eventsink.NewWriterSink(&buf), mockSink)
+
+		mockRepo.EXPECT().Dispatch(gomock.Any(), 50, gomock.Any()).DoAndReturn(dispatch)
+		mockSink.EXPECT().Handle(gomock.Any(), event).Return(errors.New("sink unavailable"))
+
+		count, err := d.DispatchPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		// Первый получатель событие уже принял: при повторе он получит дубликат
+		assert.Equal(t, line, buf.String())
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxRepository(ctrl)
+		d := usecase.NewDispatcher(mockRepo, 50)
+
+		expectedErr := errors.New("repository error")
+		mockRepo.EXPECT().Dispatch(gomock.Any(), 50, gomock.Any()).Return(0, expectedErr)
+
+		_, err := d.DispatchPending(context.Background())
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestFanout_Poll(t *testing.T) {
+	pvzID := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	event := func(id uint64) feed.Event {
+		return feed.Event{ID: id, Type: history.ProductAdded, PickupPointID: pvzID}
+	}
+
+	t.Run("events are read by cursor and late gaps are picked up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxFeedRepository(ctrl)
+		mockSink := mocks.NewMockEventSink(ctrl)
+		f := usecase.NewFanout(mockRepo, 50, time.Hour, mockSink)
+
+		gomock.InOrder(
+			mockRepo.EXPECT().LastID(gomock.Any()).Return(uint64(5), nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(5), []uint64{}, 50).
+				Return([]feed.Event{event(6), event(8)}, nil),
+			mockSink.EXPECT().Handle(gomock.Any(), event(6)).Return(nil),
+			mockSink.EXPECT().Handle(gomock.Any(), event(8)).Return(nil),
+			// 7 мог закоммититься позже 8: пропуск перечитывается
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(8), []uint64{7}, 50).
+				Return([]feed.Event{event(7)}, nil),
+			mockSink.EXPECT().Handle(gomock.Any(), event(7)).Return(nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(8), []uint64{}, 50).
+				Return(nil, nil),
+		)
+
+		// Первый вызов только запоминает, с какого номера читать
+		count, err := f.Poll(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		for _, expected := range []int{2, 1, 0} {
+			count, err := f.Poll(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, count)
+		}
+	})
+
+	t.Run("expired gap is no longer read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxFeedRepository(ctrl)
+		mockSink := mocks.NewMockEventSink(ctrl)
+		f := usecase.NewFanout(mockRepo, 50, 0, mockSink)
+
+		gomock.InOrder(
+			mockRepo.EXPECT().LastID(gomock.Any()).Return(uint64(0), nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(0), []uint64{}, 50).
+				Return([]feed.Event{event(2)}, nil),
+			mockSink.EXPECT().Handle(gomock.Any(), event(2)).Return(nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(2), []uint64{}, 50).
+				Return(nil, nil),
+		)
+
+		for i := 0; i < 3; i++ {
+			_, err := f.Poll(context.Background())
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("sink error does not stop the cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxFeedRepository(ctrl)
+		mockSink := mocks.NewMockEventSink(ctrl)
+		f := usecase.NewFanout(mockRepo, 50, time.Hour, mockSink)
+
+		gomock.InOrder(
+			mockRepo.EXPECT().LastID(gomock.Any()).Return(uint64(1), nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(1), []uint64{}, 50).
+				Return([]feed.Event{event(2), event(3)}, nil),
+			mockSink.EXPECT().Handle(gomock.Any(), event(2)).Return(errors.New("sink unavailable")),
+			mockSink.EXPECT().Handle(gomock.Any(), event(3)).Return(nil),
+			mockRepo.EXPECT().ListAfter(gomock.Any(), uint64(3), []uint64{}, 50).
+				Return(nil, nil),
+		)
+
+		for _, expected := range []int{0, 2, 0} {
+			count, err := f.Poll(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, count)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockOutboxFeedRepository(ctrl)
+		f := usecase.NewFanout(mockRepo, 50, time.Hour)
+
+		expectedErr := errors.New("repository error")
+		mockRepo.EXPECT().LastID(gomock.Any()).Return(uint64(0), expectedErr)
+
+		_, err := f.Poll(context.Background())
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
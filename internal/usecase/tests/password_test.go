@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	mail "github.com/nik-mLb/avito_task/internal/models/mail"
+	user "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testResetPolicy = usecase.ResetPolicy{
+	TokenTTL: time.Hour,
+	ResetURL: "https://pvz.example.com/reset",
+}
+
+func TestPasswordUsecase_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockPasswordRepository(ctrl)
+	mockMailer := mocks.NewMockMailer(ctrl)
+	uc := usecase.NewPasswordUsecase(mockRepo, mockMailer, testPasswordPolicy, testResetPolicy)
+
+	t.Run("known email", func(t *testing.T) {
+		userID := uuid.New()
+		var storedHash []byte
+		sent := make(chan mail.Message, 1)
+
+		mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@example.com").
+			Return(&user.User{ID: userID, Email: "test@example.com", Active: true}, nil)
+		mockRepo.EXPECT().CreatePasswordReset(gomock.Any(), userID, gomock.Any(), time.Hour).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, tokenHash []byte, _ time.Duration) error {
+				storedHash = tokenHash
+				return nil
+			})
+		mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mail.Message) error {
+				sent <- msg
+				return nil
+			})
+
+		err := uc.ForgotPassword(context.Background(), " Test@Example.com ")
+		assert.NoError(t, err)
+
+		var msg mail.Message
+		select {
+		case msg = <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("reset mail was not sent")
+		}
+		assert.Equal(t, "test@example.com", msg.To)
+
+		// В письме ссылка с токеном, а в БД - только его хеш
+		match := regexp.MustCompile(`https://pvz\.example\.com/reset\?token=(\S+)`).FindStringSubmatch(msg.Body)
+		require.Len(t, match, 2)
+		token, err := url.QueryUnescape(match[1])
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte(token))
+		assert.Equal(t, sum[:], storedHash)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "nobody@example.com").Return(nil, nil)
+
+		assert.NoError(t, uc.ForgotPassword(context.Background(), "nobody@example.com"))
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@example.com").
+			Return(&user.User{ID: uuid.New(), Email: "test@example.com"}, nil)
+
+		assert.NoError(t, uc.ForgotPassword(context.Background(), "test@example.com"))
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		assert.ErrorIs(t, uc.ForgotPassword(context.Background(), "not-an-email"), errs.ErrInvalidEmail)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@example.com").Return(nil, expectedErr)
+
+		assert.ErrorIs(t, uc.ForgotPassword(context.Background(), "test@example.com"), expectedErr)
+	})
+}
+
+func TestPasswordUsecase_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockPasswordRepository(ctrl)
+	uc := usecase.NewPasswordUsecase(mockRepo, mocks.NewMockMailer(ctrl), testPasswordPolicy, testResetPolicy)
+
+	t.Run("success", func(t *testing.T) {
+		sum := sha256.Sum256([]byte("reset-token"))
+		mockRepo.EXPECT().ResetPassword(gomock.Any(), sum[:], gomock.Any()).
+			Return(uuid.New(), nil)
+
+		assert.NoError(t, uc.ResetPassword(context.Background(), "reset-token", "newpassword1"))
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		err := uc.ResetPassword(context.Background(), "reset-token", "short")
+
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+	})
+
+	t.Run("empty token", func(t *testing.T) {
+		err := uc.ResetPassword(context.Background(), "", "newpassword1")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidResetToken)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockRepo.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, errs.ErrInvalidResetToken)
+
+		err := uc.ResetPassword(context.Background(), "used-token", "newpassword1")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidResetToken)
+	})
+}
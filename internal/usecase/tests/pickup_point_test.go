@@ -2,14 +2,19 @@ package tests
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	usecase "github.com/nik-mLb/avito_task/internal/usecase/pickup_point"
 	"github.com/nik-mLb/avito_task/internal/repository/mocks"
 	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	pickup "github.com/nik-mLb/avito_task/internal/models/pickup_point"
+	"github.com/nik-mLb/avito_task/internal/transport/dto"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 )
 
 func TestPickupPointUsecase_CreatePickupPoint(t *testing.T) {
@@ -57,7 +62,7 @@ func TestPickupPointUsecase_GetPickupPointsWithReceptions(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo.EXPECT().
-			GetPickupPointsWithReceptions(gomock.Any(), &startDate, &endDate, 2, 5).
+			GetPickupPointsWithReceptions(gomock.Any(), &startDate, &endDate, nil, 2, 5).
 			Return(nil, assert.AnError)
 
 		result, err := uc.GetPickupPointsWithReceptions(context.Background(), &startDate, &endDate, 2, 5)
@@ -66,4 +71,156 @@ func TestPickupPointUsecase_GetPickupPointsWithReceptions(t *testing.T) {
 		assert.Equal(t, assert.AnError, err)
 		assert.Nil(t, result)
 	})
-}
\ No newline at end of file
+
+	t.Run("scoped user sees only own pickup point", func(t *testing.T) {
+		pvzID := uuid.New()
+		scoped := authctx.WithPickupPoint(context.Background(), pvzID.String())
+
+		mockRepo.EXPECT().
+			GetPickupPointsWithReceptions(gomock.Any(), nil, nil, &pvzID, 1, 10).
+			Return([]dto.PickupPointListResponse{}, nil)
+
+		_, err := uc.GetPickupPointsWithReceptions(scoped, nil, nil, 1, 10)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestPickupPointUsecase_StreamPickupPointsWithReceptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockPickupPointRepository(ctrl)
+	uc := usecase.NewPickupPointUsecase(mockRepo)
+
+	t.Run("large page is passed as is", func(t *testing.T) {
+		mockRepo.EXPECT().
+			StreamPickupPointsWithReceptions(gomock.Any(), nil, nil, nil, 1, 5000, gomock.Any()).
+			Return(nil)
+
+		err := uc.StreamPickupPointsWithReceptions(context.Background(), nil, nil, 1, 5000, func(dto.PickupPointListResponse) error { return nil })
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("scoped user sees only own pickup point", func(t *testing.T) {
+		pvzID := uuid.New()
+		scoped := authctx.WithPickupPoint(context.Background(), pvzID.String())
+
+		mockRepo.EXPECT().
+			StreamPickupPointsWithReceptions(gomock.Any(), nil, nil, &pvzID, 1, 10, gomock.Any()).
+			Return(nil)
+
+		err := uc.StreamPickupPointsWithReceptions(scoped, nil, nil, 1, 10, func(dto.PickupPointListResponse) error { return nil })
+
+		assert.NoError(t, err)
+	})
+
+	for _, tt := range []struct {
+		name  string
+		page  int
+		limit int
+	}{
+		{name: "zero page", page: 0, limit: 10},
+		{name: "zero limit", page: 1, limit: 0},
+		{name: "limit above maximum", page: 1, limit: 10001},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uc.StreamPickupPointsWithReceptions(context.Background(), nil, nil, tt.page, tt.limit, func(dto.PickupPointListResponse) error { return nil })
+
+			assert.ErrorIs(t, err, errs.ErrInvalidPagination)
+		})
+	}
+}
+
+func TestPickupPointUsecase_SetCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockPickupPointRepository(ctrl)
+	uc := usecase.NewPickupPointUsecase(mockRepo)
+
+	pvzID := uuid.New()
+	capacity := 100
+
+	t.Run("default mode is hard", func(t *testing.T) {
+		expected := &pickup.Occupancy{PickupPointID: pvzID, Capacity: &capacity, Mode: pickup.CapacityHard}
+
+		mockRepo.EXPECT().
+			SetCapacity(gomock.Any(), pvzID, &capacity, pickup.CapacityHard).
+			Return(nil)
+		mockRepo.EXPECT().
+			GetOccupancy(gomock.Any(), pvzID).
+			Return(expected, nil)
+
+		result, err := uc.SetCapacity(context.Background(), pvzID.String(), &capacity, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("negative capacity", func(t *testing.T) {
+		negative := -1
+
+		_, err := uc.SetCapacity(context.Background(), pvzID.String(), &negative, "soft")
+
+		assert.Equal(t, errs.ErrInvalidCapacity, err)
+	})
+
+	t.Run("capacity above int32", func(t *testing.T) {
+		tooLarge := math.MaxInt32 + 1
+
+		_, err := uc.SetCapacity(context.Background(), pvzID.String(), &tooLarge, "hard")
+
+		assert.Equal(t, errs.ErrInvalidCapacity, err)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := uc.SetCapacity(context.Background(), pvzID.String(), &capacity, "strict")
+
+		assert.Equal(t, errs.ErrInvalidCapacity, err)
+	})
+
+	t.Run("invalid pvzId", func(t *testing.T) {
+		_, err := uc.GetOccupancy(context.Background(), "invalid-uuid")
+
+		assert.Equal(t, errs.ErrInvalidPickupPointID, err)
+	})
+
+	t.Run("occupancy outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(context.Background(), uuid.New().String())
+
+		_, err := uc.GetOccupancy(scoped, pvzID.String())
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+}
+
+func TestPickupPointUsecase_ExportPickupPoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockPickupPointRepository(ctrl)
+	uc := usecase.NewPickupPointUsecase(mockRepo)
+
+	t.Run("scoped user exports only own pickup point", func(t *testing.T) {
+		pvzID := uuid.New()
+		scoped := authctx.WithPickupPoint(context.Background(), pvzID.String())
+
+		mockRepo.EXPECT().
+			ExportPickupPoints(gomock.Any(), nil, nil, &pvzID, gomock.Any()).
+			Return(nil)
+
+		err := uc.ExportPickupPoints(scoped, nil, nil, func(pickup.ExportRow) error { return nil })
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid scope in context", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(context.Background(), "not-a-uuid")
+
+		err := uc.ExportPickupPoints(scoped, nil, nil, func(pickup.ExportRow) error { return nil })
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
 	product "github.com/nik-mLb/avito_task/internal/models/product"
 	"github.com/nik-mLb/avito_task/internal/usecase/product"
 	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 )
 
 func TestProductUsecase_AddProduct(t *testing.T) {
@@ -50,6 +52,26 @@ func TestProductUsecase_AddProduct(t *testing.T) {
 		assert.Nil(t, result)
 	})
 
+	t.Run("own pickup point", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(context.Background(), validUUID)
+		mockRepo.EXPECT().
+			AddProduct(gomock.Any(), uuid.MustParse(validUUID), validProductType).
+			Return(&product.Product{ID: uuid.New()}, nil)
+
+		_, err := uc.AddProduct(scoped, validUUID, validProductType)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(context.Background(), uuid.New().String())
+
+		result, err := uc.AddProduct(scoped, validUUID, validProductType)
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+		assert.Nil(t, result)
+	})
+
 	t.Run("invalid product type", func(t *testing.T) {
 		invalidType := "invalid-type"
 
@@ -85,15 +107,29 @@ func TestProductUsecase_DeleteLastProduct(t *testing.T) {
 	validUUID := uuid.New().String()
 
 	t.Run("successful deletion", func(t *testing.T) {
+		deleted := &product.Product{
+			ID:          uuid.New(),
+			ReceptionID: uuid.New(),
+			ProductType: product.Clothing,
+		}
+
 		mockRepo.EXPECT().
 			DeleteLastProduct(gomock.Any(), gomock.Any()).
-			Return(nil)
+			Return(deleted, nil)
 
 		err := uc.DeleteLastProduct(context.Background(), validUUID)
 
 		assert.NoError(t, err)
 	})
 
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(context.Background(), uuid.New().String())
+
+		err := uc.DeleteLastProduct(scoped, validUUID)
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+
 	t.Run("invalid pvzId format", func(t *testing.T) {
 		invalidUUID := "invalid-uuid"
 
@@ -108,7 +144,7 @@ func TestProductUsecase_DeleteLastProduct(t *testing.T) {
 
 		mockRepo.EXPECT().
 			DeleteLastProduct(gomock.Any(), gomock.Any()).
-			Return(repoError)
+			Return(nil, repoError)
 
 		err := uc.DeleteLastProduct(context.Background(), validUUID)
 
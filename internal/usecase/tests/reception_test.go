@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
 	reception "github.com/nik-mLb/avito_task/internal/models/reception"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/nik-mLb/avito_task/internal/usecase/reception"
 	mocks "github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
 )
 
 func TestCreateReception(t *testing.T) {
@@ -20,7 +23,8 @@ func TestCreateReception(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockReceptionRepository(ctrl)
-	uc := usecase.NewReceptionUsecase(mockRepo)
+	mockHistory := mocks.NewMockReceptionHistoryRepository(ctrl)
+	uc := usecase.NewReceptionUsecase(mockRepo, mockHistory, 30*time.Minute)
 
 	ctx := context.Background()
 	testPvzID := uuid.New().String()
@@ -36,7 +40,7 @@ func TestCreateReception(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().
-			CreateReception(ctx, gomock.Any(), uuidPvzID).
+			CreateReception(gomock.Any(), gomock.Any(), uuidPvzID).
 			DoAndReturn(func(_ context.Context, receptionID uuid.UUID, _ uuid.UUID) (*reception.Reception, error) {
 				assert.NotEqual(t, uuid.Nil, receptionID)
 				return expectedReception, nil
@@ -48,6 +52,14 @@ func TestCreateReception(t *testing.T) {
 		assert.Equal(t, expectedReception, result)
 	})
 
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(ctx, uuid.New().String())
+
+		_, err := uc.CreateReception(scoped, testPvzID)
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+
 	t.Run("invalid pvzId", func(t *testing.T) {
 		_, err := uc.CreateReception(ctx, "invalid-uuid")
 
@@ -59,7 +71,7 @@ func TestCreateReception(t *testing.T) {
 		expectedErr := errors.New("repository error")
 
 		mockRepo.EXPECT().
-			CreateReception(ctx, gomock.Any(), uuidPvzID).
+			CreateReception(gomock.Any(), gomock.Any(), uuidPvzID).
 			Return(nil, expectedErr)
 
 		_, err := uc.CreateReception(ctx, testPvzID)
@@ -73,7 +85,8 @@ func TestCloseReception(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockReceptionRepository(ctrl)
-	uc := usecase.NewReceptionUsecase(mockRepo)
+	mockHistory := mocks.NewMockReceptionHistoryRepository(ctrl)
+	uc := usecase.NewReceptionUsecase(mockRepo, mockHistory, 30*time.Minute)
 
 	ctx := context.Background()
 	testPvzID := uuid.New().String()
@@ -88,7 +101,7 @@ func TestCloseReception(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().
-			CloseReception(ctx, uuidPvzID).
+			CloseReception(gomock.Any(), uuidPvzID).
 			Return(expectedReception, nil)
 
 		result, err := uc.CloseReception(ctx, testPvzID)
@@ -108,11 +121,175 @@ func TestCloseReception(t *testing.T) {
 		expectedErr := errors.New("repository error")
 
 		mockRepo.EXPECT().
-			CloseReception(ctx, uuidPvzID).
+			CloseReception(gomock.Any(), uuidPvzID).
 			Return(nil, expectedErr)
 
 		_, err := uc.CloseReception(ctx, testPvzID)
 
 		assert.ErrorIs(t, err, expectedErr)
 	})
-}
\ No newline at end of file
+}
+func TestCloseStaleReceptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReceptionRepository(ctrl)
+	mockHistory := mocks.NewMockReceptionHistoryRepository(ctrl)
+	uc := usecase.NewReceptionUsecase(mockRepo, mockHistory, 30*time.Minute)
+
+	ctx := context.Background()
+	idleTimeout := 2 * time.Hour
+
+	t.Run("success", func(t *testing.T) {
+		closed := []reception.Reception{
+			{ID: uuid.New(), PickupPointID: uuid.New(), Status: "close"},
+			{ID: uuid.New(), PickupPointID: uuid.New(), Status: "close"},
+		}
+
+		mockRepo.EXPECT().
+			CloseStaleReceptions(gomock.Any(), idleTimeout, "auto: idle for more than 2h0m0s").
+			Return(closed, nil)
+
+		count, err := uc.CloseStaleReceptions(ctx, idleTimeout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("repository error")
+
+		mockRepo.EXPECT().
+			CloseStaleReceptions(gomock.Any(), idleTimeout, gomock.Any()).
+			Return(nil, expectedErr)
+
+		_, err := uc.CloseStaleReceptions(ctx, idleTimeout)
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestReopenReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReceptionRepository(ctrl)
+	mockHistory := mocks.NewMockReceptionHistoryRepository(ctrl)
+	uc := usecase.NewReceptionUsecase(mockRepo, mockHistory, 30*time.Minute)
+
+	ctx := context.Background()
+	receptionID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		expectedReception := &reception.Reception{
+			ID:            receptionID,
+			ReceptionDate: time.Now(),
+			PickupPointID: uuid.New(),
+			Status:        "in_progress",
+		}
+
+		mockRepo.EXPECT().
+			ReopenReception(gomock.Any(), receptionID, userID, "one more pallet", 30*time.Minute).
+			Return(expectedReception, nil)
+
+		result, err := uc.ReopenReception(ctx, receptionID.String(), userID.String(), "  one more pallet ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReception, result)
+	})
+
+	t.Run("invalid receptionId", func(t *testing.T) {
+		_, err := uc.ReopenReception(ctx, "invalid-uuid", userID.String(), "reason")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidReceptionID)
+	})
+
+	t.Run("empty reason", func(t *testing.T) {
+		_, err := uc.ReopenReception(ctx, receptionID.String(), userID.String(), "   ")
+
+		assert.ErrorIs(t, err, errs.ErrReopenReasonRequired)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo.EXPECT().
+			ReopenReception(gomock.Any(), receptionID, userID, "reason", 30*time.Minute).
+			Return(nil, errs.ErrReopenWindowExpired)
+
+		_, err := uc.ReopenReception(ctx, receptionID.String(), userID.String(), "reason")
+
+		assert.ErrorIs(t, err, errs.ErrReopenWindowExpired)
+	})
+}
+
+func TestGetReceptionHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReceptionRepository(ctrl)
+	mockHistory := mocks.NewMockReceptionHistoryRepository(ctrl)
+	uc := usecase.NewReceptionUsecase(mockRepo, mockHistory, 30*time.Minute)
+
+	ctx := context.Background()
+	receptionID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		events := []history.Event{
+			{ID: 1, ReceptionID: receptionID, Type: history.ReceptionOpened},
+			{ID: 2, ReceptionID: receptionID, Type: history.ReceptionClosed},
+		}
+
+		mockHistory.EXPECT().
+			GetReceptionHistory(gomock.Any(), receptionID).
+			Return(events, nil)
+
+		result, err := uc.GetReceptionHistory(ctx, receptionID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+	})
+
+	t.Run("invalid receptionId", func(t *testing.T) {
+		_, err := uc.GetReceptionHistory(ctx, "invalid-uuid")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidReceptionID)
+	})
+
+	t.Run("reception not found", func(t *testing.T) {
+		mockHistory.EXPECT().
+			GetReceptionHistory(gomock.Any(), receptionID).
+			Return(nil, errs.ErrReceptionNotFound)
+
+		_, err := uc.GetReceptionHistory(ctx, receptionID.String())
+
+		assert.ErrorIs(t, err, errs.ErrReceptionNotFound)
+	})
+
+	t.Run("own pickup point", func(t *testing.T) {
+		pvzID := uuid.New()
+		scoped := authctx.WithPickupPoint(ctx, pvzID.String())
+
+		mockHistory.EXPECT().
+			GetReceptionPickupPoint(gomock.Any(), receptionID).
+			Return(pvzID, nil)
+		mockHistory.EXPECT().
+			GetReceptionHistory(gomock.Any(), receptionID).
+			Return([]history.Event{}, nil)
+
+		_, err := uc.GetReceptionHistory(scoped, receptionID.String())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(ctx, uuid.New().String())
+
+		mockHistory.EXPECT().
+			GetReceptionPickupPoint(gomock.Any(), receptionID).
+			Return(uuid.New(), nil)
+
+		_, err := uc.GetReceptionHistory(scoped, receptionID.String())
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+}
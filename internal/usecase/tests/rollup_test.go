@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	rollup "github.com/nik-mLb/avito_task/internal/models/rollup"
+	mocks "github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/usecase/rollup"
+)
+
+func TestRollupUsecase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRollupRepository(ctrl)
+	uc := usecase.NewRollupUsecase(mockRepo, time.Minute)
+
+	ctx := context.Background()
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("refresh uses configured lag", func(t *testing.T) {
+		mockRepo.EXPECT().Refresh(gomock.Any(), time.Minute).Return(4, nil)
+
+		count, err := uc.Refresh(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("refresh error", func(t *testing.T) {
+		expectedErr := errors.New("repository error")
+		mockRepo.EXPECT().Refresh(gomock.Any(), time.Minute).Return(0, expectedErr)
+
+		_, err := uc.Refresh(ctx)
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		mockRepo.EXPECT().Backfill(gomock.Any(), from, to).Return(30, nil)
+
+		count, err := uc.Backfill(ctx, from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30, count)
+	})
+
+	t.Run("backfill invalid range", func(t *testing.T) {
+		_, err := uc.Backfill(ctx, to, from)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		mismatches := []rollup.Mismatch{{Day: from, PickupPointID: uuid.New(), RollupProducts: 1, RawProducts: 2}}
+		mockRepo.EXPECT().Check(gomock.Any(), from, to).Return(mismatches, nil)
+
+		got, err := uc.Check(ctx, from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mismatches, got)
+	})
+
+	t.Run("check invalid range", func(t *testing.T) {
+		_, err := uc.Check(ctx, to, from)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	session "github.com/nik-mLb/avito_task/internal/models/session"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionUsecase_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSessionRepository(ctrl)
+	uc := usecase.NewSessionUsecase(mockRepo, 24*time.Hour)
+	userID := uuid.New()
+
+	t.Run("current session is marked", func(t *testing.T) {
+		current := uuid.New()
+		other := uuid.New()
+		mockRepo.EXPECT().ListSessions(gomock.Any(), userID, 24*time.Hour).
+			Return([]session.Session{{ID: other, UserID: userID}, {ID: current, UserID: userID}}, nil)
+
+		sessions, err := uc.ListSessions(context.Background(), userID.String(), current.String())
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.False(t, sessions[0].Current)
+		assert.True(t, sessions[1].Current)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		_, err := uc.ListSessions(context.Background(), "not-a-uuid", "")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidUserID)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().ListSessions(gomock.Any(), userID, 24*time.Hour).Return(nil, expectedErr)
+
+		_, err := uc.ListSessions(context.Background(), userID.String(), "")
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestSessionUsecase_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSessionRepository(ctrl)
+	uc := usecase.NewSessionUsecase(mockRepo, 24*time.Hour)
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	t.Run("revoked", func(t *testing.T) {
+		mockRepo.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(nil)
+
+		assert.NoError(t, uc.RevokeSession(context.Background(), userID.String(), sessionID.String()))
+	})
+
+	t.Run("invalid session id", func(t *testing.T) {
+		err := uc.RevokeSession(context.Background(), userID.String(), "not-a-uuid")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidSessionID)
+	})
+
+	t.Run("session of another user", func(t *testing.T) {
+		mockRepo.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(errs.ErrSessionNotFound)
+
+		err := uc.RevokeSession(context.Background(), userID.String(), sessionID.String())
+
+		assert.ErrorIs(t, err, errs.ErrSessionNotFound)
+	})
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	statistics "github.com/nik-mLb/avito_task/internal/models/statistics"
+	mocks "github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/usecase/statistics"
+)
+
+func TestGetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockStatisticsRepository(ctrl)
+	uc := usecase.NewStatisticsUsecase(mockRepo)
+
+	ctx := context.Background()
+	startDate := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		groups := []statistics.Group{{Key: "Москва", Receptions: 3, Products: 12, ProductsPerReception: 4}}
+
+		mockRepo.EXPECT().
+			GetStats(gomock.Any(), statistics.Filter{GroupBy: statistics.GroupByCity, StartDate: &startDate, EndDate: &endDate}).
+			Return(groups, nil)
+
+		report, err := uc.GetStats(ctx, "city", &startDate, &endDate)
+
+		assert.NoError(t, err)
+		assert.Equal(t, statistics.GroupByCity, report.GroupBy)
+		assert.Equal(t, groups, report.Groups)
+	})
+
+	t.Run("default grouping", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetStats(gomock.Any(), statistics.Filter{GroupBy: statistics.GroupByPickupPoint}).
+			Return([]statistics.Group{}, nil)
+
+		report, err := uc.GetStats(ctx, "", nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, statistics.GroupByPickupPoint, report.GroupBy)
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		_, err := uc.GetStats(ctx, "month", nil, nil)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidStatsGroupBy)
+	})
+
+	t.Run("invalid date range", func(t *testing.T) {
+		_, err := uc.GetStats(ctx, "day", &endDate, &startDate)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidDateRange)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("repository error")
+
+		mockRepo.EXPECT().
+			GetStats(gomock.Any(), gomock.Any()).
+			Return(nil, expectedErr)
+
+		_, err := uc.GetStats(ctx, "week", nil, nil)
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nik-mLb/avito_task/internal/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret - ключ из тестовых векторов RFC 6238 для SHA1
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTP_Code(t *testing.T) {
+	// Векторы RFC 6238, последние 6 цифр 8-значных кодов
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unix, 0)))
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, code)
+	}
+}
+
+func TestTOTP_Match(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totp.Step(now)
+	previous, err := totp.Code(rfcSecret, current-1)
+	require.NoError(t, err)
+
+	t.Run("previous step within skew", func(t *testing.T) {
+		step, ok := totp.Match(rfcSecret, previous, now, 1)
+
+		assert.True(t, ok)
+		assert.Equal(t, current-1, step)
+	})
+
+	t.Run("previous step without skew", func(t *testing.T) {
+		_, ok := totp.Match(rfcSecret, previous, now, 0)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		_, ok := totp.Match(rfcSecret, "123", now, 1)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTP_ProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("PVZ Service", "admin@example.com", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/PVZ Service:admin@example.com", parsed.Path)
+	assert.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "PVZ Service", parsed.Query().Get("issuer"))
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	transfer "github.com/nik-mLb/avito_task/internal/models/transfer"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/usecase/transfer"
+)
+
+func TestTransferUsecase_CreateTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockTransferRepository(ctrl)
+	uc := usecase.NewTransferUsecase(mockRepo)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	fromPvz := uuid.New()
+	toPvz := uuid.New()
+	productID := uuid.New()
+
+	t.Run("success with duplicate products", func(t *testing.T) {
+		expected := &transfer.Transfer{ID: uuid.New(), Status: transfer.StatusCreated}
+
+		mockRepo.EXPECT().
+			CreateTransfer(gomock.Any(), gomock.Any(), fromPvz, toPvz, userID, []uuid.UUID{productID}).
+			Return(expected, nil)
+
+		result, err := uc.CreateTransfer(ctx, userID.String(), fromPvz.String(), toPvz.String(),
+			[]string{productID.String(), productID.String()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("same pickup point", func(t *testing.T) {
+		_, err := uc.CreateTransfer(ctx, userID.String(), fromPvz.String(), fromPvz.String(), []string{productID.String()})
+
+		assert.ErrorIs(t, err, errs.ErrTransferSamePickupPoint)
+	})
+
+	t.Run("source outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(ctx, toPvz.String())
+
+		_, err := uc.CreateTransfer(scoped, userID.String(), fromPvz.String(), toPvz.String(), []string{productID.String()})
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+
+	t.Run("empty product list", func(t *testing.T) {
+		_, err := uc.CreateTransfer(ctx, userID.String(), fromPvz.String(), toPvz.String(), nil)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidTransferRequest)
+	})
+
+	t.Run("invalid product id", func(t *testing.T) {
+		_, err := uc.CreateTransfer(ctx, userID.String(), fromPvz.String(), toPvz.String(), []string{"invalid-uuid"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidTransferRequest)
+	})
+}
+
+func TestTransferUsecase_ListTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockTransferRepository(ctrl)
+	uc := usecase.NewTransferUsecase(mockRepo)
+
+	ctx := context.Background()
+	pvzID := uuid.New()
+
+	t.Run("in transit only", func(t *testing.T) {
+		status := transfer.StatusDispatched
+
+		mockRepo.EXPECT().
+			ListTransfers(gomock.Any(), pvzID, &status).
+			Return([]transfer.Transfer{}, nil)
+
+		result, err := uc.ListTransfers(ctx, pvzID.String(), "dispatched")
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := uc.ListTransfers(ctx, pvzID.String(), "lost")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidTransferRequest)
+	})
+
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		scoped := authctx.WithPickupPoint(ctx, uuid.New().String())
+
+		_, err := uc.ListTransfers(scoped, pvzID.String(), "")
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+}
+
+func TestTransferUsecase_AcceptTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockTransferRepository(ctrl)
+	uc := usecase.NewTransferUsecase(mockRepo)
+
+	pvzID := uuid.New()
+	transferID := uuid.New()
+
+	t.Run("own pickup point", func(t *testing.T) {
+		ctx := authctx.WithPickupPoint(context.Background(), pvzID.String())
+		expected := &transfer.Transfer{ID: transferID, Status: transfer.StatusAccepted}
+
+		mockRepo.EXPECT().
+			AcceptTransfer(gomock.Any(), transferID, pvzID, gomock.Any()).
+			Return(expected, nil)
+
+		result, err := uc.AcceptTransfer(ctx, pvzID.String(), transferID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("pickup point outside of user scope", func(t *testing.T) {
+		ctx := authctx.WithPickupPoint(context.Background(), uuid.New().String())
+
+		_, err := uc.AcceptTransfer(ctx, pvzID.String(), transferID.String())
+
+		assert.ErrorIs(t, err, errs.ErrPickupPointForbidden)
+	})
+}
@@ -0,0 +1,360 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	user "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	"github.com/nik-mLb/avito_task/internal/totp"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func currentCode(t *testing.T, secret string) (string, int64) {
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	return code, step
+}
+
+// expectLoginFailure ожидает учет неудачной попытки входа по email и по IP клиента testClient
+func expectLoginFailure(repo *mocks.MockAuthRepository, email string) {
+	repo.EXPECT().
+		RecordLoginFailure(gomock.Any(), user.LoginScopeEmail, email, 3, 15*time.Minute, 15*time.Minute).
+		Return(nil)
+	repo.EXPECT().
+		RecordLoginFailure(gomock.Any(), user.LoginScopeIP, testClient.IP, 10, 15*time.Minute, 15*time.Minute).
+		Return(nil)
+}
+
+func TestAuthUsecase_VerifyLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	challengeHash := sha256.Sum256([]byte("challenge"))
+	userID := uuid.New()
+
+	t.Run("totp code", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret, Enabled: true}, nil)
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(true, nil)
+		mockRepo.EXPECT().DeleteLoginChallenge(gomock.Any(), challengeHash[:]).Return(nil)
+		mockRepo.EXPECT().CreateSession(gomock.Any(), userID, testClient).Return(uuid.New(), nil)
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, "admin@example.com").Return(nil)
+
+		token, codes, err := uc.VerifyLogin(context.Background(), "challenge", code, testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Empty(t, codes)
+	})
+
+	t.Run("replayed totp code", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret, Enabled: true, LastStep: step}, nil)
+		expectLoginFailure(mockRepo, "admin@example.com")
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", code, testClient)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOTP)
+	})
+
+	t.Run("unknown recovery code counts as login failure", func(t *testing.T) {
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret, Enabled: true}, nil)
+		mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(false, nil)
+		expectLoginFailure(mockRepo, "admin@example.com")
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", "abcd-efgh-ijkl-mnop", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOTP)
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		codeHash := sha256.Sum256([]byte("ABCDEFGHIJKLMNOP"))
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret, Enabled: true}, nil)
+		mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, codeHash[:]).Return(true, nil)
+		mockRepo.EXPECT().DeleteLoginChallenge(gomock.Any(), challengeHash[:]).Return(nil)
+		mockRepo.EXPECT().CreateSession(gomock.Any(), userID, testClient).Return(uuid.New(), nil)
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, "admin@example.com").Return(nil)
+
+		token, _, err := uc.VerifyLogin(context.Background(), "challenge", "abcd-efgh-ijkl-mnop", testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+	})
+
+	t.Run("enrollment", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret}, nil)
+		mockRepo.EXPECT().EnableTwoFactor(gomock.Any(), userID, step, gomock.Len(10)).Return(nil)
+		mockRepo.EXPECT().DeleteLoginChallenge(gomock.Any(), challengeHash[:]).Return(nil)
+		mockRepo.EXPECT().CreateSession(gomock.Any(), userID, testClient).Return(uuid.New(), nil)
+		mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, "admin@example.com").Return(nil)
+
+		token, codes, err := uc.VerifyLogin(context.Background(), "challenge", code, testClient)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Len(t, codes, 10)
+		assert.Len(t, strings.Split(codes[0], "-"), 4)
+	})
+
+	t.Run("wrong code during enrollment", func(t *testing.T) {
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Email: "admin@example.com", Role: "admin", Active: true, Secret: secret}, nil)
+		expectLoginFailure(mockRepo, "admin@example.com")
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", "abcdef", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOTP)
+	})
+
+	t.Run("enrollment without setup", func(t *testing.T) {
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Role: "admin", Active: true}, nil)
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", "123456", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorNotSetUp)
+	})
+
+	t.Run("invalid challenge", func(t *testing.T) {
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(nil, errs.ErrInvalidLoginChallenge)
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", "123456", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidLoginChallenge)
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), challengeHash[:], 5).
+			Return(&user.TwoFactor{UserID: userID, Role: "admin", Secret: secret, Enabled: true}, nil)
+
+		_, _, err := uc.VerifyLogin(context.Background(), "challenge", "123456", testClient)
+
+		assert.ErrorIs(t, err, errs.ErrUserDeactivated)
+	})
+}
+
+// Верный пароль не сбрасывает счетчик, пока второй фактор не пройден,
+// иначе повторный вход давал бы новые попытки подобрать код
+func TestAuthUsecase_WrongCodesKeepLoginLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	const email = "worker@example.com"
+	const password = "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	// failures - счетчик неудач по email, как его ведет репозиторий
+	failures := 0
+	mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), email, testClient.IP, 15*time.Minute).
+		DoAndReturn(func(context.Context, string, string, time.Duration) (user.LoginThrottle, error) {
+			throttle := user.LoginThrottle{Failures: failures}
+			if failures >= testLoginPolicy.MaxFailures {
+				throttle.LockedFor = testLoginPolicy.LockoutDuration
+			}
+			return throttle, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), user.LoginScopeEmail, email, 3, 15*time.Minute, 15*time.Minute).
+		DoAndReturn(func(context.Context, user.LoginScope, string, int, time.Duration, time.Duration) error {
+			failures++
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), user.LoginScopeIP, testClient.IP, 10, 15*time.Minute, 15*time.Minute).
+		Return(nil).AnyTimes()
+	mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), user.LoginScopeEmail, email).
+		DoAndReturn(func(context.Context, user.LoginScope, string) error {
+			failures = 0
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().GetUserByEmail(gomock.Any(), email).
+		Return(&user.User{ID: userID, Email: email, PasswordHash: hashedPassword, Role: "worker", Active: true, TwoFactorEnabled: true}, nil).
+		AnyTimes()
+	mockRepo.EXPECT().CreateLoginChallenge(gomock.Any(), userID, gomock.Any(), 5*time.Minute).Return(nil).AnyTimes()
+	mockRepo.EXPECT().UseLoginChallenge(gomock.Any(), gomock.Any(), 5).
+		Return(&user.TwoFactor{UserID: userID, Email: email, Role: "worker", Active: true, Secret: "JBSWY3DPEHPK3PXP", Enabled: true}, nil).
+		AnyTimes()
+	mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(false, nil).AnyTimes()
+
+	login := func() (string, error) {
+		_, err := uc.Authenticate(context.Background(), email, password, testClient)
+		var required *errs.TwoFactorRequiredError
+		if errors.As(err, &required) {
+			return required.Challenge, nil
+		}
+		return "", err
+	}
+	wrongCode := func(challenge string) {
+		_, _, err := uc.VerifyLogin(context.Background(), challenge, "abcd-efgh-ijkl-mnop", testClient)
+		require.ErrorIs(t, err, errs.ErrInvalidOTP)
+	}
+
+	challenge, err := login()
+	require.NoError(t, err)
+	wrongCode(challenge)
+	wrongCode(challenge)
+
+	challenge, err = login()
+	require.NoError(t, err)
+	assert.Equal(t, 2, failures)
+	wrongCode(challenge)
+
+	_, err = login()
+	assert.ErrorIs(t, err, errs.ErrLoginLocked)
+}
+
+func TestAuthUsecase_SetupTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+	userID := uuid.New()
+
+	t.Run("new secret", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Email: "test@example.com", Role: "worker", Active: true}, nil)
+		mockRepo.EXPECT().SetTOTPSecret(gomock.Any(), userID, gomock.Any()).Return(nil)
+
+		setup, err := uc.SetupTwoFactor(context.Background(), userID.String())
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, setup.Secret)
+		assert.Equal(t, totp.ProvisioningURI("PVZ Service", "test@example.com", setup.Secret), setup.URI)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Enabled: true}, nil)
+
+		_, err := uc.SetupTwoFactor(context.Background(), userID.String())
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorAlreadyEnabled)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := uc.SetupTwoFactor(context.Background(), "not-a-uuid")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidUserID)
+	})
+}
+
+func TestAuthUsecase_EnableTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	t.Run("enabled", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Secret: secret}, nil)
+		mockRepo.EXPECT().EnableTwoFactor(gomock.Any(), userID, step, gomock.Len(10)).Return(nil)
+
+		codes, err := uc.EnableTwoFactor(context.Background(), userID.String(), code)
+
+		assert.NoError(t, err)
+		assert.Len(t, codes, 10)
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Secret: secret}, nil)
+
+		_, err := uc.EnableTwoFactor(context.Background(), userID.String(), "12345")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOTP)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Secret: secret, Enabled: true}, nil)
+
+		_, err := uc.EnableTwoFactor(context.Background(), userID.String(), "123456")
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorAlreadyEnabled)
+	})
+}
+
+func TestAuthUsecase_DisableTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAuthRepository(ctrl)
+	uc := usecase.New(mockRepo, createTestTokenator(), testPasswordPolicy, testLoginPolicy, testRegistrationPolicy, testTwoFactorPolicy)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	t.Run("disabled", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Secret: secret, Enabled: true}, nil)
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(true, nil)
+		mockRepo.EXPECT().DisableTwoFactor(gomock.Any(), userID).Return(nil)
+
+		assert.NoError(t, uc.DisableTwoFactor(context.Background(), userID.String(), code))
+	})
+
+	t.Run("mandatory for role", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "admin", Active: true, Secret: secret, Enabled: true}, nil)
+
+		err := uc.DisableTwoFactor(context.Background(), userID.String(), "123456")
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorMandatory)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true}, nil)
+
+		err := uc.DisableTwoFactor(context.Background(), userID.String(), "123456")
+
+		assert.ErrorIs(t, err, errs.ErrTwoFactorNotSetUp)
+	})
+
+	t.Run("code already used", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), userID).
+			Return(&user.TwoFactor{UserID: userID, Role: "worker", Active: true, Secret: secret, Enabled: true}, nil)
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(false, nil)
+
+		err := uc.DisableTwoFactor(context.Background(), userID.String(), code)
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOTP)
+	})
+}
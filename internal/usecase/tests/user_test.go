@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	user "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserUsecase_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	uc := usecase.NewUserUsecase(mockRepo, testRoles)
+
+	t.Run("query is normalized", func(t *testing.T) {
+		expected := []user.User{{ID: uuid.New(), Email: "ivan@example.com", Role: "worker", Active: true}}
+		mockRepo.EXPECT().
+			ListUsers(gomock.Any(), user.UserFilter{Query: "ivan@", Role: "worker", Page: 1, Limit: 20}).
+			Return(expected, nil)
+
+		users, err := uc.ListUsers(context.Background(), user.UserFilter{Query: " Ivan@ ", Role: "worker", Page: 1, Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, users)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		_, err := uc.ListUsers(context.Background(), user.UserFilter{Role: "client", Page: 1, Limit: 20})
+
+		assert.ErrorIs(t, err, errs.ErrRoleNotAllowed)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockRepo.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(nil, expectedErr)
+
+		_, err := uc.ListUsers(context.Background(), user.UserFilter{Page: 1, Limit: 20})
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestUserUsecase_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	uc := usecase.NewUserUsecase(mockRepo, testRoles)
+	userID := uuid.New()
+
+	t.Run("found", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(&user.User{ID: userID}, nil)
+
+		u, err := uc.GetUser(context.Background(), userID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, userID, u.ID)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := uc.GetUser(context.Background(), "not-a-uuid")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidUserID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(nil, errs.ErrUserNotFound)
+
+		_, err := uc.GetUser(context.Background(), userID.String())
+
+		assert.ErrorIs(t, err, errs.ErrUserNotFound)
+	})
+}
+
+func TestUserUsecase_ChangeRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	uc := usecase.NewUserUsecase(mockRepo, testRoles)
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("changed", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateRole(gomock.Any(), userID, "admin").
+			Return(&user.User{ID: userID, Role: "admin", Active: true}, nil)
+
+		u, err := uc.ChangeRole(context.Background(), adminID.String(), userID.String(), "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", u.Role)
+	})
+
+	t.Run("role from permission policy", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateRole(gomock.Any(), userID, "supervisor").
+			Return(&user.User{ID: userID, Role: "supervisor", Active: true}, nil)
+
+		u, err := uc.ChangeRole(context.Background(), adminID.String(), userID.String(), "supervisor")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "supervisor", u.Role)
+	})
+
+	t.Run("role not allowed", func(t *testing.T) {
+		_, err := uc.ChangeRole(context.Background(), adminID.String(), userID.String(), "superuser")
+
+		assert.ErrorIs(t, err, errs.ErrRoleNotAllowed)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		_, err := uc.ChangeRole(context.Background(), adminID.String(), adminID.String(), "worker")
+
+		assert.ErrorIs(t, err, errs.ErrSelfModification)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := uc.ChangeRole(context.Background(), adminID.String(), "not-a-uuid", "admin")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidUserID)
+	})
+}
+
+func TestUserUsecase_SetActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	uc := usecase.NewUserUsecase(mockRepo, testRoles)
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("deactivate", func(t *testing.T) {
+		mockRepo.EXPECT().SetActive(gomock.Any(), userID, false).
+			Return(&user.User{ID: userID, Role: "worker"}, nil)
+
+		u, err := uc.SetActive(context.Background(), adminID.String(), userID.String(), false)
+
+		assert.NoError(t, err)
+		assert.False(t, u.Active)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		_, err := uc.SetActive(context.Background(), adminID.String(), adminID.String(), false)
+
+		assert.ErrorIs(t, err, errs.ErrSelfModification)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().SetActive(gomock.Any(), userID, true).Return(nil, errs.ErrUserNotFound)
+
+		_, err := uc.SetActive(context.Background(), adminID.String(), userID.String(), true)
+
+		assert.ErrorIs(t, err, errs.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,321 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	mocks "github.com/nik-mLb/avito_task/internal/repository/mocks"
+	usecase "github.com/nik-mLb/avito_task/internal/usecase/webhook"
+	"github.com/nik-mLb/avito_task/internal/webhook"
+)
+
+var testDeliveryPolicy = usecase.DeliveryPolicy{
+	MaxAttempts: 3,
+	BackoffBase: 30 * time.Second,
+	BackoffMax:  time.Minute,
+	BatchSize:   10,
+	Lease:       5 * time.Minute,
+}
+
+func TestWebhookUsecase_CreateSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, false), testDeliveryPolicy)
+
+	ctx := context.Background()
+	userID := uuid.New().String()
+	pvzID := uuid.New()
+
+	t.Run("success with generated secret", func(t *testing.T) {
+		mockRepo.EXPECT().
+			CreateSubscription(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, sub models.Subscription) (*models.Subscription, error) {
+				assert.Equal(t, "https://partner.example/hook", sub.URL)
+				assert.Equal(t, []history.EventType{history.ReceptionClosed}, sub.EventTypes)
+				assert.Equal(t, pvzID, *sub.PickupPointID)
+				assert.Len(t, sub.Secret, 64)
+				created := sub
+				created.Secret = ""
+				return &created, nil
+			})
+
+		sub, err := uc.CreateSubscription(ctx, userID, "https://partner.example/hook",
+			[]string{"reception_closed", "reception_closed"}, pvzID.String(), "")
+
+		assert.NoError(t, err)
+		assert.Len(t, sub.Secret, 64)
+	})
+
+	invalid := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		pvzID      string
+	}{
+		{name: "relative url", url: "/hook"},
+		{name: "unsupported scheme", url: "ftp://partner.example/hook"},
+		{name: "unknown event type", url: "https://partner.example/hook", eventTypes: []string{"reception_burned"}},
+		{name: "invalid pvzId", url: "https://partner.example/hook", pvzID: "invalid"},
+		{name: "localhost", url: "http://localhost:8080/hook"},
+		{name: "loopback address", url: "http://127.0.0.1/hook"},
+		{name: "ipv6 loopback", url: "http://[::1]/hook"},
+		{name: "private address", url: "https://10.0.0.5/hook"},
+		{name: "link-local address", url: "http://169.254.169.254/latest/meta-data"},
+		{name: "ipv4-mapped private address", url: "http://[::ffff:192.168.1.1]/hook"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.CreateSubscription(ctx, userID, tt.url, tt.eventTypes, tt.pvzID, "secret")
+
+			assert.ErrorIs(t, err, errs.ErrInvalidWebhook)
+		})
+	}
+}
+
+func TestWebhookUsecase_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	uc := usecase.NewWebhookUsecase(mockRepo, mocks.NewMockWebhookSender(ctrl), testDeliveryPolicy)
+
+	event := feed.Event{
+		Type:          history.ReceptionClosed,
+		PickupPointID: uuid.New(),
+		ReceptionID:   uuid.New(),
+		At:            time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC),
+	}
+
+	mockRepo.EXPECT().
+		EnqueueEvent(gomock.Any(), "reception_closed", event.PickupPointID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ uuid.UUID, payload []byte) (int64, error) {
+			assert.JSONEq(t, `{"event":"reception_closed","pvzId":"`+event.PickupPointID.String()+
+				`","receptionId":"`+event.ReceptionID.String()+`","occurredAt":"2025-04-20T12:00:00Z"}`, string(payload))
+			return 1, nil
+		})
+
+	assert.NoError(t, uc.Handle(context.Background(), event))
+
+	expectedErr := errors.New("repository error")
+	mockRepo.EXPECT().EnqueueEvent(gomock.Any(), "reception_closed", event.PickupPointID, gomock.Any()).Return(int64(0), expectedErr)
+
+	assert.ErrorIs(t, uc.Handle(context.Background(), event), expectedErr)
+}
+
+func TestWebhookUsecase_DeliverDue(t *testing.T) {
+	const secret = "partner-secret"
+
+	type received struct {
+		headers http.Header
+		body    []byte
+	}
+	requests := make(chan received, 10)
+	var status int
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{headers: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	payload := json.RawMessage(`{"event":"reception_closed"}`)
+	target := func(attempts int) models.Target {
+		return models.Target{
+			Delivery: models.Delivery{ID: 42, EventType: "reception_closed", Payload: payload, Attempts: attempts},
+			URL:      receiver.URL,
+			Secret:   secret,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		attempts      int
+		mock          func(repo *mocks.MockWebhookRepository)
+		expectedCount int
+	}{
+		{
+			name:     "delivered",
+			status:   http.StatusOK,
+			attempts: 1,
+			mock: func(repo *mocks.MockWebhookRepository) {
+				repo.EXPECT().MarkDelivered(gomock.Any(), int64(42), http.StatusOK).Return(nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name:     "retry with exponential backoff",
+			status:   http.StatusServiceUnavailable,
+			attempts: 1,
+			mock: func(repo *mocks.MockWebhookRepository) {
+				code := http.StatusServiceUnavailable
+				repo.EXPECT().RetryDelivery(gomock.Any(), int64(42), &code, "unexpected status 503", 30*time.Second).Return(nil)
+			},
+		},
+		{
+			name:     "backoff is capped",
+			status:   http.StatusInternalServerError,
+			attempts: 2,
+			mock: func(repo *mocks.MockWebhookRepository) {
+				code := http.StatusInternalServerError
+				repo.EXPECT().RetryDelivery(gomock.Any(), int64(42), &code, "unexpected status 500", time.Minute).Return(nil)
+			},
+		},
+		{
+			name:     "fails after max attempts",
+			status:   http.StatusInternalServerError,
+			attempts: 3,
+			mock: func(repo *mocks.MockWebhookRepository) {
+				code := http.StatusInternalServerError
+				repo.EXPECT().FailDelivery(gomock.Any(), int64(42), &code, "unexpected status 500").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockWebhookRepository(ctrl)
+			uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, true), testDeliveryPolicy)
+			status = tt.status
+
+			mockRepo.EXPECT().
+				ClaimDueDeliveries(gomock.Any(), 10, 5*time.Minute).
+				Return([]models.Target{target(tt.attempts)}, nil)
+			tt.mock(mockRepo)
+
+			count, err := uc.DeliverDue(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+
+			req := <-requests
+			assert.JSONEq(t, string(payload), string(req.body))
+			assert.Equal(t, "42", req.headers.Get(webhook.HeaderDeliveryID))
+			assert.Equal(t, "reception_closed", req.headers.Get(webhook.HeaderEvent))
+
+			timestamp, err := strconv.ParseInt(req.headers.Get(webhook.HeaderTimestamp), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, webhook.Sign(secret, timestamp, req.body), req.headers.Get(webhook.HeaderSignature))
+		})
+	}
+
+	t.Run("receiver unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, true), testDeliveryPolicy)
+
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		unavailable := target(1)
+		unavailable.URL = closed.URL
+		mockRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), 10, 5*time.Minute).Return([]models.Target{unavailable}, nil)
+		mockRepo.EXPECT().RetryDelivery(gomock.Any(), int64(42), nil, gomock.Any(), 30*time.Second).Return(nil)
+
+		count, err := uc.DeliverDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("private destination rejected at dial time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, false), testDeliveryPolicy)
+
+		mockRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), 10, 5*time.Minute).Return([]models.Target{target(1)}, nil)
+		mockRepo.EXPECT().
+			RetryDelivery(gomock.Any(), int64(42), nil, gomock.Any(), 30*time.Second).
+			DoAndReturn(func(_ context.Context, _ int64, _ *int, lastError string, _ time.Duration) error {
+				assert.Contains(t, lastError, webhook.ErrForbiddenDestination.Error())
+				return nil
+			})
+
+		count, err := uc.DeliverDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, requests)
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, true), testDeliveryPolicy)
+
+		redirector := httptest.NewServer(http.RedirectHandler(receiver.URL, http.StatusTemporaryRedirect))
+		defer redirector.Close()
+
+		redirected := target(1)
+		redirected.URL = redirector.URL
+		code := http.StatusTemporaryRedirect
+		mockRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), 10, 5*time.Minute).Return([]models.Target{redirected}, nil)
+		mockRepo.EXPECT().RetryDelivery(gomock.Any(), int64(42), &code, "unexpected status 307", 30*time.Second).Return(nil)
+
+		count, err := uc.DeliverDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, requests)
+	})
+
+	t.Run("claim error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockWebhookRepository(ctrl)
+		uc := usecase.NewWebhookUsecase(mockRepo, webhook.NewSender(time.Second, true), testDeliveryPolicy)
+
+		expectedErr := errors.New("repository error")
+		mockRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), 10, 5*time.Minute).Return(nil, expectedErr)
+
+		_, err := uc.DeliverDue(context.Background())
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestWebhookUsecase_Redeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	uc := usecase.NewWebhookUsecase(mockRepo, mocks.NewMockWebhookSender(ctrl), testDeliveryPolicy)
+
+	mockRepo.EXPECT().Redeliver(gomock.Any(), int64(5)).Return(&models.Delivery{ID: 6}, nil)
+
+	delivery, err := uc.Redeliver(context.Background(), "5")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), delivery.ID)
+
+	_, err = uc.Redeliver(context.Background(), "abc")
+	assert.ErrorIs(t, err, errs.ErrInvalidWebhook)
+}
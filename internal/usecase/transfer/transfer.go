@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/transfer"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/authctx"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=transfer.go -destination=../../repository/mocks/transfer_repository_mock.go -package=mocks TransferRepository
+type TransferRepository interface {
+	CreateTransfer(ctx context.Context, transferID, fromPvzID, toPvzID, createdBy uuid.UUID, productIDs []uuid.UUID) (*models.Transfer, error)
+	DispatchTransfer(ctx context.Context, transferID, pvzID uuid.UUID) (*models.Transfer, error)
+	AcceptTransfer(ctx context.Context, transferID, pvzID, receptionID uuid.UUID) (*models.Transfer, error)
+	ListTransfers(ctx context.Context, pvzID uuid.UUID, status *models.Status) ([]models.Transfer, error)
+}
+
+type TransferUsecase struct {
+	repo TransferRepository
+}
+
+func NewTransferUsecase(repo TransferRepository) *TransferUsecase {
+	return &TransferUsecase{repo: repo}
+}
+
+func (uc *TransferUsecase) CreateTransfer(ctx context.Context, userID, fromPvzID, toPvzID string, productIDs []string) (*models.Transfer, error) {
+	const op = "TransferUsecase.CreateTransfer"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithFields(map[string]interface{}{
+		"from_pvz_id": fromPvzID,
+		"to_pvz_id":   toPvzID,
+		"user_id":     userID,
+	})
+
+	uuidUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid userID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	uuidFrom, err := uuid.Parse(fromPvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid source pvzID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	if !authctx.PickupPointAllowed(ctx, uuidFrom.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
+	uuidTo, err := uuid.Parse(toPvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid destination pvzID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	if uuidFrom == uuidTo {
+		logger.Warn("transfer to the same pickup point")
+		return nil, errs.ErrTransferSamePickupPoint
+	}
+
+	if len(productIDs) == 0 {
+		logger.Warn("empty product list")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	seen := make(map[uuid.UUID]bool, len(productIDs))
+	ids := make([]uuid.UUID, 0, len(productIDs))
+	for _, id := range productIDs {
+		productID, err := uuid.Parse(id)
+		if err != nil {
+			logger.WithError(err).WithField("product_id", id).Warn("invalid productID")
+			return nil, errs.ErrInvalidTransferRequest
+		}
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		ids = append(ids, productID)
+	}
+
+	transfer, err := uc.repo.CreateTransfer(ctx, uuid.New(), uuidFrom, uuidTo, uuidUserID, ids)
+	if err != nil {
+		logger.WithError(err).Error("failed to create transfer")
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// DispatchTransfer отправляет перемещение от имени ПВЗ-отправителя pvzID
+func (uc *TransferUsecase) DispatchTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error) {
+	const op = "TransferUsecase.DispatchTransfer"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("transfer_id", transferID)
+
+	uuidPvzID, err := uuid.Parse(pvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
+	uuidTransferID, err := uuid.Parse(transferID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid transferID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	transfer, err := uc.repo.DispatchTransfer(ctx, uuidTransferID, uuidPvzID)
+	if err != nil {
+		logger.WithError(err).Error("failed to dispatch transfer")
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// AcceptTransfer принимает перемещение от имени ПВЗ-получателя pvzID
+func (uc *TransferUsecase) AcceptTransfer(ctx context.Context, pvzID, transferID string) (*models.Transfer, error) {
+	const op = "TransferUsecase.AcceptTransfer"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("transfer_id", transferID)
+
+	uuidPvzID, err := uuid.Parse(pvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
+	uuidTransferID, err := uuid.Parse(transferID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid transferID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	transfer, err := uc.repo.AcceptTransfer(ctx, uuidTransferID, uuidPvzID, uuid.New())
+	if err != nil {
+		logger.WithError(err).Error("failed to accept transfer")
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// ListTransfers возвращает перемещения ПВЗ; пустой status означает любой статус
+func (uc *TransferUsecase) ListTransfers(ctx context.Context, pvzID, status string) ([]models.Transfer, error) {
+	const op = "TransferUsecase.ListTransfers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("pvz_id", pvzID).WithField("status", status)
+
+	uuidPvzID, err := uuid.Parse(pvzID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid pvzID")
+		return nil, errs.ErrInvalidTransferRequest
+	}
+
+	if !authctx.PickupPointAllowed(ctx, uuidPvzID.String()) {
+		logger.Warn("pickup point is outside of user scope")
+		return nil, errs.ErrPickupPointForbidden
+	}
+
+	var statusFilter *models.Status
+	if status != "" {
+		s := models.Status(status)
+		switch s {
+		case models.StatusCreated, models.StatusDispatched, models.StatusAccepted:
+			statusFilter = &s
+		default:
+			logger.Warn("invalid transfer status")
+			return nil, errs.ErrInvalidTransferRequest
+		}
+	}
+
+	transfers, err := uc.repo.ListTransfers(ctx, uuidPvzID, statusFilter)
+	if err != nil {
+		logger.WithError(err).Error("failed to list transfers")
+		return nil, err
+	}
+
+	return transfers, nil
+}
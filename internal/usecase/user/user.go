@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	models "github.com/nik-mLb/avito_task/internal/models/user"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	authuc "github.com/nik-mLb/avito_task/internal/usecase/auth"
+)
+
+//go:generate mockgen -source=user.go -destination=../../repository/mocks/user_repository_mock.go -package=mocks UserRepository
+type UserRepository interface {
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) (*models.User, error)
+	SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.User, error)
+}
+
+type UserUsecase struct {
+	repo  UserRepository
+	roles authuc.RoleSet
+}
+
+func NewUserUsecase(repo UserRepository, roles authuc.RoleSet) *UserUsecase {
+	return &UserUsecase{repo: repo, roles: roles}
+}
+
+func (uc *UserUsecase) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	const op = "UserUsecase.ListUsers"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	filter.Query = strings.ToLower(strings.TrimSpace(filter.Query))
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("query", filter.Query).WithField("role", filter.Role)
+
+	if filter.Role != "" && !uc.roles.HasRole(filter.Role) {
+		logger.Warn("unknown role in filter")
+		return nil, errs.ErrRoleNotAllowed
+	}
+
+	users, err := uc.repo.ListUsers(ctx, filter)
+	if err != nil {
+		logger.WithError(err).Error("failed to list users")
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (uc *UserUsecase) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	const op = "UserUsecase.GetUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("user_id", userID)
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		logger.Warn("invalid user id")
+		return nil, errs.ErrInvalidUserID
+	}
+
+	user, err := uc.repo.GetUserByID(ctx, id)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get user")
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangeRole назначает пользователю роль. Его текущие токены отзываются, новая роль действует со следующего входа
+func (uc *UserUsecase) ChangeRole(ctx context.Context, actorID, userID, role string) (*models.User, error) {
+	const op = "UserUsecase.ChangeRole"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("actor_id", actorID).
+		WithField("user_id", userID).WithField("role", role)
+
+	id, err := uc.targetID(actorID, userID)
+	if err != nil {
+		logger.WithError(err).Warn("role change rejected")
+		return nil, err
+	}
+	if !uc.roles.HasRole(role) {
+		logger.Warn("role not allowed")
+		return nil, errs.ErrRoleNotAllowed
+	}
+
+	user, err := uc.repo.UpdateRole(ctx, id, role)
+	if err != nil {
+		logger.WithError(err).Warn("failed to change role")
+		return nil, err
+	}
+
+	logger.Info("user role changed")
+	return user, nil
+}
+
+// SetActive деактивирует или снова активирует пользователя. Деактивация отзывает его токены
+func (uc *UserUsecase) SetActive(ctx context.Context, actorID, userID string, active bool) (*models.User, error) {
+	const op = "UserUsecase.SetActive"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("actor_id", actorID).
+		WithField("user_id", userID).WithField("active", active)
+
+	id, err := uc.targetID(actorID, userID)
+	if err != nil {
+		logger.WithError(err).Warn("status change rejected")
+		return nil, err
+	}
+
+	user, err := uc.repo.SetActive(ctx, id, active)
+	if err != nil {
+		logger.WithError(err).Warn("failed to change user status")
+		return nil, err
+	}
+
+	logger.Info("user status changed")
+	return user, nil
+}
+
+// targetID разбирает id пользователя. Администратор не может менять себя,
+// чтобы случайно не остаться без доступа к админке
+func (uc *UserUsecase) targetID(actorID, userID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, errs.ErrInvalidUserID
+	}
+	if actor, err := uuid.Parse(actorID); err == nil && actor == id {
+		return uuid.Nil, errs.ErrSelfModification
+	}
+	return id, nil
+}
@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	errs "github.com/nik-mLb/avito_task/internal/models/errs"
+	feed "github.com/nik-mLb/avito_task/internal/models/feed"
+	history "github.com/nik-mLb/avito_task/internal/models/history"
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+	"github.com/nik-mLb/avito_task/internal/tracing"
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+)
+
+//go:generate mockgen -source=webhook.go -destination=../../repository/mocks/webhook_repository_mock.go -package=mocks WebhookRepository,WebhookSender
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub models.Subscription) (*models.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	EnqueueEvent(ctx context.Context, eventType string, pvzID uuid.UUID, payload []byte) (int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.Target, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	RetryDelivery(ctx context.Context, id int64, statusCode *int, lastError string, delay time.Duration) error
+	FailDelivery(ctx context.Context, id int64, statusCode *int, lastError string) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.Delivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (*models.Delivery, error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, target models.Target) (int, error)
+	CheckURL(u *url.URL) error
+}
+
+// DeliveryPolicy настройки повторных попыток доставки
+type DeliveryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	BatchSize   int
+	// Lease - на сколько доставка скрывается из очереди, пока идет отправка
+	Lease time.Duration
+}
+
+// deliveriesLimit - сколько последних доставок показывать в журнале подписки
+const deliveriesLimit = 100
+
+var webhookEventTypes = map[history.EventType]bool{
+	history.ReceptionOpened:     true,
+	history.ProductAdded:        true,
+	history.ProductDeleted:      true,
+	history.ReceptionClosed:     true,
+	history.ReceptionAutoClosed: true,
+	history.ReceptionReopened:   true,
+	history.TransferAccepted:    true,
+}
+
+// payload - тело запроса к партнеру
+type payload struct {
+	Event         history.EventType `json:"event"`
+	PickupPointID uuid.UUID         `json:"pvzId"`
+	ReceptionID   uuid.UUID         `json:"receptionId"`
+	ProductID     *uuid.UUID        `json:"productId,omitempty"`
+	ProductType   string            `json:"productType,omitempty"`
+	ActorID       *uuid.UUID        `json:"actorId,omitempty"`
+	Details       string            `json:"details,omitempty"`
+	OccurredAt    time.Time         `json:"occurredAt"`
+}
+
+type WebhookUsecase struct {
+	repo   WebhookRepository
+	sender WebhookSender
+	policy DeliveryPolicy
+}
+
+func NewWebhookUsecase(repo WebhookRepository, sender WebhookSender, policy DeliveryPolicy) *WebhookUsecase {
+	return &WebhookUsecase{
+		repo:   repo,
+		sender: sender,
+		policy: policy,
+	}
+}
+
+// CreateSubscription создает подписку. Если секрет не задан, он генерируется;
+// секрет возвращается только в ответе на создание
+func (uc *WebhookUsecase) CreateSubscription(ctx context.Context, userID, rawURL string, eventTypes []string, pvzID, secret string) (*models.Subscription, error) {
+	const op = "WebhookUsecase.CreateSubscription"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("url", rawURL).WithField("pvz_id", pvzID)
+
+	uuidUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid userID")
+		return nil, errs.ErrInvalidWebhook
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		logger.Warn("invalid webhook url")
+		return nil, errs.ErrInvalidWebhook
+	}
+	if err := uc.sender.CheckURL(u); err != nil {
+		logger.WithError(err).Warn("forbidden webhook destination")
+		return nil, errs.ErrInvalidWebhook
+	}
+
+	sub := models.Subscription{
+		ID:         uuid.New(),
+		URL:        u.String(),
+		EventTypes: make([]history.EventType, 0, len(eventTypes)),
+		Secret:     secret,
+		CreatedBy:  uuidUserID,
+	}
+
+	seen := make(map[history.EventType]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		eventType := history.EventType(t)
+		if !webhookEventTypes[eventType] {
+			logger.WithField("event_type", t).Warn("invalid event type")
+			return nil, errs.ErrInvalidWebhook
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			sub.EventTypes = append(sub.EventTypes, eventType)
+		}
+	}
+
+	if pvzID != "" {
+		uuidPvzID, err := uuid.Parse(pvzID)
+		if err != nil {
+			logger.WithError(err).Warn("invalid pvzID")
+			return nil, errs.ErrInvalidWebhook
+		}
+		sub.PickupPointID = &uuidPvzID
+	}
+
+	if sub.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			logger.WithError(err).Error("failed to generate secret")
+			return nil, err
+		}
+		sub.Secret = hex.EncodeToString(buf)
+	}
+
+	created, err := uc.repo.CreateSubscription(ctx, sub)
+	if err != nil {
+		logger.WithError(err).Error("failed to create subscription")
+		return nil, err
+	}
+	created.Secret = sub.Secret
+
+	return created, nil
+}
+
+func (uc *WebhookUsecase) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	const op = "WebhookUsecase.ListSubscriptions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	subs, err := uc.repo.ListSubscriptions(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed to list subscriptions")
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+func (uc *WebhookUsecase) DeleteSubscription(ctx context.Context, webhookID string) error {
+	const op = "WebhookUsecase.DeleteSubscription"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("webhook_id", webhookID)
+
+	id, err := uuid.Parse(webhookID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid webhookID")
+		return errs.ErrInvalidWebhook
+	}
+
+	if err := uc.repo.DeleteSubscription(ctx, id); err != nil {
+		logger.WithError(err).Error("failed to delete subscription")
+		return err
+	}
+
+	return nil
+}
+
+func (uc *WebhookUsecase) ListDeliveries(ctx context.Context, webhookID string) ([]models.Delivery, error) {
+	const op = "WebhookUsecase.ListDeliveries"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("webhook_id", webhookID)
+
+	id, err := uuid.Parse(webhookID)
+	if err != nil {
+		logger.WithError(err).Warn("invalid webhookID")
+		return nil, errs.ErrInvalidWebhook
+	}
+
+	deliveries, err := uc.repo.ListDeliveries(ctx, id, deliveriesLimit)
+	if err != nil {
+		logger.WithError(err).Error("failed to list deliveries")
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver ставит копию доставки в очередь на немедленную отправку
+func (uc *WebhookUsecase) Redeliver(ctx context.Context, deliveryID string) (*models.Delivery, error) {
+	const op = "WebhookUsecase.Redeliver"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).WithField("delivery_id", deliveryID)
+
+	id, err := strconv.ParseInt(deliveryID, 10, 64)
+	if err != nil || id <= 0 {
+		logger.Warn("invalid deliveryID")
+		return nil, errs.ErrInvalidWebhook
+	}
+
+	delivery, err := uc.repo.Redeliver(ctx, id)
+	if err != nil {
+		logger.WithError(err).Error("failed to redeliver")
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Handle ставит событие из outbox в очередь доставки подходящим подпискам.
+// При ошибке событие останется в outbox и будет передано повторно
+func (uc *WebhookUsecase) Handle(ctx context.Context, event feed.Event) error {
+	const op = "WebhookUsecase.Handle"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op).
+		WithField("event_type", event.Type).
+		WithField("pvz_id", event.PickupPointID)
+
+	occurredAt := event.At
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	body, err := json.Marshal(payload{
+		Event:         event.Type,
+		PickupPointID: event.PickupPointID,
+		ReceptionID:   event.ReceptionID,
+		ProductID:     event.ProductID,
+		ProductType:   event.ProductType,
+		ActorID:       event.ActorID,
+		Details:       event.Details,
+		OccurredAt:    occurredAt.UTC(),
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to marshal webhook payload")
+		return err
+	}
+
+	if _, err := uc.repo.EnqueueEvent(ctx, string(event.Type), event.PickupPointID, body); err != nil {
+		logger.WithError(err).Error("failed to enqueue webhook deliveries")
+		return err
+	}
+
+	return nil
+}
+
+// DeliverDue отправляет очередную порцию доставок и возвращает число успешных
+func (uc *WebhookUsecase) DeliverDue(ctx context.Context) (int, error) {
+	const op = "WebhookUsecase.DeliverDue"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+	logger := logctx.GetLogger(ctx).WithField("op", op)
+
+	targets, err := uc.repo.ClaimDueDeliveries(ctx, uc.policy.BatchSize, uc.policy.Lease)
+	if err != nil {
+		logger.WithError(err).Error("failed to claim deliveries")
+		return 0, err
+	}
+
+	delivered := 0
+	for _, target := range targets {
+		d := target.Delivery
+		entry := logger.WithField("delivery_id", d.ID).WithField("attempt", d.Attempts)
+
+		statusCode, sendErr := uc.sender.Send(ctx, target)
+		if sendErr == nil {
+			if err := uc.repo.MarkDelivered(ctx, d.ID, statusCode); err != nil {
+				entry.WithError(err).Error("failed to mark delivery as delivered")
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+
+		if d.Attempts >= uc.policy.MaxAttempts {
+			entry.WithError(sendErr).Warn("webhook delivery failed permanently")
+			if err := uc.repo.FailDelivery(ctx, d.ID, code, sendErr.Error()); err != nil {
+				entry.WithError(err).Error("failed to mark delivery as failed")
+				return delivered, err
+			}
+			continue
+		}
+
+		delay := uc.backoff(d.Attempts)
+		entry.WithError(sendErr).WithField("retry_in", delay).Warn("webhook delivery failed, will retry")
+		if err := uc.repo.RetryDelivery(ctx, d.ID, code, sendErr.Error(), delay); err != nil {
+			entry.WithError(err).Error("failed to schedule retry")
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+// backoff - экспоненциальная задержка перед попыткой attempts+1: base, 2*base, 4*base, ... не больше max
+func (uc *WebhookUsecase) backoff(attempts int) time.Duration {
+	delay := uc.policy.BackoffBase
+	for i := 1; i < attempts && delay < uc.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > uc.policy.BackoffMax {
+		return uc.policy.BackoffMax
+	}
+	return delay
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	models "github.com/nik-mLb/avito_task/internal/models/webhook"
+)
+
+// Заголовки запроса к партнеру
+const (
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// ErrForbiddenDestination адрес получателя указывает во внутреннюю сеть
+var ErrForbiddenDestination = errors.New("webhook destination is not a public address")
+
+// Диапазоны, которые не покрываются методами netip.Addr: shared address space (RFC 6598),
+// IETF protocol assignments, бенчмаркинг и зарезервированный класс E
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// Sender отправляет доставки вебхуков по HTTP
+type Sender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+// NewSender создает отправителя. Пока allowPrivate выключен, соединения с loopback, link-local
+// и частными адресами отклоняются в момент подключения, уже после резолва имени, поэтому
+// DNS-запись, указывающая во внутреннюю сеть, тоже не пройдет. Редиректы не выполняются:
+// ответ 3xx считается неудачной доставкой
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = checkDialAddress
+	}
+
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowPrivate: allowPrivate,
+	}
+}
+
+// CheckURL отклоняет адреса, которые заведомо ведут во внутреннюю сеть: localhost и
+// IP-литералы из закрытых диапазонов. Имена резолвятся только при отправке и проверяются там же
+func (s *Sender) CheckURL(u *url.URL) error {
+	if s.allowPrivate {
+		return nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenDestination
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return ErrForbiddenDestination
+	}
+
+	return nil
+}
+
+// checkDialAddress вызывается для каждого уже разрешенного адреса перед подключением
+func checkDialAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, address)
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, address)
+	}
+	return nil
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign возвращает подпись тела запроса: hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+// Метка времени входит в подпись, чтобы получатель мог отбросить повторно проигранные запросы
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send отправляет доставку и возвращает код ответа (0, если ответ не получен).
+// Успехом считается только ответ 2xx
+func (s *Sender) Send(ctx context.Context, target models.Target) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(target.Delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(target.Delivery.ID, 10))
+	req.Header.Set(HeaderEvent, target.Delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(target.Secret, timestamp, target.Delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/nik-mLb/avito_task/internal/transport/middleware/logctx"
+	"github.com/sirupsen/logrus"
+)
+
+// Job одна итерация фоновой задачи
+type Job func(ctx context.Context) error
+
+// Task описывает периодическую фоновую задачу
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Job      Job
+}
+
+// Run выполняет задачу с заданным интервалом до отмены контекста
+func Run(ctx context.Context, logger *logrus.Logger, task Task) {
+	entry := logrus.NewEntry(logger).WithField("worker", task.Name)
+	ctx = logctx.WithLogger(ctx, entry)
+
+	entry.WithField("interval", task.Interval).Info("worker started")
+
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			entry.Info("worker stopped")
+			return
+		case <-ticker.C:
+			if err := task.Job(ctx); err != nil {
+				entry.WithError(err).Error("worker iteration failed")
+			}
+		}
+	}
+}